@@ -9,8 +9,9 @@ import (
 
 func CORSConfig(e *echo.Echo) {
 	config := middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowMethods: []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete},
+		AllowOrigins:  []string{"*"},
+		AllowMethods:  []string{http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete},
+		ExposeHeaders: []string{"ETag", echo.HeaderXRequestID},
 	}
 	e.Use(middleware.CORSWithConfig(config))
 }
@@ -1,6 +1,9 @@
 package config
 
-import "os"
+import (
+	"os"
+	"time"
+)
 
 var (
 	ServerPort      = GetEnv("SERVER_PORT", "9000")
@@ -8,8 +11,38 @@ var (
 	MongoDatabase   = GetEnv("MONGODB_DATABASE", "movies_db")
 	JWTSecret       = GetEnv("JWT_SECRET", "R1BYcTVXVGNDU2JmWHVnZ1lnN0FKeGR3cU1RUU45QXV4SDJONFZ3ckhwS1N0ZjNCYVkzZ0F4RVBSS1UzRENwRw==")
 	JWTExpirationMs = GetEnv("JWT_EXPIRATION_MS", "86400000")
+
+	SoftDelete               = GetEnv("SOFT_DELETE", "true")
+	SoftDeleteRetentionHours = GetEnv("SOFT_DELETE_RETENTION_HOURS", "720")
+	PurgeIntervalMinutes     = GetEnv("PURGE_INTERVAL_MINUTES", "60")
+
+	CountryDeletePolicy = GetEnv("COUNTRY_DELETE_POLICY", "restrict")
+	StateDeletePolicy   = GetEnv("STATE_DELETE_POLICY", "restrict")
+	CityDeletePolicy    = GetEnv("CITY_DELETE_POLICY", "restrict")
+	CinemaDeletePolicy  = GetEnv("CINEMA_DELETE_POLICY", "restrict")
+
+	DefaultTimeZone    = GetEnv("DEFAULT_TIME_ZONE", "UTC")
+	NowShowingDays     = GetEnv("NOW_SHOWING_DAYS", "7")
+	NearbyRadiusMeters = GetEnv("NEARBY_RADIUS_METERS", "10000")
+	CalendarDays       = GetEnv("CALENDAR_DAYS", "30")
+
+	ReviewReportThreshold = GetEnv("REVIEW_REPORT_THRESHOLD", "5")
+
+	DefaultLanguage = GetEnv("DEFAULT_LANGUAGE", "en")
+	ErrorFormat     = GetEnv("ERROR_FORMAT", "problem")
+	ProblemTypeBase = GetEnv("PROBLEM_TYPE_BASE", "urn:cinema-backend:problem:")
 )
 
+// DefaultLocation is the location of DEFAULT_TIME_ZONE, the time zone of the
+// cities that have none, or UTC when it is not a valid time zone.
+func DefaultLocation() *time.Location {
+	location, err := time.LoadLocation(DefaultTimeZone)
+	if err != nil || DefaultTimeZone == "Local" {
+		return time.UTC
+	}
+	return location
+}
+
 func GetEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
 	if len(value) == 0 {
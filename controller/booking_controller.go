@@ -0,0 +1,345 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/i18n"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/repository"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"github.com/labstack/echo/v4"
+)
+
+type BookingControllerInterface interface {
+	GetBookings(c echo.Context) error
+	GetBooking(c echo.Context) error
+	SaveBooking(c echo.Context) error
+	CancelBooking(c echo.Context) error
+	CheckInBooking(c echo.Context) error
+	GetBookingCalendar(c echo.Context) error
+	GetUserCalendar(c echo.Context) error
+}
+
+type BookingController struct {
+	bookingRepository repository.BookingRepository
+	userRepository    repository.UserRepository
+	roomRepository    repository.RoomRepository
+	cinemaRepository  repository.CinemaRepository
+	movieRepository   repository.MovieRepository
+}
+
+func NewBookingController(bookingRepository repository.BookingRepository, userRepository repository.UserRepository, roomRepository repository.RoomRepository, cinemaRepository repository.CinemaRepository, movieRepository repository.MovieRepository) *BookingController {
+	return &BookingController{
+		bookingRepository: bookingRepository,
+		userRepository:    userRepository,
+		roomRepository:    roomRepository,
+		cinemaRepository:  cinemaRepository,
+		movieRepository:   movieRepository,
+	}
+}
+
+// GetBookings godoc
+// @Summary Get your bookings
+// @Description Get the bookings of the signed-in user, the latest first
+// @Tags bookings
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(xml, json)
+// @Param limit query int false "size" minimum(1)
+// @Param after query string false "after"
+// @Param before query string false "before"
+// @Param skipCount query bool false "skipCount"
+// @Success 200 {object} model.PagedBooking
+// @Failure 400 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /bookings [get]
+// @Security ApiKeyAuth
+func (bookingController *BookingController) GetBookings(c echo.Context) error {
+	pagedBooking, err := bookingController.bookingRepository.GetUserBookings(c.Request().Context(), util.GetUserIdFromToken(c), latestFirst(c))
+	if err != nil {
+		return err
+	}
+	return util.Negotiate(c, http.StatusOK, pagedBooking)
+}
+
+// GetBooking godoc
+// @Summary Get a booking
+// @Description Get one of your bookings, or any booking as an admin
+// @Tags bookings
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Booking ID"
+// @Param If-None-Match header string false "ETag of the cached representation"
+// @Success 200 {object} model.Booking
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /bookings/{id} [get]
+// @Security ApiKeyAuth
+func (bookingController *BookingController) GetBooking(c echo.Context) error {
+	booking, err := bookingController.bookingRepository.GetBooking(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+	if err := requireOwnerOrAdmin(c, booking.UserId); err != nil {
+		return err
+	}
+
+	if util.NotModified(c, booking.Version) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return util.Negotiate(c, http.StatusOK, booking)
+}
+
+// SaveBooking godoc
+// @Summary Book seats
+// @Description Book free seats of a showtime that has not started yet
+// @Tags bookings
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param booking body model.BookingInput true "Showtime and seats, such as A1"
+// @Success 201 {object} model.Booking
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /bookings [post]
+// @Security ApiKeyAuth
+func (bookingController *BookingController) SaveBooking(c echo.Context) error {
+	payload := new(model.BookingInput)
+	if err := util.BindAndValidate(c, payload); err != nil {
+		return err
+	}
+
+	booking := &model.Booking{
+		BookingInput: payload,
+		UserId:       util.GetUserIdFromToken(c),
+		CreatedAt:    time.Now(),
+	}
+	createdBooking, err := bookingController.bookingRepository.SaveBooking(c.Request().Context(), booking)
+	if err != nil {
+		return err
+	}
+
+	util.SetETag(c, createdBooking.Version)
+	return util.Negotiate(c, http.StatusCreated, createdBooking)
+}
+
+// CancelBooking godoc
+// @Summary Cancel a booking
+// @Description Cancel one of your bookings, or any booking as an admin, freeing its seats. Bookings checked in or of showtimes that started cannot be cancelled
+// @Tags bookings
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Booking ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Success 204 {object} model.Booking
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /bookings/{id} [delete]
+// @Security ApiKeyAuth
+func (bookingController *BookingController) CancelBooking(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	booking, err := bookingController.bookingRepository.GetBooking(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := requireOwnerOrAdmin(c, booking.UserId); err != nil {
+		return err
+	}
+
+	if err := bookingController.bookingRepository.CancelBooking(ctx, id); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CheckInBooking godoc
+// @Summary Check a booking in
+// @Description Record that the user of a booking arrived at the cinema, which lets them review the movie. Only admins check bookings in
+// @Tags bookings
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Booking ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Success 200 {object} model.Booking
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /bookings/{id}/check-in [post]
+// @Security ApiKeyAuth
+func (bookingController *BookingController) CheckInBooking(c echo.Context) error {
+	booking, err := bookingController.bookingRepository.CheckIn(c.Request().Context(), c.Param("id"), time.Now())
+	if err != nil {
+		return err
+	}
+
+	util.SetETag(c, booking.Version)
+	return util.Negotiate(c, http.StatusOK, booking)
+}
+
+// GetBookingCalendar godoc
+// @Summary Get a booking as a calendar event
+// @Description Get one of your bookings, or any booking as an admin, as an iCalendar event with the movie title, the room, the seats and the location of the cinema
+// @Tags bookings
+// @Produce text/calendar
+// @Param id path string true "Booking ID"
+// @Param lang query string false "Locale of the title, instead of the Accept-Language one"
+// @Success 200 {string} string
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /bookings/{id}/calendar [get]
+// @Security ApiKeyAuth
+func (bookingController *BookingController) GetBookingCalendar(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	booking, err := bookingController.bookingRepository.GetBooking(ctx, c.Param("id"))
+	if err != nil {
+		return err
+	}
+	if err := requireOwnerOrAdmin(c, booking.UserId); err != nil {
+		return err
+	}
+
+	event, err := bookingController.newBookingEvents(c).event(ctx, booking)
+	if err != nil {
+		return err
+	}
+	calendar := &model.Calendar{Name: event.Summary, Events: []model.CalendarEvent{*event}}
+	return util.Calendar(c, http.StatusOK, calendar)
+}
+
+// GetUserCalendar godoc
+// @Summary Get the upcoming bookings of a user as a calendar
+// @Description Get the bookings of the user of a calendar token for showtimes that have not started as an iCalendar feed, for calendar applications to subscribe to. The secret token stands for the credentials of the user, so the feed needs no authentication.
+// @Tags bookings
+// @Produce text/calendar
+// @Param token path string true "Calendar token"
+// @Param lang query string false "Locale of the titles, instead of the Accept-Language one"
+// @Success 200 {string} string
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /calendar/{token} [get]
+func (bookingController *BookingController) GetUserCalendar(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := bookingController.userRepository.FindByCalendarToken(ctx, c.Param("token"))
+	if err != nil {
+		return err
+	}
+	bookings, err := bookingController.bookingRepository.GetUpcomingBookings(ctx, user.ID.Hex(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	events := bookingController.newBookingEvents(c)
+	calendar := &model.Calendar{
+		Name: i18n.NewMessage("calendar.bookings", user.Name+" "+user.Lastname).Translate(events.language),
+	}
+	for i := range bookings {
+		event, err := events.event(ctx, &bookings[i])
+		// Bookings of deleted movies are left out of the feed.
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		calendar.Events = append(calendar.Events, *event)
+	}
+	return util.Calendar(c, http.StatusOK, calendar)
+}
+
+// bookingEvents writes bookings as calendar events, reading each movie, room
+// and cinema once for the whole feed.
+type bookingEvents struct {
+	controller *BookingController
+	locales    []string
+	language   string
+	movies     map[string]*model.Movie
+	rooms      map[string]*model.Room
+	cinemas    map[string]*model.Cinema
+}
+
+func (bookingController *BookingController) newBookingEvents(c echo.Context) *bookingEvents {
+	// Calendar applications send no Accept-Language, so lang also sets the
+	// language of the texts.
+	language := util.Language(c)
+	if lang := c.QueryParam("lang"); len(lang) > 0 {
+		language = i18n.Language(lang)
+	}
+	return &bookingEvents{
+		controller: bookingController,
+		locales:    util.Locales(c),
+		language:   language,
+		movies:     map[string]*model.Movie{},
+		rooms:      map[string]*model.Room{},
+		cinemas:    map[string]*model.Cinema{},
+	}
+}
+
+// event is the showtime of booking, with the movie title, the room and its
+// seats, and the cinema. A booking whose room or cinema was deleted keeps its
+// event, without them.
+func (events *bookingEvents) event(ctx context.Context, booking *model.Booking) (*model.CalendarEvent, error) {
+	movie, found := events.movies[booking.MovieId]
+	if !found {
+		var err error
+		if movie, err = events.controller.movieRepository.GetMovie(ctx, booking.MovieId); err != nil {
+			return nil, err
+		}
+		util.LocalizeMovie(movie, events.locales)
+		events.movies[booking.MovieId] = movie
+	}
+	room, found := events.rooms[booking.RoomId]
+	if !found {
+		room, _ = events.controller.roomRepository.GetRoomById(ctx, booking.RoomId)
+		events.rooms[booking.RoomId] = room
+	}
+	var cinema *model.Cinema
+	if room != nil {
+		if cinema, found = events.cinemas[room.CinemaId]; !found {
+			cinema, _ = events.controller.cinemaRepository.GetCinemaById(ctx, room.CinemaId)
+			events.cinemas[room.CinemaId] = cinema
+		}
+	}
+
+	runtime := movie.Runtime
+	if runtime <= 0 {
+		runtime = defaultShowtimeMinutes
+	}
+	event := &model.CalendarEvent{
+		UID:         booking.ID.Hex() + "@cinema-backend",
+		Start:       booking.Date,
+		End:         booking.Date.Add(time.Duration(runtime) * time.Minute),
+		Summary:     movie.Title,
+		Description: i18n.NewMessage("calendar.seats", strings.Join(booking.Seats, ", ")).Translate(events.language),
+	}
+	if room != nil {
+		event.Description = fmt.Sprintf("%s (%s)\n%s", room.Name, room.Format, event.Description)
+	}
+	if cinema != nil {
+		event.Location = cinema.Name
+		if len(cinema.Address) > 0 {
+			event.Location += ", " + cinema.Address
+		}
+		event.Geo = cinema.Location
+	}
+	return event, nil
+}
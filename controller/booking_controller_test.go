@@ -0,0 +1,147 @@
+package controller_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+)
+
+func TestBookings(t *testing.T) {
+	server := newTestServer(t)
+	anaId, anaToken := server.signUp(t, "Ana", "ana@example.com")
+	_, leoToken := server.signUp(t, "Leo", "leo@example.com")
+	_, adminToken := server.admin(t)
+	movieId, scheduleId := server.saveShowtime(t, adminToken)
+	ctx := context.Background()
+
+	var booking model.Booking
+	recorder := server.do(t, http.MethodPost, "/bookings", anaToken, map[string]interface{}{"scheduleId": scheduleId, "seats": []string{"A1", "A2"}})
+	decode(t, recorder, http.StatusCreated, &booking)
+	if booking.UserId != anaId || booking.MovieId != movieId {
+		t.Errorf("the booking is of user %s for movie %s, want Ana for Alien", booking.UserId, booking.MovieId)
+	}
+	id := booking.ID.Hex()
+
+	recorder = server.do(t, http.MethodPost, "/bookings", leoToken, map[string]interface{}{"scheduleId": scheduleId, "seats": []string{"A2", "B1"}})
+	decode(t, recorder, http.StatusConflict, nil)
+	recorder = server.do(t, http.MethodPost, "/bookings", leoToken, map[string]interface{}{"scheduleId": scheduleId, "seats": []string{"C1"}})
+	decode(t, recorder, http.StatusBadRequest, nil)
+	recorder = server.do(t, http.MethodPost, "/bookings", leoToken, map[string]interface{}{"scheduleId": scheduleId, "seats": []string{}})
+	decode(t, recorder, http.StatusBadRequest, nil)
+
+	recorder = server.do(t, http.MethodGet, "/bookings/"+id, leoToken, nil)
+	decode(t, recorder, http.StatusForbidden, nil)
+	var bookings model.PagedBooking
+	decode(t, server.do(t, http.MethodGet, "/bookings", anaToken, nil), http.StatusOK, &bookings)
+	if len(bookings.Data) != 1 || bookings.Data[0].ID.Hex() != id {
+		t.Errorf("Ana has %d bookings, want hers", len(bookings.Data))
+	}
+
+	review := map[string]interface{}{"rating": 4, "text": "A classic"}
+	recorder = server.do(t, http.MethodPost, "/movies/"+movieId+"/reviews", anaToken, review)
+	decode(t, recorder, http.StatusForbidden, nil)
+
+	recorder = server.do(t, http.MethodPost, "/bookings/"+id+"/check-in", anaToken, nil)
+	decode(t, recorder, http.StatusForbidden, nil)
+	recorder = server.do(t, http.MethodPost, "/bookings/"+id+"/check-in", adminToken, nil)
+	decode(t, recorder, http.StatusOK, &booking)
+	if booking.CheckedInAt == nil {
+		t.Error("the booking was not checked in")
+	}
+	recorder = server.do(t, http.MethodPost, "/bookings/"+id+"/check-in", adminToken, nil)
+	decode(t, recorder, http.StatusConflict, nil)
+
+	recorder = server.do(t, http.MethodPost, "/movies/"+movieId+"/reviews", anaToken, review)
+	decode(t, recorder, http.StatusCreated, nil)
+	recorder = server.do(t, http.MethodPost, "/movies/"+movieId+"/reviews", anaToken, review)
+	decode(t, recorder, http.StatusConflict, nil)
+	recorder = server.do(t, http.MethodPost, "/movies/"+movieId+"/reviews", leoToken, review)
+	decode(t, recorder, http.StatusForbidden, nil)
+
+	recorder = server.do(t, http.MethodDelete, "/bookings/"+id, anaToken, nil)
+	decode(t, recorder, http.StatusConflict, nil)
+
+	leoBooking := server.save(t, "/bookings", leoToken, map[string]interface{}{"scheduleId": scheduleId, "seats": []string{"B1"}})
+	recorder = server.do(t, http.MethodDelete, "/bookings/"+leoBooking, anaToken, nil)
+	decode(t, recorder, http.StatusForbidden, nil)
+	recorder = server.do(t, http.MethodDelete, "/bookings/"+leoBooking, leoToken, nil)
+	decode(t, recorder, http.StatusNoContent, nil)
+	saved, err := server.Schedules.GetScheduleById(ctx, scheduleId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.SeatsEmpty) != 2 {
+		t.Errorf("the showtime has free seats %v, want B1 and B2", saved.SeatsEmpty)
+	}
+}
+
+func TestBookingCalendars(t *testing.T) {
+	server := newTestServer(t)
+	_, anaToken := server.signUp(t, "Ana", "ana@example.com")
+	_, leoToken := server.signUp(t, "Leo", "leo@example.com")
+	_, adminToken := server.admin(t)
+	_, scheduleId := server.saveShowtime(t, adminToken)
+	id := server.save(t, "/bookings", anaToken, map[string]interface{}{"scheduleId": scheduleId, "seats": []string{"A1", "A2"}})
+
+	recorder := server.do(t, http.MethodGet, "/bookings/"+id+"/calendar", leoToken, nil)
+	decode(t, recorder, http.StatusForbidden, nil)
+	recorder = server.do(t, http.MethodGet, "/bookings/"+id+"/calendar?lang=es", anaToken, nil)
+	decode(t, recorder, http.StatusOK, nil)
+	event := recorder.Body.String()
+	for _, want := range []string{"UID:" + id + "@cinema-backend", "SUMMARY:Alien", `Sala 1 (2D)\nAsientos: A1\, A2`, "LOCATION:Centro"} {
+		if !strings.Contains(event, want) {
+			t.Errorf("the event of the booking lacks %q: %q", want, event)
+		}
+	}
+
+	recorder = server.do(t, http.MethodPost, "/users/me/calendar-token", "", nil)
+	decode(t, recorder, http.StatusUnauthorized, nil)
+	var first, second model.CalendarToken
+	decode(t, server.do(t, http.MethodPost, "/users/me/calendar-token", anaToken, nil), http.StatusCreated, &first)
+	if !strings.HasSuffix(first.URL, "/calendar/"+first.Token) {
+		t.Errorf("the URL of the calendar is %s", first.URL)
+	}
+	recorder = server.do(t, http.MethodGet, "/calendar/"+first.Token, "", nil)
+	decode(t, recorder, http.StatusOK, nil)
+	if feed := recorder.Body.String(); !strings.Contains(feed, "X-WR-CALNAME:Bookings of Ana Test") || !strings.Contains(feed, "UID:"+id+"@cinema-backend") {
+		t.Errorf("the calendar of Ana is %q", feed)
+	}
+
+	decode(t, server.do(t, http.MethodPost, "/users/me/calendar-token", anaToken, nil), http.StatusCreated, &second)
+	recorder = server.do(t, http.MethodGet, "/calendar/"+first.Token, "", nil)
+	decode(t, recorder, http.StatusNotFound, nil)
+	recorder = server.do(t, http.MethodGet, "/calendar/"+second.Token, "", nil)
+	decode(t, recorder, http.StatusOK, nil)
+
+	recorder = server.do(t, http.MethodDelete, "/users/me/calendar-token", anaToken, nil)
+	decode(t, recorder, http.StatusNoContent, nil)
+	recorder = server.do(t, http.MethodGet, "/calendar/"+second.Token, "", nil)
+	decode(t, recorder, http.StatusNotFound, nil)
+}
+
+// saveShowtime saves the movie Alien and a showtime of it tomorrow in Sala 1
+// of the cinema Centro, with seats A1, A2, B1 and B2, and returns their ids.
+func (server *testServer) saveShowtime(t *testing.T, adminToken string) (string, string) {
+	t.Helper()
+	_, _, cityId := server.saveCity(t, adminToken)
+	movieId := server.save(t, "/movies", adminToken, map[string]interface{}{"title": "Alien", "format": "2D"})
+
+	ctx := context.Background()
+	cinema, err := server.Cinemas.SaveCinema(ctx, &model.Cinema{CinemaInput: &model.CinemaInput{Name: "Centro", CityId: cityId}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	room, err := server.Rooms.SaveRoom(ctx, &model.Room{RoomInput: &model.RoomInput{Name: "Sala 1", Capacity: "4", Format: "2D", CinemaId: cinema.ID.Hex(), SeatMap: []string{"XX", "XX"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule, err := server.Schedules.SaveSchedule(ctx, &model.Schedule{ScheduleInput: &model.ScheduleInput{RoomId: room.ID.Hex(), MovieId: movieId, Date: time.Now().Add(24 * time.Hour)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return movieId, schedule.ID.Hex()
+}
@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/config"
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/repository"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"github.com/labstack/echo/v4"
+)
+
+type CinemaControllerInterface interface {
+	GetAllCinemas(c echo.Context) error
+	GetCinema(c echo.Context) error
+	GetNearbyCinemas(c echo.Context) error
+}
+
+type CinemaController struct {
+	cinemaRepository repository.CinemaRepository
+	movieRepository  repository.MovieRepository
+}
+
+func NewCinemaController(cinemaRepository repository.CinemaRepository, movieRepository repository.MovieRepository) *CinemaController {
+	return &CinemaController{
+		cinemaRepository: cinemaRepository,
+		movieRepository:  movieRepository,
+	}
+}
+
+// GetAllCinemas godoc
+// @Summary Get all cinemas
+// @Description Get all cinema items
+// @Tags cinemas
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(xml, json)
+// @Param page query int false "page" minimum(1)
+// @Param limit query int false "size" minimum(1)
+// @Param pagination query string false "pagination" Enums(page, cursor)
+// @Param after query string false "after"
+// @Param before query string false "before"
+// @Param sort query string false "sort"
+// @Param skipCount query bool false "skipCount"
+// @Param fields query string false "fields"
+// @Param expand query string false "expand"
+// @Success 200 {array} model.Cinema
+// @Failure 500 {object} handler.Problem
+// @Router /cinemas [get]
+// @Security ApiKeyAuth
+func (cinemaController *CinemaController) GetAllCinemas(c echo.Context) error {
+	page, _ := strconv.ParseInt(c.QueryParam("page"), 10, 64)
+	limit, _ := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
+
+	if documentQuery, ok := util.GetDocumentQuery(c); ok {
+		pagedDocument, err := cinemaController.cinemaRepository.GetAllCinemaDocuments(c.Request().Context(), documentQuery)
+		if err != nil {
+			return err
+		}
+		return util.Negotiate(c, http.StatusOK, pagedDocument)
+	}
+
+	var pagedCinema *model.PagedCinema
+	var err error
+	if cursorQuery, ok := util.GetCursorQuery(c); ok {
+		pagedCinema, err = cinemaController.cinemaRepository.GetAllCinemasByCursor(c.Request().Context(), cursorQuery)
+	} else {
+		pagedCinema, err = cinemaController.cinemaRepository.GetAllCinemas(c.Request().Context(), page, limit)
+	}
+	if err != nil {
+		return err
+	}
+	return util.Negotiate(c, http.StatusOK, pagedCinema)
+}
+
+// GetCinema godoc
+// @Summary Get a cinema
+// @Description Get a cinema item
+// @Tags cinemas
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Cinema ID"
+// @Param If-None-Match header string false "ETag of the cached representation"
+// @Param fields query string false "fields"
+// @Param expand query string false "expand"
+// @Success 200 {object} model.Cinema
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /cinemas/{id} [get]
+// @Security ApiKeyAuth
+func (cinemaController *CinemaController) GetCinema(c echo.Context) error {
+	id := c.Param("id")
+
+	if documentQuery, ok := util.GetDocumentQuery(c); ok {
+		document, err := cinemaController.cinemaRepository.GetCinemaDocument(c.Request().Context(), id, documentQuery)
+		if err != nil {
+			return err
+		}
+		return util.Negotiate(c, http.StatusOK, document)
+	}
+
+	cinema, err := cinemaController.cinemaRepository.GetCinemaById(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if util.NotModified(c, cinema.Version) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return util.Negotiate(c, http.StatusOK, cinema)
+}
+
+// GetNearbyCinemas godoc
+// @Summary Get the cinemas near a point
+// @Description Get the cinemas within a radius of a point, the nearest first, with their distance in meters
+// @Tags cinemas
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(xml, json)
+// @Param lat query number true "Latitude" minimum(-90) maximum(90)
+// @Param lng query number true "Longitude" minimum(-180) maximum(180)
+// @Param radius query number false "Radius in meters, NEARBY_RADIUS_METERS by default"
+// @Param movieId query string false "Only the cinemas with upcoming showtimes of the movie"
+// @Param limit query int false "size" minimum(1)
+// @Success 200 {object} model.NearbyCinemaList
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /cinemas/nearby [get]
+// @Security ApiKeyAuth
+func (cinemaController *CinemaController) GetNearbyCinemas(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	latitude, err := getCoordinate(c, "lat", 90)
+	if err != nil {
+		return err
+	}
+	longitude, err := getCoordinate(c, "lng", 180)
+	if err != nil {
+		return err
+	}
+
+	radius, _ := strconv.ParseFloat(config.NearbyRadiusMeters, 64)
+	if param := c.QueryParam("radius"); len(param) > 0 {
+		radius, err = strconv.ParseFloat(param, 64)
+		if err != nil || radius <= 0 {
+			return exception.InvalidParameterException("radius", param)
+		}
+	}
+
+	movieId := c.QueryParam("movieId")
+	if len(movieId) > 0 {
+		if _, err := cinemaController.movieRepository.GetMovie(ctx, movieId); err != nil {
+			return err
+		}
+	}
+
+	limit, _ := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
+	nearbyCinemas, err := cinemaController.cinemaRepository.GetNearbyCinemas(ctx, &model.NearbyCinemaQuery{
+		Latitude:  latitude,
+		Longitude: longitude,
+		Radius:    radius,
+		MovieId:   movieId,
+		From:      time.Now(),
+		Limit:     limit,
+	})
+	if err != nil {
+		return err
+	}
+	return util.Negotiate(c, http.StatusOK, nearbyCinemas)
+}
+
+// getCoordinate reads the required query parameter name as a number of
+// degrees between -bound and bound.
+func getCoordinate(c echo.Context, name string, bound float64) (float64, error) {
+	param := c.QueryParam(name)
+	if len(param) == 0 {
+		return 0, exception.ParameterException(name)
+	}
+	degrees, err := strconv.ParseFloat(param, 64)
+	if err != nil || degrees < -bound || degrees > bound {
+		return 0, exception.InvalidParameterException(name, param)
+	}
+	return degrees, nil
+}
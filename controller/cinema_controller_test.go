@@ -0,0 +1,46 @@
+package controller_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+)
+
+func TestCinemas(t *testing.T) {
+	server := newTestServer(t)
+	_, token := server.signUp(t, "Ana", "ana@example.com")
+	_, _, cityId := server.saveCity(t, token)
+
+	cinema, err := server.Cinemas.SaveCinema(context.Background(), &model.Cinema{CinemaInput: &model.CinemaInput{
+		Name:     "Centro",
+		CityId:   cityId,
+		Location: model.NewGeoPoint(6.25, -75.57),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := cinema.ID.Hex()
+
+	var got model.Cinema
+	recorder := server.do(t, http.MethodGet, "/cinemas/"+id, token, nil)
+	decode(t, recorder, http.StatusOK, &got)
+	if got.Name != "Centro" || got.Location == nil || got.Location.Latitude() != 6.25 {
+		t.Errorf("got the cinema %s at %v", got.Name, got.Location)
+	}
+
+	var cinemas model.PagedCinema
+	recorder = server.do(t, http.MethodGet, "/cinemas", token, nil)
+	decode(t, recorder, http.StatusOK, &cinemas)
+	if len(cinemas.Data) != 1 {
+		t.Errorf("listed %d cinemas, want 1", len(cinemas.Data))
+	}
+
+	for _, target := range []string{"/cinemas/nearby?lng=-75.57", "/cinemas/nearby?lat=91&lng=-75.57", "/cinemas/nearby?lat=6.25&lng=-75.57&radius=0"} {
+		recorder = server.do(t, http.MethodGet, target, token, nil)
+		decode(t, recorder, http.StatusBadRequest, nil)
+	}
+	recorder = server.do(t, http.MethodGet, "/cinemas/nearby?lat=6.25&lng=-75.57&movieId=000000000000000000000000", token, nil)
+	decode(t, recorder, http.StatusNotFound, nil)
+}
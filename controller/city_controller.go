@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"context"
+	"github.com/cbuelvasc/cinema-backend/exception"
 	"net/http"
 	"strconv"
 	"time"
@@ -16,17 +18,22 @@ type CityControllerInterface interface {
 	GetCity(c echo.Context) error
 	SaveCity(c echo.Context) error
 	DeleteCity(c echo.Context) error
+	RestoreCity(c echo.Context) error
+	ImportCities(c echo.Context) error
+	ExportCities(c echo.Context) error
 }
 
 type CityController struct {
-	cityRepository  repository.CityRepository
-	stateRepository repository.StateRepository
+	cityRepository    repository.CityRepository
+	stateRepository   repository.StateRepository
+	countryRepository repository.CountryRepository
 }
 
-func NewCityController(cityRepository repository.CityRepository, stateRepository repository.StateRepository) *CityController {
+func NewCityController(cityRepository repository.CityRepository, stateRepository repository.StateRepository, countryRepository repository.CountryRepository) *CityController {
 	return &CityController{
-		cityRepository:  cityRepository,
-		stateRepository: stateRepository,
+		cityRepository:    cityRepository,
+		stateRepository:   stateRepository,
+		countryRepository: countryRepository,
 	}
 }
 
@@ -39,9 +46,17 @@ func NewCityController(cityRepository repository.CityRepository, stateRepository
 // @Param mediaType query string false "mediaType" Enums(xml, json)
 // @Param page query int false "page" minimum(1)
 // @Param limit query int false "size" minimum(1)
+// @Param pagination query string false "pagination" Enums(page, cursor)
+// @Param after query string false "after"
+// @Param before query string false "before"
+// @Param sort query string false "sort"
+// @Param skipCount query bool false "skipCount"
+// @Param fields query string false "fields"
+// @Param expand query string false "expand"
+// @Param includeDeleted query bool false "includeDeleted"
 // @Param stateId query string true "stateId"
 // @Success 200 {array} model.City
-// @Failure 500 {object} handler.APIError
+// @Failure 500 {object} handler.Problem
 // @Router /cities [get]
 // @Security ApiKeyAuth
 func (cityController *CityController) GetAllCities(c echo.Context) error {
@@ -55,7 +70,21 @@ func (cityController *CityController) GetAllCities(c echo.Context) error {
 		}
 	}
 
-	pagedCity, err := cityController.cityRepository.GetAllCities(c.Request().Context(), page, limit, stateId)
+	if documentQuery, ok := util.GetDocumentQuery(c); ok {
+		pagedDocument, err := cityController.cityRepository.GetAllCityDocuments(c.Request().Context(), documentQuery, stateId)
+		if err != nil {
+			return err
+		}
+		return util.Negotiate(c, http.StatusOK, pagedDocument)
+	}
+
+	var pagedCity *model.PagedCity
+	var err error
+	if cursorQuery, ok := util.GetCursorQuery(c); ok {
+		pagedCity, err = cityController.cityRepository.GetAllCitiesByCursor(c.Request().Context(), cursorQuery, stateId)
+	} else {
+		pagedCity, err = cityController.cityRepository.GetAllCities(c.Request().Context(), page, limit, stateId)
+	}
 	if err != nil {
 		return err
 	}
@@ -70,9 +99,13 @@ func (cityController *CityController) GetAllCities(c echo.Context) error {
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param id path string true "City ID"
+// @Param If-None-Match header string false "ETag of the cached representation"
+// @Param fields query string false "fields"
+// @Param expand query string false "expand"
+// @Param includeDeleted query bool false "includeDeleted"
 // @Success 200 {object} model.City
-// @Failure 404 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /cities/{id} [get]
 // @Security ApiKeyAuth
 func (cityController *CityController) GetCity(c echo.Context) error {
@@ -81,11 +114,22 @@ func (cityController *CityController) GetCity(c echo.Context) error {
 		id = util.GetUserIdFromToken(c)
 	}
 
+	if documentQuery, ok := util.GetDocumentQuery(c); ok {
+		document, err := cityController.cityRepository.GetCityDocument(c.Request().Context(), id, documentQuery)
+		if err != nil {
+			return err
+		}
+		return util.Negotiate(c, http.StatusOK, document)
+	}
+
 	city, err := cityController.cityRepository.GetCityById(c.Request().Context(), id)
 	if err != nil {
 		return err
 	}
 
+	if util.NotModified(c, city.Version) {
+		return c.NoContent(http.StatusNotModified)
+	}
 	return util.Negotiate(c, http.StatusOK, city)
 }
 
@@ -98,9 +142,9 @@ func (cityController *CityController) GetCity(c echo.Context) error {
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param City body model.CityInput true "New City"
 // @Success 200 {object} model.City
-// @Failure 400 {object} handler.APIError
-// @Failure 409 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 400 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /cities [post]
 // @Security ApiKeyAuth
 func (cityController *CityController) SaveCity(c echo.Context) error {
@@ -123,6 +167,7 @@ func (cityController *CityController) SaveCity(c echo.Context) error {
 		return err
 	}
 
+	util.SetETag(c, createdCity.Version)
 	return util.Negotiate(c, http.StatusCreated, createdCity)
 }
 
@@ -134,11 +179,13 @@ func (cityController *CityController) SaveCity(c echo.Context) error {
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param id path string true "City ID"
+// @Param If-Match header string false "ETag of the version being modified"
 // @Param city body model.CityInput true "City Info"
 // @Success 200 {object} model.City
-// @Failure 400 {object} handler.APIError
-// @Failure 404 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /cities/{id} [put]
 // @Security ApiKeyAuth
 func (cityController *CityController) UpdateCity(c echo.Context) error {
@@ -155,9 +202,58 @@ func (cityController *CityController) UpdateCity(c echo.Context) error {
 	if err != nil {
 		return err
 	}
+	util.SetETag(c, user.Version)
 	return util.Negotiate(c, http.StatusOK, user)
 }
 
+// PatchCity godoc
+// @Summary Patch a city
+// @Description Partially update a city item with a JSON Merge Patch or a JSON Patch
+// @Tags cities
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "City ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Param patch body object true "Merge patch or JSON patch"
+// @Success 200 {object} model.City
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 415 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /cities/{id} [patch]
+// @Security ApiKeyAuth
+func (cityController *CityController) PatchCity(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	current, err := cityController.cityRepository.GetCityById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	payload := new(model.CityInput)
+	if err := util.BindPatch(c, current.CityInput, payload); err != nil {
+		return err
+	}
+	payload.UpdatedAt = time.Now()
+
+	// The patch was computed on the version just read, so it must not be
+	// written over a newer one.
+	if _, ok := util.GetExpectedVersions(ctx); !ok {
+		ctx = util.WithExpectedVersion(ctx, current.Version)
+	}
+
+	city, err := cityController.cityRepository.PatchCity(ctx, id, &model.City{CityInput: payload})
+	if err != nil {
+		return err
+	}
+	util.SetETag(c, city.Version)
+	return util.Negotiate(c, http.StatusOK, city)
+}
+
 // DeleteCity godoc
 // @Summary Delete a city
 // @Description Delete a city item
@@ -166,23 +262,145 @@ func (cityController *CityController) UpdateCity(c echo.Context) error {
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param id path string true "City ID"
+// @Param If-Match header string false "ETag of the version being modified"
 // @Success 204 {object} model.City
-// @Failure 404 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 404 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /cities/{id} [delete]
 // @Security ApiKeyAuth
 func (cityController *CityController) DeleteCity(c echo.Context) error {
 	id := c.Param("id")
-	stateId := c.Param("stateId")
-
-	_, err := cityController.stateRepository.GetStateById(c.Request().Context(), stateId)
-	if err != nil {
-		return err
-	}
 
-	e := cityController.cityRepository.DeleteCity(c.Request().Context(), id, stateId)
+	e := cityController.cityRepository.DeleteCity(c.Request().Context(), id)
 	if e != nil {
 		return e
 	}
 	return c.NoContent(http.StatusNoContent)
 }
+
+// RestoreCity godoc
+// @Summary Restore a city
+// @Description Restore a deleted city item
+// @Tags cities
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "City ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Success 200 {object} model.City
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /cities/{id}/restore [post]
+// @Security ApiKeyAuth
+func (cityController *CityController) RestoreCity(c echo.Context) error {
+	id := c.Param("id")
+
+	city, err := cityController.cityRepository.RestoreCity(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	util.SetETag(c, city.Version)
+	return util.Negotiate(c, http.StatusOK, city)
+}
+
+var cityColumns = []string{"name", "state", "country"}
+
+// ImportCities godoc
+// @Summary Import cities
+// @Description Create the cities of CSV or NDJSON rows that do not exist yet, matched on name within their state, given by state and country name
+// @Tags cities
+// @Accept text/csv,application/x-ndjson
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param dryRun query bool false "Validate the rows without saving them"
+// @Param rows body string true "Rows with the columns name, state and country"
+// @Success 200 {object} model.ImportResult
+// @Failure 400 {object} handler.Problem
+// @Failure 403 {object} handler.Problem
+// @Failure 415 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /cities/import [post]
+// @Security ApiKeyAuth
+func (cityController *CityController) ImportCities(c echo.Context) error {
+	return importRows(c, cityColumns, func(ctx context.Context, row map[string]string, dryRun bool) (importAction, error) {
+		if len(row["state"]) == 0 || len(row["country"]) == 0 {
+			return 0, exception.InvalidRequestException("error.state_and_country_required")
+		}
+		country, err := cityController.countryRepository.GetCountryByName(ctx, row["country"])
+		if err != nil {
+			return 0, err
+		}
+		state, err := cityController.stateRepository.GetStateByName(ctx, country.ID.Hex(), row["state"])
+		if err != nil {
+			return 0, err
+		}
+
+		payload := &model.CityInput{Name: row["name"], StateId: state.ID.Hex()}
+		if err := c.Validate(payload); err != nil {
+			return 0, exception.ValidationException(err)
+		}
+
+		_, err = cityController.cityRepository.GetCityByName(ctx, payload.StateId, payload.Name)
+		if isNotFound(err) {
+			if dryRun {
+				return importCreated, nil
+			}
+			payload.CreatedAt = time.Now()
+			payload.UpdatedAt = time.Now()
+			_, err = cityController.cityRepository.SaveCity(ctx, &model.City{CityInput: payload})
+			return importCreated, err
+		}
+		if err != nil {
+			return 0, err
+		}
+		return importUnchanged, nil
+	})
+}
+
+// ExportCities godoc
+// @Summary Export cities
+// @Description Download every city as CSV or NDJSON, in the columns accepted by the import
+// @Tags cities
+// @Produce text/csv,application/x-ndjson
+// @Param format query string false "format" Enums(csv, ndjson)
+// @Success 200 {string} string
+// @Failure 400 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /cities/export [get]
+// @Security ApiKeyAuth
+func (cityController *CityController) ExportCities(c echo.Context) error {
+	states := map[string]*model.State{}
+	countries := map[string]string{}
+	return exportRows(c, "cities", cityColumns, func(ctx context.Context, writer util.RowWriter, query *model.CursorQuery) (string, error) {
+		pagedCity, err := cityController.cityRepository.GetAllCitiesByCursor(ctx, query, "")
+		if err != nil {
+			return "", err
+		}
+		for _, city := range pagedCity.Data {
+			state, ok := states[city.StateId]
+			if !ok {
+				state, err = cityController.stateRepository.GetStateById(ctx, city.StateId)
+				if err != nil && !isNotFound(err) {
+					return "", err
+				}
+				states[city.StateId] = state
+			}
+
+			var stateName, countryName string
+			if state != nil {
+				stateName = state.Name
+				if countryName, err = countryNameOf(ctx, cityController.countryRepository, countries, state.CountryId); err != nil {
+					return "", err
+				}
+			}
+			if err := writer.Write(city.Name, stateName, countryName); err != nil {
+				return "", err
+			}
+		}
+		return nextCursor(pagedCity.Cursor), nil
+	})
+}
@@ -0,0 +1,44 @@
+package controller_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+)
+
+func TestCities(t *testing.T) {
+	server := newTestServer(t)
+	_, token := server.signUp(t, "Ana", "ana@example.com")
+	_, stateId, cityId := server.saveCity(t, token)
+
+	recorder := server.do(t, http.MethodPost, "/cities", token, map[string]string{"name": "Rionegro", "stateId": stateId, "timeZone": "Bogota"})
+	decode(t, recorder, http.StatusBadRequest, nil)
+
+	var document map[string]interface{}
+	recorder = server.do(t, http.MethodGet, "/cities/"+cityId+"?fields=name", token, nil)
+	decode(t, recorder, http.StatusOK, &document)
+	if len(document) != 2 || document["name"] != "Medellín" {
+		t.Errorf("got the fields %v, want the id and name", document)
+	}
+
+	recorder = server.do(t, http.MethodGet, "/cities/"+cityId, token, nil)
+	decode(t, recorder, http.StatusOK, nil)
+	etag := recorder.Header().Get("ETag")
+	city := map[string]string{"name": "Medellín", "stateId": stateId, "timeZone": "America/Bogota"}
+	recorder = server.do(t, http.MethodPut, "/cities/"+cityId, token, city, "If-Match", etag)
+	decode(t, recorder, http.StatusOK, nil)
+	recorder = server.do(t, http.MethodPut, "/cities/"+cityId, token, city, "If-Match", etag)
+	decode(t, recorder, http.StatusPreconditionFailed, nil)
+
+	recorder = server.do(t, http.MethodDelete, "/states/"+stateId, token, nil)
+	decode(t, recorder, http.StatusConflict, nil)
+
+	_, err := server.Cinemas.SaveCinema(context.Background(), &model.Cinema{CinemaInput: &model.CinemaInput{Name: "Centro", CityId: cityId}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder = server.do(t, http.MethodDelete, "/cities/"+cityId, token, nil)
+	decode(t, recorder, http.StatusConflict, nil)
+}
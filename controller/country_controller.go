@@ -1,10 +1,12 @@
 package controller
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/cbuelvasc/cinema-backend/exception"
 	"github.com/cbuelvasc/cinema-backend/model"
 	"github.com/cbuelvasc/cinema-backend/repository"
 	"github.com/cbuelvasc/cinema-backend/util"
@@ -16,6 +18,9 @@ type CountryControllerInterface interface {
 	GetCountry(c echo.Context) error
 	SaveCountry(c echo.Context) error
 	DeleteCountry(c echo.Context) error
+	RestoreCountry(c echo.Context) error
+	ImportCountries(c echo.Context) error
+	ExportCountries(c echo.Context) error
 }
 
 type CountryController struct {
@@ -37,16 +42,38 @@ func NewCountryController(countryRepository repository.CountryRepository) *Count
 // @Param mediaType query string false "mediaType" Enums(xml, json)
 // @Param page query int false "page" minimum(1)
 // @Param limit query int false "size" minimum(1)
+// @Param pagination query string false "pagination" Enums(page, cursor)
+// @Param after query string false "after"
+// @Param before query string false "before"
+// @Param sort query string false "sort"
+// @Param skipCount query bool false "skipCount"
+// @Param fields query string false "fields"
+// @Param expand query string false "expand"
+// @Param includeDeleted query bool false "includeDeleted"
 // @Param countryId query string true "countryId"
 // @Success 200 {array} model.Country
-// @Failure 500 {object} handler.APIError
+// @Failure 500 {object} handler.Problem
 // @Router /countries [get]
 // @Security ApiKeyAuth
 func (countryController *CountryController) GetAllCountries(c echo.Context) error {
 	page, _ := strconv.ParseInt(c.QueryParam("page"), 10, 64)
 	limit, _ := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
 
-	pagedCountry, err := countryController.countryRepository.GetAllCountries(c.Request().Context(), page, limit)
+	if documentQuery, ok := util.GetDocumentQuery(c); ok {
+		pagedDocument, err := countryController.countryRepository.GetAllCountryDocuments(c.Request().Context(), documentQuery)
+		if err != nil {
+			return err
+		}
+		return util.Negotiate(c, http.StatusOK, pagedDocument)
+	}
+
+	var pagedCountry *model.PagedCountry
+	var err error
+	if cursorQuery, ok := util.GetCursorQuery(c); ok {
+		pagedCountry, err = countryController.countryRepository.GetAllCountriesByCursor(c.Request().Context(), cursorQuery)
+	} else {
+		pagedCountry, err = countryController.countryRepository.GetAllCountries(c.Request().Context(), page, limit)
+	}
 	if err != nil {
 		return err
 	}
@@ -61,9 +88,13 @@ func (countryController *CountryController) GetAllCountries(c echo.Context) erro
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param id path string true "Country ID"
+// @Param If-None-Match header string false "ETag of the cached representation"
+// @Param fields query string false "fields"
+// @Param expand query string false "expand"
+// @Param includeDeleted query bool false "includeDeleted"
 // @Success 200 {object} model.Country
-// @Failure 404 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /countries/{id} [get]
 // @Security ApiKeyAuth
 func (countryController *CountryController) GetCountry(c echo.Context) error {
@@ -72,11 +103,22 @@ func (countryController *CountryController) GetCountry(c echo.Context) error {
 		id = util.GetUserIdFromToken(c)
 	}
 
+	if documentQuery, ok := util.GetDocumentQuery(c); ok {
+		document, err := countryController.countryRepository.GetCountryDocument(c.Request().Context(), id, documentQuery)
+		if err != nil {
+			return err
+		}
+		return util.Negotiate(c, http.StatusOK, document)
+	}
+
 	country, err := countryController.countryRepository.GetCountryById(c.Request().Context(), id)
 	if err != nil {
 		return err
 	}
 
+	if util.NotModified(c, country.Version) {
+		return c.NoContent(http.StatusNotModified)
+	}
 	return util.Negotiate(c, http.StatusOK, country)
 }
 
@@ -89,9 +131,9 @@ func (countryController *CountryController) GetCountry(c echo.Context) error {
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param country body model.CountryInput true "New country"
 // @Success 200 {object} model.Country
-// @Failure 400 {object} handler.APIError
-// @Failure 409 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 400 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /countries [post]
 // @Security ApiKeyAuth
 func (countryController *CountryController) SaveCountry(c echo.Context) error {
@@ -109,6 +151,7 @@ func (countryController *CountryController) SaveCountry(c echo.Context) error {
 		return err
 	}
 
+	util.SetETag(c, createdCountry.Version)
 	return util.Negotiate(c, http.StatusCreated, createdCountry)
 }
 
@@ -120,11 +163,13 @@ func (countryController *CountryController) SaveCountry(c echo.Context) error {
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param id path string true "Country ID"
+// @Param If-Match header string false "ETag of the version being modified"
 // @Param country body model.CountryInput true "Country Info"
 // @Success 200 {object} model.Country
-// @Failure 400 {object} handler.APIError
-// @Failure 404 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /countries/{id} [put]
 // @Security ApiKeyAuth
 func (countryController *CountryController) UpdateCountry(c echo.Context) error {
@@ -141,6 +186,55 @@ func (countryController *CountryController) UpdateCountry(c echo.Context) error
 	if err != nil {
 		return err
 	}
+	util.SetETag(c, country.Version)
+	return util.Negotiate(c, http.StatusOK, country)
+}
+
+// PatchCountry godoc
+// @Summary Patch a country
+// @Description Partially update a country item with a JSON Merge Patch or a JSON Patch
+// @Tags countries
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Country ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Param patch body object true "Merge patch or JSON patch"
+// @Success 200 {object} model.Country
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 415 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /countries/{id} [patch]
+// @Security ApiKeyAuth
+func (countryController *CountryController) PatchCountry(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	current, err := countryController.countryRepository.GetCountryById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	payload := new(model.CountryInput)
+	if err := util.BindPatch(c, current.CountryInput, payload); err != nil {
+		return err
+	}
+	payload.UpdatedAt = time.Now()
+
+	// The patch was computed on the version just read, so it must not be
+	// written over a newer one.
+	if _, ok := util.GetExpectedVersions(ctx); !ok {
+		ctx = util.WithExpectedVersion(ctx, current.Version)
+	}
+
+	country, err := countryController.countryRepository.PatchCountry(ctx, id, &model.Country{CountryInput: payload})
+	if err != nil {
+		return err
+	}
+	util.SetETag(c, country.Version)
 	return util.Negotiate(c, http.StatusOK, country)
 }
 
@@ -152,9 +246,11 @@ func (countryController *CountryController) UpdateCountry(c echo.Context) error
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param id path string true "Country ID"
+// @Param If-Match header string false "ETag of the version being modified"
 // @Success 204 {object} model.Country
-// @Failure 404 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /countries/{id} [delete]
 // @Security ApiKeyAuth
 func (countryController *CountryController) DeleteCountry(c echo.Context) error {
@@ -166,3 +262,98 @@ func (countryController *CountryController) DeleteCountry(c echo.Context) error
 	}
 	return c.NoContent(http.StatusNoContent)
 }
+
+// RestoreCountry godoc
+// @Summary Restore a country
+// @Description Restore a deleted country item
+// @Tags countries
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Country ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Success 200 {object} model.Country
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /countries/{id}/restore [post]
+// @Security ApiKeyAuth
+func (countryController *CountryController) RestoreCountry(c echo.Context) error {
+	id := c.Param("id")
+
+	country, err := countryController.countryRepository.RestoreCountry(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	util.SetETag(c, country.Version)
+	return util.Negotiate(c, http.StatusOK, country)
+}
+
+var countryColumns = []string{"name"}
+
+// ImportCountries godoc
+// @Summary Import countries
+// @Description Create the countries of CSV or NDJSON rows that do not exist yet, matched on name
+// @Tags countries
+// @Accept text/csv,application/x-ndjson
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param dryRun query bool false "Validate the rows without saving them"
+// @Param rows body string true "Rows with the column name"
+// @Success 200 {object} model.ImportResult
+// @Failure 400 {object} handler.Problem
+// @Failure 403 {object} handler.Problem
+// @Failure 415 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /countries/import [post]
+// @Security ApiKeyAuth
+func (countryController *CountryController) ImportCountries(c echo.Context) error {
+	return importRows(c, countryColumns, func(ctx context.Context, row map[string]string, dryRun bool) (importAction, error) {
+		payload := &model.CountryInput{Name: row["name"]}
+		if err := c.Validate(payload); err != nil {
+			return 0, exception.ValidationException(err)
+		}
+
+		_, err := countryController.countryRepository.GetCountryByName(ctx, payload.Name)
+		if isNotFound(err) {
+			if dryRun {
+				return importCreated, nil
+			}
+			payload.CreatedAt = time.Now()
+			payload.UpdatedAt = time.Now()
+			_, err = countryController.countryRepository.SaveCountry(ctx, &model.Country{CountryInput: payload})
+			return importCreated, err
+		}
+		if err != nil {
+			return 0, err
+		}
+		return importUnchanged, nil
+	})
+}
+
+// ExportCountries godoc
+// @Summary Export countries
+// @Description Download every country as CSV or NDJSON, in the columns accepted by the import
+// @Tags countries
+// @Produce text/csv,application/x-ndjson
+// @Param format query string false "format" Enums(csv, ndjson)
+// @Success 200 {string} string
+// @Failure 400 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /countries/export [get]
+// @Security ApiKeyAuth
+func (countryController *CountryController) ExportCountries(c echo.Context) error {
+	return exportRows(c, "countries", countryColumns, func(ctx context.Context, writer util.RowWriter, query *model.CursorQuery) (string, error) {
+		pagedCountry, err := countryController.countryRepository.GetAllCountriesByCursor(ctx, query)
+		if err != nil {
+			return "", err
+		}
+		for _, country := range pagedCountry.Data {
+			if err := writer.Write(country.Name); err != nil {
+				return "", err
+			}
+		}
+		return nextCursor(pagedCountry.Cursor), nil
+	})
+}
@@ -0,0 +1,64 @@
+package controller_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/util"
+)
+
+func TestCountries(t *testing.T) {
+	server := newTestServer(t)
+	_, token := server.signUp(t, "Ana", "ana@example.com")
+	_, adminToken := server.admin(t)
+
+	var country model.Country
+	recorder := server.do(t, http.MethodPost, "/countries", token, map[string]string{"name": "Colombia", "timeZone": "Mars/Olympus"})
+	decode(t, recorder, http.StatusBadRequest, nil)
+	recorder = server.do(t, http.MethodPost, "/countries", token, map[string]string{"name": "Colombia", "timeZone": "America/Bogota"})
+	decode(t, recorder, http.StatusCreated, &country)
+
+	rows := "name\nColombia\nPeru\n\"\"\n"
+	recorder = server.request(http.MethodPost, "/countries/import", token, util.MIMETextCSV, rows)
+	decode(t, recorder, http.StatusForbidden, nil)
+	var result model.ImportResult
+	recorder = server.request(http.MethodPost, "/countries/import", adminToken, util.MIMETextCSV, rows)
+	decode(t, recorder, http.StatusOK, &result)
+	if result.Created != 1 || result.Unchanged != 1 || result.Failed != 1 {
+		t.Errorf("imported %d, left %d and failed %d countries, want 1, 1 and 1", result.Created, result.Unchanged, result.Failed)
+	}
+
+	var countries model.PagedCountry
+	recorder = server.do(t, http.MethodGet, "/countries?page=1&limit=1", token, nil)
+	decode(t, recorder, http.StatusOK, &countries)
+	if len(countries.Data) != 1 || countries.PageInfo.Total != 2 || countries.PageInfo.TotalPage != 2 {
+		t.Errorf("listed %d of %d countries, want 1 of 2", len(countries.Data), countries.PageInfo.Total)
+	}
+
+	recorder = server.do(t, http.MethodGet, "/countries/export?format=csv", token, nil)
+	decode(t, recorder, http.StatusOK, nil)
+	if export := recorder.Body.String(); !strings.Contains(export, "Colombia") || !strings.Contains(export, "Peru") {
+		t.Errorf("exported %q", export)
+	}
+
+	id := country.ID.Hex()
+	recorder = server.do(t, http.MethodGet, "/countries/"+id, token, nil)
+	decode(t, recorder, http.StatusOK, nil)
+	recorder = server.do(t, http.MethodGet, "/countries/"+id, token, nil, "If-None-Match", recorder.Header().Get("ETag"))
+	decode(t, recorder, http.StatusNotModified, nil)
+
+	recorder = server.request(http.MethodPatch, "/countries/"+id, token, util.MIMEJSONPatch, `[{"op": "replace", "path": "/name", "value": "República de Colombia"}]`)
+	decode(t, recorder, http.StatusOK, &country)
+	if country.Name != "República de Colombia" {
+		t.Errorf("patched the country to %q", country.Name)
+	}
+
+	recorder = server.do(t, http.MethodDelete, "/countries/"+id, token, nil)
+	decode(t, recorder, http.StatusNoContent, nil)
+	recorder = server.do(t, http.MethodGet, "/countries/"+id+"?includeDeleted=true", token, nil)
+	decode(t, recorder, http.StatusForbidden, nil)
+	recorder = server.do(t, http.MethodGet, "/countries/"+id+"?includeDeleted=true", adminToken, nil)
+	decode(t, recorder, http.StatusOK, nil)
+}
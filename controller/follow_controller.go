@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/repository"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"github.com/labstack/echo/v4"
+)
+
+type FollowControllerInterface interface {
+	FollowUser(c echo.Context) error
+	UnfollowUser(c echo.Context) error
+	GetFollowers(c echo.Context) error
+	GetFollowing(c echo.Context) error
+}
+
+type FollowController struct {
+	followRepository repository.FollowRepository
+	userRepository   repository.UserRepository
+}
+
+func NewFollowController(followRepository repository.FollowRepository, userRepository repository.UserRepository) *FollowController {
+	return &FollowController{
+		followRepository: followRepository,
+		userRepository:   userRepository,
+	}
+}
+
+// FollowUser godoc
+// @Summary Follow a user
+// @Description Follow a user, to read their tweets in your home timeline
+// @Tags follows
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "User ID"
+// @Success 201 {object} model.Follow
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /users/{id}/follow [post]
+// @Security ApiKeyAuth
+func (followController *FollowController) FollowUser(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+	followerId := util.GetUserIdFromToken(c)
+	if id == followerId {
+		return exception.InvalidRequestException("error.follow_self")
+	}
+
+	if _, err := followController.userRepository.GetUser(ctx, id); err != nil {
+		return err
+	}
+
+	follow, err := followController.followRepository.Follow(ctx, followerId, id)
+	if err != nil {
+		return err
+	}
+	return util.Negotiate(c, http.StatusCreated, follow)
+}
+
+// UnfollowUser godoc
+// @Summary Unfollow a user
+// @Description Stop following a user
+// @Tags follows
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "User ID"
+// @Success 204 {object} model.Follow
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /users/{id}/follow [delete]
+// @Security ApiKeyAuth
+func (followController *FollowController) UnfollowUser(c echo.Context) error {
+	err := followController.followRepository.Unfollow(c.Request().Context(), util.GetUserIdFromToken(c), c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetFollowers godoc
+// @Summary Get the followers of a user
+// @Description Get the users following a user, the latest followers first
+// @Tags follows
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(xml, json)
+// @Param id path string true "User ID, or me"
+// @Param limit query int false "size" minimum(1)
+// @Param after query string false "after"
+// @Param before query string false "before"
+// @Param skipCount query bool false "skipCount"
+// @Success 200 {object} model.PagedUser
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /users/{id}/followers [get]
+// @Security ApiKeyAuth
+func (followController *FollowController) GetFollowers(c echo.Context) error {
+	return followController.getFollows(c, followController.followRepository.GetFollowers)
+}
+
+// GetFollowing godoc
+// @Summary Get the users a user follows
+// @Description Get the users a user follows, the latest followed first
+// @Tags follows
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(xml, json)
+// @Param id path string true "User ID, or me"
+// @Param limit query int false "size" minimum(1)
+// @Param after query string false "after"
+// @Param before query string false "before"
+// @Param skipCount query bool false "skipCount"
+// @Success 200 {object} model.PagedUser
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /users/{id}/following [get]
+// @Security ApiKeyAuth
+func (followController *FollowController) GetFollowing(c echo.Context) error {
+	return followController.getFollows(c, followController.followRepository.GetFollowing)
+}
+
+func (followController *FollowController) getFollows(c echo.Context, find func(ctx context.Context, userId string, query *model.CursorQuery) (*model.PagedUser, error)) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+	if id == "me" {
+		id = util.GetUserIdFromToken(c)
+	}
+
+	if _, err := followController.userRepository.GetUser(ctx, id); err != nil {
+		return err
+	}
+
+	pagedUser, err := find(ctx, id, latestFirst(c))
+	if err != nil {
+		return err
+	}
+	return util.Negotiate(c, http.StatusOK, pagedUser)
+}
+
+// latestFirst reads the cursor pagination parameters of a listing that is
+// always ordered by creation date, the latest first.
+func latestFirst(c echo.Context) *model.CursorQuery {
+	query, ok := util.GetCursorQuery(c)
+	if !ok {
+		limit, _ := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
+		skipCount, _ := strconv.ParseBool(c.QueryParam("skipCount"))
+		query = &model.CursorQuery{Limit: limit, SkipCount: skipCount}
+	}
+	query.Sort = "-created_at"
+	return query
+}
@@ -0,0 +1,49 @@
+package controller_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+)
+
+func TestFollows(t *testing.T) {
+	server := newTestServer(t)
+	anaId, anaToken := server.signUp(t, "Ana", "ana@example.com")
+	leoId, leoToken := server.signUp(t, "Leo", "leo@example.com")
+
+	recorder := server.do(t, http.MethodPost, "/users/"+leoId+"/follow", leoToken, nil)
+	decode(t, recorder, http.StatusBadRequest, nil)
+	recorder = server.do(t, http.MethodPost, "/users/000000000000000000000000/follow", leoToken, nil)
+	decode(t, recorder, http.StatusNotFound, nil)
+
+	recorder = server.do(t, http.MethodPost, "/users/"+anaId+"/follow", leoToken, nil)
+	decode(t, recorder, http.StatusCreated, nil)
+	recorder = server.do(t, http.MethodPost, "/users/"+anaId+"/follow", leoToken, nil)
+	decode(t, recorder, http.StatusConflict, nil)
+
+	var users model.PagedUser
+	recorder = server.do(t, http.MethodGet, "/users/"+anaId+"/followers", leoToken, nil)
+	decode(t, recorder, http.StatusOK, &users)
+	if len(users.Data) != 1 || users.Data[0].ID.Hex() != leoId {
+		t.Errorf("Ana has %d followers, want Leo", len(users.Data))
+	}
+	recorder = server.do(t, http.MethodGet, "/users/me/following", leoToken, nil)
+	decode(t, recorder, http.StatusOK, &users)
+	if len(users.Data) != 1 || users.Data[0].ID.Hex() != anaId {
+		t.Errorf("Leo follows %d users, want Ana", len(users.Data))
+	}
+
+	server.save(t, "/tweets", anaToken, map[string]string{"userId": anaId, "message": "Hello"})
+	var tweets model.PagedTweet
+	recorder = server.do(t, http.MethodGet, "/timeline", leoToken, nil)
+	decode(t, recorder, http.StatusOK, &tweets)
+	if len(tweets.Data) != 1 || tweets.Data[0].UserId != anaId {
+		t.Errorf("the timeline of Leo has %d tweets, want the tweet of Ana", len(tweets.Data))
+	}
+
+	recorder = server.do(t, http.MethodDelete, "/users/"+anaId+"/follow", leoToken, nil)
+	decode(t, recorder, http.StatusNoContent, nil)
+	recorder = server.do(t, http.MethodDelete, "/users/"+anaId+"/follow", leoToken, nil)
+	decode(t, recorder, http.StatusNotFound, nil)
+}
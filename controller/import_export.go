@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"github.com/labstack/echo/v4"
+)
+
+const exportPageSize int64 = 200
+
+type importAction int
+
+const (
+	importCreated importAction = iota
+	importUpdated
+	importUnchanged
+)
+
+// importRow upserts one row, or only checks it on a dry run, and tells what
+// it did.
+type importRow func(ctx context.Context, row map[string]string, dryRun bool) (importAction, error)
+
+// importRows imports every row of the CSV or NDJSON request body. A failing
+// row is reported and skipped, the others are still imported.
+func importRows(c echo.Context, columns []string, importRow importRow) error {
+	reader, err := util.NewRowReader(c, columns)
+	if err != nil {
+		return err
+	}
+	dryRun, _ := strconv.ParseBool(c.QueryParam("dryRun"))
+	language := util.Language(c)
+
+	// Every row is matched on its natural key: If-Match cannot apply.
+	ctx := util.WithoutExpectedVersion(c.Request().Context())
+
+	result := &model.ImportResult{DryRun: dryRun, Errors: []model.ImportError{}}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		result.Rows++
+
+		var action importAction
+		if err == nil {
+			action, err = importRow(ctx, row, dryRun)
+		}
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, model.ImportError{Row: result.Rows, Message: errorMessage(err, language)})
+			continue
+		}
+
+		switch action {
+		case importCreated:
+			result.Created++
+		case importUpdated:
+			result.Updated++
+		default:
+			result.Unchanged++
+		}
+	}
+	return util.Negotiate(c, http.StatusOK, result)
+}
+
+// exportPage writes one page of records and returns the cursor of the next
+// one, empty after the last page.
+type exportPage func(ctx context.Context, writer util.RowWriter, query *model.CursorQuery) (string, error)
+
+// exportRows streams a whole collection page by page.
+func exportRows(c echo.Context, name string, columns []string, exportPage exportPage) error {
+	writer, err := util.NewRowWriter(c, name, columns)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	query := &model.CursorQuery{Limit: exportPageSize, SkipCount: true}
+	for {
+		next, err := exportPage(ctx, writer, query)
+		if err != nil {
+			// The status is already sent: all that can be done is to cut the
+			// download short.
+			return err
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		query.After = next
+	}
+}
+
+// rowInt parses the number in column, 0 when empty.
+func rowInt(row map[string]string, column string) (int, error) {
+	if len(row[column]) == 0 {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(row[column])
+	if err != nil {
+		return 0, exception.InvalidRequestException("error.column_not_number", column, row[column])
+	}
+	return value, nil
+}
+
+func nextCursor(cursor *model.CursorInfo) string {
+	if cursor == nil || !cursor.HasNext {
+		return ""
+	}
+	return cursor.Next
+}
+
+func isNotFound(err error) bool {
+	he, ok := err.(*echo.HTTPError)
+	return ok && he.Code == http.StatusNotFound
+}
+
+func errorMessage(err error, language string) string {
+	if he, ok := err.(*echo.HTTPError); ok {
+		if message, ok := exception.Translate(he.Message, language); ok {
+			return message
+		}
+		return fmt.Sprint(he.Message)
+	}
+	return err.Error()
+}
@@ -0,0 +1,284 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/config"
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/repository"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"github.com/labstack/echo/v4"
+)
+
+type ListingControllerInterface interface {
+	GetNowShowing(c echo.Context) error
+	GetComingSoon(c echo.Context) error
+	GetCinemaCalendar(c echo.Context) error
+}
+
+// defaultShowtimeMinutes is the length of the calendar events of the movies
+// without a runtime.
+const defaultShowtimeMinutes = 120
+
+// calendarPageSize is the number of movies read at a time for the calendar
+// feeds.
+const calendarPageSize = 50
+
+type ListingController struct {
+	listingRepository repository.ListingRepository
+	cityRepository    repository.CityRepository
+	cinemaRepository  repository.CinemaRepository
+}
+
+func NewListingController(listingRepository repository.ListingRepository, cityRepository repository.CityRepository, cinemaRepository repository.CinemaRepository) *ListingController {
+	return &ListingController{
+		listingRepository: listingRepository,
+		cityRepository:    cityRepository,
+		cinemaRepository:  cinemaRepository,
+	}
+}
+
+// GetNowShowing godoc
+// @Summary Get the movies now showing
+// @Description Get the movies with upcoming showtimes in a city or cinema, grouped by movie and then cinema, with the times and formats of the showtimes in the local time of each cinema
+// @Tags listings
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(xml, json)
+// @Param cityId query string false "cityId"
+// @Param cinemaId query string false "cinemaId"
+// @Param from query string false "Start of the range, as a local YYYY-MM-DD or YYYY-MM-DDTHH:MM, or an RFC 3339 time, now by default"
+// @Param to query string false "End of the range, as a local YYYY-MM-DD or YYYY-MM-DDTHH:MM, or an RFC 3339 time, NOW_SHOWING_DAYS after the start by default"
+// @Param page query int false "page" minimum(1)
+// @Param limit query int false "size" minimum(1)
+// @Param lang query string false "Locale of the titles, instead of the Accept-Language one"
+// @Success 200 {object} model.NowShowingList
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /movies/now-showing [get]
+// @Security ApiKeyAuth
+func (listingController *ListingController) GetNowShowing(c echo.Context) error {
+	ctx := c.Request().Context()
+	page, _ := strconv.ParseInt(c.QueryParam("page"), 10, 64)
+	limit, _ := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
+	query := &model.ShowingQuery{
+		CityId:   c.QueryParam("cityId"),
+		CinemaId: c.QueryParam("cinemaId"),
+		Page:     page,
+		Limit:    limit,
+	}
+
+	// Days are calendar days of the city or cinema listed.
+	location := config.DefaultLocation()
+	if len(query.CityId) > 0 {
+		cityLocation, err := listingController.cityRepository.GetCityTimeZone(ctx, query.CityId)
+		if err != nil {
+			return err
+		}
+		location = cityLocation
+	}
+	if len(query.CinemaId) > 0 {
+		cinema, err := listingController.cinemaRepository.GetCinemaById(ctx, query.CinemaId)
+		if err != nil {
+			return err
+		}
+		// A cinema left without its city keeps the default time zone.
+		if cinemaLocation, err := listingController.cityRepository.GetCityTimeZone(ctx, cinema.CityId); err == nil {
+			location = cinemaLocation
+		}
+	}
+
+	// Showtimes already started are not on anymore.
+	now := time.Now().In(location)
+	from, ok, err := getListingTime(c, "from", false, location)
+	if err != nil {
+		return err
+	}
+	if !ok || from.Before(now) {
+		from = now
+	}
+	from = from.In(location)
+	to, ok, err := getListingTime(c, "to", true, location)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// AddDate keeps the time of day across daylight saving time changes.
+		days, _ := strconv.Atoi(config.NowShowingDays)
+		to = from.AddDate(0, 0, days)
+	}
+	if !to.After(from) {
+		return exception.InvalidParameterException("to", c.QueryParam("to"))
+	}
+	query.From, query.To = from, to.In(location)
+
+	nowShowing, err := listingController.listingRepository.GetNowShowing(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	locales := util.Locales(c)
+	for _, showing := range nowShowing.Data {
+		util.LocalizeMovie(showing.Movie, locales)
+	}
+	return util.Negotiate(c, http.StatusOK, nowShowing)
+}
+
+// GetComingSoon godoc
+// @Summary Get the movies coming soon
+// @Description Get the movies released from a date on that have no showtimes yet, the next releases first
+// @Tags listings
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(xml, json)
+// @Param from query string false "First release date, as YYYY-MM-DD, today by default"
+// @Param to query string false "Last release date, as YYYY-MM-DD"
+// @Param page query int false "page" minimum(1)
+// @Param limit query int false "size" minimum(1)
+// @Param lang query string false "Locale of the titles, instead of the Accept-Language one"
+// @Success 200 {object} model.PagedMovie
+// @Failure 400 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /movies/coming-soon [get]
+// @Security ApiKeyAuth
+func (listingController *ListingController) GetComingSoon(c echo.Context) error {
+	page, _ := strconv.ParseInt(c.QueryParam("page"), 10, 64)
+	limit, _ := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
+
+	now := time.Now().UTC()
+	query := &model.ComingSoonQuery{
+		From:  model.NewDate(now.Year(), now.Month(), now.Day()),
+		Page:  page,
+		Limit: limit,
+	}
+	if param := c.QueryParam("from"); len(param) > 0 {
+		from, err := model.ParseDate(param)
+		if err != nil {
+			return exception.InvalidParameterException("from", param)
+		}
+		query.From = from
+	}
+	if param := c.QueryParam("to"); len(param) > 0 {
+		to, err := model.ParseDate(param)
+		if err != nil {
+			return exception.InvalidParameterException("to", param)
+		}
+		query.To = &to
+	}
+
+	comingSoon, err := listingController.listingRepository.GetComingSoon(c.Request().Context(), query)
+	if err != nil {
+		return err
+	}
+
+	locales := util.Locales(c)
+	for i := range comingSoon.Data {
+		util.LocalizeMovie(&comingSoon.Data[i], locales)
+	}
+	return util.Negotiate(c, http.StatusOK, comingSoon)
+}
+
+// GetCinemaCalendar godoc
+// @Summary Get the programme of a cinema as a calendar
+// @Description Get the upcoming showtimes of a cinema as an iCalendar feed, for calendar applications to subscribe to. The feed needs no authentication.
+// @Tags listings
+// @Produce text/calendar
+// @Param id path string true "Cinema ID"
+// @Param lang query string false "Locale of the titles, instead of the Accept-Language one"
+// @Success 200 {string} string
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /cinemas/{id}/calendar [get]
+func (listingController *ListingController) GetCinemaCalendar(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	cinema, err := listingController.cinemaRepository.GetCinemaById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// The feed lists every showtime of the range, so it reads all the pages.
+	days, _ := strconv.Atoi(config.CalendarDays)
+	now := time.Now()
+	query := &model.ShowingQuery{
+		CinemaId: id,
+		From:     now,
+		To:       now.AddDate(0, 0, days),
+		Page:     1,
+		Limit:    calendarPageSize,
+	}
+	var showings []model.NowShowing
+	for {
+		nowShowing, err := listingController.listingRepository.GetNowShowing(ctx, query)
+		if err != nil {
+			return err
+		}
+		showings = append(showings, nowShowing.Data...)
+		if query.Page >= nowShowing.PageInfo.TotalPage {
+			break
+		}
+		query.Page++
+	}
+
+	calendar := &model.Calendar{Name: cinema.Name}
+	location := cinema.Name
+	if len(cinema.Address) > 0 {
+		location += ", " + cinema.Address
+	}
+	locales := util.Locales(c)
+	for _, showing := range showings {
+		util.LocalizeMovie(showing.Movie, locales)
+		runtime := showing.Movie.Runtime
+		if runtime <= 0 {
+			runtime = defaultShowtimeMinutes
+		}
+		for _, cinemaShowtimes := range showing.Cinemas {
+			calendar.TimeZone = cinemaShowtimes.TimeZone
+			for _, showtime := range cinemaShowtimes.Showtimes {
+				calendar.Events = append(calendar.Events, model.CalendarEvent{
+					UID:         showtime.ID + "@cinema-backend",
+					Start:       showtime.Date,
+					End:         showtime.Date.Add(time.Duration(runtime) * time.Minute),
+					Summary:     showing.Movie.Title,
+					Description: fmt.Sprintf("%s (%s)", showtime.Room, showtime.Format),
+					Location:    location,
+					Geo:         cinema.Location,
+				})
+			}
+		}
+	}
+	sort.SliceStable(calendar.Events, func(i, j int) bool {
+		return calendar.Events[i].Start.Before(calendar.Events[j].Start)
+	})
+
+	return util.Calendar(c, http.StatusOK, calendar)
+}
+
+// getListingTime reads the query parameter name as an RFC 3339 time, a time
+// without offset in location, or a date of location, which stands for the
+// first instant of the day, or of the next day when end is set so that the
+// whole day is included. It reports whether the parameter was given.
+func getListingTime(c echo.Context, name string, end bool, location *time.Location) (time.Time, bool, error) {
+	param := c.QueryParam(name)
+	if len(param) == 0 {
+		return time.Time{}, false, nil
+	}
+	if instant, err := model.ParseLocalTime(param, location); err == nil {
+		return instant, true, nil
+	}
+	date, err := model.ParseDate(param)
+	if err != nil {
+		return time.Time{}, false, exception.InvalidParameterException(name, param)
+	}
+	if end {
+		date = date.AddDays(1)
+	}
+	return date.In(location), true, nil
+}
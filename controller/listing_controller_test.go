@@ -0,0 +1,49 @@
+package controller_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+)
+
+func TestListings(t *testing.T) {
+	server := newTestServer(t)
+	_, token := server.signUp(t, "Ana", "ana@example.com")
+	_, _, cityId := server.saveCity(t, token)
+
+	for _, movie := range []map[string]interface{}{
+		{"title": "Dune", "format": "2D", "releaseYear": 2030, "releaseMonth": 3, "releaseDay": 1},
+		{"title": "Alien", "format": "2D", "releaseYear": 1979, "releaseMonth": 5, "releaseDay": 25},
+	} {
+		server.save(t, "/movies", token, movie)
+	}
+
+	var comingSoon model.PagedMovie
+	recorder := server.do(t, http.MethodGet, "/movies/coming-soon?from=2029-01-01", token, nil)
+	decode(t, recorder, http.StatusOK, &comingSoon)
+	if len(comingSoon.Data) != 1 || comingSoon.Data[0].Title != "Dune" {
+		t.Errorf("%d movies are coming soon, want Dune", len(comingSoon.Data))
+	}
+	recorder = server.do(t, http.MethodGet, "/movies/coming-soon?from=2029-02-30", token, nil)
+	decode(t, recorder, http.StatusBadRequest, nil)
+
+	recorder = server.do(t, http.MethodGet, "/movies/now-showing?cityId="+cityId, token, nil)
+	decode(t, recorder, http.StatusOK, nil)
+	recorder = server.do(t, http.MethodGet, "/movies/now-showing?cityId="+cityId+"&from=2030-01-02&to=2030-01-01", token, nil)
+	decode(t, recorder, http.StatusBadRequest, nil)
+	recorder = server.do(t, http.MethodGet, "/movies/now-showing?cityId=000000000000000000000000", token, nil)
+	decode(t, recorder, http.StatusNotFound, nil)
+
+	cinema, err := server.Cinemas.SaveCinema(context.Background(), &model.Cinema{CinemaInput: &model.CinemaInput{Name: "Centro", CityId: cityId}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder = server.do(t, http.MethodGet, "/cinemas/"+cinema.ID.Hex()+"/calendar", "", nil)
+	decode(t, recorder, http.StatusOK, nil)
+	if calendar := recorder.Body.String(); !strings.HasPrefix(calendar, "BEGIN:VCALENDAR") || !strings.Contains(calendar, "Centro") {
+		t.Errorf("the calendar of the cinema is %q", calendar)
+	}
+}
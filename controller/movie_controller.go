@@ -1,9 +1,13 @@
 package controller
 
 import (
+	"context"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/i18n"
 	"github.com/cbuelvasc/cinema-backend/model"
 	"github.com/cbuelvasc/cinema-backend/repository"
 	"github.com/cbuelvasc/cinema-backend/util"
@@ -13,19 +17,28 @@ import (
 type MovieControllerInterface interface {
 	GetAllMovie(c echo.Context) error
 	GetMovie(c echo.Context) error
+	SearchMovies(c echo.Context) error
 	SaveMovie(c echo.Context) error
 	DeleteMovie(c echo.Context) error
+	RestoreMovie(c echo.Context) error
+	ImportMovies(c echo.Context) error
+	ExportMovies(c echo.Context) error
+	GetMovieTranslations(c echo.Context) error
+	SaveMovieTranslation(c echo.Context) error
+	DeleteMovieTranslation(c echo.Context) error
 }
 
 type MovieController struct {
-	movieRepository repository.MovieRepository
-	userRepository  repository.UserRepository
+	movieRepository       repository.MovieRepository
+	movieSearchRepository repository.MovieSearchRepository
+	userRepository        repository.UserRepository
 }
 
-func NewMovieController(movieRepository repository.MovieRepository, userRepository repository.UserRepository) *MovieController {
+func NewMovieController(movieRepository repository.MovieRepository, movieSearchRepository repository.MovieSearchRepository, userRepository repository.UserRepository) *MovieController {
 	return &MovieController{
-		movieRepository: movieRepository,
-		userRepository:  userRepository,
+		movieRepository:       movieRepository,
+		movieSearchRepository: movieSearchRepository,
+		userRepository:        userRepository,
 	}
 }
 
@@ -38,21 +51,159 @@ func NewMovieController(movieRepository repository.MovieRepository, userReposito
 // @Param mediaType query string false "mediaType" Enums(xml, json)
 // @Param page query int false "page" minimum(1)
 // @Param limit query int false "size" minimum(1)
+// @Param pagination query string false "pagination" Enums(page, cursor)
+// @Param after query string false "after"
+// @Param before query string false "before"
+// @Param sort query string false "sort"
+// @Param skipCount query bool false "skipCount"
+// @Param fields query string false "fields"
+// @Param expand query string false "expand"
+// @Param includeDeleted query bool false "includeDeleted"
+// @Param lang query string false "Locale of the titles, instead of the Accept-Language one"
+// @Param format query string false "format"
+// @Param genre query string false "Comma separated genres, all of which the movies have"
+// @Param language query string false "Spoken language, such as es"
+// @Param subtitle query string false "Subtitle language, such as en"
+// @Param ratingCountry query string false "Country of the age rating, such as CO"
+// @Param rating query string false "Age rating, such as PG-13"
+// @Param cast query string false "Part of the name of a cast member"
+// @Param crew query string false "Part of the name of a crew member"
+// @Param crewJob query string false "Job of the crew member, such as Director"
+// @Param minRuntime query int false "Shortest runtime in minutes"
+// @Param maxRuntime query int false "Longest runtime in minutes"
+// @Param releaseYear query int false "releaseYear"
+// @Param releasedAfter query string false "First release date, as YYYY-MM-DD"
+// @Param releasedBefore query string false "Last release date, as YYYY-MM-DD"
 // @Success 200 {array} model.Movie
-// @Failure 500 {object} handler.APIError
+// @Failure 400 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /movies [get]
 // @Security ApiKeyAuth
 func (movieController *MovieController) GetAllMovie(c echo.Context) error {
 	page, _ := strconv.ParseInt(c.QueryParam("page"), 10, 64)
 	limit, _ := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
 
-	pagedMovie, err := movieController.movieRepository.GetAllMovies(c.Request().Context(), page, limit)
+	filter, err := getMovieFilter(c)
 	if err != nil {
 		return err
 	}
+
+	if documentQuery, ok := util.GetDocumentQuery(c); ok {
+		pagedDocument, err := movieController.movieRepository.GetAllMovieDocuments(c.Request().Context(), documentQuery, filter)
+		if err != nil {
+			return err
+		}
+		return util.Negotiate(c, http.StatusOK, pagedDocument)
+	}
+
+	var pagedMovie *model.PagedMovie
+	if cursorQuery, ok := util.GetCursorQuery(c); ok {
+		pagedMovie, err = movieController.movieRepository.GetAllMoviesByCursor(c.Request().Context(), cursorQuery, filter)
+	} else {
+		pagedMovie, err = movieController.movieRepository.GetAllMovies(c.Request().Context(), page, limit, filter)
+	}
+	if err != nil {
+		return err
+	}
+
+	locales := util.Locales(c)
+	for i := range pagedMovie.Data {
+		util.LocalizeMovie(&pagedMovie.Data[i], locales)
+	}
 	return util.Negotiate(c, http.StatusOK, pagedMovie)
 }
 
+// getMovieFilter reads the filters of the movie list from the query string.
+func getMovieFilter(c echo.Context) (*model.MovieFilter, error) {
+	filter := &model.MovieFilter{
+		Format:        c.QueryParam("format"),
+		Language:      c.QueryParam("language"),
+		Subtitle:      c.QueryParam("subtitle"),
+		RatingCountry: c.QueryParam("ratingCountry"),
+		Rating:        c.QueryParam("rating"),
+		Cast:          c.QueryParam("cast"),
+		Crew:          c.QueryParam("crew"),
+		CrewJob:       c.QueryParam("crewJob"),
+	}
+
+	for _, genre := range strings.Split(c.QueryParam("genre"), ",") {
+		if genre = strings.TrimSpace(genre); len(genre) > 0 {
+			filter.Genres = append(filter.Genres, genre)
+		}
+	}
+
+	for name, value := range map[string]*int{
+		"minRuntime":  &filter.MinRuntime,
+		"maxRuntime":  &filter.MaxRuntime,
+		"releaseYear": &filter.ReleaseYear,
+	} {
+		if param := c.QueryParam(name); len(param) > 0 {
+			number, err := strconv.Atoi(param)
+			if err != nil || number < 0 {
+				return nil, exception.InvalidParameterException(name, param)
+			}
+			*value = number
+		}
+	}
+
+	for name, value := range map[string]**model.Date{
+		"releasedAfter":  &filter.ReleasedAfter,
+		"releasedBefore": &filter.ReleasedBefore,
+	} {
+		if param := c.QueryParam(name); len(param) > 0 {
+			date, err := model.ParseDate(param)
+			if err != nil {
+				return nil, exception.InvalidParameterException(name, param)
+			}
+			*value = &date
+		}
+	}
+	return filter, nil
+}
+
+// SearchMovies godoc
+// @Summary Search movies
+// @Description Search movies by title in every locale, ranked by relevance. Partial words and small typos also match.
+// @Tags movies
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(xml, json)
+// @Param q query string true "Search text"
+// @Param format query string false "format"
+// @Param releaseYear query int false "releaseYear"
+// @Param page query int false "page" minimum(1)
+// @Param limit query int false "size" minimum(1)
+// @Param lang query string false "Locale of the titles, instead of the Accept-Language one"
+// @Success 200 {object} model.PagedMovieSearch
+// @Failure 400 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /movies/search [get]
+// @Security ApiKeyAuth
+func (movieController *MovieController) SearchMovies(c echo.Context) error {
+	page, _ := strconv.ParseInt(c.QueryParam("page"), 10, 64)
+	limit, _ := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
+	releaseYear, _ := strconv.Atoi(c.QueryParam("releaseYear"))
+
+	query := &model.MovieSearchQuery{
+		Text:        c.QueryParam("q"),
+		Format:      c.QueryParam("format"),
+		ReleaseYear: releaseYear,
+		Page:        page,
+		Limit:       limit,
+	}
+
+	pagedMovieSearch, err := movieController.movieSearchRepository.SearchMovies(c.Request().Context(), query)
+	if err != nil {
+		return err
+	}
+
+	locales := util.Locales(c)
+	for _, hit := range pagedMovieSearch.Data {
+		util.LocalizeMovie(hit.Movie, locales)
+	}
+	return util.Negotiate(c, http.StatusOK, pagedMovieSearch)
+}
+
 // GetMovie godoc
 // @Summary Get a movie
 // @Description Get a movie item
@@ -61,9 +212,14 @@ func (movieController *MovieController) GetAllMovie(c echo.Context) error {
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param id path string true "Movie ID"
+// @Param If-None-Match header string false "ETag of the cached representation"
+// @Param fields query string false "fields"
+// @Param expand query string false "expand"
+// @Param includeDeleted query bool false "includeDeleted"
+// @Param lang query string false "Locale of the title, instead of the Accept-Language one"
 // @Success 200 {object} model.Movie
-// @Failure 404 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /movies/{id} [get]
 // @Security ApiKeyAuth
 func (movieController *MovieController) GetMovie(c echo.Context) error {
@@ -72,11 +228,23 @@ func (movieController *MovieController) GetMovie(c echo.Context) error {
 		id = util.GetUserIdFromToken(c)
 	}
 
+	if documentQuery, ok := util.GetDocumentQuery(c); ok {
+		document, err := movieController.movieRepository.GetMovieDocument(c.Request().Context(), id, documentQuery)
+		if err != nil {
+			return err
+		}
+		return util.Negotiate(c, http.StatusOK, document)
+	}
+
 	movie, err := movieController.movieRepository.GetMovie(c.Request().Context(), id)
 	if err != nil {
 		return err
 	}
 
+	if util.NotModified(c, movie.Version) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	util.LocalizeMovie(movie, util.Locales(c))
 	return util.Negotiate(c, http.StatusOK, movie)
 }
 
@@ -89,9 +257,9 @@ func (movieController *MovieController) GetMovie(c echo.Context) error {
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param movie body model.MovieInput true "New movie"
 // @Success 200 {object} model.Movie
-// @Failure 400 {object} handler.APIError
-// @Failure 409 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 400 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /movies [post]
 // @Security ApiKeyAuth
 func (movieController *MovieController) SaveMovie(c echo.Context) error {
@@ -107,6 +275,8 @@ func (movieController *MovieController) SaveMovie(c echo.Context) error {
 		return err
 	}
 
+	util.SetETag(c, createdMovie.Version)
+	util.LocalizeMovie(createdMovie, util.Locales(c))
 	return util.Negotiate(c, http.StatusCreated, createdMovie)
 }
 
@@ -118,11 +288,13 @@ func (movieController *MovieController) SaveMovie(c echo.Context) error {
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param id path string true "Movie ID"
+// @Param If-Match header string false "ETag of the version being modified"
 // @Param movie body model.MovieInput true "Movie Info"
 // @Success 200 {object} model.Movie
-// @Failure 400 {object} handler.APIError
-// @Failure 404 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /movies/{id} [put]
 // @Security ApiKeyAuth
 func (movieController *MovieController) UpdateMovie(c echo.Context) error {
@@ -138,9 +310,70 @@ func (movieController *MovieController) UpdateMovie(c echo.Context) error {
 	if err != nil {
 		return err
 	}
+	util.SetETag(c, movie.Version)
+	util.LocalizeMovie(movie, util.Locales(c))
 	return util.Negotiate(c, http.StatusOK, movie)
 }
 
+// PatchMovie godoc
+// @Summary Patch a movie
+// @Description Partially update a movie item with a JSON Merge Patch or a JSON Patch
+// @Tags movies
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Movie ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Param patch body object true "Merge patch or JSON patch"
+// @Success 200 {object} model.Movie
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 415 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /movies/{id} [patch]
+// @Security ApiKeyAuth
+func (movieController *MovieController) PatchMovie(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	current, err := movieController.movieRepository.GetMovie(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	payload := new(model.MovieInput)
+	if err := util.BindPatch(c, current.MovieInput, payload); err != nil {
+		return err
+	}
+	// A patch of the release year, month or day moves the release date.
+	if sameDate(payload.ReleaseDate, current.ReleaseDate) && (payload.ReleaseYear != current.ReleaseYear || payload.ReleaseMonth != current.ReleaseMonth || payload.ReleaseDay != current.ReleaseDay) {
+		payload.ReleaseDate = nil
+	}
+
+	// The patch was computed on the version just read, so it must not be
+	// written over a newer one.
+	if _, ok := util.GetExpectedVersions(ctx); !ok {
+		ctx = util.WithExpectedVersion(ctx, current.Version)
+	}
+
+	movie, err := movieController.movieRepository.PatchMovie(ctx, id, &model.Movie{MovieInput: payload})
+	if err != nil {
+		return err
+	}
+	util.SetETag(c, movie.Version)
+	util.LocalizeMovie(movie, util.Locales(c))
+	return util.Negotiate(c, http.StatusOK, movie)
+}
+
+func sameDate(a *model.Date, b *model.Date) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Time().Equal(b.Time())
+}
+
 // DeleteMovie godoc
 // @Summary Delete a movie
 // @Description Delete a new movie item
@@ -149,9 +382,11 @@ func (movieController *MovieController) UpdateMovie(c echo.Context) error {
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param id path string true "Movie ID"
+// @Param If-Match header string false "ETag of the version being modified"
 // @Success 204 {object} model.Movie
-// @Failure 404 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /movies/{id} [delete]
 // @Security ApiKeyAuth
 func (movieController *MovieController) DeleteMovie(c echo.Context) error {
@@ -164,3 +399,221 @@ func (movieController *MovieController) DeleteMovie(c echo.Context) error {
 	}
 	return c.NoContent(http.StatusNoContent)
 }
+
+// RestoreMovie godoc
+// @Summary Restore a movie
+// @Description Restore a deleted movie item
+// @Tags movies
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Movie ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Success 200 {object} model.Movie
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /movies/{id}/restore [post]
+// @Security ApiKeyAuth
+func (movieController *MovieController) RestoreMovie(c echo.Context) error {
+	id := c.Param("id")
+
+	movie, err := movieController.movieRepository.RestoreMovie(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	util.SetETag(c, movie.Version)
+	util.LocalizeMovie(movie, util.Locales(c))
+	return util.Negotiate(c, http.StatusOK, movie)
+}
+
+var movieColumns = []string{"title", "format", "releaseYear", "releaseMonth", "releaseDay"}
+
+// ImportMovies godoc
+// @Summary Import movies
+// @Description Create or update movies from CSV or NDJSON rows, matched on title and release year
+// @Tags movies
+// @Accept text/csv,application/x-ndjson
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param dryRun query bool false "Validate the rows without saving them"
+// @Param rows body string true "Rows with the columns title, format, releaseYear, releaseMonth and releaseDay"
+// @Success 200 {object} model.ImportResult
+// @Failure 400 {object} handler.Problem
+// @Failure 403 {object} handler.Problem
+// @Failure 415 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /movies/import [post]
+// @Security ApiKeyAuth
+func (movieController *MovieController) ImportMovies(c echo.Context) error {
+	return importRows(c, movieColumns, func(ctx context.Context, row map[string]string, dryRun bool) (importAction, error) {
+		payload := &model.MovieInput{Title: row["title"], Format: row["format"]}
+		var err error
+		if payload.ReleaseYear, err = rowInt(row, "releaseYear"); err != nil {
+			return 0, err
+		}
+		if payload.ReleaseMonth, err = rowInt(row, "releaseMonth"); err != nil {
+			return 0, err
+		}
+		if payload.ReleaseDay, err = rowInt(row, "releaseDay"); err != nil {
+			return 0, err
+		}
+		if err := c.Validate(payload); err != nil {
+			return 0, exception.ValidationException(err)
+		}
+
+		existing, err := movieController.movieRepository.GetMovieByTitle(ctx, payload.Title, payload.ReleaseYear)
+		if isNotFound(err) {
+			if dryRun {
+				return importCreated, nil
+			}
+			_, err = movieController.movieRepository.SaveMovie(ctx, &model.Movie{MovieInput: payload})
+			return importCreated, err
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if existing.Format == payload.Format && existing.ReleaseMonth == payload.ReleaseMonth && existing.ReleaseDay == payload.ReleaseDay {
+			return importUnchanged, nil
+		}
+		if !dryRun {
+			_, err = movieController.movieRepository.UpdateMovie(ctx, existing.ID.Hex(), &model.Movie{MovieInput: payload})
+		}
+		return importUpdated, err
+	})
+}
+
+// ExportMovies godoc
+// @Summary Export movies
+// @Description Download the movies as CSV or NDJSON, in the columns accepted by the import. The filters of the movie list apply.
+// @Tags movies
+// @Produce text/csv,application/x-ndjson
+// @Param format query string false "format" Enums(csv, ndjson)
+// @Param genre query string false "Comma separated genres, all of which the movies have"
+// @Param releasedAfter query string false "First release date, as YYYY-MM-DD"
+// @Param releasedBefore query string false "Last release date, as YYYY-MM-DD"
+// @Success 200 {string} string
+// @Failure 400 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /movies/export [get]
+// @Security ApiKeyAuth
+func (movieController *MovieController) ExportMovies(c echo.Context) error {
+	filter, err := getMovieFilter(c)
+	if err != nil {
+		return err
+	}
+	// The format parameter names the format of the export here.
+	filter.Format = ""
+
+	return exportRows(c, "movies", movieColumns, func(ctx context.Context, writer util.RowWriter, query *model.CursorQuery) (string, error) {
+		pagedMovie, err := movieController.movieRepository.GetAllMoviesByCursor(ctx, query, filter)
+		if err != nil {
+			return "", err
+		}
+		for _, movie := range pagedMovie.Data {
+			if err := writer.Write(movie.Title, movie.Format, movie.ReleaseYear, movie.ReleaseMonth, movie.ReleaseDay); err != nil {
+				return "", err
+			}
+		}
+		return nextCursor(pagedMovie.Cursor), nil
+	})
+}
+
+// GetMovieTranslations godoc
+// @Summary Get the translations of a movie
+// @Description Get the title, synopsis and tagline of a movie in every locale it is translated to
+// @Tags movies
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Movie ID"
+// @Success 200 {array} model.MovieTranslation
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /movies/{id}/translations [get]
+// @Security ApiKeyAuth
+func (movieController *MovieController) GetMovieTranslations(c echo.Context) error {
+	id := c.Param("id")
+
+	movie, err := movieController.movieRepository.GetMovie(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	translations := movie.Translations
+	if translations == nil {
+		translations = []model.MovieTranslation{}
+	}
+	util.SetETag(c, movie.Version)
+	return util.Negotiate(c, http.StatusOK, translations)
+}
+
+// SaveMovieTranslation godoc
+// @Summary Translate a movie
+// @Description Create or replace the title, synopsis and tagline of a movie in a locale
+// @Tags movies
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Movie ID"
+// @Param locale path string true "Locale, such as es or es-CO"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Param translation body model.MovieTranslation true "Translation"
+// @Success 200 {array} model.MovieTranslation
+// @Failure 400 {object} handler.Problem
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /movies/{id}/translations/{locale} [put]
+// @Security ApiKeyAuth
+func (movieController *MovieController) SaveMovieTranslation(c echo.Context) error {
+	id := c.Param("id")
+	locale, ok := i18n.NormalizeLocale(c.Param("locale"))
+	if !ok {
+		return exception.InvalidParameterException("locale", c.Param("locale"))
+	}
+
+	payload := new(model.MovieTranslation)
+	if err := util.BindAndValidate(c, payload); err != nil {
+		return err
+	}
+	payload.Locale = locale
+
+	movie, err := movieController.movieRepository.SaveMovieTranslation(c.Request().Context(), id, payload)
+	if err != nil {
+		return err
+	}
+	util.SetETag(c, movie.Version)
+	return util.Negotiate(c, http.StatusOK, movie.Translations)
+}
+
+// DeleteMovieTranslation godoc
+// @Summary Delete a translation of a movie
+// @Description Delete the title, synopsis and tagline of a movie in a locale
+// @Tags movies
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Movie ID"
+// @Param locale path string true "Locale, such as es or es-CO"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Success 204
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /movies/{id}/translations/{locale} [delete]
+// @Security ApiKeyAuth
+func (movieController *MovieController) DeleteMovieTranslation(c echo.Context) error {
+	id := c.Param("id")
+
+	movie, err := movieController.movieRepository.DeleteMovieTranslation(c.Request().Context(), id, c.Param("locale"))
+	if err != nil {
+		return err
+	}
+	util.SetETag(c, movie.Version)
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,59 @@
+package controller_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+)
+
+func TestMovies(t *testing.T) {
+	server := newTestServer(t)
+	_, token := server.signUp(t, "Ana", "ana@example.com")
+	_, adminToken := server.admin(t)
+
+	recorder := server.do(t, http.MethodPost, "/movies", token, map[string]interface{}{
+		"title": "Pan's Labyrinth", "format": "2D", "releaseYear": 2006, "releaseMonth": 2, "releaseDay": 30,
+	})
+	decode(t, recorder, http.StatusBadRequest, nil)
+
+	var movie model.Movie
+	recorder = server.do(t, http.MethodPost, "/movies", token, map[string]interface{}{
+		"title": "Pan's Labyrinth", "format": "2D", "releaseYear": 2006, "releaseMonth": 10, "releaseDay": 11,
+	})
+	decode(t, recorder, http.StatusCreated, &movie)
+	id := movie.ID.Hex()
+
+	translation := map[string]string{"title": "El Laberinto del Fauno"}
+	recorder = server.do(t, http.MethodPut, "/movies/"+id+"/translations/es", token, translation)
+	decode(t, recorder, http.StatusForbidden, nil)
+	recorder = server.do(t, http.MethodPut, "/movies/"+id+"/translations/es", adminToken, translation)
+	decode(t, recorder, http.StatusOK, nil)
+
+	recorder = server.do(t, http.MethodGet, "/movies/"+id, token, nil, "Accept-Language", "es-CO")
+	decode(t, recorder, http.StatusOK, &movie)
+	if movie.Title != "El Laberinto del Fauno" || movie.Locale != "es" {
+		t.Errorf("got the movie %q in %q, want it in es", movie.Title, movie.Locale)
+	}
+
+	var found model.PagedMovieSearch
+	recorder = server.do(t, http.MethodGet, "/movies/search?q=fauno", token, nil)
+	decode(t, recorder, http.StatusOK, &found)
+	if len(found.Data) != 1 || found.Data[0].ID.Hex() != id || found.PageInfo.HasNext {
+		t.Errorf("searching \"fauno\" found %d movies with a next page %t", len(found.Data), found.PageInfo.HasNext)
+	}
+	recorder = server.do(t, http.MethodGet, "/movies/search", token, nil)
+	decode(t, recorder, http.StatusBadRequest, nil)
+
+	recorder = server.do(t, http.MethodDelete, "/movies/"+id, token, nil)
+	decode(t, recorder, http.StatusNoContent, nil)
+	recorder = server.do(t, http.MethodGet, "/movies/"+id, token, nil)
+	decode(t, recorder, http.StatusNotFound, nil)
+
+	recorder = server.do(t, http.MethodPost, "/movies/"+id+"/restore", token, nil)
+	decode(t, recorder, http.StatusForbidden, nil)
+	recorder = server.do(t, http.MethodPost, "/movies/"+id+"/restore", adminToken, nil)
+	decode(t, recorder, http.StatusOK, nil)
+	recorder = server.do(t, http.MethodGet, "/movies/"+id, token, nil)
+	decode(t, recorder, http.StatusOK, nil)
+}
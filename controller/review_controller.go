@@ -0,0 +1,304 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/repository"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"github.com/labstack/echo/v4"
+)
+
+type ReviewControllerInterface interface {
+	GetMovieReviews(c echo.Context) error
+	GetReview(c echo.Context) error
+	SaveReview(c echo.Context) error
+	UpdateReview(c echo.Context) error
+	DeleteReview(c echo.Context) error
+	MarkReviewHelpful(c echo.Context) error
+	ReportReview(c echo.Context) error
+}
+
+type ReviewController struct {
+	reviewRepository  repository.ReviewRepository
+	movieRepository   repository.MovieRepository
+	bookingRepository repository.BookingRepository
+}
+
+func NewReviewController(reviewRepository repository.ReviewRepository, movieRepository repository.MovieRepository, bookingRepository repository.BookingRepository) *ReviewController {
+	return &ReviewController{
+		reviewRepository:  reviewRepository,
+		movieRepository:   movieRepository,
+		bookingRepository: bookingRepository,
+	}
+}
+
+// GetMovieReviews godoc
+// @Summary Get the reviews of a movie
+// @Description Get the reviews of a movie, the most helpful or the latest first
+// @Tags reviews
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(xml, json)
+// @Param id path string true "Movie ID"
+// @Param sort query string false "sort" Enums(helpful, date)
+// @Param page query int false "page" minimum(1)
+// @Param limit query int false "size" minimum(1)
+// @Success 200 {object} model.PagedReview
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /movies/{id}/reviews [get]
+// @Security ApiKeyAuth
+func (reviewController *ReviewController) GetMovieReviews(c echo.Context) error {
+	ctx := c.Request().Context()
+	movieId := c.Param("id")
+
+	sort := c.QueryParam("sort")
+	switch sort {
+	case "":
+		sort = enums.ReviewSortHelpful
+	case enums.ReviewSortHelpful, enums.ReviewSortDate:
+	default:
+		return exception.InvalidParameterException("sort", sort)
+	}
+
+	if _, err := reviewController.movieRepository.GetMovie(ctx, movieId); err != nil {
+		return err
+	}
+
+	page, _ := strconv.ParseInt(c.QueryParam("page"), 10, 64)
+	limit, _ := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
+	pagedReview, err := reviewController.reviewRepository.GetMovieReviews(ctx, &model.ReviewQuery{
+		MovieId: movieId,
+		Sort:    sort,
+		Page:    page,
+		Limit:   limit,
+	})
+	if err != nil {
+		return err
+	}
+	return util.Negotiate(c, http.StatusOK, pagedReview)
+}
+
+// GetReview godoc
+// @Summary Get a review
+// @Description Get a review item
+// @Tags reviews
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Review ID"
+// @Param If-None-Match header string false "ETag of the cached representation"
+// @Success 200 {object} model.Review
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /reviews/{id} [get]
+// @Security ApiKeyAuth
+func (reviewController *ReviewController) GetReview(c echo.Context) error {
+	review, err := reviewController.reviewRepository.GetReview(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	if util.NotModified(c, review.Version) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return util.Negotiate(c, http.StatusOK, review)
+}
+
+// SaveReview godoc
+// @Summary Review a movie
+// @Description Rate a movie from 1 to 5 stars and optionally review it, once per user. Only users checked in to a showtime of the movie may review it
+// @Tags reviews
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Movie ID"
+// @Param review body model.ReviewInput true "New review"
+// @Success 201 {object} model.Review
+// @Failure 400 {object} handler.Problem
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /movies/{id}/reviews [post]
+// @Security ApiKeyAuth
+func (reviewController *ReviewController) SaveReview(c echo.Context) error {
+	ctx := c.Request().Context()
+	movieId := c.Param("id")
+	userId := util.GetUserIdFromToken(c)
+
+	payload := new(model.ReviewInput)
+	if err := util.BindAndValidate(c, payload); err != nil {
+		return err
+	}
+
+	if _, err := reviewController.movieRepository.GetMovie(ctx, movieId); err != nil {
+		return err
+	}
+	checkedIn, err := reviewController.bookingRepository.HasCheckedIn(ctx, userId, movieId)
+	if err != nil {
+		return err
+	}
+	if !checkedIn {
+		return exception.ForbiddenException()
+	}
+
+	payload.CreatedAt = time.Now()
+	payload.UpdatedAt = time.Now()
+	review := &model.Review{
+		ReviewInput: payload,
+		MovieId:     movieId,
+		UserId:      userId,
+	}
+
+	createdReview, err := reviewController.reviewRepository.SaveReview(ctx, review)
+	if err != nil {
+		return err
+	}
+
+	util.SetETag(c, createdReview.Version)
+	return util.Negotiate(c, http.StatusCreated, createdReview)
+}
+
+// UpdateReview godoc
+// @Summary Update a review
+// @Description Change the rating and text of your review
+// @Tags reviews
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Review ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Param review body model.ReviewInput true "Update review"
+// @Success 200 {object} model.Review
+// @Failure 400 {object} handler.Problem
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /reviews/{id} [put]
+// @Security ApiKeyAuth
+func (reviewController *ReviewController) UpdateReview(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	review, err := reviewController.reviewRepository.GetReview(ctx, id)
+	if err != nil {
+		return err
+	}
+	if review.UserId != util.GetUserIdFromToken(c) {
+		return exception.ForbiddenException()
+	}
+
+	payload := new(model.ReviewInput)
+	if err := util.BindAndValidate(c, payload); err != nil {
+		return err
+	}
+	payload.UpdatedAt = time.Now()
+
+	updatedReview, err := reviewController.reviewRepository.UpdateReview(ctx, id, &model.Review{ReviewInput: payload})
+	if err != nil {
+		return err
+	}
+
+	util.SetETag(c, updatedReview.Version)
+	return util.Negotiate(c, http.StatusOK, updatedReview)
+}
+
+// DeleteReview godoc
+// @Summary Delete a review
+// @Description Delete your review, or any review as an admin
+// @Tags reviews
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Review ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Success 204 {object} model.Review
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /reviews/{id} [delete]
+// @Security ApiKeyAuth
+func (reviewController *ReviewController) DeleteReview(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	review, err := reviewController.reviewRepository.GetReview(ctx, id)
+	if err != nil {
+		return err
+	}
+	if review.UserId != util.GetUserIdFromToken(c) && util.GetUserRoleFromToken(c) != enums.RoleAdmin {
+		return exception.ForbiddenException()
+	}
+
+	if err := reviewController.reviewRepository.DeleteReview(ctx, id); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// MarkReviewHelpful godoc
+// @Summary Vote a review helpful
+// @Description Vote a review of someone else helpful, once per user
+// @Tags reviews
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Review ID"
+// @Success 200 {object} model.Review
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /reviews/{id}/helpful [post]
+// @Security ApiKeyAuth
+func (reviewController *ReviewController) MarkReviewHelpful(c echo.Context) error {
+	return reviewController.voteReview(c, enums.ReviewVoteHelpful)
+}
+
+// ReportReview godoc
+// @Summary Report a review
+// @Description Report a review of someone else as abusive or off topic, once per user. Reviews reported REVIEW_REPORT_THRESHOLD times are no longer listed
+// @Tags reviews
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Review ID"
+// @Success 200 {object} model.Review
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /reviews/{id}/report [post]
+// @Security ApiKeyAuth
+func (reviewController *ReviewController) ReportReview(c echo.Context) error {
+	return reviewController.voteReview(c, enums.ReviewVoteReport)
+}
+
+// voteReview counts the vote of the user of the token on a review, which
+// must not be theirs.
+func (reviewController *ReviewController) voteReview(c echo.Context, vote string) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+	userId := util.GetUserIdFromToken(c)
+
+	review, err := reviewController.reviewRepository.GetReview(ctx, id)
+	if err != nil {
+		return err
+	}
+	if review.UserId == userId {
+		return exception.ForbiddenException()
+	}
+
+	review, err = reviewController.reviewRepository.VoteReview(ctx, id, userId, vote)
+	if err != nil {
+		return err
+	}
+	return util.Negotiate(c, http.StatusOK, review)
+}
@@ -0,0 +1,67 @@
+package controller_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+)
+
+func TestReviews(t *testing.T) {
+	server := newTestServer(t)
+	anaId, anaToken := server.signUp(t, "Ana", "ana@example.com")
+	_, leoToken := server.signUp(t, "Leo", "leo@example.com")
+	_, adminToken := server.admin(t)
+	movieId := server.save(t, "/movies", anaToken, map[string]interface{}{"title": "Alien", "format": "2D"})
+
+	review, err := server.Reviews.SaveReview(context.Background(), &model.Review{
+		ReviewInput: &model.ReviewInput{Rating: 4, CreatedAt: time.Now()},
+		MovieId:     movieId,
+		UserId:      anaId,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := review.ID.Hex()
+
+	var reviews model.PagedReview
+	recorder := server.do(t, http.MethodGet, "/movies/"+movieId+"/reviews?sort=date", leoToken, nil)
+	decode(t, recorder, http.StatusOK, &reviews)
+	if len(reviews.Data) != 1 || reviews.Data[0].ID.Hex() != id {
+		t.Errorf("Alien has %d reviews, want the review of Ana", len(reviews.Data))
+	}
+	recorder = server.do(t, http.MethodGet, "/movies/"+movieId+"/reviews?sort=stars", leoToken, nil)
+	decode(t, recorder, http.StatusBadRequest, nil)
+
+	recorder = server.do(t, http.MethodPost, "/reviews/"+id+"/helpful", anaToken, nil)
+	decode(t, recorder, http.StatusForbidden, nil)
+	var voted model.Review
+	recorder = server.do(t, http.MethodPost, "/reviews/"+id+"/helpful", leoToken, nil)
+	decode(t, recorder, http.StatusOK, &voted)
+	if voted.Helpful != 1 {
+		t.Errorf("the review has %d helpful votes, want 1", voted.Helpful)
+	}
+
+	update := map[string]interface{}{"rating": 5, "text": "A classic"}
+	recorder = server.do(t, http.MethodPut, "/reviews/"+id, leoToken, update)
+	decode(t, recorder, http.StatusForbidden, nil)
+	recorder = server.do(t, http.MethodPut, "/reviews/"+id, anaToken, map[string]interface{}{"rating": 6})
+	decode(t, recorder, http.StatusBadRequest, nil)
+	recorder = server.do(t, http.MethodPut, "/reviews/"+id, anaToken, update)
+	decode(t, recorder, http.StatusOK, nil)
+
+	var movie model.Movie
+	decode(t, server.do(t, http.MethodGet, "/movies/"+movieId, leoToken, nil), http.StatusOK, &movie)
+	if movie.Score == nil || movie.Score.Average != 5 {
+		t.Errorf("Alien scores %v, want 5", movie.Score)
+	}
+
+	recorder = server.do(t, http.MethodDelete, "/reviews/"+id, leoToken, nil)
+	decode(t, recorder, http.StatusForbidden, nil)
+	recorder = server.do(t, http.MethodDelete, "/reviews/"+id, adminToken, nil)
+	decode(t, recorder, http.StatusNoContent, nil)
+	recorder = server.do(t, http.MethodGet, "/reviews/"+id, leoToken, nil)
+	decode(t, recorder, http.StatusNotFound, nil)
+}
@@ -0,0 +1,155 @@
+package controller_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cbuelvasc/cinema-backend/controller"
+	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/cbuelvasc/cinema-backend/handler"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/repository/repositorytest"
+	"github.com/cbuelvasc/cinema-backend/routes"
+	"github.com/cbuelvasc/cinema-backend/security"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"github.com/labstack/echo/v4"
+)
+
+// testServer serves the API as main does, over the memory repositories.
+type testServer struct {
+	*repositorytest.Repositories
+	echo *echo.Echo
+}
+
+func newTestServer(t *testing.T) *testServer {
+	repositories := repositorytest.Memory(t)
+
+	e := echo.New()
+	e.HTTPErrorHandler = handler.ErrorHandler
+	e.Validator = util.NewValidationUtil()
+	handler.RequestIDConfig(e)
+	security.WebSecurityConfig(e)
+	security.IncludeDeletedConfig(e)
+	handler.PreconditionConfig(e)
+
+	authValidator := security.NewAuthValidator(repositories.Users)
+	routes.GetUserApiRoutes(e, controller.NewUserController(repositories.Users, authValidator))
+	routes.GetTweetApiRoutes(e, controller.NewTweetController(repositories.Tweets, repositories.Users))
+	routes.GetFollowApiRoutes(e, controller.NewFollowController(repositories.Follows, repositories.Users))
+	routes.GetMovieApiRoutes(e, controller.NewMovieController(repositories.Movies, repositories.Search, repositories.Users))
+	routes.GetCountryApiRoutes(e, controller.NewCountryController(repositories.Countries))
+	routes.GetStateApiRoutes(e, controller.NewStateController(repositories.States, repositories.Countries))
+	routes.GetCityApiRoutes(e, controller.NewCityController(repositories.Cities, repositories.States, repositories.Countries))
+	routes.GetCinemaApiRoutes(e, controller.NewCinemaController(repositories.Cinemas, repositories.Movies))
+	routes.GetListingApiRoutes(e, controller.NewListingController(repositories.Listings, repositories.Cities, repositories.Cinemas))
+	routes.GetReviewApiRoutes(e, controller.NewReviewController(repositories.Reviews, repositories.Movies, repositories.Bookings))
+	routes.GetBookingApiRoutes(e, controller.NewBookingController(repositories.Bookings, repositories.Users, repositories.Rooms, repositories.Cinemas, repositories.Movies))
+
+	return &testServer{Repositories: repositories, echo: e}
+}
+
+// request sends body as contentType with the token of a signed-in user, if
+// any, and returns the response.
+func (server *testServer) request(method string, target string, token string, contentType string, body string, headers ...string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, enums.BasePath+target, bytes.NewBufferString(body))
+	if len(body) > 0 {
+		request.Header.Set(echo.HeaderContentType, contentType)
+	}
+	if len(token) > 0 {
+		request.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		request.Header.Set(headers[i], headers[i+1])
+	}
+	recorder := httptest.NewRecorder()
+	server.echo.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// do sends payload as JSON.
+func (server *testServer) do(t *testing.T, method string, target string, token string, payload interface{}, headers ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body []byte
+	if payload != nil {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return server.request(method, target, token, echo.MIMEApplicationJSON, string(body), headers...)
+}
+
+// signUp signs a customer up and in, returning their id and token.
+func (server *testServer) signUp(t *testing.T, name string, email string) (string, string) {
+	t.Helper()
+	credentials := map[string]string{"email": email, "password": "secret123"}
+	recorder := server.do(t, http.MethodPost, "/signup", "", map[string]string{
+		"name":     name,
+		"lastname": "Test",
+		"email":    email,
+		"password": credentials["password"],
+	})
+	var user model.User
+	decode(t, recorder, http.StatusCreated, &user)
+
+	recorder = server.do(t, http.MethodPost, "/signin", "", credentials)
+	var token model.Token
+	decode(t, recorder, http.StatusOK, &token)
+	return user.ID.Hex(), token.Token
+}
+
+// admin saves an admin and returns their id and token.
+func (server *testServer) admin(t *testing.T) (string, string) {
+	t.Helper()
+	user, err := server.Users.SaveUser(context.Background(), &model.User{UserInput: &model.UserInput{
+		Name:     "Admin",
+		Lastname: "Test",
+		Email:    "admin@example.com",
+		Role:     enums.RoleAdmin,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := util.GenerateJwtToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user.ID.Hex(), token
+}
+
+// decode checks the status of a response and decodes its JSON body into v,
+// unless v is nil.
+func decode(t *testing.T, recorder *httptest.ResponseRecorder, status int, v interface{}) {
+	t.Helper()
+	if recorder.Code != status {
+		t.Fatalf("the response is %d %s, want %d", recorder.Code, recorder.Body.String(), status)
+	}
+	if v != nil {
+		if err := json.Unmarshal(recorder.Body.Bytes(), v); err != nil {
+			t.Fatalf("decoding %s: %v", recorder.Body.String(), err)
+		}
+	}
+}
+
+// save posts payload to target and returns the id of the created resource.
+func (server *testServer) save(t *testing.T, target string, token string, payload interface{}) string {
+	t.Helper()
+	var created struct {
+		ID string `json:"id"`
+	}
+	decode(t, server.do(t, http.MethodPost, target, token, payload), http.StatusCreated, &created)
+	return created.ID
+}
+
+// saveCity saves a city in a state of a new country and returns their ids.
+func (server *testServer) saveCity(t *testing.T, token string) (string, string, string) {
+	t.Helper()
+	countryId := server.save(t, "/countries", token, map[string]string{"name": "Colombia"})
+	stateId := server.save(t, "/states", token, map[string]string{"name": "Antioquia", "countryId": countryId})
+	cityId := server.save(t, "/cities", token, map[string]string{"name": "Medellín", "stateId": stateId, "timeZone": "America/Bogota"})
+	return countryId, stateId, cityId
+}
@@ -1,6 +1,9 @@
 package controller
 
 import (
+	"context"
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/i18n"
 	"net/http"
 	"strconv"
 	"time"
@@ -16,6 +19,9 @@ type StateControllerInterface interface {
 	GetState(c echo.Context) error
 	SaveState(c echo.Context) error
 	DeleteState(c echo.Context) error
+	RestoreState(c echo.Context) error
+	ImportStates(c echo.Context) error
+	ExportStates(c echo.Context) error
 }
 
 type StateController struct {
@@ -39,9 +45,17 @@ func NewStateController(stateRepository repository.StateRepository, countryRepos
 // @Param mediaType query string false "mediaType" Enums(xml, json)
 // @Param page query int false "page" minimum(1)
 // @Param limit query int false "size" minimum(1)
+// @Param pagination query string false "pagination" Enums(page, cursor)
+// @Param after query string false "after"
+// @Param before query string false "before"
+// @Param sort query string false "sort"
+// @Param skipCount query bool false "skipCount"
+// @Param fields query string false "fields"
+// @Param expand query string false "expand"
+// @Param includeDeleted query bool false "includeDeleted"
 // @Param countryId query string true "countryId"
 // @Success 200 {array} model.State
-// @Failure 500 {object} handler.APIError
+// @Failure 500 {object} handler.Problem
 // @Router /states [get]
 // @Security ApiKeyAuth
 func (stateController *StateController) GetAllStates(c echo.Context) error {
@@ -55,7 +69,21 @@ func (stateController *StateController) GetAllStates(c echo.Context) error {
 		}
 	}
 
-	pagedState, err := stateController.stateRepository.GetAllStates(c.Request().Context(), page, limit, countryId)
+	if documentQuery, ok := util.GetDocumentQuery(c); ok {
+		pagedDocument, err := stateController.stateRepository.GetAllStateDocuments(c.Request().Context(), documentQuery, countryId)
+		if err != nil {
+			return err
+		}
+		return util.Negotiate(c, http.StatusOK, pagedDocument)
+	}
+
+	var pagedState *model.PagedState
+	var err error
+	if cursorQuery, ok := util.GetCursorQuery(c); ok {
+		pagedState, err = stateController.stateRepository.GetAllStatesByCursor(c.Request().Context(), cursorQuery, countryId)
+	} else {
+		pagedState, err = stateController.stateRepository.GetAllStates(c.Request().Context(), page, limit, countryId)
+	}
 	if err != nil {
 		return err
 	}
@@ -70,9 +98,13 @@ func (stateController *StateController) GetAllStates(c echo.Context) error {
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param id path string true "State ID"
+// @Param If-None-Match header string false "ETag of the cached representation"
+// @Param fields query string false "fields"
+// @Param expand query string false "expand"
+// @Param includeDeleted query bool false "includeDeleted"
 // @Success 200 {object} model.State
-// @Failure 404 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /states/{id} [get]
 // @Security ApiKeyAuth
 func (stateController *StateController) GetState(c echo.Context) error {
@@ -81,11 +113,22 @@ func (stateController *StateController) GetState(c echo.Context) error {
 		id = util.GetUserIdFromToken(c)
 	}
 
+	if documentQuery, ok := util.GetDocumentQuery(c); ok {
+		document, err := stateController.stateRepository.GetStateDocument(c.Request().Context(), id, documentQuery)
+		if err != nil {
+			return err
+		}
+		return util.Negotiate(c, http.StatusOK, document)
+	}
+
 	states, err := stateController.stateRepository.GetStateById(c.Request().Context(), id)
 	if err != nil {
 		return err
 	}
 
+	if util.NotModified(c, states.Version) {
+		return c.NoContent(http.StatusNotModified)
+	}
 	return util.Negotiate(c, http.StatusOK, states)
 }
 
@@ -98,9 +141,9 @@ func (stateController *StateController) GetState(c echo.Context) error {
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param states body model.StateInput true "New states"
 // @Success 200 {object} model.State
-// @Failure 400 {object} handler.APIError
-// @Failure 409 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 400 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /states [post]
 // @Security ApiKeyAuth
 func (stateController *StateController) SaveState(c echo.Context) error {
@@ -123,6 +166,7 @@ func (stateController *StateController) SaveState(c echo.Context) error {
 		return err
 	}
 
+	util.SetETag(c, createdState.Version)
 	return util.Negotiate(c, http.StatusCreated, createdState)
 }
 
@@ -134,11 +178,13 @@ func (stateController *StateController) SaveState(c echo.Context) error {
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param id path string true "State ID"
+// @Param If-Match header string false "ETag of the version being modified"
 // @Param state body model.StateInput true "State Info"
 // @Success 200 {object} model.State
-// @Failure 400 {object} handler.APIError
-// @Failure 404 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /states/{id} [put]
 // @Security ApiKeyAuth
 func (stateController *StateController) UpdateState(c echo.Context) error {
@@ -154,9 +200,58 @@ func (stateController *StateController) UpdateState(c echo.Context) error {
 	if err != nil {
 		return err
 	}
+	util.SetETag(c, user.Version)
 	return util.Negotiate(c, http.StatusOK, user)
 }
 
+// PatchState godoc
+// @Summary Patch a state
+// @Description Partially update a state item with a JSON Merge Patch or a JSON Patch
+// @Tags states
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "State ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Param patch body object true "Merge patch or JSON patch"
+// @Success 200 {object} model.State
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 415 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /states/{id} [patch]
+// @Security ApiKeyAuth
+func (stateController *StateController) PatchState(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+
+	current, err := stateController.stateRepository.GetStateById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	payload := new(model.StateInput)
+	if err := util.BindPatch(c, current.StateInput, payload); err != nil {
+		return err
+	}
+	payload.UpdatedAt = time.Now()
+
+	// The patch was computed on the version just read, so it must not be
+	// written over a newer one.
+	if _, ok := util.GetExpectedVersions(ctx); !ok {
+		ctx = util.WithExpectedVersion(ctx, current.Version)
+	}
+
+	state, err := stateController.stateRepository.PatchState(ctx, id, &model.State{StateInput: payload})
+	if err != nil {
+		return err
+	}
+	util.SetETag(c, state.Version)
+	return util.Negotiate(c, http.StatusOK, state)
+}
+
 // DeleteState godoc
 // @Summary Delete a states
 // @Description Delete a new states item
@@ -165,9 +260,11 @@ func (stateController *StateController) UpdateState(c echo.Context) error {
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param id path string true "State ID"
+// @Param If-Match header string false "ETag of the version being modified"
 // @Success 204 {object} model.State
-// @Failure 404 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /states/{id} [delete]
 // @Security ApiKeyAuth
 func (stateController *StateController) DeleteState(c echo.Context) error {
@@ -179,3 +276,127 @@ func (stateController *StateController) DeleteState(c echo.Context) error {
 	}
 	return c.NoContent(http.StatusNoContent)
 }
+
+// RestoreState godoc
+// @Summary Restore a state
+// @Description Restore a deleted state item
+// @Tags states
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "State ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Success 200 {object} model.State
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /states/{id}/restore [post]
+// @Security ApiKeyAuth
+func (stateController *StateController) RestoreState(c echo.Context) error {
+	id := c.Param("id")
+
+	state, err := stateController.stateRepository.RestoreState(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	util.SetETag(c, state.Version)
+	return util.Negotiate(c, http.StatusOK, state)
+}
+
+var stateColumns = []string{"name", "country"}
+
+// ImportStates godoc
+// @Summary Import states
+// @Description Create the states of CSV or NDJSON rows that do not exist yet, matched on name within their country, given by name
+// @Tags states
+// @Accept text/csv,application/x-ndjson
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param dryRun query bool false "Validate the rows without saving them"
+// @Param rows body string true "Rows with the columns name and country"
+// @Success 200 {object} model.ImportResult
+// @Failure 400 {object} handler.Problem
+// @Failure 403 {object} handler.Problem
+// @Failure 415 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /states/import [post]
+// @Security ApiKeyAuth
+func (stateController *StateController) ImportStates(c echo.Context) error {
+	return importRows(c, stateColumns, func(ctx context.Context, row map[string]string, dryRun bool) (importAction, error) {
+		if len(row["country"]) == 0 {
+			return 0, exception.InvalidRequestException("validation.required", i18n.Field("country"))
+		}
+		country, err := stateController.countryRepository.GetCountryByName(ctx, row["country"])
+		if err != nil {
+			return 0, err
+		}
+
+		payload := &model.StateInput{Name: row["name"], CountryId: country.ID.Hex()}
+		if err := c.Validate(payload); err != nil {
+			return 0, exception.ValidationException(err)
+		}
+
+		_, err = stateController.stateRepository.GetStateByName(ctx, payload.CountryId, payload.Name)
+		if isNotFound(err) {
+			if dryRun {
+				return importCreated, nil
+			}
+			payload.CreatedAt = time.Now()
+			payload.UpdatedAt = time.Now()
+			_, err = stateController.stateRepository.SaveState(ctx, &model.State{StateInput: payload})
+			return importCreated, err
+		}
+		if err != nil {
+			return 0, err
+		}
+		return importUnchanged, nil
+	})
+}
+
+// ExportStates godoc
+// @Summary Export states
+// @Description Download every state as CSV or NDJSON, in the columns accepted by the import
+// @Tags states
+// @Produce text/csv,application/x-ndjson
+// @Param format query string false "format" Enums(csv, ndjson)
+// @Success 200 {string} string
+// @Failure 400 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /states/export [get]
+// @Security ApiKeyAuth
+func (stateController *StateController) ExportStates(c echo.Context) error {
+	countries := map[string]string{}
+	return exportRows(c, "states", stateColumns, func(ctx context.Context, writer util.RowWriter, query *model.CursorQuery) (string, error) {
+		pagedState, err := stateController.stateRepository.GetAllStatesByCursor(ctx, query, "")
+		if err != nil {
+			return "", err
+		}
+		for _, state := range pagedState.Data {
+			countryName, err := countryNameOf(ctx, stateController.countryRepository, countries, state.CountryId)
+			if err != nil {
+				return "", err
+			}
+			if err := writer.Write(state.Name, countryName); err != nil {
+				return "", err
+			}
+		}
+		return nextCursor(pagedState.Cursor), nil
+	})
+}
+
+// countryNameOf returns the name of the country with id, through names,
+// which caches the ones already read. Unknown countries have no name.
+func countryNameOf(ctx context.Context, countryRepository repository.CountryRepository, names map[string]string, id string) (string, error) {
+	if name, ok := names[id]; ok {
+		return name, nil
+	}
+	country, err := countryRepository.GetCountryById(ctx, id)
+	if err != nil && !isNotFound(err) {
+		return "", err
+	}
+	if country != nil {
+		names[id] = country.Name
+	}
+	return names[id], nil
+}
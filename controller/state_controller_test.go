@@ -0,0 +1,52 @@
+package controller_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+)
+
+func TestStates(t *testing.T) {
+	server := newTestServer(t)
+	_, token := server.signUp(t, "Ana", "ana@example.com")
+
+	recorder := server.do(t, http.MethodPost, "/states", token, map[string]string{"name": "Antioquia", "countryId": "000000000000000000000000"})
+	decode(t, recorder, http.StatusNotFound, nil)
+
+	colombia := server.save(t, "/countries", token, map[string]string{"name": "Colombia"})
+	peru := server.save(t, "/countries", token, map[string]string{"name": "Peru"})
+	antioquia := server.save(t, "/states", token, map[string]string{"name": "Antioquia", "countryId": colombia})
+	server.save(t, "/states", token, map[string]string{"name": "Caldas", "countryId": colombia})
+	server.save(t, "/states", token, map[string]string{"name": "Cusco", "countryId": peru})
+
+	var states model.PagedState
+	recorder = server.do(t, http.MethodGet, "/states?pagination=cursor&limit=1&countryId="+colombia, token, nil)
+	decode(t, recorder, http.StatusOK, &states)
+	if len(states.Data) != 1 || !states.Cursor.HasNext {
+		t.Fatalf("listed %d states with a next page %t, want 1 with a next page", len(states.Data), states.Cursor.HasNext)
+	}
+	first := states.Data[0].Name
+	recorder = server.do(t, http.MethodGet, "/states?limit=1&countryId="+colombia+"&after="+states.Cursor.Next, token, nil)
+	decode(t, recorder, http.StatusOK, &states)
+	if len(states.Data) != 1 || states.Data[0].Name == first || states.Data[0].CountryId != colombia || states.Cursor.HasNext {
+		t.Errorf("the next page has %d states after %s, want the other state of Colombia", len(states.Data), first)
+	}
+
+	var country model.Country
+	decode(t, server.do(t, http.MethodGet, "/countries/"+colombia, token, nil), http.StatusOK, &country)
+	if len(country.States) != 2 {
+		t.Errorf("Colombia links %d states, want 2", len(country.States))
+	}
+
+	recorder = server.do(t, http.MethodDelete, "/countries/"+colombia, token, nil)
+	decode(t, recorder, http.StatusConflict, nil)
+
+	var state model.State
+	recorder = server.do(t, http.MethodPut, "/states/"+antioquia, token, map[string]string{"name": "Antioquia", "countryId": peru})
+	decode(t, recorder, http.StatusOK, &state)
+	decode(t, server.do(t, http.MethodGet, "/countries/"+colombia, token, nil), http.StatusOK, &country)
+	if state.CountryId != peru || len(country.States) != 1 {
+		t.Errorf("moved the state to %s, leaving %d states in Colombia", state.CountryId, len(country.States))
+	}
+}
@@ -18,6 +18,15 @@ type TweetControllerInterface interface {
 	GetTweet(c echo.Context) error
 	SaveTweet(c echo.Context) error
 	DeleteTweet(c echo.Context) error
+	RestoreTweet(c echo.Context) error
+	GetTimeline(c echo.Context) error
+	GetConversation(c echo.Context) error
+	GetHashtagTweets(c echo.Context) error
+	GetMentionTweets(c echo.Context) error
+	LikeTweet(c echo.Context) error
+	UnlikeTweet(c echo.Context) error
+	Retweet(c echo.Context) error
+	Unretweet(c echo.Context) error
 }
 
 type TweetController struct {
@@ -41,9 +50,17 @@ func NewTweetController(tweetRepository repository.TweetRepository, userReposito
 // @Param mediaType query string false "mediaType" Enums(xml, json)
 // @Param page query int false "page" minimum(1)
 // @Param limit query int false "size" minimum(1)
+// @Param pagination query string false "pagination" Enums(page, cursor)
+// @Param after query string false "after"
+// @Param before query string false "before"
+// @Param sort query string false "sort"
+// @Param skipCount query bool false "skipCount"
+// @Param fields query string false "fields"
+// @Param expand query string false "expand"
+// @Param includeDeleted query bool false "includeDeleted"
 // @Param userId query string true "userId"
 // @Success 200 {array} model.Tweet
-// @Failure 500 {object} handler.APIError
+// @Failure 500 {object} handler.Problem
 // @Router /tweets [get]
 // @Security ApiKeyAuth
 func (tweetController *TweetController) GetAllTweet(c echo.Context) error {
@@ -59,7 +76,20 @@ func (tweetController *TweetController) GetAllTweet(c echo.Context) error {
 		return err
 	}
 
-	pagedUser, err := tweetController.tweetRepository.GetAllTweets(c.Request().Context(), page, limit, userId)
+	if documentQuery, ok := util.GetDocumentQuery(c); ok {
+		pagedDocument, err := tweetController.tweetRepository.GetAllTweetDocuments(c.Request().Context(), documentQuery, userId)
+		if err != nil {
+			return err
+		}
+		return util.Negotiate(c, http.StatusOK, pagedDocument)
+	}
+
+	var pagedUser *model.PagedTweet
+	if cursorQuery, ok := util.GetCursorQuery(c); ok {
+		pagedUser, err = tweetController.tweetRepository.GetAllTweetsByCursor(c.Request().Context(), cursorQuery, userId)
+	} else {
+		pagedUser, err = tweetController.tweetRepository.GetAllTweets(c.Request().Context(), page, limit, userId)
+	}
 	if err != nil {
 		return err
 	}
@@ -74,9 +104,13 @@ func (tweetController *TweetController) GetAllTweet(c echo.Context) error {
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param id path string true "Tweet ID"
+// @Param If-None-Match header string false "ETag of the cached representation"
+// @Param fields query string false "fields"
+// @Param expand query string false "expand"
+// @Param includeDeleted query bool false "includeDeleted"
 // @Success 200 {object} model.Tweet
-// @Failure 404 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /tweets/{id} [get]
 // @Security ApiKeyAuth
 func (tweetController *TweetController) GetTweet(c echo.Context) error {
@@ -85,11 +119,22 @@ func (tweetController *TweetController) GetTweet(c echo.Context) error {
 		id = util.GetUserIdFromToken(c)
 	}
 
+	if documentQuery, ok := util.GetDocumentQuery(c); ok {
+		document, err := tweetController.tweetRepository.GetTweetDocument(c.Request().Context(), id, documentQuery)
+		if err != nil {
+			return err
+		}
+		return util.Negotiate(c, http.StatusOK, document)
+	}
+
 	tweet, err := tweetController.tweetRepository.GetTweet(c.Request().Context(), id)
 	if err != nil {
 		return err
 	}
 
+	if util.NotModified(c, tweet.Version) {
+		return c.NoContent(http.StatusNotModified)
+	}
 	return util.Negotiate(c, http.StatusOK, tweet)
 }
 
@@ -100,11 +145,12 @@ func (tweetController *TweetController) GetTweet(c echo.Context) error {
 // @Accept json,xml
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
-// @Param tweet body model.TweetInput true "New tweet"
+// @Param tweet body model.TweetInput true "New tweet, a reply with replyToId or a quote tweet with quoteOfId"
 // @Success 200 {object} model.Tweet
-// @Failure 400 {object} handler.APIError
-// @Failure 409 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /tweets [post]
 // @Security ApiKeyAuth
 func (tweetController *TweetController) SaveTweet(c echo.Context) error {
@@ -127,6 +173,7 @@ func (tweetController *TweetController) SaveTweet(c echo.Context) error {
 		return err
 	}
 
+	util.SetETag(c, createdTweet.Version)
 	return util.Negotiate(c, http.StatusCreated, createdTweet)
 }
 
@@ -138,9 +185,11 @@ func (tweetController *TweetController) SaveTweet(c echo.Context) error {
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param id path string true "Tweet ID"
+// @Param If-Match header string false "ETag of the version being modified"
 // @Success 204 {object} model.Tweet
-// @Failure 404 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /tweets/{id} [delete]
 // @Security ApiKeyAuth
 func (tweetController *TweetController) DeleteTweet(c echo.Context) error {
@@ -161,3 +210,232 @@ func (tweetController *TweetController) DeleteTweet(c echo.Context) error {
 	}
 	return c.NoContent(http.StatusNoContent)
 }
+
+// RestoreTweet godoc
+// @Summary Restore a tweet
+// @Description Restore a deleted tweet item
+// @Tags tweets
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Tweet ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Success 200 {object} model.Tweet
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /tweets/{id}/restore [post]
+// @Security ApiKeyAuth
+func (tweetController *TweetController) RestoreTweet(c echo.Context) error {
+	id := c.Param("id")
+
+	tweet, err := tweetController.tweetRepository.RestoreTweet(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	util.SetETag(c, tweet.Version)
+	return util.Negotiate(c, http.StatusOK, tweet)
+}
+
+// GetTimeline godoc
+// @Summary Get the home timeline
+// @Description Get the tweets of the users you follow, the latest first
+// @Tags tweets
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(xml, json)
+// @Param limit query int false "size" minimum(1)
+// @Param after query string false "after"
+// @Param before query string false "before"
+// @Param skipCount query bool false "skipCount"
+// @Success 200 {object} model.PagedTweet
+// @Failure 400 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /timeline [get]
+// @Security ApiKeyAuth
+func (tweetController *TweetController) GetTimeline(c echo.Context) error {
+	pagedTweet, err := tweetController.tweetRepository.GetTimeline(c.Request().Context(), util.GetUserIdFromToken(c), latestFirst(c))
+	if err != nil {
+		return err
+	}
+	return util.Negotiate(c, http.StatusOK, pagedTweet)
+}
+
+// GetConversation godoc
+// @Summary Get a conversation
+// @Description Get the tweet that started the conversation of a tweet and every reply in it, the oldest first
+// @Tags tweets
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(xml, json)
+// @Param id path string true "Tweet ID"
+// @Param limit query int false "size" minimum(1)
+// @Param after query string false "after"
+// @Param before query string false "before"
+// @Param skipCount query bool false "skipCount"
+// @Success 200 {object} model.PagedTweet
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /tweets/{id}/conversation [get]
+// @Security ApiKeyAuth
+func (tweetController *TweetController) GetConversation(c echo.Context) error {
+	// Conversations read like a thread, the oldest first.
+	query := latestFirst(c)
+	query.Sort = "created_at"
+
+	pagedTweet, err := tweetController.tweetRepository.GetConversation(c.Request().Context(), c.Param("id"), query)
+	if err != nil {
+		return err
+	}
+	return util.Negotiate(c, http.StatusOK, pagedTweet)
+}
+
+// GetHashtagTweets godoc
+// @Summary Get the tweets of a hashtag
+// @Description Get the tweets with a hashtag, in any case, the latest first
+// @Tags tweets
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(xml, json)
+// @Param tag path string true "Hashtag, without #"
+// @Param limit query int false "size" minimum(1)
+// @Param after query string false "after"
+// @Param before query string false "before"
+// @Param skipCount query bool false "skipCount"
+// @Success 200 {object} model.PagedTweet
+// @Failure 400 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /hashtags/{tag}/tweets [get]
+// @Security ApiKeyAuth
+func (tweetController *TweetController) GetHashtagTweets(c echo.Context) error {
+	pagedTweet, err := tweetController.tweetRepository.GetHashtagTweets(c.Request().Context(), c.Param("tag"), latestFirst(c))
+	if err != nil {
+		return err
+	}
+	return util.Negotiate(c, http.StatusOK, pagedTweet)
+}
+
+// GetMentionTweets godoc
+// @Summary Get the tweets mentioning a user
+// @Description Get the tweets mentioning the username of a user, the latest first
+// @Tags tweets
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(xml, json)
+// @Param id path string true "User ID, or me"
+// @Param limit query int false "size" minimum(1)
+// @Param after query string false "after"
+// @Param before query string false "before"
+// @Param skipCount query bool false "skipCount"
+// @Success 200 {object} model.PagedTweet
+// @Failure 400 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /users/{id}/mentions [get]
+// @Security ApiKeyAuth
+func (tweetController *TweetController) GetMentionTweets(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+	if id == "me" {
+		id = util.GetUserIdFromToken(c)
+	}
+
+	if _, err := tweetController.userRepository.GetUser(ctx, id); err != nil {
+		return err
+	}
+
+	pagedTweet, err := tweetController.tweetRepository.GetMentionTweets(ctx, id, latestFirst(c))
+	if err != nil {
+		return err
+	}
+	return util.Negotiate(c, http.StatusOK, pagedTweet)
+}
+
+// LikeTweet godoc
+// @Summary Like a tweet
+// @Description Like a tweet, once per user
+// @Tags tweets
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Tweet ID"
+// @Success 200 {object} model.Tweet
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /tweets/{id}/like [post]
+// @Security ApiKeyAuth
+func (tweetController *TweetController) LikeTweet(c echo.Context) error {
+	tweet, err := tweetController.tweetRepository.LikeTweet(c.Request().Context(), c.Param("id"), util.GetUserIdFromToken(c))
+	if err != nil {
+		return err
+	}
+	return util.Negotiate(c, http.StatusOK, tweet)
+}
+
+// UnlikeTweet godoc
+// @Summary Unlike a tweet
+// @Description Take back your like of a tweet
+// @Tags tweets
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Tweet ID"
+// @Success 200 {object} model.Tweet
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /tweets/{id}/like [delete]
+// @Security ApiKeyAuth
+func (tweetController *TweetController) UnlikeTweet(c echo.Context) error {
+	tweet, err := tweetController.tweetRepository.UnlikeTweet(c.Request().Context(), c.Param("id"), util.GetUserIdFromToken(c))
+	if err != nil {
+		return err
+	}
+	return util.Negotiate(c, http.StatusOK, tweet)
+}
+
+// Retweet godoc
+// @Summary Retweet a tweet
+// @Description Retweet a tweet, once per user. Retweeting a retweet retweets the original tweet
+// @Tags tweets
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "Tweet ID"
+// @Success 201 {object} model.Tweet
+// @Failure 404 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /tweets/{id}/retweet [post]
+// @Security ApiKeyAuth
+func (tweetController *TweetController) Retweet(c echo.Context) error {
+	retweet, err := tweetController.tweetRepository.Retweet(c.Request().Context(), c.Param("id"), util.GetUserIdFromToken(c))
+	if err != nil {
+		return err
+	}
+
+	util.SetETag(c, retweet.Version)
+	return util.Negotiate(c, http.StatusCreated, retweet)
+}
+
+// Unretweet godoc
+// @Summary Undo a retweet
+// @Description Delete your retweet of a tweet
+// @Tags tweets
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "ID of the retweeted tweet"
+// @Success 204 {object} model.Tweet
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /tweets/{id}/retweet [delete]
+// @Security ApiKeyAuth
+func (tweetController *TweetController) Unretweet(c echo.Context) error {
+	err := tweetController.tweetRepository.Unretweet(c.Request().Context(), c.Param("id"), util.GetUserIdFromToken(c))
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
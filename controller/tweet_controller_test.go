@@ -0,0 +1,63 @@
+package controller_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+)
+
+func TestTweets(t *testing.T) {
+	server := newTestServer(t)
+	anaId, anaToken := server.signUp(t, "Ana", "ana@example.com")
+	_, leoToken := server.signUp(t, "Leo", "leo@example.com")
+	_, adminToken := server.admin(t)
+
+	recorder := server.do(t, http.MethodPost, "/tweets", anaToken, map[string]string{"userId": anaId})
+	decode(t, recorder, http.StatusBadRequest, nil)
+
+	var tweet model.Tweet
+	recorder = server.do(t, http.MethodPost, "/tweets", anaToken, map[string]string{"userId": anaId, "message": "Watching #Dune tonight"})
+	decode(t, recorder, http.StatusCreated, &tweet)
+	id := tweet.ID.Hex()
+	if tweet.Entities == nil || len(tweet.Entities.Hashtags) != 1 {
+		t.Errorf("the tweet has the entities %v, want the hashtag #dune", tweet.Entities)
+	}
+
+	var tweets model.PagedTweet
+	recorder = server.do(t, http.MethodGet, "/hashtags/DUNE/tweets", leoToken, nil)
+	decode(t, recorder, http.StatusOK, &tweets)
+	if len(tweets.Data) != 1 || tweets.Data[0].ID.Hex() != id {
+		t.Errorf("#dune has %d tweets, want the tweet of Ana", len(tweets.Data))
+	}
+
+	decode(t, server.do(t, http.MethodPost, "/tweets/"+id+"/like", leoToken, nil), http.StatusOK, nil)
+	recorder = server.do(t, http.MethodPost, "/tweets/"+id+"/like", leoToken, nil)
+	decode(t, recorder, http.StatusOK, &tweet)
+	if tweet.Likes != 1 {
+		t.Errorf("liking twice left %d likes, want 1", tweet.Likes)
+	}
+	recorder = server.do(t, http.MethodDelete, "/tweets/"+id+"/like", leoToken, nil)
+	decode(t, recorder, http.StatusOK, &tweet)
+	if tweet.Likes != 0 {
+		t.Errorf("unliking left %d likes, want 0", tweet.Likes)
+	}
+
+	recorder = server.do(t, http.MethodPost, "/tweets/"+id+"/retweet", leoToken, nil)
+	decode(t, recorder, http.StatusCreated, nil)
+	recorder = server.do(t, http.MethodPost, "/tweets/"+id+"/retweet", leoToken, nil)
+	decode(t, recorder, http.StatusConflict, nil)
+	recorder = server.do(t, http.MethodDelete, "/tweets/"+id+"/retweet", leoToken, nil)
+	decode(t, recorder, http.StatusNoContent, nil)
+	recorder = server.do(t, http.MethodDelete, "/tweets/"+id+"/retweet", leoToken, nil)
+	decode(t, recorder, http.StatusNotFound, nil)
+
+	recorder = server.do(t, http.MethodDelete, "/tweets/"+id+"/user/"+anaId, anaToken, nil)
+	decode(t, recorder, http.StatusNoContent, nil)
+	recorder = server.do(t, http.MethodGet, "/tweets/"+id, anaToken, nil)
+	decode(t, recorder, http.StatusNotFound, nil)
+	recorder = server.do(t, http.MethodPost, "/tweets/"+id+"/restore", anaToken, nil)
+	decode(t, recorder, http.StatusForbidden, nil)
+	recorder = server.do(t, http.MethodPost, "/tweets/"+id+"/restore", adminToken, nil)
+	decode(t, recorder, http.StatusOK, nil)
+}
@@ -3,7 +3,10 @@ package controller
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/cbuelvasc/cinema-backend/enums"
 	"github.com/cbuelvasc/cinema-backend/exception"
 	"github.com/cbuelvasc/cinema-backend/model"
 	"github.com/cbuelvasc/cinema-backend/repository"
@@ -18,7 +21,12 @@ type UserControllerInterface interface {
 	GetAllUser(c echo.Context) error
 	GetUser(c echo.Context) error
 	UpdateUser(c echo.Context) error
+	PatchUser(c echo.Context) error
+	ChangePassword(c echo.Context) error
+	ResetCalendarToken(c echo.Context) error
+	RevokeCalendarToken(c echo.Context) error
 	DeleteUser(c echo.Context) error
+	RestoreUser(c echo.Context) error
 }
 
 type UserController struct {
@@ -39,9 +47,9 @@ func NewUserController(userRepository repository.UserRepository, authValidator *
 // @Param mediaType query string false "mediaType" Enums(xml, json)
 // @Param user body model.SignInInput true "SignIn"
 // @Success 200 {array} model.User
-// @Failure 400 {object} handler.APIError
-// @Failure 401 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 400 {object} handler.Problem
+// @Failure 401 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /signin [post]
 func (userController *UserController) AuthenticateUser(c echo.Context) error {
 	payload := new(model.SignInInput)
@@ -71,9 +79,9 @@ func (userController *UserController) AuthenticateUser(c echo.Context) error {
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param user body model.UserInput true "New User"
 // @Success 200 {object} model.User
-// @Failure 400 {object} handler.APIError
-// @Failure 409 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 400 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /signup [post]
 func (userController *UserController) SaveUser(c echo.Context) error {
 	payload := new(model.UserInput)
@@ -86,6 +94,7 @@ func (userController *UserController) SaveUser(c echo.Context) error {
 		return exception.ConflictException("User", "email", payload.Email)
 	}
 
+	payload.Role = enums.RoleUser
 	user := &model.User{UserInput: payload}
 
 	//encrypt password
@@ -99,6 +108,7 @@ func (userController *UserController) SaveUser(c echo.Context) error {
 		return err
 	}
 
+	util.SetETag(c, createdUser.Version)
 	return util.Negotiate(c, http.StatusCreated, createdUser)
 }
 
@@ -111,15 +121,40 @@ func (userController *UserController) SaveUser(c echo.Context) error {
 // @Param mediaType query string false "mediaType" Enums(xml, json)
 // @Param page query int false "page" minimum(1)
 // @Param limit query int false "size" minimum(1)
+// @Param pagination query string false "pagination" Enums(page, cursor)
+// @Param after query string false "after"
+// @Param before query string false "before"
+// @Param sort query string false "sort"
+// @Param skipCount query bool false "skipCount"
+// @Param fields query string false "fields"
+// @Param expand query string false "expand"
+// @Param includeDeleted query bool false "includeDeleted"
 // @Success 200 {array} model.User
-// @Failure 500 {object} handler.APIError
+// @Failure 500 {object} handler.Problem
 // @Router /users [get]
 // @Security ApiKeyAuth
 func (userController *UserController) GetAllUser(c echo.Context) error {
 	page, _ := strconv.ParseInt(c.QueryParam("page"), 10, 64)
 	limit, _ := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
 
-	pagedUser, _ := userController.userRepository.GetAllUser(c.Request().Context(), page, limit)
+	if documentQuery, ok := util.GetDocumentQuery(c); ok {
+		pagedDocument, err := userController.userRepository.GetAllUserDocuments(c.Request().Context(), documentQuery)
+		if err != nil {
+			return err
+		}
+		return util.Negotiate(c, http.StatusOK, pagedDocument)
+	}
+
+	var pagedUser *model.PagedUser
+	var err error
+	if cursorQuery, ok := util.GetCursorQuery(c); ok {
+		pagedUser, err = userController.userRepository.GetAllUserByCursor(c.Request().Context(), cursorQuery)
+	} else {
+		pagedUser, err = userController.userRepository.GetAllUser(c.Request().Context(), page, limit)
+	}
+	if err != nil {
+		return err
+	}
 	return util.Negotiate(c, http.StatusOK, pagedUser)
 }
 
@@ -131,9 +166,13 @@ func (userController *UserController) GetAllUser(c echo.Context) error {
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param id path string true "User ID"
+// @Param If-None-Match header string false "ETag of the cached representation"
+// @Param fields query string false "fields"
+// @Param expand query string false "expand"
+// @Param includeDeleted query bool false "includeDeleted"
 // @Success 200 {object} model.User
-// @Failure 404 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 404 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /users/{id} [get]
 // @Security ApiKeyAuth
 func (userController *UserController) GetUser(c echo.Context) error {
@@ -142,11 +181,22 @@ func (userController *UserController) GetUser(c echo.Context) error {
 		id = util.GetUserIdFromToken(c)
 	}
 
+	if documentQuery, ok := util.GetDocumentQuery(c); ok {
+		document, err := userController.userRepository.GetUserDocument(c.Request().Context(), id, documentQuery)
+		if err != nil {
+			return err
+		}
+		return util.Negotiate(c, http.StatusOK, document)
+	}
+
 	user, err := userController.userRepository.GetUser(c.Request().Context(), id)
 	if err != nil {
 		return err
 	}
 
+	if util.NotModified(c, user.Version) {
+		return c.NoContent(http.StatusNotModified)
+	}
 	return util.Negotiate(c, http.StatusOK, user)
 }
 
@@ -158,15 +208,21 @@ func (userController *UserController) GetUser(c echo.Context) error {
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param id path string true "User ID"
+// @Param If-Match header string false "ETag of the version being modified"
 // @Param user body model.UserInput true "User Info"
 // @Success 200 {object} model.User
-// @Failure 400 {object} handler.APIError
-// @Failure 404 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 400 {object} handler.Problem
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /users/{id} [put]
 // @Security ApiKeyAuth
 func (userController *UserController) UpdateUser(c echo.Context) error {
 	id := c.Param("id")
+	if err := requireOwnerOrAdmin(c, id); err != nil {
+		return err
+	}
 
 	payload := new(model.UserInput)
 
@@ -178,9 +234,191 @@ func (userController *UserController) UpdateUser(c echo.Context) error {
 	if err != nil {
 		return err
 	}
+	util.SetETag(c, user.Version)
+	return util.Negotiate(c, http.StatusOK, user)
+}
+
+// PatchUser godoc
+// @Summary Patch a user
+// @Description Partially update a user item with a JSON Merge Patch or a JSON Patch
+// @Tags users
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "User ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Param patch body object true "Merge patch or JSON patch"
+// @Success 200 {object} model.User
+// @Failure 400 {object} handler.Problem
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 409 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 415 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /users/{id} [patch]
+// @Security ApiKeyAuth
+func (userController *UserController) PatchUser(c echo.Context) error {
+	id := c.Param("id")
+	ctx := c.Request().Context()
+	if err := requireOwnerOrAdmin(c, id); err != nil {
+		return err
+	}
+
+	current, err := userController.userRepository.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	current.Password = ""
+
+	payload := new(model.UserInput)
+	if err := util.BindPatch(c, current.UserInput, payload); err != nil {
+		return err
+	}
+	if field := readOnlyUserField(current.UserInput, payload); len(field) > 0 {
+		return exception.InvalidRequestException("error.patch_read_only", field)
+	}
+	payload.UpdatedAt = time.Now()
+
+	// The patch was computed on the version just read, so it must not be
+	// written over a newer one.
+	if _, ok := util.GetExpectedVersions(ctx); !ok {
+		ctx = util.WithExpectedVersion(ctx, current.Version)
+	}
+
+	user, err := userController.userRepository.PatchUser(ctx, id, &model.User{UserInput: payload})
+	if err != nil {
+		return err
+	}
+	util.SetETag(c, user.Version)
 	return util.Negotiate(c, http.StatusOK, user)
 }
 
+// ChangePassword godoc
+// @Summary Change the password of a user
+// @Description Change your password, confirming the current one, or the password of any user as an admin
+// @Tags users
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "User ID, or me"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Param password body model.PasswordInput true "Current and new password"
+// @Success 204 {object} model.User
+// @Failure 400 {object} handler.Problem
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /users/{id}/password [put]
+// @Security ApiKeyAuth
+func (userController *UserController) ChangePassword(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+	if id == "me" {
+		id = util.GetUserIdFromToken(c)
+	}
+	if err := requireOwnerOrAdmin(c, id); err != nil {
+		return err
+	}
+
+	payload := new(model.PasswordInput)
+	if err := util.BindAndValidate(c, payload); err != nil {
+		return err
+	}
+
+	user, err := userController.userRepository.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if id == util.GetUserIdFromToken(c) {
+		if _, valid := userController.authValidator.ValidateCredentials(ctx, user.Email, payload.CurrentPassword); !valid {
+			return exception.ForbiddenException()
+		}
+	}
+
+	hashedPassword, err := util.EncryptPassword(payload.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err := userController.userRepository.UpdatePassword(ctx, id, string(hashedPassword)); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ResetCalendarToken godoc
+// @Summary Get a new calendar feed URL
+// @Description Generate the secret URL of the personal calendar feed of a user, with their upcoming bookings. The URL replaces the previous one, which stops working, and is only shown now
+// @Tags users
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "User ID, or me"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Success 201 {object} model.CalendarToken
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /users/{id}/calendar-token [post]
+// @Security ApiKeyAuth
+func (userController *UserController) ResetCalendarToken(c echo.Context) error {
+	id := c.Param("id")
+	if id == "me" {
+		id = util.GetUserIdFromToken(c)
+	}
+	if err := requireOwnerOrAdmin(c, id); err != nil {
+		return err
+	}
+
+	token, err := util.NewCalendarToken()
+	if err != nil {
+		return err
+	}
+	if err := userController.userRepository.SetCalendarToken(c.Request().Context(), id, token); err != nil {
+		return err
+	}
+
+	path := enums.BasePath + strings.Replace(enums.GetUserCalendar, ":token", token, 1)
+	calendarToken := &model.CalendarToken{
+		Token: token,
+		URL:   c.Scheme() + "://" + c.Request().Host + path,
+	}
+	return util.Negotiate(c, http.StatusCreated, calendarToken)
+}
+
+// RevokeCalendarToken godoc
+// @Summary Revoke the calendar feed URL
+// @Description Stop the personal calendar feed of a user, until a new URL is generated
+// @Tags users
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "User ID, or me"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Success 204 {object} model.User
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /users/{id}/calendar-token [delete]
+// @Security ApiKeyAuth
+func (userController *UserController) RevokeCalendarToken(c echo.Context) error {
+	id := c.Param("id")
+	if id == "me" {
+		id = util.GetUserIdFromToken(c)
+	}
+	if err := requireOwnerOrAdmin(c, id); err != nil {
+		return err
+	}
+
+	if err := userController.userRepository.SetCalendarToken(c.Request().Context(), id, ""); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
 // DeleteUser godoc
 // @Summary Delete a user
 // @Description Delete a new user item
@@ -189,13 +427,19 @@ func (userController *UserController) UpdateUser(c echo.Context) error {
 // @Produce json
 // @Param mediaType query string false "mediaType" Enums(json, xml)
 // @Param id path string true "User ID"
+// @Param If-Match header string false "ETag of the version being modified"
 // @Success 204 {object} model.User
-// @Failure 404 {object} handler.APIError
-// @Failure 500 {object} handler.APIError
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
 // @Router /users/{id} [delete]
 // @Security ApiKeyAuth
 func (userController *UserController) DeleteUser(c echo.Context) error {
 	id := c.Param("id")
+	if err := requireOwnerOrAdmin(c, id); err != nil {
+		return err
+	}
 
 	err := userController.userRepository.DeleteUser(c.Request().Context(), id)
 	if err != nil {
@@ -204,6 +448,60 @@ func (userController *UserController) DeleteUser(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// RestoreUser godoc
+// @Summary Restore a user
+// @Description Restore a deleted user item
+// @Tags users
+// @Accept json,xml
+// @Produce json
+// @Param mediaType query string false "mediaType" Enums(json, xml)
+// @Param id path string true "User ID"
+// @Param If-Match header string false "ETag of the version being modified"
+// @Success 200 {object} model.User
+// @Failure 403 {object} handler.Problem
+// @Failure 404 {object} handler.Problem
+// @Failure 412 {object} handler.Problem
+// @Failure 500 {object} handler.Problem
+// @Router /users/{id}/restore [post]
+// @Security ApiKeyAuth
+func (userController *UserController) RestoreUser(c echo.Context) error {
+	id := c.Param("id")
+
+	user, err := userController.userRepository.RestoreUser(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	util.SetETag(c, user.Version)
+	return util.Negotiate(c, http.StatusOK, user)
+}
+
+// requireOwnerOrAdmin lets only the user id, or an admin, change the account.
+func requireOwnerOrAdmin(c echo.Context, id string) error {
+	if id != util.GetUserIdFromToken(c) && util.GetUserRoleFromToken(c) != enums.RoleAdmin {
+		return exception.ForbiddenException()
+	}
+	return nil
+}
+
+// readOnlyUserField returns the first field a patch changed that can't be
+// patched: the email and role are managed apart, and passwords only change
+// through ChangePassword.
+func readOnlyUserField(current *model.UserInput, patched *model.UserInput) string {
+	switch {
+	case patched.Email != current.Email:
+		return "email"
+	case patched.Role != current.Role:
+		return "role"
+	case len(patched.Password) > 0:
+		return "password"
+	case !patched.CreatedAt.Equal(current.CreatedAt):
+		return "created_at"
+	case !patched.UpdatedAt.Equal(current.UpdatedAt):
+		return "updated_at"
+	}
+	return ""
+}
+
 func beforeSave(user *model.User) (err error) {
 	hashedPassword, err := util.EncryptPassword(user.Password)
 	if err != nil {
@@ -0,0 +1,59 @@
+package controller_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/util"
+)
+
+func TestUsers(t *testing.T) {
+	server := newTestServer(t)
+	anaId, anaToken := server.signUp(t, "Ana", "ana@example.com")
+	leoId, leoToken := server.signUp(t, "Leo", "leo@example.com")
+	_, adminToken := server.admin(t)
+
+	recorder := server.do(t, http.MethodPost, "/signup", "", map[string]string{
+		"name": "Ana", "lastname": "Test", "email": "ana@example.com", "password": "secret123",
+	})
+	decode(t, recorder, http.StatusConflict, nil)
+
+	recorder = server.do(t, http.MethodPost, "/signin", "", map[string]string{"email": "ana@example.com", "password": "wrong"})
+	decode(t, recorder, http.StatusUnauthorized, nil)
+
+	recorder = server.do(t, http.MethodGet, "/users/"+anaId, "", nil)
+	decode(t, recorder, http.StatusUnauthorized, nil)
+
+	var user model.User
+	recorder = server.do(t, http.MethodGet, "/users/"+anaId, leoToken, nil)
+	decode(t, recorder, http.StatusOK, &user)
+	if user.Name != "Ana" || len(user.Password) > 0 {
+		t.Errorf("got the user %s with the password %q", user.Name, user.Password)
+	}
+	etag := recorder.Header().Get("ETag")
+
+	patch := `{"biography": "Cinephile"}`
+	recorder = server.request(http.MethodPatch, "/users/"+anaId, leoToken, util.MIMEMergePatch, patch)
+	decode(t, recorder, http.StatusForbidden, nil)
+	recorder = server.do(t, http.MethodDelete, "/users/"+anaId, leoToken, nil)
+	decode(t, recorder, http.StatusForbidden, nil)
+
+	for _, patch := range []string{`{"email": "ana@example.org"}`, `{"role": "ROLE_ADMIN"}`, `{"password": "hijacked"}`} {
+		recorder = server.request(http.MethodPatch, "/users/"+anaId, anaToken, util.MIMEMergePatch, patch)
+		decode(t, recorder, http.StatusBadRequest, nil)
+	}
+
+	recorder = server.request(http.MethodPatch, "/users/"+anaId, anaToken, util.MIMEMergePatch, patch, "If-Match", etag)
+	decode(t, recorder, http.StatusOK, &user)
+	if user.Biography != "Cinephile" || user.Email != "ana@example.com" {
+		t.Errorf("patched the user to %q with the email %s", user.Biography, user.Email)
+	}
+	recorder = server.request(http.MethodPatch, "/users/"+anaId, anaToken, util.MIMEMergePatch, patch, "If-Match", etag)
+	decode(t, recorder, http.StatusPreconditionFailed, nil)
+
+	recorder = server.do(t, http.MethodDelete, "/users/"+leoId, adminToken, nil)
+	decode(t, recorder, http.StatusNoContent, nil)
+	recorder = server.do(t, http.MethodGet, "/users/"+leoId, anaToken, nil)
+	decode(t, recorder, http.StatusNotFound, nil)
+}
@@ -0,0 +1,7 @@
+package enums
+
+const (
+	DeletePolicyRestrict = "restrict"
+	DeletePolicyCascade  = "cascade"
+	DeletePolicyNullify  = "nullify"
+)
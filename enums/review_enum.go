@@ -0,0 +1,9 @@
+package enums
+
+const (
+	ReviewSortHelpful = "helpful"
+	ReviewSortDate    = "date"
+
+	ReviewVoteHelpful = "helpful"
+	ReviewVoteReport  = "report"
+)
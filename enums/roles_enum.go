@@ -0,0 +1,6 @@
+package enums
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
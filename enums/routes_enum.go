@@ -6,38 +6,101 @@ const (
 	SignIn = "/signin"
 	SignUp = "/signup"
 
-	GetUsers       = "/users"
-	GetUserById    = "/users/:id"
-	UpdateUserById = "/users/:id"
-	DeleteUserById = "/users/:id"
-
-	GetMovies       = "/movies"
-	CreateMovie     = "/movies"
-	GetMovieById    = "/movies/:id"
-	UpdateMovieById = "/movies/:id"
-	DeleteMovieById = "/movies/:id"
-
-	GetCountries      = "/countries"
-	CreateCountry     = "/countries"
-	GetCountryById    = "/countries/:id"
-	UpdateCountryById = "/countries/:id"
-	DeleteCountryById = "/countries/:id"
-
-	GetStates       = "/states"
-	CreateState     = "/states"
-	GetStateById    = "/states/:id"
-	UpdateStateById = "/states/:id"
-	DeleteStateById = "/states/:id"
-
-	GetCities      = "/cities"
-	CreateCity     = "/cities"
-	GetCityById    = "/cities/:id"
-	UpdateCityById = "/cities/:id"
-	DeleteCityById = "/cities/:id"
-
-	GetTweets       = "/tweets"
-	CreateTweets    = "/tweets"
-	GetTweetById    = "/tweets/:id"
-	UpdateTweetById = "/tweets/:id"
-	DeleteTweetById = "/tweets/:id/user/:userId"
+	GetUsers            = "/users"
+	GetUserById         = "/users/:id"
+	UpdateUserById      = "/users/:id"
+	PatchUserById       = "/users/:id"
+	DeleteUserById      = "/users/:id"
+	RestoreUserById     = "/users/:id/restore"
+	ChangePassword      = "/users/:id/password"
+	ResetCalendarToken  = "/users/:id/calendar-token"
+	RevokeCalendarToken = "/users/:id/calendar-token"
+	FollowUser          = "/users/:id/follow"
+	UnfollowUser        = "/users/:id/follow"
+	GetFollowers        = "/users/:id/followers"
+	GetFollowing        = "/users/:id/following"
+
+	GetMovies              = "/movies"
+	CreateMovie            = "/movies"
+	SearchMovies           = "/movies/search"
+	GetMovieById           = "/movies/:id"
+	UpdateMovieById        = "/movies/:id"
+	PatchMovieById         = "/movies/:id"
+	DeleteMovieById        = "/movies/:id"
+	RestoreMovieById       = "/movies/:id/restore"
+	ImportMovies           = "/movies/import"
+	ExportMovies           = "/movies/export"
+	GetMovieTranslations   = "/movies/:id/translations"
+	SaveMovieTranslation   = "/movies/:id/translations/:locale"
+	DeleteMovieTranslation = "/movies/:id/translations/:locale"
+
+	GetCountries       = "/countries"
+	CreateCountry      = "/countries"
+	GetCountryById     = "/countries/:id"
+	UpdateCountryById  = "/countries/:id"
+	PatchCountryById   = "/countries/:id"
+	DeleteCountryById  = "/countries/:id"
+	RestoreCountryById = "/countries/:id/restore"
+	ImportCountries    = "/countries/import"
+	ExportCountries    = "/countries/export"
+
+	GetStates        = "/states"
+	CreateState      = "/states"
+	GetStateById     = "/states/:id"
+	UpdateStateById  = "/states/:id"
+	PatchStateById   = "/states/:id"
+	DeleteStateById  = "/states/:id"
+	RestoreStateById = "/states/:id/restore"
+	ImportStates     = "/states/import"
+	ExportStates     = "/states/export"
+
+	GetCities       = "/cities"
+	CreateCity      = "/cities"
+	GetCityById     = "/cities/:id"
+	UpdateCityById  = "/cities/:id"
+	PatchCityById   = "/cities/:id"
+	DeleteCityById  = "/cities/:id"
+	RestoreCityById = "/cities/:id/restore"
+	ImportCities    = "/cities/import"
+	ExportCities    = "/cities/export"
+
+	GetCinemas       = "/cinemas"
+	GetCinemaById    = "/cinemas/:id"
+	GetNearbyCinemas = "/cinemas/nearby"
+
+	GetCinemaCalendar = "/cinemas/:id/calendar"
+
+	GetNowShowing = "/movies/now-showing"
+	GetComingSoon = "/movies/coming-soon"
+
+	GetMovieReviews   = "/movies/:id/reviews"
+	CreateMovieReview = "/movies/:id/reviews"
+	GetReviewById     = "/reviews/:id"
+	UpdateReviewById  = "/reviews/:id"
+	DeleteReviewById  = "/reviews/:id"
+	MarkReviewHelpful = "/reviews/:id/helpful"
+	ReportReview      = "/reviews/:id/report"
+
+	GetBookings       = "/bookings"
+	GetBookingById    = "/bookings/:id"
+	CreateBooking     = "/bookings"
+	CancelBookingById = "/bookings/:id"
+	CheckInBooking    = "/bookings/:id/check-in"
+	GetBookingEvent   = "/bookings/:id/calendar"
+	GetUserCalendar   = "/calendar/:token"
+
+	GetTweets        = "/tweets"
+	CreateTweets     = "/tweets"
+	GetTweetById     = "/tweets/:id"
+	UpdateTweetById  = "/tweets/:id"
+	DeleteTweetById  = "/tweets/:id/user/:userId"
+	RestoreTweetById = "/tweets/:id/restore"
+	GetTimeline      = "/timeline"
+	GetConversation  = "/tweets/:id/conversation"
+	LikeTweet        = "/tweets/:id/like"
+	UnlikeTweet      = "/tweets/:id/like"
+	Retweet          = "/tweets/:id/retweet"
+	Unretweet        = "/tweets/:id/retweet"
+	GetHashtagTweets = "/hashtags/:tag/tweets"
+	GetMentionTweets = "/users/:id/mentions"
 )
@@ -1,40 +1,106 @@
 package exception
 
 import (
-	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/cbuelvasc/cinema-backend/i18n"
 	"github.com/labstack/echo/v4"
 )
 
 func ResourceNotFoundException(resourceName string, fieldName string, fieldValue string) error {
-	msg := fmt.Sprintf("%s not found with %s: %s", resourceName, fieldName, fieldValue)
+	msg := i18n.NewMessage("error.not_found", i18n.Resource(resourceName), i18n.Field(fieldName), fieldValue)
 	return echo.NewHTTPError(http.StatusNotFound, msg)
 }
 
 func TweetNotFoundException(resourceName string, fieldNameOne string, fieldValueOne string, fieldNameTwo string, fieldValueTwo string) error {
-	msg := fmt.Sprintf("%s not found with %s: %s and %s: %s", resourceName, fieldNameOne, fieldValueOne, fieldNameTwo, fieldValueTwo)
-	return echo.NewHTTPError(http.StatusBadRequest, msg)
+	msg := i18n.NewMessage("error.not_found_two", i18n.Resource(resourceName), i18n.Field(fieldNameOne), fieldValueOne, i18n.Field(fieldNameTwo), fieldValueTwo)
+	return echo.NewHTTPError(http.StatusNotFound, msg)
+}
+
+// ResourceNotFoundByFieldsException is the 404 of a resource looked up by
+// two fields, such as the follow of a follower and a followed user.
+func ResourceNotFoundByFieldsException(resourceName string, fieldNameOne string, fieldValueOne string, fieldNameTwo string, fieldValueTwo string) error {
+	msg := i18n.NewMessage("error.not_found_two", i18n.Resource(resourceName), i18n.Field(fieldNameOne), fieldValueOne, i18n.Field(fieldNameTwo), fieldValueTwo)
+	return echo.NewHTTPError(http.StatusNotFound, msg)
+}
+
+// ResourcesNotFoundException is the 404 of an empty list of resources,
+// named in plural.
+func ResourcesNotFoundException(resourceName string) error {
+	msg := i18n.NewMessage("error.none_found", i18n.Resource(resourceName))
+	return echo.NewHTTPError(http.StatusNotFound, msg)
+}
+
+func FilteredResourcesNotFoundException(resourceName string, fieldName string, fieldValue string) error {
+	msg := i18n.NewMessage("error.none_found_with", i18n.Resource(resourceName), i18n.Field(fieldName), fieldValue)
+	return echo.NewHTTPError(http.StatusNotFound, msg)
 }
 
 func BadRequestException(msg string) error {
 	return echo.NewHTTPError(http.StatusBadRequest, msg)
 }
 
+// InvalidRequestException is the 400 of a request that cannot be processed,
+// explained by the catalog text of key.
+func InvalidRequestException(key string, params ...interface{}) error {
+	return echo.NewHTTPError(http.StatusBadRequest, i18n.NewMessage(key, params...))
+}
+
 func NotFoundRequestException(msg string) error {
 	return echo.NewHTTPError(http.StatusNotFound, msg)
 }
 
 func ParameterException(parameterName string) error {
-	msg := fmt.Sprintf("Parameter named %s is required", parameterName)
+	msg := i18n.NewMessage("error.parameter_required", i18n.Field(parameterName))
+	return echo.NewHTTPError(http.StatusBadRequest, msg)
+}
+
+func InvalidParameterException(parameterName string, value string) error {
+	msg := i18n.NewMessage("error.parameter_invalid", i18n.Field(parameterName), value)
 	return echo.NewHTTPError(http.StatusBadRequest, msg)
 }
 
 func ConflictException(resourceName string, fieldName string, fieldValue string) error {
-	msg := fmt.Sprintf("%s with %s: %s already exists", resourceName, fieldName, fieldValue)
+	msg := i18n.NewMessage("error.conflict", i18n.Resource(resourceName), i18n.Field(fieldName), fieldValue)
 	return echo.NewHTTPError(http.StatusConflict, msg)
 }
 
+func ConflictRequestException(msg string) error {
+	return echo.NewHTTPError(http.StatusConflict, msg)
+}
+
+// RequestConflictException is the 409 of a request that conflicts with the
+// current state of the resource, explained by the catalog text of key.
+func RequestConflictException(key string, params ...interface{}) error {
+	return echo.NewHTTPError(http.StatusConflict, i18n.NewMessage(key, params...))
+}
+
+// ChildrenConflictException is the 409 of deleting a resource that still
+// has count children, named in plural.
+func ChildrenConflictException(resourceName string, id string, count int64, childrenName string) error {
+	msg := i18n.NewMessage("error.has_children", i18n.Resource(resourceName), id, count, i18n.Resource(childrenName))
+	return echo.NewHTTPError(http.StatusConflict, msg)
+}
+
+func UnsupportedMediaTypeException(mediaTypes ...string) error {
+	msg := i18n.NewMessage("error.unsupported_media_type", strings.Join(mediaTypes, ", "))
+	return echo.NewHTTPError(http.StatusUnsupportedMediaType, msg)
+}
+
+func NotAcceptableException(mediaTypes ...string) error {
+	msg := i18n.NewMessage("error.not_acceptable", strings.Join(mediaTypes, ", "))
+	return echo.NewHTTPError(http.StatusNotAcceptable, msg)
+}
+
+func PreconditionFailedException() error {
+	return echo.NewHTTPError(http.StatusPreconditionFailed, i18n.NewMessage("error.precondition_failed"))
+}
+
 func UnauthorizedException() error {
 	return echo.ErrUnauthorized
 }
+
+func ForbiddenException() error {
+	return echo.NewHTTPError(http.StatusForbidden, i18n.NewMessage("error.forbidden"))
+}
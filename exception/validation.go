@@ -0,0 +1,102 @@
+package exception
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/cbuelvasc/cinema-backend/i18n"
+	"github.com/go-playground/validator"
+	"github.com/labstack/echo/v4"
+)
+
+// FieldError is a member of a request body that failed validation.
+type FieldError struct {
+	Field   string `json:"field" xml:"field"`
+	Rule    string `json:"rule" xml:"rule"`
+	Param   string `json:"param,omitempty" xml:"param,omitempty"`
+	Message string `json:"message,omitempty" xml:"message,omitempty"`
+}
+
+// Translate returns the message of fieldError in language.
+func (fieldError FieldError) Translate(language string) string {
+	key := "validation." + fieldError.Rule
+	if !i18n.Exists(key) {
+		key = "validation.invalid"
+	}
+	return i18n.NewMessage(key, i18n.Field(fieldError.Field), fieldError.Param).Translate(language)
+}
+
+// ValidationError is the message of the error returned for an invalid
+// request body.
+type ValidationError struct {
+	Message string
+	Errors  []FieldError
+}
+
+func (validationError *ValidationError) Error() string {
+	return validationError.Message
+}
+
+// embeddedFieldName names embedded structs, whose fields are members of the
+// enclosing object.
+const embeddedFieldName = "^"
+
+// FieldName names the fields of validation errors as the clients send them,
+// after their json tag.
+func FieldName(field reflect.StructField) string {
+	if field.Anonymous && len(field.Tag.Get("json")) == 0 {
+		return embeddedFieldName
+	}
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if len(name) == 0 {
+		return field.Name
+	}
+	return name
+}
+
+// ValidationException turns the error of a failed validation into a 400 that
+// lists the failing fields. Other errors are plain bad requests.
+func ValidationException(err error) error {
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return BadRequestException(err.Error())
+	}
+
+	fieldErrors := make([]FieldError, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		// The namespace starts with the name of the validated type.
+		var path []string
+		for _, name := range strings.Split(fieldError.Namespace(), ".")[1:] {
+			if name != embeddedFieldName {
+				path = append(path, name)
+			}
+		}
+		field := strings.Join(path, ".")
+		fieldErrors = append(fieldErrors, FieldError{
+			Field: field,
+			Rule:  fieldError.Tag(),
+			Param: fieldError.Param(),
+		})
+	}
+	return echo.NewHTTPError(http.StatusBadRequest, &ValidationError{Message: err.Error(), Errors: fieldErrors})
+}
+
+// Translate returns the text of the message of an error in language.
+func Translate(message interface{}, language string) (string, bool) {
+	switch message := message.(type) {
+	case *i18n.Message:
+		return message.Translate(language), true
+	case *ValidationError:
+		texts := make([]string, 0, len(message.Errors))
+		for _, fieldError := range message.Errors {
+			texts = append(texts, fieldError.Translate(language))
+		}
+		return strings.Join(texts, ", "), true
+	case string:
+		return message, true
+	case error:
+		return message.Error(), true
+	}
+	return "", false
+}
@@ -1,9 +1,15 @@
 package handler
 
 import (
+	"encoding/xml"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/cbuelvasc/cinema-backend/config"
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/i18n"
 	"github.com/cbuelvasc/cinema-backend/util"
 	"github.com/labstack/echo/v4"
 )
@@ -16,6 +22,34 @@ type APIError struct {
 	Timestamp int64  `json:"timestamp" xml:"timestamp"`
 }
 
+// Problem is an RFC 7807 problem details error response.
+type Problem struct {
+	XMLName   xml.Name               `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+	Type      string                 `json:"type" xml:"type"`
+	Title     string                 `json:"title" xml:"title"`
+	Status    int                    `json:"status" xml:"status"`
+	Detail    string                 `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance  string                 `json:"instance" xml:"instance"`
+	RequestId string                 `json:"requestId,omitempty" xml:"requestId,omitempty"`
+	Errors    []exception.FieldError `json:"errors,omitempty" xml:"errors>error,omitempty"`
+}
+
+// problemTypes name the kind of error of each status, the last part of the
+// type of a problem.
+var problemTypes = map[int]string{
+	http.StatusBadRequest:           "bad-request",
+	http.StatusUnauthorized:         "unauthorized",
+	http.StatusForbidden:            "forbidden",
+	http.StatusNotFound:             "not-found",
+	http.StatusMethodNotAllowed:     "method-not-allowed",
+	http.StatusNotAcceptable:        "not-acceptable",
+	http.StatusConflict:             "conflict",
+	http.StatusPreconditionFailed:   "precondition-failed",
+	http.StatusUnsupportedMediaType: "unsupported-media-type",
+	http.StatusTooManyRequests:      "too-many-requests",
+	http.StatusInternalServerError:  "internal-error",
+}
+
 func ErrorHandler(err error, c echo.Context) {
 	he, ok := err.(*echo.HTTPError)
 	if ok {
@@ -31,26 +65,69 @@ func ErrorHandler(err error, c echo.Context) {
 		}
 	}
 
-	code := he.Code
-	message := he.Message
-	if m, ok := he.Message.(string); ok {
-		message = &APIError{
-			Status:    code,
-			Message:   m,
-			Path:      c.Request().RequestURI,
-			Timestamp: time.Now().Unix(),
-		}
-	}
+	language := util.Language(c)
 
 	// Send response
 	if !c.Response().Committed {
 		if c.Request().Method == http.MethodHead {
-			err = c.NoContent(code)
+			err = c.NoContent(he.Code)
+		} else if config.ErrorFormat == "legacy" {
+			err = util.NegotiateOrDefault(c, he.Code, legacyError(he, c, language))
 		} else {
-			util.Negotiate(c, code, message)
+			err = util.NegotiateProblem(c, he.Code, problem(he, c, language))
 		}
 		if err != nil {
 			c.Logger().Error(err)
 		}
 	}
 }
+
+func problem(he *echo.HTTPError, c echo.Context, language string) *Problem {
+	problemType := "about:blank"
+	if name, ok := problemTypes[he.Code]; ok {
+		problemType = config.ProblemTypeBase + name
+	}
+
+	title := http.StatusText(he.Code)
+	if key := fmt.Sprintf("status.%d", he.Code); i18n.Exists(key) {
+		title = i18n.NewMessage(key).Translate(language)
+	}
+
+	problem := &Problem{
+		Type:      problemType,
+		Title:     title,
+		Status:    he.Code,
+		Instance:  c.Request().RequestURI,
+		RequestId: c.Response().Header().Get(echo.HeaderXRequestID),
+	}
+
+	if validationError, ok := he.Message.(*exception.ValidationError); ok {
+		problem.Type = config.ProblemTypeBase + "validation-error"
+		problem.Detail = i18n.NewMessage("error.invalid_body").Translate(language)
+		for _, fieldError := range validationError.Errors {
+			fieldError.Message = fieldError.Translate(language)
+			problem.Errors = append(problem.Errors, fieldError)
+		}
+		return problem
+	}
+
+	if detail, ok := exception.Translate(he.Message, language); ok && !strings.EqualFold(detail, problem.Title) && !strings.EqualFold(detail, http.StatusText(he.Code)) {
+		problem.Detail = detail
+	}
+	return problem
+}
+
+// legacyError is the error response used before problem details, sent when
+// ERROR_FORMAT is legacy.
+func legacyError(he *echo.HTTPError, c echo.Context, language string) interface{} {
+	message := he.Message
+	if m, ok := exception.Translate(message, language); ok {
+		return &APIError{
+			Status:    he.Code,
+			Message:   m,
+			Path:      c.Request().RequestURI,
+			Timestamp: time.Now().Unix(),
+		}
+	}
+	return message
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"github.com/labstack/echo/v4"
+)
+
+// PreconditionConfig makes writes conditional on the If-Match header: the
+// versions it lists travel in the request context and repositories reject
+// the write when the stored document is at none of them. If-Match: * matches
+// any version, and weak tags match none, so a header of weak tags only fails
+// with 412.
+func PreconditionConfig(e *echo.Echo) {
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			switch c.Request().Method {
+			case http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodPost:
+			default:
+				return next(c)
+			}
+
+			ifMatch := strings.TrimSpace(strings.Join(c.Request().Header.Values(util.HeaderIfMatch), ","))
+			if len(ifMatch) == 0 {
+				return next(c)
+			}
+
+			var versions []int64
+			for _, etag := range strings.Split(ifMatch, ",") {
+				etag = strings.TrimSpace(etag)
+				if etag == "*" {
+					return next(c)
+				}
+				// If-Match compares strongly: weak tags never match.
+				if strings.HasPrefix(etag, "W/") {
+					continue
+				}
+				if version, ok := util.ParseETag(etag); ok {
+					versions = append(versions, version)
+				}
+			}
+			if len(versions) == 0 {
+				return exception.PreconditionFailedException()
+			}
+
+			ctx := util.WithExpectedVersion(c.Request().Context(), versions...)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	})
+}
@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// RequestIDConfig gives every request an id, the X-Request-ID header it came
+// with or a new one, which is sent back in the response and in errors.
+func RequestIDConfig(e *echo.Echo) {
+	e.Use(middleware.RequestID())
+}
@@ -0,0 +1,72 @@
+package i18n
+
+var catalogEN = map[string]string{
+	"error.not_found":              "{0} not found with {1}: {2}",
+	"error.not_found_two":          "{0} not found with {1}: {2} and {3}: {4}",
+	"error.none_found":             "{0} not found",
+	"error.none_found_with":        "{0} not found with {1}: {2}",
+	"error.conflict":               "{0} with {1}: {2} already exists",
+	"error.has_children":           "{0} with id: {1} still has {2} {3}",
+	"error.parameter_required":     "Parameter named {0} is required",
+	"error.parameter_invalid":      "Parameter named {0} is invalid: {1}",
+	"error.unsupported_media_type": "Unsupported media type, expected one of: {0}",
+	"error.not_acceptable":         "Not acceptable, available media types: {0}",
+	"error.precondition_failed":    "The resource has been modified since it was read",
+	"error.forbidden":              "Forbidden",
+	"error.invalid_body":           "The request body is invalid",
+	"error.follow_self":            "Users cannot follow themselves",
+	"error.showtime_started":       "The showtime has already started",
+	"error.seats_taken":            "Seats already taken: {0}",
+	"error.unknown_seats":          "Seats not in the room: {0}",
+	"error.booking_checked_in":     "The booking has already been checked in",
+
+	"error.invalid_merge_patch":      "Invalid merge patch: {0}",
+	"error.invalid_json_patch":       "Invalid JSON patch: {0}",
+	"error.invalid_patched_document": "The patched document is invalid: {0}",
+	"error.patch_missing_value":      "Missing value in {0} operation",
+	"error.patch_invalid_value":      "Invalid value in {0} operation: {1}",
+	"error.patch_test_failed":        "Test failed at {0}",
+	"error.patch_move_into_child":    "Cannot move {0} into one of its children",
+	"error.patch_invalid_operation":  "Invalid patch operation: {0}",
+	"error.patch_invalid_path":       "Invalid path: {0}",
+	"error.patch_path_not_found":     "Path not found: {0}",
+	"error.patch_invalid_index":      "Invalid array index: {0}",
+	"error.patch_read_only":          "{0} cannot be patched",
+
+	"error.invalid_csv_header":         "Invalid CSV header: {0}",
+	"error.invalid_csv_row":            "Invalid CSV row: {0}",
+	"error.unknown_column":             "Unknown column \"{0}\", expected: {1}",
+	"error.invalid_json":               "Invalid JSON: {0}",
+	"error.unknown_field":              "Unknown field \"{0}\"",
+	"error.field_not_scalar":           "Field \"{0}\" must be a string, a number or a boolean",
+	"error.column_not_number":          "Column \"{0}\" must be a number: {1}",
+	"error.state_and_country_required": "State and country are required",
+
+	"validation.required": "{0} is required",
+	"validation.email":    "{0} must be a valid email address",
+	"validation.min":      "{0} must be at least {1}",
+	"validation.max":      "{0} must be at most {1}",
+	"validation.gte":      "{0} must be at least {1}",
+	"validation.lte":      "{0} must be at most {1}",
+	"validation.len":      "{0} must have a length of {1}",
+	"validation.oneof":    "{0} must be one of: {1}",
+	"validation.url":      "{0} must be a valid URL",
+	"validation.timezone": "{0} must be an IANA time zone, such as America/Bogota",
+	"validation.username": "{0} must have 1 to 15 letters, digits or underscores",
+	"validation.invalid":  "{0} is invalid",
+
+	"status.400": "Bad Request",
+	"status.401": "Unauthorized",
+	"status.403": "Forbidden",
+	"status.404": "Not Found",
+	"status.405": "Method Not Allowed",
+	"status.406": "Not Acceptable",
+	"status.409": "Conflict",
+	"status.412": "Precondition Failed",
+	"status.415": "Unsupported Media Type",
+	"status.429": "Too Many Requests",
+	"status.500": "Internal Server Error",
+
+	"calendar.seats":    "Seats: {0}",
+	"calendar.bookings": "Bookings of {0}",
+}
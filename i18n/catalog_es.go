@@ -0,0 +1,155 @@
+package i18n
+
+var catalogES = map[string]string{
+	"error.not_found":              "No se encontró {0} con {1} {2}",
+	"error.not_found_two":          "No se encontró {0} con {1} {2} y {3} {4}",
+	"error.none_found":             "No se encontraron {0}",
+	"error.none_found_with":        "No se encontraron {0} con {1} {2}",
+	"error.conflict":               "Ya existe {0} con {1} {2}",
+	"error.has_children":           "No se puede eliminar {0} con id {1}: todavía tiene {2} {3}",
+	"error.parameter_required":     "El parámetro {0} es obligatorio",
+	"error.parameter_invalid":      "El parámetro {0} no es válido: {1}",
+	"error.unsupported_media_type": "Tipo de contenido no admitido, se esperaba uno de: {0}",
+	"error.not_acceptable":         "Ninguno de los formatos aceptados está disponible, los disponibles son: {0}",
+	"error.precondition_failed":    "El recurso ha sido modificado desde que se leyó",
+	"error.forbidden":              "Prohibido",
+	"error.invalid_body":           "El cuerpo de la solicitud no es válido",
+	"error.follow_self":            "Los usuarios no pueden seguirse a sí mismos",
+	"error.showtime_started":       "La función ya comenzó",
+	"error.seats_taken":            "Asientos ya ocupados: {0}",
+	"error.unknown_seats":          "Asientos que no están en la sala: {0}",
+	"error.booking_checked_in":     "Ya se registró la llegada de la reserva",
+
+	"error.invalid_merge_patch":      "Merge patch no válido: {0}",
+	"error.invalid_json_patch":       "JSON Patch no válido: {0}",
+	"error.invalid_patched_document": "El documento modificado no es válido: {0}",
+	"error.patch_missing_value":      "Falta el valor de la operación {0}",
+	"error.patch_invalid_value":      "Valor no válido en la operación {0}: {1}",
+	"error.patch_test_failed":        "La prueba falló en {0}",
+	"error.patch_move_into_child":    "No se puede mover {0} dentro de uno de sus hijos",
+	"error.patch_invalid_operation":  "Operación de patch no válida: {0}",
+	"error.patch_invalid_path":       "Ruta no válida: {0}",
+	"error.patch_path_not_found":     "No se encontró la ruta: {0}",
+	"error.patch_invalid_index":      "Índice de arreglo no válido: {0}",
+	"error.patch_read_only":          "{0} no se puede modificar con un parche",
+
+	"error.invalid_csv_header":         "Encabezado CSV no válido: {0}",
+	"error.invalid_csv_row":            "Fila CSV no válida: {0}",
+	"error.unknown_column":             "Columna desconocida \"{0}\", se esperaba: {1}",
+	"error.invalid_json":               "JSON no válido: {0}",
+	"error.unknown_field":              "Campo desconocido \"{0}\"",
+	"error.field_not_scalar":           "El campo \"{0}\" debe ser un texto, un número o un booleano",
+	"error.column_not_number":          "La columna \"{0}\" debe ser un número: {1}",
+	"error.state_and_country_required": "El estado y el país son obligatorios",
+
+	"validation.required": "El campo {0} es obligatorio",
+	"validation.email":    "El campo {0} debe ser un correo electrónico válido",
+	"validation.min":      "El campo {0} debe ser como mínimo {1}",
+	"validation.max":      "El campo {0} debe ser como máximo {1}",
+	"validation.gte":      "El campo {0} debe ser como mínimo {1}",
+	"validation.lte":      "El campo {0} debe ser como máximo {1}",
+	"validation.len":      "El campo {0} debe tener una longitud de {1}",
+	"validation.oneof":    "El campo {0} debe ser uno de: {1}",
+	"validation.url":      "El campo {0} debe ser una URL válida",
+	"validation.timezone": "El campo {0} debe ser una zona horaria IANA, como America/Bogota",
+	"validation.username": "El campo {0} debe tener de 1 a 15 letras, dígitos o guiones bajos",
+	"validation.invalid":  "El campo {0} no es válido",
+
+	"status.400": "Solicitud incorrecta",
+	"status.401": "No autorizado",
+	"status.403": "Prohibido",
+	"status.404": "No encontrado",
+	"status.405": "Método no permitido",
+	"status.406": "No aceptable",
+	"status.409": "Conflicto",
+	"status.412": "Precondición fallida",
+	"status.415": "Tipo de contenido no admitido",
+	"status.429": "Demasiadas solicitudes",
+	"status.500": "Error interno del servidor",
+
+	"calendar.seats":    "Asientos: {0}",
+	"calendar.bookings": "Reservas de {0}",
+
+	"resource.User":        "el usuario",
+	"resource.Tweet":       "el tweet",
+	"resource.Tweets":      "tweets",
+	"resource.Movie":       "la película",
+	"resource.Country":     "el país",
+	"resource.Countries":   "países",
+	"resource.State":       "el estado",
+	"resource.States":      "estados",
+	"resource.City":        "la ciudad",
+	"resource.Cities":      "ciudades",
+	"resource.Cinema":      "el cine",
+	"resource.Room":        "la sala",
+	"resource.Translation": "la traducción",
+	"resource.Review":      "la reseña",
+	"resource.Follow":      "el seguimiento",
+	"resource.Showtime":    "la función",
+	"resource.Booking":     "la reserva",
+	"resource.Calendar":    "el calendario",
+	"resource.states":      "estados",
+	"resource.cities":      "ciudades",
+	"resource.cinemas":     "cines",
+
+	"field.name":           "nombre",
+	"field.lastname":       "apellido",
+	"field.email":          "correo electrónico",
+	"field.password":       "contraseña",
+	"field.newPassword":    "contraseña nueva",
+	"field.title":          "título",
+	"field.format":         "formato",
+	"field.releaseYear":    "año de estreno",
+	"field.releaseMonth":   "mes de estreno",
+	"field.releaseDay":     "día de estreno",
+	"field.message":        "mensaje",
+	"field.capacity":       "capacidad",
+	"field.seatMap":        "mapa de asientos",
+	"field.userId":         "usuario",
+	"field.movieId":        "película",
+	"field.roomId":         "sala",
+	"field.cinemaId":       "cine",
+	"field.cityId":         "ciudad",
+	"field.stateId":        "estado",
+	"field.countryId":      "país",
+	"field.country":        "país",
+	"field.state":          "estado",
+	"field.cursor":         "cursor",
+	"field.fields":         "campos",
+	"field.expand":         "expandir",
+	"field.sort":           "orden",
+	"field.locale":         "idioma",
+	"field.synopsis":       "sinopsis",
+	"field.tagline":        "lema",
+	"field.releaseDate":    "fecha de estreno",
+	"field.runtime":        "duración",
+	"field.genres":         "géneros",
+	"field.ratings":        "clasificaciones",
+	"field.languages":      "idiomas",
+	"field.subtitles":      "subtítulos",
+	"field.cast":           "reparto",
+	"field.crew":           "equipo",
+	"field.trailerUrl":     "tráiler",
+	"field.posterUrl":      "póster",
+	"field.minRuntime":     "duración mínima",
+	"field.maxRuntime":     "duración máxima",
+	"field.releasedAfter":  "estrenada desde",
+	"field.releasedBefore": "estrenada hasta",
+	"field.address":        "dirección",
+	"field.location":       "ubicación",
+	"field.lat":            "latitud",
+	"field.lng":            "longitud",
+	"field.radius":         "radio",
+	"field.timeZone":       "zona horaria",
+	"field.rating":         "puntuación",
+	"field.text":           "texto",
+	"field.followerId":     "seguidor",
+	"field.followingId":    "seguido",
+	"field.username":       "nombre de usuario",
+	"field.replyToId":      "respuesta a",
+	"field.quoteOfId":      "cita de",
+	"field.retweetOfId":    "retuit de",
+	"field.scheduleId":     "función",
+	"field.seats":          "asientos",
+	"field.token":          "token",
+}
@@ -0,0 +1,221 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cbuelvasc/cinema-backend/config"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	ut "github.com/go-playground/universal-translator"
+)
+
+// catalogs hold the texts of every supported language by key. Texts take
+// their parameters as {0}, {1}...
+var catalogs = map[string]map[string]string{
+	"en": catalogEN,
+	"es": catalogES,
+}
+
+var universalTranslator = ut.New(en.New(), en.New(), es.New())
+
+func init() {
+	for language, catalog := range catalogs {
+		translator, _ := universalTranslator.GetTranslator(language)
+		for key, text := range catalog {
+			if err := translator.Add(key, text, false); err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
+// Message is a text to translate: a catalog key and its parameters.
+// Parameters that are messages too are translated first, so that resource
+// and field names read in the same language as the sentence.
+type Message struct {
+	Key    string
+	Params []interface{}
+}
+
+func NewMessage(key string, params ...interface{}) *Message {
+	return &Message{Key: key, Params: params}
+}
+
+// Resource is the name of a kind of resource, such as Country.
+func Resource(name string) *Message {
+	return NewMessage("resource." + name)
+}
+
+// Field is the name of a field, such as id.
+func Field(name string) *Message {
+	return NewMessage("field." + name)
+}
+
+// Translate returns the text of message in language. Unknown keys of
+// resources and fields read as the name itself.
+func (message *Message) Translate(language string) string {
+	params := make([]string, 0, len(message.Params))
+	for _, param := range message.Params {
+		switch param := param.(type) {
+		case *Message:
+			params = append(params, param.Translate(language))
+		case string:
+			params = append(params, param)
+		case int:
+			params = append(params, strconv.Itoa(param))
+		case int64:
+			params = append(params, strconv.FormatInt(param, 10))
+		}
+	}
+
+	translator, found := universalTranslator.GetTranslator(language)
+	if !found {
+		translator = universalTranslator.GetFallback()
+	}
+	text, err := translator.T(message.Key, params...)
+	if err != nil {
+		if i := strings.Index(message.Key, "."); i >= 0 {
+			return message.Key[i+1:]
+		}
+		return message.Key
+	}
+	return text
+}
+
+// String is the English text, the one of logs and command line errors.
+func (message *Message) String() string {
+	return message.Translate("en")
+}
+
+func (message *Message) Error() string {
+	return message.String()
+}
+
+// Exists reports whether key is in the catalogs.
+func Exists(key string) bool {
+	_, ok := catalogEN[key]
+	return ok
+}
+
+// Language returns the supported language the Accept-Language header
+// prefers, or the default language.
+func Language(acceptLanguage string) string {
+	for _, tag := range acceptedTags(acceptLanguage) {
+		// es-CO is served in es.
+		tag = strings.SplitN(tag, "-", 2)[0]
+		if _, ok := catalogs[tag]; ok {
+			return tag
+		}
+		if tag == "*" {
+			break
+		}
+	}
+	return DefaultLanguage()
+}
+
+// Locales returns the locales the Accept-Language header asks for, most
+// preferred first, each followed by its broader locales and the default
+// language last: es-CO, es, en.
+func Locales(acceptLanguage string) []string {
+	var locales []string
+	seen := map[string]bool{}
+	add := func(locale string) {
+		if !seen[locale] {
+			seen[locale] = true
+			locales = append(locales, locale)
+		}
+	}
+
+	for _, tag := range acceptedTags(acceptLanguage) {
+		locale, ok := NormalizeLocale(tag)
+		if !ok {
+			continue
+		}
+		add(locale)
+		for i := strings.LastIndex(locale, "-"); i > 0; i = strings.LastIndex(locale, "-") {
+			locale = locale[:i]
+			add(locale)
+		}
+	}
+	add(DefaultLanguage())
+	return locales
+}
+
+// NormalizeLocale returns tag in its usual case, es-CO for es-co, and whether
+// it is a well formed locale.
+func NormalizeLocale(tag string) (string, bool) {
+	parts := strings.Split(strings.TrimSpace(tag), "-")
+	for i, part := range parts {
+		if len(part) == 0 || len(part) > 8 {
+			return "", false
+		}
+		for _, r := range part {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+				return "", false
+			}
+		}
+		switch {
+		case i == 0:
+			if len(part) < 2 || len(part) > 3 {
+				return "", false
+			}
+			parts[i] = strings.ToLower(part)
+		case len(part) == 2:
+			parts[i] = strings.ToUpper(part)
+		case len(part) == 4:
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		default:
+			parts[i] = strings.ToLower(part)
+		}
+	}
+	return strings.Join(parts, "-"), true
+}
+
+// acceptedTags returns the language tags of the Accept-Language header in
+// lower case, the ones with the highest quality first.
+func acceptedTags(acceptLanguage string) []string {
+	type languageRange struct {
+		tag     string
+		quality float64
+	}
+
+	var ranges []languageRange
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if len(tag) == 0 {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				quality, _ = strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, languageRange{tag: tag, quality: quality})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	tags := make([]string, 0, len(ranges))
+	for _, languageRange := range ranges {
+		tags = append(tags, languageRange.tag)
+	}
+	return tags
+}
+
+// DefaultLanguage is the language of requests that accept none of the
+// supported ones.
+func DefaultLanguage() string {
+	if _, ok := catalogs[config.DefaultLanguage]; ok {
+		return config.DefaultLanguage
+	}
+	return "en"
+}
@@ -0,0 +1,33 @@
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/repository"
+)
+
+// StartPurgeJob removes, every interval, the documents that were soft-deleted
+// more than retention ago. A non positive interval disables the job.
+func StartPurgeJob(purgeRepository repository.PurgeRepository, interval time.Duration, retention time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			purged, err := purgeRepository.PurgeDeleted(context.Background(), time.Now().Add(-retention))
+			if err != nil {
+				log.Println("Error when purge deleted documents : ", err.Error())
+				continue
+			}
+			if purged > 0 {
+				log.Printf("Purged %d deleted documents", purged)
+			}
+		}
+	}()
+}
@@ -1,25 +1,40 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/cbuelvasc/cinema-backend/config"
 	"github.com/cbuelvasc/cinema-backend/controller"
 	"github.com/cbuelvasc/cinema-backend/handler"
+	"github.com/cbuelvasc/cinema-backend/job"
+	"github.com/cbuelvasc/cinema-backend/migration"
 	"github.com/cbuelvasc/cinema-backend/repository"
 	"github.com/cbuelvasc/cinema-backend/routes"
 	"github.com/cbuelvasc/cinema-backend/security"
+	"github.com/cbuelvasc/cinema-backend/seed"
 	"github.com/cbuelvasc/cinema-backend/util"
 	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
+var mongoConnection *mongo.Database
 var userController *controller.UserController
 var tweetController *controller.TweetController
+var followController *controller.FollowController
 var movieController *controller.MovieController
 var countryController *controller.CountryController
 var stateController *controller.StateController
 var cityController *controller.CityController
+var cinemaController *controller.CinemaController
+var listingController *controller.ListingController
+var reviewController *controller.ReviewController
+var bookingController *controller.BookingController
 
 // @title Cinema REST API
 // @description Provides access to the core features of Cinema REST API
@@ -32,27 +47,58 @@ var cityController *controller.CityController
 // @in header
 // @name Authorization
 func main() {
+	if len(os.Args) > 1 {
+		var err error
+		switch os.Args[1] {
+		case "migrate":
+			err = migration.Command(context.Background(), mongoConnection, os.Args[2:], os.Stdout)
+		case "seed":
+			err = seed.Command(context.Background(), mongoConnection, os.Args[2:], os.Stdout)
+		default:
+			err = fmt.Errorf("unknown command %q, expected migrate or seed", os.Args[1])
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Only the server purges deleted documents, not the one-shot commands.
+	purgeInterval, _ := strconv.Atoi(config.PurgeIntervalMinutes)
+	retention, _ := strconv.Atoi(config.SoftDeleteRetentionHours)
+	purgeRepository := repository.NewPurgeRepository(mongoConnection)
+	job.StartPurgeJob(purgeRepository, time.Duration(purgeInterval)*time.Minute, time.Duration(retention)*time.Hour)
+
 	e := echo.New()
 
 	e.HTTPErrorHandler = handler.ErrorHandler
 	e.Validator = util.NewValidationUtil()
+	handler.RequestIDConfig(e)
 	config.CORSConfig(e)
 	security.WebSecurityConfig(e)
 
 	security.WebSecurityConfig(e)
+	security.IncludeDeletedConfig(e)
+	handler.PreconditionConfig(e)
 
 	routes.GetUserApiRoutes(e, userController)
 	routes.GetTweetApiRoutes(e, tweetController)
+	routes.GetFollowApiRoutes(e, followController)
 	routes.GetMovieApiRoutes(e, movieController)
 	routes.GetCountryApiRoutes(e, countryController)
 	routes.GetStateApiRoutes(e, stateController)
 	routes.GetCityApiRoutes(e, cityController)
+	routes.GetCinemaApiRoutes(e, cinemaController)
+	routes.GetListingApiRoutes(e, listingController)
+	routes.GetReviewApiRoutes(e, reviewController)
+	routes.GetBookingApiRoutes(e, bookingController)
 	routes.GetSwaggerRoutes(e)
 	e.Logger.Fatal(e.Start(fmt.Sprintf(":%s", config.ServerPort)))
 }
 
 func init() {
-	mongoConnection, errorMongoConn := config.MongoConnection()
+	var errorMongoConn error
+	mongoConnection, errorMongoConn = config.MongoConnection()
 
 	if errorMongoConn != nil {
 		log.Println("Error when connect mongo : ", errorMongoConn.Error())
@@ -64,8 +110,12 @@ func init() {
 	tweetRepository := repository.NewTeewtRepository(mongoConnection)
 	tweetController = controller.NewTweetController(tweetRepository, userRepository)
 
+	followRepository := repository.NewFollowRepository(mongoConnection)
+	followController = controller.NewFollowController(followRepository, userRepository)
+
 	movieRepository := repository.NewMovieRepository(mongoConnection)
-	movieController = controller.NewMovieController(movieRepository, userRepository)
+	movieSearchRepository := repository.NewMovieSearchRepository(mongoConnection)
+	movieController = controller.NewMovieController(movieRepository, movieSearchRepository, userRepository)
 
 	countryRepository := repository.NewCountryRepository(mongoConnection)
 	countryController = controller.NewCountryController(countryRepository)
@@ -74,5 +124,18 @@ func init() {
 	stateController = controller.NewStateController(stateRepository, countryRepository)
 
 	cityRepository := repository.NewCityRepository(mongoConnection)
-	cityController = controller.NewCityController(cityRepository, stateRepository)
+	cityController = controller.NewCityController(cityRepository, stateRepository, countryRepository)
+
+	cinemaRepository := repository.NewCinemaRepository(mongoConnection)
+	cinemaController = controller.NewCinemaController(cinemaRepository, movieRepository)
+
+	listingRepository := repository.NewListingRepository(mongoConnection)
+	listingController = controller.NewListingController(listingRepository, cityRepository, cinemaRepository)
+
+	roomRepository := repository.NewRoomRepository(mongoConnection)
+	bookingRepository := repository.NewBookingRepository(mongoConnection)
+	bookingController = controller.NewBookingController(bookingRepository, userRepository, roomRepository, cinemaRepository, movieRepository)
+
+	reviewRepository := repository.NewReviewRepository(mongoConnection)
+	reviewController = controller.NewReviewController(reviewRepository, movieRepository, bookingRepository)
 }
@@ -0,0 +1,28 @@
+package migration
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Emails identify users when they sign in, so two users can never share one.
+// Soft-deleted users keep theirs until they are purged, so they can be
+// restored.
+func init() {
+	register(Migration{
+		Version: 1,
+		Name:    "users_email_index",
+		Up: func(ctx context.Context, database *mongo.Database) error {
+			return createIndexes(ctx, database, "users", mongo.IndexModel{
+				Keys:    bson.D{{Key: "email", Value: 1}},
+				Options: options.Index().SetName("users_email_unique").SetUnique(true),
+			})
+		},
+		Down: func(ctx context.Context, database *mongo.Database) error {
+			return dropIndexes(ctx, database, "users", "users_email_unique")
+		},
+	})
+}
@@ -0,0 +1,51 @@
+package migration
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// foreignKeys are the fields referencing another document, by collection.
+// Children are listed, counted and deleted through them.
+var foreignKeys = []struct {
+	Collection string
+	Field      string
+}{
+	{"states", "countryId"},
+	{"cities", "stateId"},
+	{"cinemas", "cityId"},
+	{"rooms", "cinemaId"},
+	{"tweets", "userId"},
+	{"schedules", "roomId"},
+	{"schedules", "movieId"},
+}
+
+func init() {
+	register(Migration{
+		Version: 2,
+		Name:    "foreign_key_indexes",
+		Up: func(ctx context.Context, database *mongo.Database) error {
+			for _, foreignKey := range foreignKeys {
+				err := createIndexes(ctx, database, foreignKey.Collection, mongo.IndexModel{
+					Keys:    bson.D{{Key: foreignKey.Field, Value: 1}},
+					Options: options.Index().SetName(foreignKey.Collection + "_" + foreignKey.Field),
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(ctx context.Context, database *mongo.Database) error {
+			for _, foreignKey := range foreignKeys {
+				if err := dropIndexes(ctx, database, foreignKey.Collection, foreignKey.Collection+"_"+foreignKey.Field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
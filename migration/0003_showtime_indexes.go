@@ -0,0 +1,37 @@
+package migration
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Showtimes are looked up by movie or by room over a range of dates, and a
+// room cannot show two movies at the same time.
+func init() {
+	register(Migration{
+		Version: 3,
+		Name:    "showtime_indexes",
+		Up: func(ctx context.Context, database *mongo.Database) error {
+			return createIndexes(ctx, database, "schedules",
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "roomId", Value: 1}, {Key: "date", Value: 1}},
+					Options: options.Index().SetName("schedules_roomId_date_unique").SetUnique(true),
+				},
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "movieId", Value: 1}, {Key: "date", Value: 1}},
+					Options: options.Index().SetName("schedules_movieId_date"),
+				},
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "date", Value: 1}},
+					Options: options.Index().SetName("schedules_date"),
+				},
+			)
+		},
+		Down: func(ctx context.Context, database *mongo.Database) error {
+			return dropIndexes(ctx, database, "schedules", "schedules_roomId_date_unique", "schedules_movieId_date", "schedules_date")
+		},
+	})
+}
@@ -0,0 +1,74 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/cbuelvasc/cinema-backend/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Movies are searched by their translated titles too. A collection has a
+// single text index, so the title one is replaced. Movies saved before their
+// normalized title was maintained on writes get it too; it stays on Down, as
+// writes keep maintaining it.
+func init() {
+	register(Migration{
+		Version: 4,
+		Name:    "movie_translations_text_index",
+		Up: func(ctx context.Context, database *mongo.Database) error {
+			if err := dropIndexes(ctx, database, "movies", "movies_title_text"); err != nil {
+				return err
+			}
+			err := createIndexes(ctx, database, "movies", mongo.IndexModel{
+				Keys: bson.D{{Key: "title", Value: "text"}, {Key: "searchTitle", Value: "text"}, {Key: "translations.title", Value: "text"}},
+				Options: options.Index().
+					SetName("movies_text").
+					SetDefaultLanguage("none").
+					SetWeights(bson.M{"title": 10, "searchTitle": 5, "translations.title": 8}),
+			})
+			if err != nil {
+				return err
+			}
+			return backfillSearchTitles(ctx, database.Collection("movies"))
+		},
+		Down: func(ctx context.Context, database *mongo.Database) error {
+			if err := dropIndexes(ctx, database, "movies", "movies_text"); err != nil {
+				return err
+			}
+			return createIndexes(ctx, database, "movies", mongo.IndexModel{
+				Keys: bson.D{{Key: "title", Value: "text"}, {Key: "searchTitle", Value: "text"}},
+				Options: options.Index().
+					SetName("movies_title_text").
+					SetDefaultLanguage("none").
+					SetWeights(bson.M{"title": 10, "searchTitle": 5}),
+			})
+		},
+	})
+}
+
+// backfillSearchTitles sets the normalized title of the movies without one.
+func backfillSearchTitles(ctx context.Context, collection *mongo.Collection) error {
+	cursor, err := collection.Find(ctx, bson.M{"searchTitle": bson.M{"$exists": false}}, options.Find().SetProjection(bson.M{"title": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var movie struct {
+			ID    primitive.ObjectID `bson:"_id"`
+			Title string             `bson:"title"`
+		}
+		if err := cursor.Decode(&movie); err != nil {
+			return err
+		}
+		update := bson.M{"$set": bson.M{"searchTitle": util.NormalizeText(movie.Title)}}
+		if _, err := collection.UpdateOne(ctx, bson.M{"_id": movie.ID}, update); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
@@ -0,0 +1,29 @@
+package migration
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Nearby cinemas are found with $geoNear, which needs a geospatial index on
+// their location.
+func init() {
+	register(Migration{
+		Version: 5,
+		Name:    "cinema_location_index",
+		Up: func(ctx context.Context, database *mongo.Database) error {
+			return createIndexes(ctx, database, "cinemas",
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "location", Value: "2dsphere"}},
+					Options: options.Index().SetName("cinemas_location_2dsphere"),
+				},
+			)
+		},
+		Down: func(ctx context.Context, database *mongo.Database) error {
+			return dropIndexes(ctx, database, "cinemas", "cinemas_location_2dsphere")
+		},
+	})
+}
@@ -0,0 +1,30 @@
+package migration
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Reviews are listed by movie, and looked up by movie and user to keep one
+// review per user and movie. The index is not unique, since deleted reviews
+// stay until purged.
+func init() {
+	register(Migration{
+		Version: 6,
+		Name:    "review_indexes",
+		Up: func(ctx context.Context, database *mongo.Database) error {
+			return createIndexes(ctx, database, "reviews",
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "movieId", Value: 1}, {Key: "userId", Value: 1}},
+					Options: options.Index().SetName("reviews_movieId_userId"),
+				},
+			)
+		},
+		Down: func(ctx context.Context, database *mongo.Database) error {
+			return dropIndexes(ctx, database, "reviews", "reviews_movieId_userId")
+		},
+	})
+}
@@ -0,0 +1,33 @@
+package migration
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// A user follows another at most once. Followers are listed by the user they
+// follow, the latest first.
+func init() {
+	register(Migration{
+		Version: 7,
+		Name:    "follow_indexes",
+		Up: func(ctx context.Context, database *mongo.Database) error {
+			return createIndexes(ctx, database, "follows",
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "followerId", Value: 1}, {Key: "followingId", Value: 1}},
+					Options: options.Index().SetName("follows_followerId_followingId").SetUnique(true),
+				},
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "followingId", Value: 1}, {Key: "created_at", Value: -1}},
+					Options: options.Index().SetName("follows_followingId_created_at"),
+				},
+			)
+		},
+		Down: func(ctx context.Context, database *mongo.Database) error {
+			return dropIndexes(ctx, database, "follows", "follows_followerId_followingId", "follows_followingId_created_at")
+		},
+	})
+}
@@ -0,0 +1,53 @@
+package migration
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Mentions name users by username, so two users can never share one. Users
+// without a username are left out of the index. Tweets are listed by
+// conversation, hashtag and mentioned user.
+func init() {
+	register(Migration{
+		Version: 8,
+		Name:    "tweet_interaction_indexes",
+		Up: func(ctx context.Context, database *mongo.Database) error {
+			err := createIndexes(ctx, database, "users", mongo.IndexModel{
+				Keys: bson.D{{Key: "username", Value: 1}},
+				Options: options.Index().SetName("users_username_unique").SetUnique(true).
+					SetPartialFilterExpression(bson.M{"username": bson.M{"$type": "string"}}),
+			})
+			if err != nil {
+				return err
+			}
+			return createIndexes(ctx, database, "tweets",
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "conversationId", Value: 1}, {Key: "created_at", Value: 1}},
+					Options: options.Index().SetName("tweets_conversationId_created_at"),
+				},
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "entities.hashtags.tag", Value: 1}, {Key: "created_at", Value: -1}},
+					Options: options.Index().SetName("tweets_hashtags_created_at"),
+				},
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "entities.mentions.userId", Value: 1}, {Key: "created_at", Value: -1}},
+					Options: options.Index().SetName("tweets_mentions_created_at"),
+				},
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "retweetOfId", Value: 1}, {Key: "userId", Value: 1}},
+					Options: options.Index().SetName("tweets_retweetOfId_userId"),
+				},
+			)
+		},
+		Down: func(ctx context.Context, database *mongo.Database) error {
+			if err := dropIndexes(ctx, database, "tweets", "tweets_conversationId_created_at", "tweets_hashtags_created_at", "tweets_mentions_created_at", "tweets_retweetOfId_userId"); err != nil {
+				return err
+			}
+			return dropIndexes(ctx, database, "users", "users_username_unique")
+		},
+	})
+}
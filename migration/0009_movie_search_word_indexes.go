@@ -0,0 +1,76 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/cbuelvasc/cinema-backend/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Partial words and typos are searched with anchored regular expressions on
+// the words of the titles, which the indexes of their arrays bound. Movies
+// saved before the words were maintained on writes get them too; they stay on
+// Down, as writes keep maintaining them.
+func init() {
+	register(Migration{
+		Version: 9,
+		Name:    "movie_search_word_indexes",
+		Up: func(ctx context.Context, database *mongo.Database) error {
+			err := createIndexes(ctx, database, "movies",
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "searchWords", Value: 1}},
+					Options: options.Index().SetName("movies_searchWords"),
+				},
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "translationWords", Value: 1}},
+					Options: options.Index().SetName("movies_translationWords"),
+				},
+			)
+			if err != nil {
+				return err
+			}
+			return backfillSearchWords(ctx, database.Collection("movies"))
+		},
+		Down: func(ctx context.Context, database *mongo.Database) error {
+			return dropIndexes(ctx, database, "movies", "movies_searchWords", "movies_translationWords")
+		},
+	})
+}
+
+// backfillSearchWords sets the words of the titles of the movies without them.
+func backfillSearchWords(ctx context.Context, collection *mongo.Collection) error {
+	projection := bson.M{"title": 1, "translations.title": 1}
+	cursor, err := collection.Find(ctx, bson.M{"searchWords": bson.M{"$exists": false}}, options.Find().SetProjection(projection))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var movie struct {
+			ID           primitive.ObjectID `bson:"_id"`
+			Title        string             `bson:"title"`
+			Translations []struct {
+				Title string `bson:"title"`
+			} `bson:"translations"`
+		}
+		if err := cursor.Decode(&movie); err != nil {
+			return err
+		}
+		titles := make([]string, 0, len(movie.Translations))
+		for _, translation := range movie.Translations {
+			titles = append(titles, translation.Title)
+		}
+		update := bson.M{"$set": bson.M{
+			"searchWords":      util.SearchWords(movie.Title),
+			"translationWords": util.SearchWords(titles...),
+		}}
+		if _, err := collection.UpdateOne(ctx, bson.M{"_id": movie.ID}, update); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
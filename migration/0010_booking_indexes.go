@@ -0,0 +1,33 @@
+package migration
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Users list their bookings, the latest first. Reviews look up whether the
+// user was checked in to a showtime of the movie.
+func init() {
+	register(Migration{
+		Version: 10,
+		Name:    "booking_indexes",
+		Up: func(ctx context.Context, database *mongo.Database) error {
+			return createIndexes(ctx, database, "bookings",
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "created_at", Value: -1}},
+					Options: options.Index().SetName("bookings_userId_created_at"),
+				},
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "movieId", Value: 1}},
+					Options: options.Index().SetName("bookings_userId_movieId"),
+				},
+			)
+		},
+		Down: func(ctx context.Context, database *mongo.Database) error {
+			return dropIndexes(ctx, database, "bookings", "bookings_userId_created_at", "bookings_userId_movieId")
+		},
+	})
+}
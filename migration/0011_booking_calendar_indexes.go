@@ -0,0 +1,39 @@
+package migration
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Personal calendar feeds look their user up by the hash of the token in
+// their URL, and list the upcoming bookings of the user. Users without a
+// feed are left out of the index.
+func init() {
+	register(Migration{
+		Version: 11,
+		Name:    "booking_calendar_indexes",
+		Up: func(ctx context.Context, database *mongo.Database) error {
+			err := createIndexes(ctx, database, "users", mongo.IndexModel{
+				Keys: bson.D{{Key: "calendarToken", Value: 1}},
+				Options: options.Index().SetName("users_calendarToken").
+					SetPartialFilterExpression(bson.M{"calendarToken": bson.M{"$type": "string"}}),
+			})
+			if err != nil {
+				return err
+			}
+			return createIndexes(ctx, database, "bookings", mongo.IndexModel{
+				Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "date", Value: 1}},
+				Options: options.Index().SetName("bookings_userId_date"),
+			})
+		},
+		Down: func(ctx context.Context, database *mongo.Database) error {
+			if err := dropIndexes(ctx, database, "bookings", "bookings_userId_date"); err != nil {
+				return err
+			}
+			return dropIndexes(ctx, database, "users", "users_calendarToken")
+		},
+	})
+}
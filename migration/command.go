@@ -0,0 +1,75 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var errUsage = errors.New("usage: migrate up [version] | down [steps] | status")
+
+// Command runs the migrate subcommand with its arguments:
+//
+//	migrate up [version]  applies the pending migrations, up to version
+//	migrate down [steps]  reverts the last steps migrations, one by default
+//	migrate status        lists the migrations and when they were applied
+func Command(ctx context.Context, Connection *mongo.Database, args []string, out io.Writer) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errUsage
+	}
+
+	var argument int64
+	if len(args) == 2 {
+		value, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || value < 1 {
+			return errUsage
+		}
+		argument = value
+	}
+
+	migrator := NewMigrator(Connection)
+	switch args[0] {
+	case "up":
+		done, err := migrator.Up(ctx, argument)
+		printMigrations(out, "Applied", done)
+		return err
+	case "down":
+		if argument == 0 {
+			argument = 1
+		}
+		done, err := migrator.Down(ctx, int(argument))
+		printMigrations(out, "Reverted", done)
+		return err
+	case "status":
+		if len(args) > 1 {
+			return errUsage
+		}
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%04d %-24s %s\n", status.Version, status.Name, applied)
+		}
+		return nil
+	default:
+		return errUsage
+	}
+}
+
+func printMigrations(out io.Writer, action string, done []Migration) {
+	if len(done) == 0 {
+		fmt.Fprintln(out, "No migrations to run")
+	}
+	for _, migration := range done {
+		fmt.Fprintf(out, "%s %04d %s\n", action, migration.Version, migration.Name)
+	}
+}
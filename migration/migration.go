@@ -0,0 +1,172 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migration is one versioned change of the database schema. Each migration
+// lives in its own file, named after its version, and registers itself.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(ctx context.Context, database *mongo.Database) error
+	Down    func(ctx context.Context, database *mongo.Database) error
+}
+
+// MigrationStatus tells whether a migration has been applied, and when.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// appliedMigration is the record kept in the migrations collection for every
+// applied migration.
+type appliedMigration struct {
+	Version   int64     `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+var migrations []Migration
+
+func register(migration Migration) {
+	for _, registered := range migrations {
+		if registered.Version == migration.Version {
+			panic(fmt.Sprintf("migration %d registered twice", migration.Version))
+		}
+	}
+	migrations = append(migrations, migration)
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+}
+
+type Migrator struct {
+	Connection *mongo.Database
+}
+
+func NewMigrator(Connection *mongo.Database) *Migrator {
+	return &Migrator{Connection: Connection}
+}
+
+// Up applies, in order, the pending migrations up to version target, or all
+// of them when target is 0. It stops at the first failure, leaving the ones
+// applied so far recorded.
+func (migrator *Migrator) Up(ctx context.Context, target int64) ([]Migration, error) {
+	applied, err := migrator.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range migrations {
+		if target > 0 && migration.Version > target {
+			break
+		}
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := migration.Up(ctx, migrator.Connection); err != nil {
+			return done, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		record := appliedMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}
+		if _, err := migrator.Connection.Collection("migrations").InsertOne(ctx, record); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down reverts the last steps applied migrations, newest first.
+func (migrator *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := migrator.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		if err := migration.Down(ctx, migrator.Connection); err != nil {
+			return done, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		if _, err := migrator.Connection.Collection("migrations").DeleteOne(ctx, bson.M{"_id": migration.Version}); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Status lists every known migration in order.
+func (migrator *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := migrator.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Migration: migration}
+		if record, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &record.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (migrator *Migrator) applied(ctx context.Context) (map[int64]appliedMigration, error) {
+	cursor, err := migrator.Connection.Collection("migrations").Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	var records []appliedMigration
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]appliedMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// createIndexes creates the indexes of collection. Indexes that already
+// exist with the same definition are left as they are.
+func createIndexes(ctx context.Context, database *mongo.Database, collection string, indexes ...mongo.IndexModel) error {
+	_, err := database.Collection(collection).Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// dropIndexes drops the named indexes of collection, ignoring the ones that
+// do not exist.
+func dropIndexes(ctx context.Context, database *mongo.Database, collection string, names ...string) error {
+	for _, name := range names {
+		_, err := database.Collection(collection).Indexes().DropOne(ctx, name)
+		var commandError mongo.CommandError
+		if errors.As(err, &commandError) && (commandError.Name == "IndexNotFound" || commandError.Name == "NamespaceNotFound") {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Booking holds seats of a showtime for a user. It copies the movie, room and
+// date of the showtime, so bookings are listed without reading the
+// showtimes. Staff check the user in at the cinema, after which the user may
+// review the movie.
+type Booking struct {
+	*BookingInput `bson:",inline"`
+	ID            primitive.ObjectID `json:"id" xml:"id" bson:"_id,omitempty"`
+	Version       int64              `json:"version" xml:"version" bson:"version"`
+	UserId        string             `json:"userId" xml:"userId" bson:"userId"`
+	MovieId       string             `json:"movieId" xml:"movieId" bson:"movieId"`
+	RoomId        string             `json:"roomId" xml:"roomId" bson:"roomId"`
+	Date          time.Time          `json:"date" xml:"date" bson:"date"`
+	CheckedInAt   *time.Time         `json:"checkedInAt,omitempty" xml:"checkedInAt,omitempty" bson:"checkedInAt,omitempty"`
+	CreatedAt     time.Time          `json:"created_at" xml:"created_at" bson:"created_at"`
+	SoftDelete    `bson:",inline"`
+}
+
+type BookingInput struct {
+	ScheduleId string   `json:"scheduleId,omitempty" xml:"scheduleId,omitempty" bson:"scheduleId" validate:"required"`
+	Seats      []string `json:"seats,omitempty" xml:"seats,omitempty" bson:"seats" validate:"required,min=1,dive,required"`
+}
+
+type PagedBooking struct {
+	Data   []Booking   `json:"data" xml:"data"`
+	Cursor *CursorInfo `json:"cursor,omitempty" xml:"cursor,omitempty"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+)
+
+// Calendar is a feed of events, written as iCalendar (RFC 5545) for calendar
+// applications to import or subscribe to.
+type Calendar struct {
+	Name     string
+	TimeZone string
+	Events   []CalendarEvent
+}
+
+// CalendarEvent is an event of a Calendar. UID identifies it across updates
+// of the feed, so calendar applications update it instead of duplicating it.
+type CalendarEvent struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Updated     time.Time
+	Summary     string
+	Description string
+	Location    string
+	Geo         *GeoPoint
+}
+
+// CalendarToken is the secret of the personal calendar feed of a user and the
+// URL of the feed. Only a hash of the token is stored, so it is shown once,
+// when it is generated.
+type CalendarToken struct {
+	Token string `json:"token" xml:"token"`
+	URL   string `json:"url" xml:"url"`
+}
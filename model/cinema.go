@@ -9,11 +9,15 @@ import (
 type Cinema struct {
 	*CinemaInput `bson:",inline"`
 	ID           primitive.ObjectID `json:"id" xml:"id" bson:"_id,omitempty"`
+	Version      int64              `json:"version" xml:"version" bson:"version"`
+	SoftDelete   `bson:",inline"`
 }
 
 type CinemaInput struct {
 	Name      string    `json:"name,omitempty" xml:"name,omitempty" bson:"name" validate:"required"`
 	CityId    string    `json:"cityId,omitempty" xml:"cityId,omitempty" bson:"cityId" validate:"required"`
+	Address   string    `json:"address,omitempty" xml:"address,omitempty" bson:"address"`
+	Location  *GeoPoint `json:"location,omitempty" xml:"location,omitempty" bson:"location,omitempty"`
 	Premieres []string  `json:"premieres,omitempty" xml:"premieres,omitempty" bson:"premieres"`
 	Rooms     []string  `json:"rooms,omitempty" xml:"rooms,omitempty" bson:"rooms"`
 	CreatedAt time.Time `json:"created_at,omitempty" xml:"created_at,omitempty" bson:"created_at"`
@@ -21,6 +25,28 @@ type CinemaInput struct {
 }
 
 type PagedCinema struct {
-	Data     []Cinema                       `json:"data" xml:"data"`
-	PageInfo mongopagination.PaginationData `json:"pageInfo" xml:"pageInfo"`
+	Data     []Cinema                        `json:"data" xml:"data"`
+	PageInfo *mongopagination.PaginationData `json:"pageInfo,omitempty" xml:"pageInfo,omitempty"`
+	Cursor   *CursorInfo                     `json:"cursor,omitempty" xml:"cursor,omitempty"`
+}
+
+// NearbyCinemaQuery selects the cinemas within Radius meters of a point,
+// showing MovieId from From on when it is given.
+type NearbyCinemaQuery struct {
+	Latitude  float64
+	Longitude float64
+	Radius    float64
+	MovieId   string
+	From      time.Time
+	Limit     int64
+}
+
+// NearbyCinema is a cinema and its distance in meters to the point searched.
+type NearbyCinema struct {
+	*Cinema  `bson:",inline"`
+	Distance float64 `json:"distance" xml:"distance" bson:"distance"`
+}
+
+type NearbyCinemaList struct {
+	Data []NearbyCinema `json:"data" xml:"data"`
 }
@@ -10,17 +10,21 @@ import (
 type City struct {
 	*CityInput `bson:",inline"`
 	ID         primitive.ObjectID `json:"id" xml:"id" bson:"_id,omitempty"`
+	Version    int64              `json:"version" xml:"version" bson:"version"`
+	SoftDelete `bson:",inline"`
 }
 
 type CityInput struct {
 	Name      string    `json:"name,omitempty" xml:"name,omitempty" bson:"name" validate:"required"`
 	StateId   string    `json:"stateId,omitempty" xml:"stateId,omitempty" bson:"stateId" validate:"required"`
+	TimeZone  string    `json:"timeZone,omitempty" xml:"timeZone,omitempty" bson:"timeZone" validate:"omitempty,timezone"`
 	Cinemas   []string  `json:"cinemas,omitempty" xml:"cinemas,omitempty" bson:"cinemas"`
 	CreatedAt time.Time `json:"created_at,omitempty" xml:"created_at,omitempty" bson:"created_at"`
 	UpdatedAt time.Time `json:"updated_at,omitempty" xml:"updated_at,omitempty" bson:"updated_at"`
 }
 
 type PagedCity struct {
-	Data     []City                         `json:"data" xml:"data"`
-	PageInfo mongopagination.PaginationData `json:"pageInfo" xml:"pageInfo"`
+	Data     []City                          `json:"data" xml:"data"`
+	PageInfo *mongopagination.PaginationData `json:"pageInfo,omitempty" xml:"pageInfo,omitempty"`
+	Cursor   *CursorInfo                     `json:"cursor,omitempty" xml:"cursor,omitempty"`
 }
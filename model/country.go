@@ -9,16 +9,20 @@ import (
 type Country struct {
 	*CountryInput `bson:",inline"`
 	ID            primitive.ObjectID `json:"id" xml:"id" bson:"_id,omitempty"`
+	Version       int64              `json:"version" xml:"version" bson:"version"`
+	SoftDelete    `bson:",inline"`
 }
 
 type CountryInput struct {
 	Name      string    `json:"name,omitempty" xml:"name,omitempty" bson:"name" validate:"required"`
 	States    []string  `json:"states,omitempty" xml:"states,omitempty" bson:"states"`
+	TimeZone  string    `json:"timeZone,omitempty" xml:"timeZone,omitempty" bson:"timeZone" validate:"omitempty,timezone"`
 	CreatedAt time.Time `json:"created_at,omitempty" xml:"created_at,omitempty" bson:"created_at"`
 	UpdatedAt time.Time `json:"updated_at,omitempty" xml:"updated_at,omitempty" bson:"updated_at"`
 }
 
 type PagedCountry struct {
-	Data     []Country                      `json:"data" xml:"data"`
-	PageInfo mongopagination.PaginationData `json:"pageInfo" xml:"pageInfo"`
+	Data     []Country                       `json:"data" xml:"data"`
+	PageInfo *mongopagination.PaginationData `json:"pageInfo,omitempty" xml:"pageInfo,omitempty"`
+	Cursor   *CursorInfo                     `json:"cursor,omitempty" xml:"cursor,omitempty"`
 }
@@ -0,0 +1,21 @@
+package model
+
+// CursorQuery holds the parameters of an opaque cursor based listing.
+// Sort is the name of the field the results are ordered by; a leading "-"
+// orders them descending. Results are always tie-broken on _id.
+type CursorQuery struct {
+	After     string
+	Before    string
+	Limit     int64
+	Sort      string
+	SkipCount bool
+}
+
+type CursorInfo struct {
+	Next    string `json:"next,omitempty" xml:"next,omitempty"`
+	Prev    string `json:"prev,omitempty" xml:"prev,omitempty"`
+	Limit   int64  `json:"limit" xml:"limit"`
+	HasNext bool   `json:"hasNext" xml:"hasNext"`
+	HasPrev bool   `json:"hasPrev" xml:"hasPrev"`
+	Total   *int64 `json:"total,omitempty" xml:"total,omitempty"`
+}
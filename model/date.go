@@ -0,0 +1,79 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+const dateLayout = "2006-01-02"
+
+// Date is a calendar day, written as 2006-01-02 and stored as a BSON date at
+// midnight UTC.
+type Date struct {
+	time time.Time
+}
+
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDate reads a day as 2006-01-02, or the day of an RFC 3339 timestamp.
+func ParseDate(text string) (Date, error) {
+	if parsed, err := time.Parse(dateLayout, text); err == nil {
+		return Date{time: parsed}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, text)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", text)
+	}
+	return NewDate(parsed.Year(), parsed.Month(), parsed.Day()), nil
+}
+
+// Time is the midnight UTC starting the day.
+func (date Date) Time() time.Time {
+	return date.time
+}
+
+// In is the first instant of the day in location. Around daylight saving
+// time changes days last 23 or 25 hours, so the next day starts at
+// date.AddDays(1).In(location) rather than 24 hours later.
+func (date Date) In(location *time.Location) time.Time {
+	return time.Date(date.time.Year(), date.time.Month(), date.time.Day(), 0, 0, 0, 0, location)
+}
+
+func (date Date) AddDays(days int) Date {
+	return Date{time: date.time.AddDate(0, 0, days)}
+}
+
+func (date Date) String() string {
+	return date.time.Format(dateLayout)
+}
+
+func (date Date) MarshalText() ([]byte, error) {
+	return []byte(date.String()), nil
+}
+
+func (date *Date) UnmarshalText(text []byte) error {
+	parsed, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+	*date = parsed
+	return nil
+}
+
+func (date Date) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.MarshalValue(date.time)
+}
+
+func (date *Date) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	var value time.Time
+	if err := (bson.RawValue{Type: t, Value: data}).Unmarshal(&value); err != nil {
+		return err
+	}
+	*date = NewDate(value.UTC().Year(), value.UTC().Month(), value.UTC().Day())
+	return nil
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"encoding/xml"
+	"sort"
+
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+)
+
+// DocumentQuery describes a read that trims the returned fields and embeds
+// referenced resources instead of decoding into the typed models.
+type DocumentQuery struct {
+	Fields []string
+	Expand []string
+	Page   int64
+	Limit  int64
+	Cursor *CursorQuery
+}
+
+// Document is a loosely typed resource as returned by sparse or expanded reads.
+type Document map[string]interface{}
+
+func (document Document) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(document))
+	for key := range document {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := e.EncodeElement(document[key], xml.StartElement{Name: xml.Name{Local: key}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+type PagedDocument struct {
+	Data     []Document                      `json:"data" xml:"data"`
+	PageInfo *mongopagination.PaginationData `json:"pageInfo,omitempty" xml:"pageInfo,omitempty"`
+	Cursor   *CursorInfo                     `json:"cursor,omitempty" xml:"cursor,omitempty"`
+}
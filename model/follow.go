@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Follow is the link from a user, the follower, to a user whose tweets they
+// read in their home timeline.
+type Follow struct {
+	ID          primitive.ObjectID `json:"id" xml:"id" bson:"_id,omitempty"`
+	FollowerId  string             `json:"followerId" xml:"followerId" bson:"followerId"`
+	FollowingId string             `json:"followingId" xml:"followingId" bson:"followingId"`
+	CreatedAt   time.Time          `json:"created_at" xml:"created_at" bson:"created_at"`
+}
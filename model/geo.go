@@ -0,0 +1,20 @@
+package model
+
+// GeoPoint is a GeoJSON point, as indexed by MongoDB 2dsphere indexes. Its
+// coordinates are the longitude and then the latitude.
+type GeoPoint struct {
+	Type        string    `json:"type" xml:"type" bson:"type" validate:"eq=Point"`
+	Coordinates []float64 `json:"coordinates" xml:"coordinates>coordinate" bson:"coordinates" validate:"len=2"`
+}
+
+func NewGeoPoint(latitude float64, longitude float64) *GeoPoint {
+	return &GeoPoint{Type: "Point", Coordinates: []float64{longitude, latitude}}
+}
+
+func (point *GeoPoint) Latitude() float64 {
+	return point.Coordinates[1]
+}
+
+func (point *GeoPoint) Longitude() float64 {
+	return point.Coordinates[0]
+}
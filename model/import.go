@@ -0,0 +1,18 @@
+package model
+
+// ImportResult reports what an import did, or would do on a dry run, with
+// the rows that failed. Rows are numbered from 1, not counting a CSV header.
+type ImportResult struct {
+	DryRun    bool          `json:"dryRun" xml:"dryRun"`
+	Rows      int           `json:"rows" xml:"rows"`
+	Created   int           `json:"created" xml:"created"`
+	Updated   int           `json:"updated" xml:"updated"`
+	Unchanged int           `json:"unchanged" xml:"unchanged"`
+	Failed    int           `json:"failed" xml:"failed"`
+	Errors    []ImportError `json:"errors" xml:"errors>error"`
+}
+
+type ImportError struct {
+	Row     int    `json:"row" xml:"row"`
+	Message string `json:"message" xml:"message"`
+}
@@ -1,6 +1,6 @@
 package model
 
-import "github.com/dgrijalva/jwt-go"
+import "github.com/golang-jwt/jwt"
 
 type JwtCustomClaims struct {
 	ID        string `json:"id" xml:"id"`
@@ -11,6 +11,7 @@ type JwtCustomClaims struct {
 	Biography string `json:"biography" xml:"biography"`
 	Location  string `json:"location" xml:"location"`
 	WebSite   string `json:"webSite" xml:"webSite"`
+	Role      string `json:"role" xml:"role"`
 	jwt.StandardClaims
 }
 
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+)
+
+// ShowingQuery selects the showtimes of the now showing listing: the ones
+// between From and To in the cinemas of a city, or in a single cinema. The
+// listing is paginated by movie.
+type ShowingQuery struct {
+	CityId   string
+	CinemaId string
+	From     time.Time
+	To       time.Time
+	Page     int64
+	Limit    int64
+}
+
+// NowShowing is a movie and its showtimes, grouped by cinema.
+type NowShowing struct {
+	Movie   *Movie            `json:"movie" xml:"movie"`
+	Cinemas []CinemaShowtimes `json:"cinemas" xml:"cinemas>cinema"`
+}
+
+// CinemaShowtimes gives the dates of its showtimes in the local time of the
+// cinema, in TimeZone.
+type CinemaShowtimes struct {
+	CinemaId  string     `json:"cinemaId" xml:"cinemaId"`
+	Name      string     `json:"name" xml:"name"`
+	TimeZone  string     `json:"timeZone" xml:"timeZone"`
+	Formats   []string   `json:"formats" xml:"formats>format"`
+	Showtimes []Showtime `json:"showtimes" xml:"showtimes>showtime"`
+}
+
+// Showtime is a showing of a movie, in the format of its room.
+type Showtime struct {
+	ID     string    `json:"id" xml:"id"`
+	Date   time.Time `json:"date" xml:"date"`
+	Format string    `json:"format" xml:"format"`
+	RoomId string    `json:"roomId" xml:"roomId"`
+	Room   string    `json:"room" xml:"room"`
+}
+
+type NowShowingList struct {
+	Data     []NowShowing                    `json:"data" xml:"data"`
+	From     time.Time                       `json:"from" xml:"from"`
+	To       time.Time                       `json:"to" xml:"to"`
+	PageInfo *mongopagination.PaginationData `json:"pageInfo,omitempty" xml:"pageInfo,omitempty"`
+}
+
+// ComingSoonQuery selects the movies released from From, and until To when
+// given, that have no showtimes yet.
+type ComingSoonQuery struct {
+	From  Date
+	To    *Date
+	Page  int64
+	Limit int64
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"time"
 
 	mongopagination "github.com/gobeam/mongo-go-pagination"
@@ -8,21 +9,145 @@ import (
 )
 
 type Movie struct {
-	*MovieInput `bson:",inline"`
-	ID          primitive.ObjectID `json:"id" xml:"id" bson:"_id,omitempty"`
+	*MovieInput        `bson:",inline"`
+	ID                 primitive.ObjectID `json:"id" xml:"id" bson:"_id,omitempty"`
+	Version            int64              `json:"version" xml:"version" bson:"version"`
+	Locale             string             `json:"locale,omitempty" xml:"locale,omitempty" bson:"-"`
+	Translations       []MovieTranslation `json:"-" xml:"-" bson:"translations,omitempty"`
+	SearchTranslations []string           `json:"-" xml:"-" bson:"searchTranslations,omitempty"`
+	TranslationWords   []string           `json:"-" xml:"-" bson:"translationWords,omitempty"`
+	Score              *MovieScore        `json:"score,omitempty" xml:"score,omitempty" bson:"score,omitempty"`
+	SoftDelete         `bson:",inline"`
+}
+
+// MovieTranslation is the metadata of a movie in one locale, such as es or
+// es-CO.
+type MovieTranslation struct {
+	Locale   string `json:"locale" xml:"locale" bson:"locale"`
+	Title    string `json:"title" xml:"title" bson:"title" validate:"required"`
+	Synopsis string `json:"synopsis,omitempty" xml:"synopsis,omitempty" bson:"synopsis,omitempty"`
+	Tagline  string `json:"tagline,omitempty" xml:"tagline,omitempty" bson:"tagline,omitempty"`
 }
 
 type MovieInput struct {
-	Title        string    `json:"title,omitempty" xml:"title,omitempty" bson:"title" validate:"required"`
-	Format       string    `json:"format,omitempty" xml:"format,omitempty" bson:"format" validate:"required"`
-	ReleaseYear  int       `json:"releaseYear,omitempty" xml:"releaseYear,omitempty" bson:"releaseYear"`
-	ReleaseMonth int       `json:"releaseMonth,omitempty" xml:"releaseMonth,omitempty" bson:"releaseMonth"`
-	ReleaseDay   int       `json:"releaseDay,omitempty" xml:"releaseDay,omitempty" bson:"releaseDay"`
-	CreatedAt    time.Time `json:"created_at,omitempty" xml:"created_at,omitempty" bson:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at,omitempty" xml:"updated_at,omitempty" bson:"updated_at"`
+	Title        string       `json:"title,omitempty" xml:"title,omitempty" bson:"title" validate:"required"`
+	Format       string       `json:"format,omitempty" xml:"format,omitempty" bson:"format" validate:"required"`
+	ReleaseDate  *Date        `json:"releaseDate,omitempty" xml:"releaseDate,omitempty" bson:"releaseDate,omitempty"`
+	ReleaseYear  int          `json:"releaseYear,omitempty" xml:"releaseYear,omitempty" bson:"releaseYear"`
+	ReleaseMonth int          `json:"releaseMonth,omitempty" xml:"releaseMonth,omitempty" bson:"releaseMonth" validate:"min=0,max=12"`
+	ReleaseDay   int          `json:"releaseDay,omitempty" xml:"releaseDay,omitempty" bson:"releaseDay" validate:"min=0,max=31"`
+	Runtime      int          `json:"runtime,omitempty" xml:"runtime,omitempty" bson:"runtime,omitempty" validate:"min=0"`
+	Genres       []string     `json:"genres,omitempty" xml:"genres>genre,omitempty" bson:"genres,omitempty" validate:"dive,required"`
+	Ratings      []AgeRating  `json:"ratings,omitempty" xml:"ratings>rating,omitempty" bson:"ratings,omitempty" validate:"dive"`
+	Synopsis     string       `json:"synopsis,omitempty" xml:"synopsis,omitempty" bson:"synopsis,omitempty"`
+	Tagline      string       `json:"tagline,omitempty" xml:"tagline,omitempty" bson:"tagline,omitempty"`
+	Languages    []string     `json:"languages,omitempty" xml:"languages>language,omitempty" bson:"languages,omitempty" validate:"dive,required"`
+	Subtitles    []string     `json:"subtitles,omitempty" xml:"subtitles>subtitle,omitempty" bson:"subtitles,omitempty" validate:"dive,required"`
+	Cast         []CastMember `json:"cast,omitempty" xml:"cast>member,omitempty" bson:"cast,omitempty" validate:"dive"`
+	Crew         []CrewMember `json:"crew,omitempty" xml:"crew>member,omitempty" bson:"crew,omitempty" validate:"dive"`
+	TrailerUrl   string       `json:"trailerUrl,omitempty" xml:"trailerUrl,omitempty" bson:"trailerUrl,omitempty" validate:"omitempty,url"`
+	PosterUrl    string       `json:"posterUrl,omitempty" xml:"posterUrl,omitempty" bson:"posterUrl,omitempty" validate:"omitempty,url"`
+	SearchTitle  string       `json:"-" xml:"-" bson:"searchTitle"`
+	SearchWords  []string     `json:"-" xml:"-" bson:"searchWords,omitempty"`
+	CreatedAt    time.Time    `json:"created_at,omitempty" xml:"created_at,omitempty" bson:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at,omitempty" xml:"updated_at,omitempty" bson:"updated_at"`
+}
+
+// AgeRating is the classification of a movie in a country, such as PG-13 in
+// US or 12 in CO.
+type AgeRating struct {
+	Country string `json:"country" xml:"country" bson:"country" validate:"required,len=2"`
+	Rating  string `json:"rating" xml:"rating" bson:"rating" validate:"required"`
+}
+
+type CastMember struct {
+	Name      string `json:"name" xml:"name" bson:"name" validate:"required"`
+	Character string `json:"character,omitempty" xml:"character,omitempty" bson:"character,omitempty"`
+}
+
+// CrewMember is a person who made the movie and their job, such as Director
+// or Screenplay.
+type CrewMember struct {
+	Name string `json:"name" xml:"name" bson:"name" validate:"required"`
+	Job  string `json:"job" xml:"job" bson:"job" validate:"required"`
+}
+
+// Normalize writes genres in lower case and rating countries in upper case,
+// so that filters match them whatever the case they were sent in. It also
+// fills in the release date from the release year, month and day, or the
+// other way around, the date winning when both are given. Validation rejects
+// the days that are not in their month before.
+func (input *MovieInput) Normalize() {
+	for i, genre := range input.Genres {
+		input.Genres[i] = strings.ToLower(strings.TrimSpace(genre))
+	}
+	for i := range input.Ratings {
+		input.Ratings[i].Country = strings.ToUpper(input.Ratings[i].Country)
+	}
+
+	if input.ReleaseDate != nil {
+		released := input.ReleaseDate.Time()
+		input.ReleaseYear, input.ReleaseMonth, input.ReleaseDay = released.Year(), int(released.Month()), released.Day()
+	} else if input.ReleaseYear > 0 {
+		month, day := input.ReleaseMonth, input.ReleaseDay
+		if month == 0 {
+			month = 1
+		}
+		if day == 0 {
+			day = 1
+		}
+		releaseDate := NewDate(input.ReleaseYear, time.Month(month), day)
+		input.ReleaseDate = &releaseDate
+	}
+}
+
+// MovieFilter narrows the movies of a list. Zero fields do not filter.
+type MovieFilter struct {
+	Format         string
+	Genres         []string
+	Language       string
+	Subtitle       string
+	RatingCountry  string
+	Rating         string
+	Cast           string
+	Crew           string
+	CrewJob        string
+	MinRuntime     int
+	MaxRuntime     int
+	ReleaseYear    int
+	ReleasedAfter  *Date
+	ReleasedBefore *Date
 }
 
 type PagedMovie struct {
-	Data     []Movie                        `json:"data" xml:"data"`
-	PageInfo mongopagination.PaginationData `json:"pageInfo" xml:"pageInfo"`
+	Data     []Movie                         `json:"data" xml:"data"`
+	PageInfo *mongopagination.PaginationData `json:"pageInfo,omitempty" xml:"pageInfo,omitempty"`
+	Cursor   *CursorInfo                     `json:"cursor,omitempty" xml:"cursor,omitempty"`
+}
+
+type MovieSearchQuery struct {
+	Text        string
+	Format      string
+	ReleaseYear int
+	Page        int64
+	Limit       int64
+}
+
+type MovieSearchHit struct {
+	*Movie
+	Score float64 `json:"score" xml:"score"`
+}
+
+// SearchPageInfo tells whether a search has more results. Typos are matched
+// by scoring candidates, so the number of matches is not known ahead.
+type SearchPageInfo struct {
+	Page    int64 `json:"page" xml:"page"`
+	Limit   int64 `json:"limit" xml:"limit"`
+	HasNext bool  `json:"hasNext" xml:"hasNext"`
+	HasPrev bool  `json:"hasPrev" xml:"hasPrev"`
+}
+
+type PagedMovieSearch struct {
+	Data     []MovieSearchHit `json:"data" xml:"data"`
+	PageInfo *SearchPageInfo  `json:"pageInfo,omitempty" xml:"pageInfo,omitempty"`
 }
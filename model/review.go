@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	mongopagination "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Review is the rating from 1 to 5 stars a customer gives a movie, with an
+// optional text. Other customers vote it helpful or report it.
+type Review struct {
+	*ReviewInput `bson:",inline"`
+	ID           primitive.ObjectID `json:"id" xml:"id" bson:"_id,omitempty"`
+	Version      int64              `json:"version" xml:"version" bson:"version"`
+	MovieId      string             `json:"movieId" xml:"movieId" bson:"movieId"`
+	UserId       string             `json:"userId" xml:"userId" bson:"userId"`
+	Helpful      int64              `json:"helpful" xml:"helpful" bson:"helpful"`
+	Reports      int64              `json:"reports" xml:"reports" bson:"reports"`
+	HelpfulBy    []string           `json:"-" xml:"-" bson:"helpfulBy,omitempty"`
+	ReportedBy   []string           `json:"-" xml:"-" bson:"reportedBy,omitempty"`
+	SoftDelete   `bson:",inline"`
+}
+
+type ReviewInput struct {
+	Rating    int       `json:"rating,omitempty" xml:"rating,omitempty" bson:"rating" validate:"required,min=1,max=5"`
+	Text      string    `json:"text,omitempty" xml:"text,omitempty" bson:"text,omitempty" validate:"max=5000"`
+	CreatedAt time.Time `json:"created_at,omitempty" xml:"created_at,omitempty" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at,omitempty" xml:"updated_at,omitempty" bson:"updated_at"`
+}
+
+type PagedReview struct {
+	Data     []Review                        `json:"data" xml:"data"`
+	PageInfo *mongopagination.PaginationData `json:"pageInfo,omitempty" xml:"pageInfo,omitempty"`
+}
+
+// ReviewQuery selects a page of the reviews of a movie, sorted by helpful
+// votes or by date, the highest or latest first.
+type ReviewQuery struct {
+	MovieId string
+	Sort    string
+	Page    int64
+	Limit   int64
+}
+
+// MovieScore sums up the reviews of a movie: their average rating and how
+// many reviews gave each number of stars, from 1 to 5.
+type MovieScore struct {
+	Average   float64      `json:"average" xml:"average" bson:"average"`
+	Count     int64        `json:"count" xml:"count" bson:"count"`
+	Histogram []StarsCount `json:"histogram" xml:"histogram>stars" bson:"histogram"`
+}
+
+type StarsCount struct {
+	Stars int   `json:"stars" xml:"stars,attr" bson:"stars"`
+	Count int64 `json:"count" xml:"count,attr" bson:"count"`
+}
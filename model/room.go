@@ -9,6 +9,8 @@ import (
 type Room struct {
 	*RoomInput `bson:",inline"`
 	ID         primitive.ObjectID `json:"id" xml:"id" bson:"_id,omitempty"`
+	Version    int64              `json:"version" xml:"version" bson:"version"`
+	SoftDelete `bson:",inline"`
 }
 
 type RoomInput struct {
@@ -16,12 +18,14 @@ type RoomInput struct {
 	Capacity  string    `json:"capacity,omitempty" xml:"capacity,omitempty" bson:"capacity" validate:"required"`
 	Format    string    `json:"format,omitempty" xml:"format,omitempty" bson:"format" validate:"required"`
 	CinemaId  string    `json:"cinemaId,omitempty" xml:"cinemaId,omitempty" bson:"cinemaId" validate:"required"`
+	SeatMap   []string  `json:"seatMap,omitempty" xml:"seatMap,omitempty" bson:"seatMap"`
 	Schedules []string  `json:"schedules,omitempty" xml:"schedules,omitempty" bson:"schedules"`
 	CreatedAt time.Time `json:"created_at,omitempty" xml:"created_at,omitempty" bson:"created_at"`
 	UpdatedAt time.Time `json:"updated_at,omitempty" xml:"updated_at,omitempty" bson:"updated_at"`
 }
 
 type PagedRoom struct {
-	Data     []Room                         `json:"data" xml:"data"`
-	PageInfo mongopagination.PaginationData `json:"pageInfo" xml:"pageInfo"`
+	Data     []Room                          `json:"data" xml:"data"`
+	PageInfo *mongopagination.PaginationData `json:"pageInfo,omitempty" xml:"pageInfo,omitempty"`
+	Cursor   *CursorInfo                     `json:"cursor,omitempty" xml:"cursor,omitempty"`
 }
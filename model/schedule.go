@@ -9,6 +9,8 @@ import (
 type Schedule struct {
 	*ScheduleInput `bson:",inline"`
 	ID             primitive.ObjectID `json:"id" xml:"id" bson:"_id,omitempty"`
+	Version        int64              `json:"version" xml:"version" bson:"version"`
+	SoftDelete     `bson:",inline"`
 }
 
 type ScheduleInput struct {
@@ -22,6 +24,7 @@ type ScheduleInput struct {
 }
 
 type PagedSchedule struct {
-	Data     []Schedule                     `json:"data" xml:"data"`
-	PageInfo mongopagination.PaginationData `json:"pageInfo" xml:"pageInfo"`
+	Data     []Schedule                      `json:"data" xml:"data"`
+	PageInfo *mongopagination.PaginationData `json:"pageInfo,omitempty" xml:"pageInfo,omitempty"`
+	Cursor   *CursorInfo                     `json:"cursor,omitempty" xml:"cursor,omitempty"`
 }
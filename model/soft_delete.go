@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// SoftDelete marks a document as deleted without removing it. Deleted
+// documents are hidden from reads until restored or purged. DeletedWith is
+// the id of the parent whose deletion cascaded to the document, which brings
+// it back when restored.
+type SoftDelete struct {
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" xml:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy   string     `json:"deleted_by,omitempty" xml:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+	DeletedWith string     `json:"deleted_with,omitempty" xml:"deleted_with,omitempty" bson:"deleted_with,omitempty"`
+}
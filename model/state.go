@@ -10,6 +10,8 @@ import (
 type State struct {
 	*StateInput `bson:",inline"`
 	ID          primitive.ObjectID `json:"id" xml:"id" bson:"_id,omitempty"`
+	Version     int64              `json:"version" xml:"version" bson:"version"`
+	SoftDelete  `bson:",inline"`
 }
 
 type StateInput struct {
@@ -29,6 +31,7 @@ type StateResponse struct {
 }
 
 type PagedState struct {
-	Data     []State                        `json:"data" xml:"data"`
-	PageInfo mongopagination.PaginationData `json:"pageInfo" xml:"pageInfo"`
+	Data     []State                         `json:"data" xml:"data"`
+	PageInfo *mongopagination.PaginationData `json:"pageInfo,omitempty" xml:"pageInfo,omitempty"`
+	Cursor   *CursorInfo                     `json:"cursor,omitempty" xml:"cursor,omitempty"`
 }
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+var localTimeLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+// LoadTimeZone returns the location of the IANA time zone name, such as
+// America/Bogota. An empty name is UTC.
+func LoadTimeZone(name string) (*time.Location, error) {
+	if name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %s", name)
+	}
+	return time.LoadLocation(name)
+}
+
+// ParseLocalTime reads an RFC 3339 time, or a wall clock time without offset
+// such as 2030-01-10T19:00 in location. Wall clock times skipped or repeated
+// by a daylight saving time change resolve the way time.Date does.
+func ParseLocalTime(text string, location *time.Location) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, text); err == nil {
+		return parsed, nil
+	}
+	for _, layout := range localTimeLayouts {
+		if parsed, err := time.ParseInLocation(layout, text, location); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected YYYY-MM-DDTHH:MM with an optional offset", text)
+}
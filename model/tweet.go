@@ -1,25 +1,104 @@
 package model
 
 import (
+	"regexp"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	mongopagination "github.com/gobeam/mongo-go-pagination"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Tweet struct {
-	*TweetInput `bson:",inline"`
-	ID          primitive.ObjectID `json:"id" xml:"id" bson:"_id,omitempty"`
+	*TweetInput    `bson:",inline"`
+	ID             primitive.ObjectID `json:"id" xml:"id" bson:"_id,omitempty"`
+	Version        int64              `json:"version" xml:"version" bson:"version"`
+	ConversationId string             `json:"conversationId,omitempty" xml:"conversationId,omitempty" bson:"conversationId,omitempty"`
+	RetweetOfId    string             `json:"retweetOfId,omitempty" xml:"retweetOfId,omitempty" bson:"retweetOfId,omitempty"`
+	Entities       *TweetEntities     `json:"entities,omitempty" xml:"entities,omitempty" bson:"entities,omitempty"`
+	Likes          int64              `json:"likes" xml:"likes" bson:"likes"`
+	Replies        int64              `json:"replies" xml:"replies" bson:"replies"`
+	Retweets       int64              `json:"retweets" xml:"retweets" bson:"retweets"`
+	LikedBy        []string           `json:"-" xml:"-" bson:"likedBy,omitempty"`
+	SoftDelete     `bson:",inline"`
 }
 
 type TweetInput struct {
 	UserId    string    `json:"userId,omitempty" xml:"userId,omitempty" bson:"userId" validate:"required"`
 	Message   string    `json:"message,omitempty" xml:"message,omitempty" bson:"message" validate:"required"`
+	ReplyToId string    `json:"replyToId,omitempty" xml:"replyToId,omitempty" bson:"replyToId,omitempty"`
+	QuoteOfId string    `json:"quoteOfId,omitempty" xml:"quoteOfId,omitempty" bson:"quoteOfId,omitempty"`
 	CreatedAt time.Time `json:"created_at,omitempty" xml:"created_at,omitempty" bson:"created_at"`
 	UpdatedAt time.Time `json:"updated_at,omitempty" xml:"updated_at,omitempty" bson:"updated_at"`
 }
 
 type PagedTweet struct {
-	Data     []Tweet                        `json:"data" xml:"data"`
-	PageInfo mongopagination.PaginationData `json:"pageInfo" xml:"pageInfo"`
+	Data     []Tweet                         `json:"data" xml:"data"`
+	PageInfo *mongopagination.PaginationData `json:"pageInfo,omitempty" xml:"pageInfo,omitempty"`
+	Cursor   *CursorInfo                     `json:"cursor,omitempty" xml:"cursor,omitempty"`
+}
+
+// TweetEntities are the @mentions and #hashtags of the message of a tweet.
+// Start and End are the positions, in characters, of each in the message.
+type TweetEntities struct {
+	Mentions []Mention `json:"mentions,omitempty" xml:"mentions>mention,omitempty" bson:"mentions,omitempty"`
+	Hashtags []Hashtag `json:"hashtags,omitempty" xml:"hashtags>hashtag,omitempty" bson:"hashtags,omitempty"`
+}
+
+// Mention is an @username in a tweet, with the id of the user of that name
+// when there is one.
+type Mention struct {
+	Username string `json:"username" xml:"username" bson:"username"`
+	UserId   string `json:"userId,omitempty" xml:"userId,omitempty" bson:"userId,omitempty"`
+	Start    int    `json:"start" xml:"start" bson:"start"`
+	End      int    `json:"end" xml:"end" bson:"end"`
+}
+
+// Hashtag is a #tag in a tweet, in lower case so that feeds match it
+// whatever the case it was written in.
+type Hashtag struct {
+	Tag   string `json:"tag" xml:"tag" bson:"tag"`
+	Start int    `json:"start" xml:"start" bson:"start"`
+	End   int    `json:"end" xml:"end" bson:"end"`
+}
+
+var (
+	mentionPattern = regexp.MustCompile(`(^|[^A-Za-z0-9_@])@([A-Za-z0-9_]+)`)
+	hashtagPattern = regexp.MustCompile(`(^|[^\p{L}\p{N}_#&])#([\p{L}\p{N}_]+)`)
+	digitsPattern  = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// ParseTweetEntities finds the @mentions and #hashtags of message. Mentions
+// of names that cannot be usernames, as in email addresses, and hashtags made
+// only of digits are left out. It returns nil when there are none.
+func ParseTweetEntities(message string) *TweetEntities {
+	entities := &TweetEntities{}
+	for _, match := range mentionPattern.FindAllStringSubmatchIndex(message, -1) {
+		username := message[match[4]:match[5]]
+		if !UsernamePattern.MatchString(username) {
+			continue
+		}
+		entities.Mentions = append(entities.Mentions, Mention{
+			Username: strings.ToLower(username),
+			Start:    utf8.RuneCountInString(message[:match[4]-1]),
+			End:      utf8.RuneCountInString(message[:match[5]]),
+		})
+	}
+	for _, match := range hashtagPattern.FindAllStringSubmatchIndex(message, -1) {
+		tag := message[match[4]:match[5]]
+		if digitsPattern.MatchString(tag) {
+			continue
+		}
+		entities.Hashtags = append(entities.Hashtags, Hashtag{
+			Tag:   strings.ToLower(tag),
+			Start: utf8.RuneCountInString(message[:match[4]-1]),
+			End:   utf8.RuneCountInString(message[:match[5]]),
+		})
+	}
+
+	if len(entities.Mentions) == 0 && len(entities.Hashtags) == 0 {
+		return nil
+	}
+	return entities
 }
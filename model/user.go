@@ -1,6 +1,8 @@
 package model
 
 import (
+	"regexp"
+	"strings"
 	"time"
 
 	mongopagination "github.com/gobeam/mongo-go-pagination"
@@ -10,6 +12,13 @@ import (
 type User struct {
 	*UserInput `bson:",inline"`
 	ID         primitive.ObjectID `json:"id" xml:"id" bson:"_id,omitempty"`
+	Version    int64              `json:"version" xml:"version" bson:"version"`
+	Followers  int64              `json:"followers" xml:"followers" bson:"followers"`
+	Following  int64              `json:"following" xml:"following" bson:"following"`
+	// CalendarToken is the hash of the secret of the personal calendar feed
+	// of the user, if they have one. It is never shown.
+	CalendarToken string `json:"-" xml:"-" bson:"calendarToken,omitempty"`
+	SoftDelete    `bson:",inline"`
 }
 
 type UserInput struct {
@@ -17,22 +26,41 @@ type UserInput struct {
 	Lastname  string    `json:"lastname,omitempty" xml:"lastname,omitempty" bson:"lastname" validate:"required"`
 	BirthDate time.Time `json:"birthDate,omitempty" xml:"birthDate,omitempty" bson:"birthDate"`
 	Email     string    `json:"email" xml:"email" bson:"email" validate:"required,email"`
+	Username  string    `json:"username,omitempty" xml:"username,omitempty" bson:"username,omitempty" validate:"omitempty,username"`
 	Password  string    `json:"password,omitempty" xml:"password,omitempty" bson:"password"`
 	Avatar    string    `json:"avatar,omitempty" xml:"avatar,omitempty" bson:"avatar"`
 	Banner    string    `json:"banner,omitempty" xml:"banner,omitempty" bson:"banner"`
 	Biography string    `json:"biography,omitempty" xml:"biography,omitempty" bson:"biography"`
 	Location  string    `json:"location,omitempty" xml:"location,omitempty" bson:"location"`
 	WebSite   string    `json:"webSite,omitempty" xml:"webSite,omitempty" bson:"webSite"`
+	Role      string    `json:"role,omitempty" xml:"role,omitempty" bson:"role"`
 	CreatedAt time.Time `json:"created_at,omitempty" xml:"created_at,omitempty" bson:"created_at"`
 	UpdatedAt time.Time `json:"updated_at,omitempty" xml:"updated_at,omitempty" bson:"updated_at"`
 }
 
+// UsernamePattern matches usernames: 1 to 15 letters, digits or underscores.
+var UsernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`)
+
+// Normalize writes the username in lower case, as usernames are matched
+// whatever the case they are written in, such as in @mentions.
+func (input *UserInput) Normalize() {
+	input.Username = strings.ToLower(input.Username)
+}
+
 type SignInInput struct {
 	Email    string `json:"email" xml:"email" bson:"email" validate:"required,email"`
 	Password string `json:"password" xml:"password" bson:"password" validate:"required"`
 }
 
+// PasswordInput changes the password of a user. Users changing their own
+// password confirm it with the current one.
+type PasswordInput struct {
+	CurrentPassword string `json:"currentPassword,omitempty" xml:"currentPassword,omitempty"`
+	NewPassword     string `json:"newPassword" xml:"newPassword" validate:"required"`
+}
+
 type PagedUser struct {
-	Data     []User                         `json:"data" xml:"data"`
-	PageInfo mongopagination.PaginationData `json:"pageInfo" xml:"pageInfo"`
+	Data     []User                          `json:"data" xml:"data"`
+	PageInfo *mongopagination.PaginationData `json:"pageInfo,omitempty" xml:"pageInfo,omitempty"`
+	Cursor   *CursorInfo                     `json:"cursor,omitempty" xml:"cursor,omitempty"`
 }
@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// BookingRepository stores the bookings of users and keeps the free and
+// occupied seats of their showtimes in step with them.
+type BookingRepository interface {
+	GetUserBookings(ctx context.Context, userId string, query *model.CursorQuery) (*model.PagedBooking, error)
+	GetUpcomingBookings(ctx context.Context, userId string, from time.Time) ([]model.Booking, error)
+	GetBooking(ctx context.Context, id string) (*model.Booking, error)
+	SaveBooking(ctx context.Context, booking *model.Booking) (*model.Booking, error)
+	CancelBooking(ctx context.Context, id string) error
+	CheckIn(ctx context.Context, id string, at time.Time) (*model.Booking, error)
+	HasCheckedIn(ctx context.Context, userId string, movieId string) (bool, error)
+}
+
+type bookingRepositoryImpl struct {
+	Connection *mongo.Database
+}
+
+func NewBookingRepository(Connection *mongo.Database) BookingRepository {
+	return &bookingRepositoryImpl{Connection: Connection}
+}
+
+func (bookingRepository *bookingRepositoryImpl) GetUserBookings(ctx context.Context, userId string, query *model.CursorQuery) (*model.PagedBooking, error) {
+	collection := bookingRepository.Connection.Collection("bookings")
+
+	documents, cursorInfo, err := findByCursor(ctx, collection, notDeleted(ctx, bson.M{"userId": userId}), nil, query, nil, "created_at")
+	if err != nil {
+		return nil, err
+	}
+	return pagedBookings(documents, cursorInfo)
+}
+
+func (bookingRepository *bookingRepositoryImpl) GetUpcomingBookings(ctx context.Context, userId string, from time.Time) ([]model.Booking, error) {
+	return findUpcomingBookings(ctx, mongoCollection(bookingRepository.Connection, "bookings"), userId, from)
+}
+
+func (bookingRepository *bookingRepositoryImpl) GetBooking(ctx context.Context, id string) (*model.Booking, error) {
+	return findBooking(ctx, mongoCollection(bookingRepository.Connection, "bookings"), id)
+}
+
+func (bookingRepository *bookingRepositoryImpl) SaveBooking(ctx context.Context, booking *model.Booking) (*model.Booking, error) {
+	return insertBooking(ctx, mongoCollection(bookingRepository.Connection, "bookings"), booking)
+}
+
+func (bookingRepository *bookingRepositoryImpl) CancelBooking(ctx context.Context, id string) error {
+	return cancelBooking(ctx, mongoCollection(bookingRepository.Connection, "bookings"), id)
+}
+
+func (bookingRepository *bookingRepositoryImpl) CheckIn(ctx context.Context, id string, at time.Time) (*model.Booking, error) {
+	return checkInBooking(ctx, mongoCollection(bookingRepository.Connection, "bookings"), id, at)
+}
+
+func (bookingRepository *bookingRepositoryImpl) HasCheckedIn(ctx context.Context, userId string, movieId string) (bool, error) {
+	return hasCheckedIn(ctx, mongoCollection(bookingRepository.Connection, "bookings"), userId, movieId)
+}
+
+func pagedBookings(documents []bson.Raw, cursorInfo *model.CursorInfo) (*model.PagedBooking, error) {
+	bookings := []model.Booking{}
+	for _, document := range documents {
+		var booking model.Booking
+		if err := bson.Unmarshal(document, &booking); err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+	return &model.PagedBooking{
+		Data:   bookings,
+		Cursor: cursorInfo,
+	}, nil
+}
+
+// findUpcomingBookings lists the bookings of userId for showtimes from from
+// on, the soonest first.
+func findUpcomingBookings(ctx context.Context, bookings documentStore, userId string, from time.Time) ([]model.Booking, error) {
+	upcoming := []model.Booking{}
+	filter := notDeleted(ctx, bson.M{"userId": userId, "date": bson.M{"$gte": from}})
+	if err := bookings.Find(ctx, filter, &upcoming); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].Date.Before(upcoming[j].Date)
+	})
+	return upcoming, nil
+}
+
+func findBooking(ctx context.Context, bookings documentStore, id string) (*model.Booking, error) {
+	var booking model.Booking
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	found, err := bookings.FindOne(ctx, filter, nil, &booking)
+	if err != nil || !found {
+		return nil, exception.ResourceNotFoundException("Booking", "id", id)
+	}
+	return &booking, nil
+}
+
+// insertBooking takes the seats of the booking from the free seats of its
+// showtime, which must not have started by the time the booking was created.
+// Seats someone else holds are a conflict, and seats the room does not have
+// a bad request.
+func insertBooking(ctx context.Context, bookings documentStore, booking *model.Booking) (*model.Booking, error) {
+	booking.ID = primitive.NewObjectID()
+	booking.Version = 1
+	booking.Seats = distinctSeats(booking.Seats)
+
+	err := bookings.Transaction(ctx, func(ctx context.Context) error {
+		schedules := bookings.Collection("schedules")
+		schedule, err := findSchedule(ctx, schedules, booking.ScheduleId)
+		if err != nil {
+			return err
+		}
+		if !schedule.Date.After(booking.CreatedAt) {
+			return exception.RequestConflictException("error.showtime_started")
+		}
+
+		if taken := missingSeats(booking.Seats, schedule.SeatsEmpty); len(taken) > 0 {
+			if unknown := missingSeats(taken, schedule.SeatsOccupied); len(unknown) > 0 {
+				return exception.InvalidRequestException("error.unknown_seats", strings.Join(unknown, ", "))
+			}
+			return exception.RequestConflictException("error.seats_taken", strings.Join(taken, ", "))
+		}
+
+		booking.MovieId = schedule.MovieId
+		booking.RoomId = schedule.RoomId
+		booking.Date = schedule.Date
+		if err := moveSeats(ctx, schedules, schedule, booking.Seats, false); err != nil {
+			return err
+		}
+		return bookings.InsertOne(ctx, booking)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// cancelBooking deletes a booking and frees its seats. Bookings already
+// checked in, or of showtimes that started, stay.
+func cancelBooking(ctx context.Context, bookings documentStore, id string) error {
+	return bookings.Transaction(ctx, func(ctx context.Context) error {
+		booking, err := findBooking(ctx, bookings, id)
+		if err != nil {
+			return err
+		}
+		if booking.CheckedInAt != nil {
+			return exception.RequestConflictException("error.booking_checked_in")
+		}
+		if !booking.Date.After(time.Now()) {
+			return exception.RequestConflictException("error.showtime_started")
+		}
+
+		deleted, err := deleteDocument(ctx, bookings, bson.M{"_id": booking.ID})
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return exception.ResourceNotFoundException("Booking", "id", id)
+		}
+
+		// A deleted showtime has no seats left to free.
+		schedules := bookings.Collection("schedules")
+		schedule, err := findSchedule(ctx, schedules, booking.ScheduleId)
+		if err != nil {
+			return nil
+		}
+		return moveSeats(ctx, schedules, schedule, booking.Seats, true)
+	})
+}
+
+// checkInBooking records that the user of a booking arrived at the cinema,
+// once: checking a booking in twice is a conflict.
+func checkInBooking(ctx context.Context, bookings documentStore, id string, at time.Time) (*model.Booking, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId, "checkedInAt": nil})
+
+	var booking model.Booking
+	found, err := updateDocument(ctx, bookings, filter, bson.M{"checkedInAt": at}, &booking)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		if _, err := findBooking(ctx, bookings, id); err != nil {
+			return nil, err
+		}
+		return nil, exception.RequestConflictException("error.booking_checked_in")
+	}
+	return &booking, nil
+}
+
+// hasCheckedIn tells whether userId was checked in to a showtime of movieId.
+func hasCheckedIn(ctx context.Context, bookings documentStore, userId string, movieId string) (bool, error) {
+	count, err := bookings.Count(ctx, bson.M{
+		"userId":      userId,
+		"movieId":     movieId,
+		"checkedInAt": bson.M{"$ne": nil},
+		"deleted_at":  nil,
+	})
+	return count > 0, err
+}
+
+// moveSeats moves seats of schedule from its free seats to its occupied
+// ones, or back when free is set. The update only applies to the version of
+// the showtime read, so two bookings racing for the same seats cannot both
+// take them.
+func moveSeats(ctx context.Context, schedules documentStore, schedule *model.Schedule, seats []string, free bool) error {
+	empty, occupied := schedule.SeatsEmpty, schedule.SeatsOccupied
+	if free {
+		occupied, empty = withoutSeats(occupied, seats), append(append([]string{}, empty...), seats...)
+	} else {
+		empty, occupied = withoutSeats(empty, seats), append(append([]string{}, occupied...), seats...)
+	}
+
+	var version interface{} = schedule.Version
+	if schedule.Version == 0 {
+		version = bson.M{"$in": bson.A{0, nil}}
+	}
+	filter := bson.M{"_id": schedule.ID, "version": version}
+	update := bson.M{
+		"$set": bson.M{"seatsEmpty": empty, "seatsOccupied": occupied},
+		"$inc": bson.M{"version": 1},
+	}
+
+	matched, err := schedules.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		return exception.RequestConflictException("error.seats_taken", strings.Join(seats, ", "))
+	}
+	return nil
+}
+
+func distinctSeats(seats []string) []string {
+	distinct := make([]string, 0, len(seats))
+	seen := map[string]bool{}
+	for _, seat := range seats {
+		if !seen[seat] {
+			seen[seat] = true
+			distinct = append(distinct, seat)
+		}
+	}
+	return distinct
+}
+
+// missingSeats returns the seats that are not in available.
+func missingSeats(seats []string, available []string) []string {
+	var missing []string
+	for _, seat := range seats {
+		if !containsSeat(available, seat) {
+			missing = append(missing, seat)
+		}
+	}
+	return missing
+}
+
+func withoutSeats(seats []string, removed []string) []string {
+	kept := []string{}
+	for _, seat := range seats {
+		if !containsSeat(removed, seat) {
+			kept = append(kept, seat)
+		}
+	}
+	return kept
+}
+
+func containsSeat(seats []string, seat string) bool {
+	for _, candidate := range seats {
+		if candidate == seat {
+			return true
+		}
+	}
+	return false
+}
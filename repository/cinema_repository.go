@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"math"
+
 	"github.com/cbuelvasc/cinema-backend/exception"
 	"github.com/cbuelvasc/cinema-backend/model"
 	paginate "github.com/gobeam/mongo-go-pagination"
@@ -12,13 +14,20 @@ import (
 
 type CinemaRepository interface {
 	GetAllCinemas(ctx context.Context, page int64, limit int64) (*model.PagedCinema, error)
+	GetAllCinemasByCursor(ctx context.Context, query *model.CursorQuery) (*model.PagedCinema, error)
+	GetAllCinemaDocuments(ctx context.Context, query *model.DocumentQuery) (*model.PagedDocument, error)
 	GetCinemaById(ctx context.Context, id string) (*model.Cinema, error)
+	GetCinemaDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error)
 	GetCinemaByCity(ctx context.Context, cityId string) (*model.Cinema, error)
 	SaveCinema(ctx context.Context, cinema *model.Cinema) (*model.Cinema, error)
 	UpdateCinema(ctx context.Context, id string, cinemaId *model.Cinema) (*model.Cinema, error)
 	DeleteCinema(ctx context.Context, id string, cinemaId string) error
+	RestoreCinema(ctx context.Context, id string) (*model.Cinema, error)
+	GetNearbyCinemas(ctx context.Context, query *model.NearbyCinemaQuery) (*model.NearbyCinemaList, error)
 }
 
+const maxNearbyLimit int64 = 50
+
 type cinemaRepositoryImpl struct {
 	Connection *mongo.Database
 }
@@ -27,40 +36,78 @@ func NewCinemaRepository(Connection *mongo.Database) CinemaRepository {
 	return &cinemaRepositoryImpl{Connection: Connection}
 }
 
+var cinemaProjection = bson.D{
+	{"id", 1},
+	{"name", 1},
+	{"cityId", 1},
+	{"address", 1},
+	{"location", 1},
+	{"premieres", 1},
+	{"rooms", 1},
+	{"created_at", 1},
+	{"version", 1},
+	{"deleted_at", 1},
+	{"deleted_by", 1},
+}
+
 func (cinemaRepository *cinemaRepositoryImpl) GetAllCinemas(ctx context.Context, page int64, limit int64) (*model.PagedCinema, error) {
 	var cinemas []model.Cinema
 
-	filter := bson.M{
-	}
+	filter := notDeleted(ctx, bson.M{
+	})
 
 	collection := cinemaRepository.Connection.Collection("cinemas")
 
-	projection := bson.D{
-		{"id", 1},
-		{"name", 1},
-		{"cityId", 1},
-		{"premieres", 1},
-		{"rooms", 1},
-		{"created_at", 1},
+	paginatedData, err := paginate.New(collection).Context(ctx).Limit(limit).Page(page).Select(cinemaProjection).Filter(filter).Decode(&cinemas).Find()
+	if err != nil {
+		return nil, err
 	}
 
-	paginatedData, err := paginate.New(collection).Context(ctx).Limit(limit).Page(page).Select(projection).Filter(filter).Decode(&cinemas).Find()
+	return &model.PagedCinema{
+		Data:     cinemas,
+		PageInfo: &paginatedData.Pagination,
+	}, nil
+}
+
+func (cinemaRepository *cinemaRepositoryImpl) GetAllCinemasByCursor(ctx context.Context, query *model.CursorQuery) (*model.PagedCinema, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	collection := cinemaRepository.Connection.Collection("cinemas")
+
+	documents, cursorInfo, err := findByCursor(ctx, collection, filter, cinemaProjection, query, nil, "name", "created_at")
 	if err != nil {
 		return nil, err
 	}
 
+	cinemas := make([]model.Cinema, 0, len(documents))
+	for _, document := range documents {
+		var cinema model.Cinema
+		if err := bson.Unmarshal(document, &cinema); err != nil {
+			return nil, err
+		}
+		cinemas = append(cinemas, cinema)
+	}
+
 	return &model.PagedCinema{
-		Data:     cinemas,
-		PageInfo: paginatedData.Pagination,
+		Data:   cinemas,
+		Cursor: cursorInfo,
 	}, nil
 }
 
+func (cinemaRepository *cinemaRepositoryImpl) GetAllCinemaDocuments(ctx context.Context, query *model.DocumentQuery) (*model.PagedDocument, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	collection := cinemaRepository.Connection.Collection("cinemas")
+
+	return findDocuments(ctx, collection, filter, query, "name", "created_at")
+}
+
 func (cinemaRepository *cinemaRepositoryImpl) GetCinemaById(ctx context.Context, id string) (*model.Cinema, error) {
 	var cinema model.Cinema
 	objectId, _ := primitive.ObjectIDFromHex(id)
-	filter := bson.M{
+	filter := notDeleted(ctx, bson.M{
 		"_id": objectId,
-	}
+	})
 
 	err := cinemaRepository.Connection.Collection("cinemas").FindOne(ctx, filter).Decode(&cinema)
 	if err != nil {
@@ -69,12 +116,36 @@ func (cinemaRepository *cinemaRepositoryImpl) GetCinemaById(ctx context.Context,
 	return &cinema, nil
 }
 
+func (cinemaRepository *cinemaRepositoryImpl) GetCinemaDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{
+		"_id": objectId,
+	})
+
+	document, err := findDocument(ctx, cinemaRepository.Connection.Collection("cinemas"), filter, query)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, exception.ResourceNotFoundException("Cinema", "id", id)
+	}
+	return document, nil
+}
+
 func (cinemaRepository *cinemaRepositoryImpl) GetCinemaByCity(ctx context.Context, cityId string) (*model.Cinema, error) {
 	panic("implement me")
 }
 
 func (cinemaRepository *cinemaRepositoryImpl) SaveCinema(ctx context.Context, cinema *model.Cinema) (*model.Cinema, error) {
-	panic("implement me")
+	cinema.ID = primitive.NewObjectID()
+	cinema.Version = 1
+	cinema.Rooms = []string{}
+
+	if err := insertLinked(ctx, mongoCollection(cinemaRepository.Connection, "cinemas"), cinema, cinema.ID, cinema.CityId); err != nil {
+		return nil, err
+	}
+
+	return cinema, nil
 }
 
 func (cinemaRepository *cinemaRepositoryImpl) UpdateCinema(ctx context.Context, id string, cinemaId *model.Cinema) (*model.Cinema, error) {
@@ -82,5 +153,111 @@ func (cinemaRepository *cinemaRepositoryImpl) UpdateCinema(ctx context.Context,
 }
 
 func (cinemaRepository *cinemaRepositoryImpl) DeleteCinema(ctx context.Context, id string, cinemaId string) error {
-	panic("implement me")
-}
\ No newline at end of file
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := bson.M{
+		"_id": objectId,
+	}
+
+	deleted, err := deleteLinked(ctx, mongoCollection(cinemaRepository.Connection, "cinemas"), filter)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return exception.ResourceNotFoundException("Cinema", "id", id)
+	}
+
+	return nil
+}
+
+func (cinemaRepository *cinemaRepositoryImpl) RestoreCinema(ctx context.Context, id string) (*model.Cinema, error) {
+	restored, err := restoreLinked(ctx, mongoCollection(cinemaRepository.Connection, "cinemas"), id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, exception.ResourceNotFoundException("Cinema", "id", id)
+	}
+
+	return cinemaRepository.GetCinemaById(ctx, id)
+}
+
+func (cinemaRepository *cinemaRepositoryImpl) GetNearbyCinemas(ctx context.Context, query *model.NearbyCinemaQuery) (*model.NearbyCinemaList, error) {
+	filter, err := nearbyCinemaFilter(ctx, mongoCollection(cinemaRepository.Connection, "cinemas"), query)
+	if err != nil {
+		return nil, err
+	}
+
+	// $geoNear needs the 2dsphere index on location and sorts by distance.
+	pipeline := bson.A{
+		bson.M{"$geoNear": bson.M{
+			"near":          model.NewGeoPoint(query.Latitude, query.Longitude),
+			"distanceField": "distance",
+			"maxDistance":   query.Radius,
+			"spherical":     true,
+			"query":         filter,
+		}},
+		bson.M{"$limit": nearbyLimit(query.Limit)},
+	}
+
+	cursor, err := cinemaRepository.Connection.Collection("cinemas").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	cinemas := []model.NearbyCinema{}
+	if err := cursor.All(ctx, &cinemas); err != nil {
+		return nil, err
+	}
+	for i := range cinemas {
+		cinemas[i].Distance = math.Round(cinemas[i].Distance)
+	}
+	return &model.NearbyCinemaList{Data: cinemas}, nil
+}
+
+// nearbyCinemaFilter selects the cinemas not deleted and, when query names a
+// movie, the ones with a showtime of it from query.From on.
+func nearbyCinemaFilter(ctx context.Context, cinemas documentStore, query *model.NearbyCinemaQuery) (bson.M, error) {
+	filter := notDeleted(ctx, bson.M{})
+	if len(query.MovieId) == 0 {
+		return filter, nil
+	}
+
+	var showtimes []model.Schedule
+	showtimeFilter := notDeleted(ctx, bson.M{"movieId": query.MovieId, "date": bson.M{"$gte": query.From}})
+	if err := cinemas.Collection("schedules").Find(ctx, showtimeFilter, &showtimes); err != nil {
+		return nil, err
+	}
+	roomIds := bson.A{}
+	for _, showtime := range showtimes {
+		if objectId, err := primitive.ObjectIDFromHex(showtime.RoomId); err == nil {
+			roomIds = append(roomIds, objectId)
+		}
+	}
+
+	var rooms []model.Room
+	if len(roomIds) > 0 {
+		if err := cinemas.Collection("rooms").Find(ctx, notDeleted(ctx, bson.M{"_id": bson.M{"$in": roomIds}}), &rooms); err != nil {
+			return nil, err
+		}
+	}
+	cinemaIds := bson.A{}
+	for _, room := range rooms {
+		if objectId, err := primitive.ObjectIDFromHex(room.CinemaId); err == nil {
+			cinemaIds = append(cinemaIds, objectId)
+		}
+	}
+
+	filter["_id"] = bson.M{"$in": cinemaIds}
+	return filter, nil
+}
+
+func nearbyLimit(limit int64) int64 {
+	if limit < 1 {
+		return 10
+	}
+	if limit > maxNearbyLimit {
+		return maxNearbyLimit
+	}
+	return limit
+}
@@ -2,7 +2,7 @@ package repository
 
 import (
 	"context"
-	"fmt"
+	"time"
 
 	"github.com/cbuelvasc/cinema-backend/exception"
 	"github.com/cbuelvasc/cinema-backend/model"
@@ -14,10 +14,17 @@ import (
 
 type CityRepository interface {
 	GetAllCities(ctx context.Context, page int64, limit int64, stateId string) (*model.PagedCity, error)
+	GetAllCitiesByCursor(ctx context.Context, query *model.CursorQuery, stateId string) (*model.PagedCity, error)
+	GetAllCityDocuments(ctx context.Context, query *model.DocumentQuery, stateId string) (*model.PagedDocument, error)
 	GetCityById(ctx context.Context, id string) (*model.City, error)
+	GetCityDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error)
+	GetCityByName(ctx context.Context, stateId string, name string) (*model.City, error)
 	SaveCity(ctx context.Context, city *model.City) (*model.City, error)
 	UpdateCity(ctx context.Context, id string, city *model.City) (*model.City, error)
-	DeleteCity(ctx context.Context, id string, cityId string) error
+	PatchCity(ctx context.Context, id string, city *model.City) (*model.City, error)
+	DeleteCity(ctx context.Context, id string) error
+	RestoreCity(ctx context.Context, id string) (*model.City, error)
+	GetCityTimeZone(ctx context.Context, id string) (*time.Location, error)
 }
 
 type cityRepositoryImpl struct {
@@ -28,42 +35,97 @@ func NewCityRepository(Connection *mongo.Database) CityRepository {
 	return &cityRepositoryImpl{Connection: Connection}
 }
 
+var cityProjection = bson.D{
+	{"id", 1},
+	{"name", 1},
+	{"timeZone", 1},
+	{"stateId", 1},
+	{"cinemas", 1},
+	{"created_at", 1},
+	{"version", 1},
+	{"deleted_at", 1},
+	{"deleted_by", 1},
+}
+
 func (cityRepository *cityRepositoryImpl) GetAllCities(ctx context.Context, page int64, limit int64, stateId string) (*model.PagedCity, error) {
 	var cities []model.City
 
-	filter := bson.M{}
+	var filter = bson.M{}
+	if len(stateId) > 0 {
+		filter = bson.M{
+			"stateId": stateId,
+		}
+	}
+	filter = notDeleted(ctx, filter)
 
 	collection := cityRepository.Connection.Collection("cities")
 
-	projection := bson.D{
-		{"id", 1},
-		{"name", 1},
-		{"countryId", 1},
-		{"cities", 1},
-		{"created_at", 1},
-	}
-
-	paginatedData, err := paginate.New(collection).Context(ctx).Limit(limit).Page(page).Select(projection).Filter(filter).Decode(&cities).Find()
+	paginatedData, err := paginate.New(collection).Context(ctx).Limit(limit).Page(page).Select(cityProjection).Filter(filter).Decode(&cities).Find()
 	if err != nil {
 		return nil, err
 	}
 
 	if cities == nil {
-		return nil, exception.NotFoundRequestException("Cities not found")
+		return nil, exception.ResourcesNotFoundException("Cities")
 	}
 
 	return &model.PagedCity{
 		Data:     cities,
-		PageInfo: paginatedData.Pagination,
+		PageInfo: &paginatedData.Pagination,
 	}, nil
 }
 
+func (cityRepository *cityRepositoryImpl) GetAllCitiesByCursor(ctx context.Context, query *model.CursorQuery, stateId string) (*model.PagedCity, error) {
+	var filter = bson.M{}
+	if len(stateId) > 0 {
+		filter = bson.M{
+			"stateId": stateId,
+		}
+	}
+	filter = notDeleted(ctx, filter)
+
+	collection := cityRepository.Connection.Collection("cities")
+
+	documents, cursorInfo, err := findByCursor(ctx, collection, filter, cityProjection, query, nil, "name", "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	cities := make([]model.City, 0, len(documents))
+	for _, document := range documents {
+		var city model.City
+		if err := bson.Unmarshal(document, &city); err != nil {
+			return nil, err
+		}
+		cities = append(cities, city)
+	}
+
+	return &model.PagedCity{
+		Data:   cities,
+		Cursor: cursorInfo,
+	}, nil
+}
+
+func (cityRepository *cityRepositoryImpl) GetAllCityDocuments(ctx context.Context, query *model.DocumentQuery, stateId string) (*model.PagedDocument, error) {
+	var filter = bson.M{}
+	if len(stateId) > 0 {
+		filter = bson.M{
+			"stateId": stateId,
+		}
+	}
+	filter = notDeleted(ctx, filter)
+
+	collection := cityRepository.Connection.Collection("cities")
+
+	return findDocuments(ctx, collection, filter, query, "name", "created_at")
+}
+
 func (cityRepository *cityRepositoryImpl) GetCityById(ctx context.Context, id string) (*model.City, error) {
 	var city model.City
 	objectId, _ := primitive.ObjectIDFromHex(id)
-	filter := bson.M{
+	filter := notDeleted(ctx, bson.M{
 		"_id": objectId,
-	}
+	})
 
 	err := cityRepository.Connection.Collection("cities").FindOne(ctx, filter).Decode(&city)
 	if err != nil {
@@ -72,10 +134,39 @@ func (cityRepository *cityRepositoryImpl) GetCityById(ctx context.Context, id st
 	return &city, nil
 }
 
+func (cityRepository *cityRepositoryImpl) GetCityDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{
+		"_id": objectId,
+	})
+
+	document, err := findDocument(ctx, cityRepository.Connection.Collection("cities"), filter, query)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, exception.ResourceNotFoundException("City", "id", id)
+	}
+	return document, nil
+}
+
+func (cityRepository *cityRepositoryImpl) GetCityByName(ctx context.Context, stateId string, name string) (*model.City, error) {
+	var city model.City
+	filter := notDeleted(ctx, bson.M{"stateId": stateId, "name": name})
+
+	err := cityRepository.Connection.Collection("cities").FindOne(ctx, filter).Decode(&city)
+	if err != nil {
+		return nil, exception.ResourceNotFoundException("City", "name", name)
+	}
+	return &city, nil
+}
+
 func (cityRepository *cityRepositoryImpl) SaveCity(ctx context.Context, city *model.City) (*model.City, error) {
 	city.ID = primitive.NewObjectID()
+	city.Version = 1
+	city.Cinemas = []string{}
 
-	_, err := cityRepository.Connection.Collection("cities").InsertOne(ctx, city)
+	err := insertLinked(ctx, mongoCollection(cityRepository.Connection, "cities"), city, city.ID, city.StateId)
 	if err != nil {
 		return nil, err
 	}
@@ -83,32 +174,105 @@ func (cityRepository *cityRepositoryImpl) SaveCity(ctx context.Context, city *mo
 	return city, nil
 }
 
+// cityUpdateFields returns the fields an update writes: only the ones set in
+// city.
+func cityUpdateFields(city *model.City) bson.M {
+	fields := bson.M{}
+	if len(city.Name) > 0 {
+		fields["name"] = city.Name
+	}
+	if len(city.StateId) > 0 {
+		fields["stateId"] = city.StateId
+	}
+	if len(city.TimeZone) > 0 {
+		fields["timeZone"] = city.TimeZone
+	}
+	if !city.UpdatedAt.IsZero() {
+		fields["updated_at"] = city.UpdatedAt
+	}
+	return fields
+}
+
 func (cityRepository *cityRepositoryImpl) UpdateCity(ctx context.Context, id string, city *model.City) (*model.City, error) {
-	city.ID = primitive.NewObjectID()
+	objectId, _ := primitive.ObjectIDFromHex(id)
 
-	_, err := cityRepository.Connection.Collection("cities").InsertOne(ctx, city)
+	registry := cityUpdateFields(city)
+
+	filter := notDeleted(ctx, bson.M{"_id": bson.M{"$eq": objectId}})
+
+	var updated model.City
+	found, err := updateLinked(ctx, mongoCollection(cityRepository.Connection, "cities"), filter, registry, &updated)
 	if err != nil {
 		return nil, err
 	}
+	if !found {
+		return nil, exception.ResourceNotFoundException("City", "id", id)
+	}
 
-	return city, nil
+	return &updated, nil
+}
+
+// cityPatchFields returns the fields a patch writes: every patchable field of
+// city, so fields cleared by the patch are cleared in the stored document too.
+func cityPatchFields(city *model.City) bson.M {
+	fields := bson.M{
+		"name":       city.Name,
+		"stateId":    city.StateId,
+		"timeZone":   city.TimeZone,
+		"updated_at": city.UpdatedAt,
+	}
+	return fields
+}
+
+func (cityRepository *cityRepositoryImpl) PatchCity(ctx context.Context, id string, city *model.City) (*model.City, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+
+	registry := cityPatchFields(city)
+
+	filter := notDeleted(ctx, bson.M{"_id": bson.M{"$eq": objectId}})
+
+	var updated model.City
+	found, err := updateLinked(ctx, mongoCollection(cityRepository.Connection, "cities"), filter, registry, &updated)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, exception.ResourceNotFoundException("City", "id", id)
+	}
+
+	return &updated, nil
 }
 
-func (cityRepository *cityRepositoryImpl) DeleteCity(ctx context.Context, id string, cityId string) error {
+func (cityRepository *cityRepositoryImpl) DeleteCity(ctx context.Context, id string) error {
 	objectId, _ := primitive.ObjectIDFromHex(id)
 	filter := bson.M{
-		"_id":    objectId,
-		"cityId": cityId,
+		"_id": objectId,
 	}
 
-	result, err := cityRepository.Connection.Collection("cities").DeleteOne(ctx, filter)
+	deleted, err := deleteLinked(ctx, mongoCollection(cityRepository.Connection, "cities"), filter)
 	if err != nil {
 		return err
 	}
 
-	if result.DeletedCount == 0 {
-		return exception.NotFoundRequestException(fmt.Sprintf("City not found with id: %s and cityId: %s", id, cityId))
+	if !deleted {
+		return exception.ResourceNotFoundException("City", "id", id)
 	}
 
 	return nil
 }
+
+func (cityRepository *cityRepositoryImpl) RestoreCity(ctx context.Context, id string) (*model.City, error) {
+	restored, err := restoreLinked(ctx, mongoCollection(cityRepository.Connection, "cities"), id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, exception.ResourceNotFoundException("City", "id", id)
+	}
+
+	return cityRepository.GetCityById(ctx, id)
+}
+
+func (cityRepository *cityRepositoryImpl) GetCityTimeZone(ctx context.Context, id string) (*time.Location, error) {
+	return findCityTimeZone(ctx, mongoCollection(cityRepository.Connection, "cities"), id)
+}
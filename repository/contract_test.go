@@ -0,0 +1,15 @@
+package repository_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/cbuelvasc/cinema-backend/repository/repositorytest"
+)
+
+// TestRepositories runs the repository contract on the memory repositories,
+// and on the MongoDB ones when MONGODB_TEST_URL points to a replica set.
+func TestRepositories(t *testing.T) {
+	t.Run("memory", func(t *testing.T) { repositorytest.Run(t, repositorytest.Memory) })
+	t.Run("mongo", func(t *testing.T) { repositorytest.Run(t, repositorytest.Mongo(os.Getenv("MONGODB_TEST_URL"))) })
+}
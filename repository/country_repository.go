@@ -2,7 +2,6 @@ package repository
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/cbuelvasc/cinema-backend/exception"
 	"github.com/cbuelvasc/cinema-backend/model"
@@ -14,10 +13,16 @@ import (
 
 type CountryRepository interface {
 	GetAllCountries(ctx context.Context, page int64, limit int64) (*model.PagedCountry, error)
+	GetAllCountriesByCursor(ctx context.Context, query *model.CursorQuery) (*model.PagedCountry, error)
+	GetAllCountryDocuments(ctx context.Context, query *model.DocumentQuery) (*model.PagedDocument, error)
 	GetCountryById(ctx context.Context, id string) (*model.Country, error)
+	GetCountryDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error)
+	GetCountryByName(ctx context.Context, name string) (*model.Country, error)
 	SaveCountry(ctx context.Context, country *model.Country) (*model.Country, error)
 	UpdateCountry(ctx context.Context, id string, country *model.Country) (*model.Country, error)
+	PatchCountry(ctx context.Context, id string, country *model.Country) (*model.Country, error)
 	DeleteCountry(ctx context.Context, id string) error
+	RestoreCountry(ctx context.Context, id string) (*model.Country, error)
 }
 
 type countryRepositoryImpl struct {
@@ -28,42 +33,79 @@ func NewCountryRepository(Connection *mongo.Database) CountryRepository {
 	return &countryRepositoryImpl{Connection: Connection}
 }
 
+var countryProjection = bson.D{
+	{"id", 1},
+	{"name", 1},
+	{"timeZone", 1},
+	{"states", 1},
+	{"created_at", 1},
+	{"updated_at", 1},
+	{"version", 1},
+	{"deleted_at", 1},
+	{"deleted_by", 1},
+}
+
 func (countryRepository *countryRepositoryImpl) GetAllCountries(ctx context.Context, page int64, limit int64) (*model.PagedCountry, error) {
 	var countries []model.Country
 
-	filter := bson.M{}
+	filter := notDeleted(ctx, bson.M{})
 
 	collection := countryRepository.Connection.Collection("countries")
 
-	projection := bson.D{
-		{"id", 1},
-		{"name", 1},
-		{"states", 1},
-		{"created_at", 1},
-		{"updated_at", 1},
-	}
-
-	paginatedData, err := paginate.New(collection).Context(ctx).Limit(limit).Page(page).Select(projection).Filter(filter).Decode(&countries).Find()
+	paginatedData, err := paginate.New(collection).Context(ctx).Limit(limit).Page(page).Select(countryProjection).Filter(filter).Decode(&countries).Find()
 	if err != nil {
 		return nil, err
 	}
 
 	if countries == nil {
-		return nil, exception.NotFoundRequestException("Countries not found")
+		return nil, exception.ResourcesNotFoundException("Countries")
 	}
 
 	return &model.PagedCountry{
 		Data:     countries,
-		PageInfo: paginatedData.Pagination,
+		PageInfo: &paginatedData.Pagination,
+	}, nil
+}
+
+func (countryRepository *countryRepositoryImpl) GetAllCountriesByCursor(ctx context.Context, query *model.CursorQuery) (*model.PagedCountry, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	collection := countryRepository.Connection.Collection("countries")
+
+	documents, cursorInfo, err := findByCursor(ctx, collection, filter, countryProjection, query, nil, "name", "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	countries := make([]model.Country, 0, len(documents))
+	for _, document := range documents {
+		var country model.Country
+		if err := bson.Unmarshal(document, &country); err != nil {
+			return nil, err
+		}
+		countries = append(countries, country)
+	}
+
+	return &model.PagedCountry{
+		Data:   countries,
+		Cursor: cursorInfo,
 	}, nil
 }
 
+func (countryRepository *countryRepositoryImpl) GetAllCountryDocuments(ctx context.Context, query *model.DocumentQuery) (*model.PagedDocument, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	collection := countryRepository.Connection.Collection("countries")
+
+	return findDocuments(ctx, collection, filter, query, "name", "created_at")
+}
+
 func (countryRepository *countryRepositoryImpl) GetCountryById(ctx context.Context, id string) (*model.Country, error) {
 	var country model.Country
 	objectId, _ := primitive.ObjectIDFromHex(id)
-	filter := bson.M{
+	filter := notDeleted(ctx, bson.M{
 		"_id": objectId,
-	}
+	})
 
 	err := countryRepository.Connection.Collection("countries").FindOne(ctx, filter).Decode(&country)
 	if err != nil {
@@ -72,8 +114,37 @@ func (countryRepository *countryRepositoryImpl) GetCountryById(ctx context.Conte
 	return &country, nil
 }
 
+func (countryRepository *countryRepositoryImpl) GetCountryDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{
+		"_id": objectId,
+	})
+
+	document, err := findDocument(ctx, countryRepository.Connection.Collection("countries"), filter, query)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, exception.ResourceNotFoundException("Country", "id", id)
+	}
+	return document, nil
+}
+
+func (countryRepository *countryRepositoryImpl) GetCountryByName(ctx context.Context, name string) (*model.Country, error) {
+	var country model.Country
+	filter := notDeleted(ctx, bson.M{"name": name})
+
+	err := countryRepository.Connection.Collection("countries").FindOne(ctx, filter).Decode(&country)
+	if err != nil {
+		return nil, exception.ResourceNotFoundException("Country", "name", name)
+	}
+	return &country, nil
+}
+
 func (countryRepository *countryRepositoryImpl) SaveCountry(ctx context.Context, country *model.Country) (*model.Country, error) {
 	country.ID = primitive.NewObjectID()
+	country.Version = 1
+	country.States = []string{}
 
 	_, err := countryRepository.Connection.Collection("countries").InsertOne(ctx, country)
 	if err != nil {
@@ -83,15 +154,69 @@ func (countryRepository *countryRepositoryImpl) SaveCountry(ctx context.Context,
 	return country, nil
 }
 
+// countryUpdateFields returns the fields an update writes: only the ones set in
+// country.
+func countryUpdateFields(country *model.Country) bson.M {
+	fields := bson.M{}
+	if len(country.Name) > 0 {
+		fields["name"] = country.Name
+	}
+	if len(country.TimeZone) > 0 {
+		fields["timeZone"] = country.TimeZone
+	}
+	if !country.UpdatedAt.IsZero() {
+		fields["updated_at"] = country.UpdatedAt
+	}
+	return fields
+}
+
 func (countryRepository *countryRepositoryImpl) UpdateCountry(ctx context.Context, id string, country *model.Country) (*model.Country, error) {
-	country.ID = primitive.NewObjectID()
+	objectId, _ := primitive.ObjectIDFromHex(id)
 
-	_, err := countryRepository.Connection.Collection("countries").InsertOne(ctx, country)
+	registry := countryUpdateFields(country)
+
+	filter := notDeleted(ctx, bson.M{"_id": bson.M{"$eq": objectId}})
+
+	var updated model.Country
+	found, err := updateDocument(ctx, mongoCollection(countryRepository.Connection, "countries"), filter, registry, &updated)
 	if err != nil {
 		return nil, err
 	}
+	if !found {
+		return nil, exception.ResourceNotFoundException("Country", "id", id)
+	}
 
-	return country, nil
+	return &updated, nil
+}
+
+// countryPatchFields returns the fields a patch writes: every patchable field of
+// country, so fields cleared by the patch are cleared in the stored document too.
+func countryPatchFields(country *model.Country) bson.M {
+	fields := bson.M{
+		"name":       country.Name,
+		"timeZone":   country.TimeZone,
+		"updated_at": country.UpdatedAt,
+	}
+	return fields
+}
+
+func (countryRepository *countryRepositoryImpl) PatchCountry(ctx context.Context, id string, country *model.Country) (*model.Country, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+
+	registry := countryPatchFields(country)
+
+	filter := notDeleted(ctx, bson.M{"_id": bson.M{"$eq": objectId}})
+
+	var updated model.Country
+	found, err := updateDocument(ctx, mongoCollection(countryRepository.Connection, "countries"), filter, registry, &updated)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, exception.ResourceNotFoundException("Country", "id", id)
+	}
+
+	return &updated, nil
 }
 
 func (countryRepository *countryRepositoryImpl) DeleteCountry(ctx context.Context, id string) error {
@@ -100,14 +225,26 @@ func (countryRepository *countryRepositoryImpl) DeleteCountry(ctx context.Contex
 		"_id": objectId,
 	}
 
-	result, err := countryRepository.Connection.Collection("countries").DeleteOne(ctx, filter)
+	deleted, err := deleteLinked(ctx, mongoCollection(countryRepository.Connection, "countries"), filter)
 	if err != nil {
 		return err
 	}
 
-	if result.DeletedCount == 0 {
-		return exception.NotFoundRequestException(fmt.Sprintf("Country not found with id: %s", id))
+	if !deleted {
+		return exception.ResourceNotFoundException("Country", "id", id)
 	}
 
 	return nil
 }
+
+func (countryRepository *countryRepositoryImpl) RestoreCountry(ctx context.Context, id string) (*model.Country, error) {
+	restored, err := restoreDocument(ctx, mongoCollection(countryRepository.Connection, "countries"), id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, exception.ResourceNotFoundException("Country", "id", id)
+	}
+
+	return countryRepository.GetCountryById(ctx, id)
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultCursorLimit int64 = 10
+	maxCursorLimit     int64 = 100
+)
+
+// cursorToken is the decoded form of the opaque after/before tokens: the
+// value of the sort key and the _id of the boundary document.
+type cursorToken struct {
+	Value interface{}        `bson:"v"`
+	ID    primitive.ObjectID `bson:"id"`
+}
+
+// errCursorId is returned for documents whose _id is not an ObjectID, which
+// cursors cannot point at.
+var errCursorId = errors.New("cursor: _id is not an ObjectID")
+
+func encodeCursor(raw bson.Raw, field string) (string, error) {
+	id, ok := raw.Lookup("_id").ObjectIDOK()
+	if !ok {
+		return "", errCursorId
+	}
+	token := cursorToken{ID: id}
+	if field != "_id" {
+		if value, err := raw.LookupErr(field); err == nil {
+			token.Value = value
+		}
+	}
+
+	data, err := bson.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(cursor string) (*cursorToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, exception.InvalidParameterException("cursor", cursor)
+	}
+
+	var token cursorToken
+	if err := bson.Unmarshal(data, &token); err != nil {
+		return nil, exception.InvalidParameterException("cursor", cursor)
+	}
+	return &token, nil
+}
+
+func cursorSortField(query *model.CursorQuery, allowed ...string) (string, int, error) {
+	if len(query.Sort) == 0 {
+		return "_id", 1, nil
+	}
+
+	field, direction := query.Sort, 1
+	if strings.HasPrefix(field, "-") {
+		field, direction = field[1:], -1
+	}
+	if field == "_id" || field == "id" {
+		return "_id", direction, nil
+	}
+	for _, name := range allowed {
+		if name == field {
+			return field, direction, nil
+		}
+	}
+	return "", 0, exception.InvalidParameterException("sort", field)
+}
+
+// cursorPlan holds what a cursor query resolves to: the filter of the page,
+// its order and how many documents to read.
+type cursorPlan struct {
+	Filter    bson.M
+	Sort      bson.D
+	Field     string
+	Limit     int64
+	Backwards bool
+}
+
+func newCursorPlan(filter bson.M, query *model.CursorQuery, sortFields ...string) (*cursorPlan, error) {
+	field, direction, err := cursorSortField(query, sortFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &cursorPlan{Filter: filter, Field: field, Limit: query.Limit, Backwards: len(query.Before) > 0}
+	if plan.Limit <= 0 {
+		plan.Limit = defaultCursorLimit
+	}
+	if plan.Limit > maxCursorLimit {
+		plan.Limit = maxCursorLimit
+	}
+
+	cursor := query.After
+	if plan.Backwards {
+		cursor = query.Before
+	}
+
+	if len(cursor) > 0 {
+		token, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		operator := "$gt"
+		if (direction < 0) != plan.Backwards {
+			operator = "$lt"
+		}
+
+		var boundary bson.M
+		if field == "_id" {
+			boundary = bson.M{"_id": bson.M{operator: token.ID}}
+		} else {
+			boundary = bson.M{"$or": bson.A{
+				bson.M{field: bson.M{operator: token.Value}},
+				bson.M{field: token.Value, "_id": bson.M{operator: token.ID}},
+			}}
+		}
+		plan.Filter = bson.M{"$and": bson.A{filter, boundary}}
+	}
+
+	sortDirection := direction
+	if plan.Backwards {
+		sortDirection = -direction
+	}
+	plan.Sort = bson.D{{Key: field, Value: sortDirection}}
+	if field != "_id" {
+		plan.Sort = append(plan.Sort, bson.E{Key: "_id", Value: sortDirection})
+	}
+	return plan, nil
+}
+
+// projection adds the sort key to inclusion projections, as the cursors of
+// the page are built from it.
+func (plan *cursorPlan) projection(projection bson.D) bson.D {
+	if plan.Field != "_id" && len(projection) > 0 && isInclusion(projection) {
+		return append(append(bson.D{}, projection...), bson.E{Key: plan.Field, Value: 1})
+	}
+	return projection
+}
+
+// page turns the documents read for plan, at most Limit+1 of them, into the
+// page and its cursors. Total is left to the caller.
+func (plan *cursorPlan) page(documents []bson.Raw, query *model.CursorQuery) ([]bson.Raw, *model.CursorInfo, error) {
+	hasMore := int64(len(documents)) > plan.Limit
+	if hasMore {
+		documents = documents[:plan.Limit]
+	}
+	if plan.Backwards {
+		for i, j := 0, len(documents)-1; i < j; i, j = i+1, j-1 {
+			documents[i], documents[j] = documents[j], documents[i]
+		}
+	}
+
+	info := &model.CursorInfo{Limit: plan.Limit}
+	if plan.Backwards {
+		info.HasPrev = hasMore
+		info.HasNext = true
+	} else {
+		info.HasNext = hasMore
+		info.HasPrev = len(query.After) > 0
+	}
+
+	var err error
+	if len(documents) > 0 {
+		if info.HasNext {
+			if info.Next, err = encodeCursor(documents[len(documents)-1], plan.Field); err != nil {
+				return nil, nil, err
+			}
+		}
+		if info.HasPrev {
+			if info.Prev, err = encodeCursor(documents[0], plan.Field); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return documents, info, nil
+}
+
+// findByCursor returns the page of documents matching filter that sits right
+// after (or before) the cursor in query, ordered on the sort key and _id.
+// The lookups stages, if any, only run on the documents of the page.
+func findByCursor(ctx context.Context, collection *mongo.Collection, filter bson.M, projection bson.D, query *model.CursorQuery, lookups bson.A, sortFields ...string) ([]bson.Raw, *model.CursorInfo, error) {
+	plan, err := newCursorPlan(filter, query, sortFields...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": plan.Filter},
+		bson.M{"$sort": plan.Sort},
+		bson.M{"$limit": plan.Limit + 1},
+	}
+	pipeline = append(pipeline, lookups...)
+	if projection = plan.projection(projection); len(projection) > 0 {
+		pipeline = append(pipeline, bson.M{"$project": projection})
+	}
+
+	result, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer result.Close(ctx)
+
+	documents := make([]bson.Raw, 0, plan.Limit+1)
+	for result.Next(ctx) {
+		documents = append(documents, append(bson.Raw(nil), result.Current...))
+	}
+	if err := result.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	documents, info, err := plan.page(documents, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !query.SkipCount {
+		total, err := collection.CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, nil, err
+		}
+		info.Total = &total
+	}
+
+	return documents, info, nil
+}
+
+func isInclusion(projection bson.D) bool {
+	for _, element := range projection {
+		if value, ok := element.Value.(int); ok && value == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	paginate "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// relation describes how a resource references another one. References are
+// stored as hex strings while _id is an ObjectID, so both sides are compared
+// as strings.
+type relation struct {
+	Collection   string
+	LocalField   string
+	ForeignField string
+	Many         bool
+}
+
+// relations lists, per collection, the resources that can be embedded through
+// the expand parameter.
+var relations = map[string]map[string]relation{
+	"countries": {
+		"states": {Collection: "states", LocalField: "_id", ForeignField: "countryId", Many: true},
+	},
+	"states": {
+		"country": {Collection: "countries", LocalField: "countryId", ForeignField: "_id"},
+		"cities":  {Collection: "cities", LocalField: "_id", ForeignField: "stateId", Many: true},
+	},
+	"cities": {
+		"state":   {Collection: "states", LocalField: "stateId", ForeignField: "_id"},
+		"cinemas": {Collection: "cinemas", LocalField: "_id", ForeignField: "cityId", Many: true},
+	},
+	"cinemas": {
+		"city":  {Collection: "cities", LocalField: "cityId", ForeignField: "_id"},
+		"rooms": {Collection: "rooms", LocalField: "_id", ForeignField: "cinemaId", Many: true},
+	},
+	"rooms": {
+		"cinema": {Collection: "cinemas", LocalField: "cinemaId", ForeignField: "_id"},
+	},
+	"tweets": {
+		"user": {Collection: "users", LocalField: "userId", ForeignField: "_id"},
+	},
+	"users":  {},
+	"movies": {},
+}
+
+// hiddenFields are never returned, whatever the requested fields are.
+var hiddenFields = map[string][]string{
+	"users": {"password", "calendarToken"},
+}
+
+var fieldNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*(\.[A-Za-z][A-Za-z0-9_]*)*$`)
+
+// documentProjection keeps the requested fields of collection, and the
+// requested expansions whole unless some of their fields are requested.
+func documentProjection(collection string, fields []string, expand []string) (bson.D, error) {
+	if len(fields) == 0 {
+		projection := bson.D{}
+		for _, field := range hiddenFields[collection] {
+			projection = append(projection, bson.E{Key: field, Value: 0})
+		}
+		return projection, nil
+	}
+
+	projection := bson.D{{Key: "_id", Value: 1}}
+	for _, field := range fields {
+		if field == "id" {
+			continue
+		}
+		if !fieldNamePattern.MatchString(field) {
+			return nil, exception.InvalidParameterException("fields", field)
+		}
+		if isHidden(collection, field) {
+			continue
+		}
+		projection = append(projection, bson.E{Key: field, Value: 1})
+	}
+	for _, name := range expand {
+		if !isProjected(projection, name) {
+			projection = append(projection, bson.E{Key: name, Value: 1})
+		}
+	}
+	return projection, nil
+}
+
+// isProjected reports whether projection keeps name or any field of it.
+func isProjected(projection bson.D, name string) bool {
+	for _, element := range projection {
+		if element.Key == name || strings.HasPrefix(element.Key, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func isHidden(collection string, field string) bool {
+	for _, hidden := range hiddenFields[collection] {
+		if hidden == field {
+			return true
+		}
+	}
+	return false
+}
+
+func documentLookups(ctx context.Context, collection string, expand []string) (bson.A, error) {
+	lookups := bson.A{}
+	for _, name := range expand {
+		relation, ok := relations[collection][name]
+		if !ok {
+			return nil, exception.InvalidParameterException("expand", name)
+		}
+
+		match := notDeleted(ctx, bson.M{"$expr": bson.M{"$eq": bson.A{bson.M{"$toString": "$" + relation.ForeignField}, "$$ref"}}})
+		pipeline := bson.A{
+			bson.M{"$match": match},
+		}
+		if hidden, _ := documentProjection(relation.Collection, nil, nil); len(hidden) > 0 {
+			pipeline = append(pipeline, bson.M{"$project": hidden})
+		}
+
+		lookups = append(lookups, bson.M{"$lookup": bson.M{
+			"from":     relation.Collection,
+			"let":      bson.M{"ref": bson.M{"$toString": "$" + relation.LocalField}},
+			"pipeline": pipeline,
+			"as":       name,
+		}})
+		if !relation.Many {
+			lookups = append(lookups, bson.M{"$unwind": bson.M{"path": "$" + name, "preserveNullAndEmptyArrays": true}})
+		}
+	}
+	return lookups, nil
+}
+
+// findDocument returns the first document matching filter, trimmed and
+// expanded as requested, or nil when there is none.
+func findDocument(ctx context.Context, collection *mongo.Collection, filter bson.M, query *model.DocumentQuery) (model.Document, error) {
+	projection, err := documentProjection(collection.Name(), query.Fields, query.Expand)
+	if err != nil {
+		return nil, err
+	}
+	lookups, err := documentLookups(ctx, collection.Name(), query.Expand)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": filter},
+		bson.M{"$limit": 1},
+	}
+	pipeline = append(pipeline, lookups...)
+	if len(projection) > 0 {
+		pipeline = append(pipeline, bson.M{"$project": projection})
+	}
+
+	result, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close(ctx)
+
+	if !result.Next(ctx) {
+		return nil, result.Err()
+	}
+	return toDocument(result.Current)
+}
+
+// findDocuments lists the documents matching filter, using cursor pagination
+// when query carries a cursor and page/limit pagination otherwise.
+func findDocuments(ctx context.Context, collection *mongo.Collection, filter bson.M, query *model.DocumentQuery, sortFields ...string) (*model.PagedDocument, error) {
+	projection, err := documentProjection(collection.Name(), query.Fields, query.Expand)
+	if err != nil {
+		return nil, err
+	}
+	lookups, err := documentLookups(ctx, collection.Name(), query.Expand)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Cursor != nil {
+		documents, cursorInfo, err := findByCursor(ctx, collection, filter, projection, query.Cursor, lookups, sortFields...)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := toDocuments(documents)
+		if err != nil {
+			return nil, err
+		}
+		return &model.PagedDocument{Data: data, Cursor: cursorInfo}, nil
+	}
+
+	page, limit := query.Page, query.Limit
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": filter},
+		bson.M{"$sort": bson.M{"_id": 1}},
+		bson.M{"$skip": (page - 1) * limit},
+		bson.M{"$limit": limit},
+	}
+	pipeline = append(pipeline, lookups...)
+	if len(projection) > 0 {
+		pipeline = append(pipeline, bson.M{"$project": projection})
+	}
+
+	result, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close(ctx)
+
+	var documents []bson.Raw
+	if err := result.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	data, err := toDocuments(documents)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PagedDocument{Data: data, PageInfo: paginationData(total, page, limit)}, nil
+}
+
+// paginationData mirrors the page information computed by mongo-go-pagination.
+func paginationData(total int64, page int64, limit int64) *paginate.PaginationData {
+	paginator := paginate.Paginator{
+		TotalRecord: total,
+		TotalPage:   int64(math.Ceil(float64(total) / float64(limit))),
+		Offset:      (page - 1) * limit,
+		Limit:       limit,
+		Page:        page,
+		PrevPage:    page,
+		NextPage:    page + 1,
+	}
+	if page > 1 {
+		paginator.PrevPage = page - 1
+	}
+	if page == paginator.TotalPage {
+		paginator.NextPage = page
+	}
+	return paginator.PaginationData()
+}
+
+func toDocuments(documents []bson.Raw) ([]model.Document, error) {
+	data := make([]model.Document, 0, len(documents))
+	for _, raw := range documents {
+		document, err := toDocument(raw)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, document)
+	}
+	return data, nil
+}
+
+func toDocument(raw bson.Raw) (model.Document, error) {
+	var m bson.M
+	if err := bson.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return toDocumentValue(m).(model.Document), nil
+}
+
+// toDocumentValue converts decoded BSON into plain values, renaming _id to id
+// so documents read like the typed models.
+func toDocumentValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.M:
+		document := model.Document{}
+		for key, item := range v {
+			if key == "_id" {
+				key = "id"
+			}
+			document[key] = toDocumentValue(item)
+		}
+		return document
+	case bson.D:
+		return toDocumentValue(v.Map())
+	case bson.A:
+		items := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			items = append(items, toDocumentValue(item))
+		}
+		return items
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.DateTime:
+		return v.Time().UTC()
+	default:
+		return v
+	}
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// documentStore is what the write helpers (versions, soft delete, links
+// between collections) need from a collection, so they behave the same on
+// MongoDB and on a MemoryStore.
+type documentStore interface {
+	Name() string
+	Collection(name string) documentStore
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+	FindOne(ctx context.Context, filter bson.M, projection bson.M, result interface{}) (bool, error)
+	Find(ctx context.Context, filter bson.M, results interface{}) error
+	FindIds(ctx context.Context, filter bson.M) ([]primitive.ObjectID, error)
+	Count(ctx context.Context, filter bson.M) (int64, error)
+	InsertOne(ctx context.Context, document interface{}) error
+	UpdateOne(ctx context.Context, filter bson.M, update bson.M) (int64, error)
+	UpdateMany(ctx context.Context, filter bson.M, update bson.M) (int64, error)
+	FindOneAndUpdate(ctx context.Context, filter bson.M, update bson.M, result interface{}) (bool, error)
+	DeleteOne(ctx context.Context, filter bson.M) (int64, error)
+}
+
+type mongoStore struct {
+	collection *mongo.Collection
+}
+
+func mongoCollection(database *mongo.Database, name string) documentStore {
+	return &mongoStore{collection: database.Collection(name)}
+}
+
+func (store *mongoStore) Name() string {
+	return store.collection.Name()
+}
+
+func (store *mongoStore) Collection(name string) documentStore {
+	return mongoCollection(store.collection.Database(), name)
+}
+
+func (store *mongoStore) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTransaction(ctx, store.collection.Database(), fn)
+}
+
+func (store *mongoStore) FindOne(ctx context.Context, filter bson.M, projection bson.M, result interface{}) (bool, error) {
+	findOptions := options.FindOne()
+	if projection != nil {
+		findOptions.SetProjection(projection)
+	}
+
+	err := store.collection.FindOne(ctx, filter, findOptions).Decode(result)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (store *mongoStore) Find(ctx context.Context, filter bson.M, results interface{}) error {
+	cursor, err := store.collection.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return cursor.All(ctx, results)
+}
+
+func (store *mongoStore) FindIds(ctx context.Context, filter bson.M) ([]primitive.ObjectID, error) {
+	cursor, err := store.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(documents))
+	for _, document := range documents {
+		ids = append(ids, document.ID)
+	}
+	return ids, nil
+}
+
+func (store *mongoStore) Count(ctx context.Context, filter bson.M) (int64, error) {
+	return store.collection.CountDocuments(ctx, filter)
+}
+
+func (store *mongoStore) InsertOne(ctx context.Context, document interface{}) error {
+	_, err := store.collection.InsertOne(ctx, document)
+	return err
+}
+
+func (store *mongoStore) UpdateOne(ctx context.Context, filter bson.M, update bson.M) (int64, error) {
+	result, err := store.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.MatchedCount, nil
+}
+
+func (store *mongoStore) UpdateMany(ctx context.Context, filter bson.M, update bson.M) (int64, error) {
+	result, err := store.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.MatchedCount, nil
+}
+
+func (store *mongoStore) FindOneAndUpdate(ctx context.Context, filter bson.M, update bson.M, result interface{}) (bool, error) {
+	updateOptions := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := store.collection.FindOneAndUpdate(ctx, filter, update, updateOptions).Decode(result)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (store *mongoStore) DeleteOne(ctx context.Context, filter bson.M) (int64, error) {
+	result, err := store.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
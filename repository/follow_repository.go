@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// FollowRepository stores who follows whom, and keeps the followers and
+// following counts of users in step.
+type FollowRepository interface {
+	Follow(ctx context.Context, followerId string, followingId string) (*model.Follow, error)
+	Unfollow(ctx context.Context, followerId string, followingId string) error
+	GetFollowers(ctx context.Context, userId string, query *model.CursorQuery) (*model.PagedUser, error)
+	GetFollowing(ctx context.Context, userId string, query *model.CursorQuery) (*model.PagedUser, error)
+}
+
+type followRepositoryImpl struct {
+	Connection *mongo.Database
+}
+
+func NewFollowRepository(Connection *mongo.Database) FollowRepository {
+	return &followRepositoryImpl{Connection: Connection}
+}
+
+func (followRepository *followRepositoryImpl) Follow(ctx context.Context, followerId string, followingId string) (*model.Follow, error) {
+	return insertFollow(ctx, mongoCollection(followRepository.Connection, "follows"), followerId, followingId)
+}
+
+func (followRepository *followRepositoryImpl) Unfollow(ctx context.Context, followerId string, followingId string) error {
+	return deleteFollow(ctx, mongoCollection(followRepository.Connection, "follows"), followerId, followingId)
+}
+
+func (followRepository *followRepositoryImpl) GetFollowers(ctx context.Context, userId string, query *model.CursorQuery) (*model.PagedUser, error) {
+	collection := followRepository.Connection.Collection("follows")
+
+	documents, cursorInfo, err := findByCursor(ctx, collection, bson.M{"followingId": userId}, nil, query, nil, "created_at")
+	if err != nil {
+		return nil, err
+	}
+	return followUsers(ctx, mongoCollection(followRepository.Connection, "follows"), documents, cursorInfo, "followerId")
+}
+
+func (followRepository *followRepositoryImpl) GetFollowing(ctx context.Context, userId string, query *model.CursorQuery) (*model.PagedUser, error) {
+	collection := followRepository.Connection.Collection("follows")
+
+	documents, cursorInfo, err := findByCursor(ctx, collection, bson.M{"followerId": userId}, nil, query, nil, "created_at")
+	if err != nil {
+		return nil, err
+	}
+	return followUsers(ctx, mongoCollection(followRepository.Connection, "follows"), documents, cursorInfo, "followingId")
+}
+
+// insertFollow makes followerId follow followingId and counts the new
+// follower and followed user. Following someone twice is a conflict. Like
+// votes on reviews, the counts keep the version of the users.
+func insertFollow(ctx context.Context, follows documentStore, followerId string, followingId string) (*model.Follow, error) {
+	follow := &model.Follow{
+		ID:          primitive.NewObjectID(),
+		FollowerId:  followerId,
+		FollowingId: followingId,
+		CreatedAt:   time.Now(),
+	}
+
+	err := follows.Transaction(ctx, func(ctx context.Context) error {
+		count, err := follows.Count(ctx, bson.M{"followerId": followerId, "followingId": followingId})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return exception.ConflictException("Follow", "userId", followingId)
+		}
+
+		err = follows.InsertOne(ctx, follow)
+		if mongo.IsDuplicateKeyError(err) {
+			return exception.ConflictException("Follow", "userId", followingId)
+		}
+		if err != nil {
+			return err
+		}
+		return countFollow(ctx, follows, followerId, followingId, 1)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return follow, nil
+}
+
+// deleteFollow makes followerId stop following followingId and updates the
+// counts of both users.
+func deleteFollow(ctx context.Context, follows documentStore, followerId string, followingId string) error {
+	return follows.Transaction(ctx, func(ctx context.Context) error {
+		deleted, err := follows.DeleteOne(ctx, bson.M{"followerId": followerId, "followingId": followingId})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return exception.ResourceNotFoundByFieldsException("Follow", "followerId", followerId, "followingId", followingId)
+		}
+		return countFollow(ctx, follows, followerId, followingId, -1)
+	})
+}
+
+func countFollow(ctx context.Context, follows documentStore, followerId string, followingId string, delta int) error {
+	users := follows.Collection("users")
+
+	followerObjectId, _ := primitive.ObjectIDFromHex(followerId)
+	if _, err := users.UpdateOne(ctx, bson.M{"_id": followerObjectId}, bson.M{"$inc": bson.M{"following": delta}}); err != nil {
+		return err
+	}
+	followingObjectId, _ := primitive.ObjectIDFromHex(followingId)
+	_, err := users.UpdateOne(ctx, bson.M{"_id": followingObjectId}, bson.M{"$inc": bson.M{"followers": delta}})
+	return err
+}
+
+// followUsers turns a page of follows into the users at field of each, in
+// the order of the page. Deleted users are left out.
+func followUsers(ctx context.Context, follows documentStore, documents []bson.Raw, cursorInfo *model.CursorInfo, field string) (*model.PagedUser, error) {
+	ids := bson.A{}
+	order := make([]string, 0, len(documents))
+	for _, document := range documents {
+		id, _ := document.Lookup(field).StringValueOK()
+		objectId, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, objectId)
+		order = append(order, id)
+	}
+
+	var found []model.User
+	if len(ids) > 0 {
+		if err := follows.Collection("users").Find(ctx, notDeleted(ctx, bson.M{"_id": bson.M{"$in": ids}}), &found); err != nil {
+			return nil, err
+		}
+	}
+	usersById := map[string]model.User{}
+	for _, user := range found {
+		user.Password = ""
+		usersById[user.ID.Hex()] = user
+	}
+
+	users := make([]model.User, 0, len(order))
+	for _, id := range order {
+		if user, ok := usersById[id]; ok {
+			users = append(users, user)
+		}
+	}
+	return &model.PagedUser{
+		Data:   users,
+		Cursor: cursorInfo,
+	}, nil
+}
+
+// followingIds returns the ids of the users userId follows. It loads the
+// whole follow list, which the timeline then matches with an unbounded $in;
+// that is acceptable while users follow a modest number of accounts.
+func followingIds(ctx context.Context, follows documentStore, userId string) (bson.A, error) {
+	var following []model.Follow
+	if err := follows.Find(ctx, bson.M{"followerId": userId}, &following); err != nil {
+		return nil, err
+	}
+
+	ids := bson.A{}
+	for _, follow := range following {
+		ids = append(ids, follow.FollowingId)
+	}
+	return ids, nil
+}
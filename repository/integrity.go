@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cbuelvasc/cinema-backend/config"
+	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// link is a parent/child relation of the geography hierarchy: children hold
+// the id of their parent and parents list the ids of their children.
+type link struct {
+	Parent        string
+	ParentName    string
+	Child         string
+	ChildName     string
+	ParentField   string
+	ChildrenField string
+	DeletePolicy  *string
+}
+
+var (
+	countryStates = link{Parent: "countries", ParentName: "Country", Child: "states", ChildName: "states", ParentField: "countryId", ChildrenField: "states", DeletePolicy: &config.CountryDeletePolicy}
+	stateCities   = link{Parent: "states", ParentName: "State", Child: "cities", ChildName: "cities", ParentField: "stateId", ChildrenField: "cities", DeletePolicy: &config.StateDeletePolicy}
+	cityCinemas   = link{Parent: "cities", ParentName: "City", Child: "cinemas", ChildName: "cinemas", ParentField: "cityId", ChildrenField: "cinemas", DeletePolicy: &config.CityDeletePolicy}
+	cinemaRooms   = link{Parent: "cinemas", ParentName: "Cinema", Child: "rooms", ChildName: "rooms", ParentField: "cinemaId", ChildrenField: "rooms", DeletePolicy: &config.CinemaDeletePolicy}
+)
+
+// childLinks lists, per collection, the relations to its children.
+var childLinks = map[string][]link{
+	"countries": {countryStates},
+	"states":    {stateCities},
+	"cities":    {cityCinemas},
+	"cinemas":   {cinemaRooms},
+}
+
+// parentLinks gives, per collection, the relation to its parent.
+var parentLinks = map[string]link{
+	"states":  countryStates,
+	"cities":  stateCities,
+	"cinemas": cityCinemas,
+	"rooms":   cinemaRooms,
+}
+
+// deletePolicy returns the configured policy of l. Unknown values restrict,
+// so a typo in the configuration never deletes data.
+func (l link) deletePolicy() string {
+	switch *l.DeletePolicy {
+	case enums.DeletePolicyCascade, enums.DeletePolicyNullify:
+		return *l.DeletePolicy
+	default:
+		return enums.DeletePolicyRestrict
+	}
+}
+
+// insertLinked inserts document and adds it to the children of its parent.
+func insertLinked(ctx context.Context, collection documentStore, document interface{}, id primitive.ObjectID, parentId string) error {
+	return collection.Transaction(ctx, func(ctx context.Context) error {
+		if err := collection.InsertOne(ctx, document); err != nil {
+			return err
+		}
+		return attachChild(ctx, collection, id, parentId)
+	})
+}
+
+// updateLinked runs updateDocument and, when the update changes the parent
+// of the document, moves it to the children of the new parent.
+func updateLinked(ctx context.Context, collection documentStore, filter bson.M, fields bson.M, result interface{}) (bool, error) {
+	l, ok := parentLinks[collection.Name()]
+	newParentId, changesParent := fields[l.ParentField].(string)
+	if !ok || !changesParent {
+		return updateDocument(ctx, collection, filter, fields, result)
+	}
+
+	found := false
+	err := collection.Transaction(ctx, func(ctx context.Context) error {
+		before, err := findLinked(ctx, collection, withExpectedVersion(ctx, filter), l.ParentField)
+		if err != nil || before == nil {
+			found = false
+			if err == nil {
+				err = checkVersion(ctx, collection, filter)
+			}
+			return err
+		}
+
+		if found, err = updateDocument(ctx, collection, filter, fields, result); err != nil || !found {
+			return err
+		}
+
+		oldParentId, _ := before[l.ParentField].(string)
+		if oldParentId == newParentId {
+			return nil
+		}
+		if err := detachChild(ctx, collection, before["_id"].(primitive.ObjectID), oldParentId); err != nil {
+			return err
+		}
+		return attachChild(ctx, collection, before["_id"].(primitive.ObjectID), newParentId)
+	})
+	return found, err
+}
+
+// deleteLinked applies the delete policy of every child relation of the
+// document matching filter, deletes it and removes it from its parent.
+func deleteLinked(ctx context.Context, collection documentStore, filter bson.M) (bool, error) {
+	l, hasParent := parentLinks[collection.Name()]
+
+	deleted := false
+	err := collection.Transaction(ctx, func(ctx context.Context) error {
+		document, err := findLinked(ctx, collection, withExpectedVersion(ctx, notDeleted(ctx, filter)), l.ParentField)
+		if err != nil || document == nil {
+			deleted = false
+			if err == nil {
+				err = checkVersion(ctx, collection, notDeleted(ctx, filter))
+			}
+			return err
+		}
+		id := document["_id"].(primitive.ObjectID)
+
+		if err := deleteChildren(ctx, collection, id); err != nil {
+			return err
+		}
+		if deleted, err = deleteDocument(ctx, collection, filter); err != nil || !deleted {
+			return err
+		}
+		if !hasParent {
+			return nil
+		}
+		parentId, _ := document[l.ParentField].(string)
+		return detachChild(ctx, collection, id, parentId)
+	})
+	return deleted, err
+}
+
+// restoreLinked restores a soft-deleted document and adds it back to the
+// children of its parent, which must not be deleted itself. The children its
+// deletion cascaded to are restored with it.
+func restoreLinked(ctx context.Context, collection documentStore, id string) (bool, error) {
+	restored := false
+	err := collection.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if restored, err = restoreDocument(ctx, collection, id); err != nil || !restored {
+			return err
+		}
+		objectId, _ := primitive.ObjectIDFromHex(id)
+		return restoreAttached(ctx, collection, objectId)
+	})
+	return restored, err
+}
+
+// restoreAttached adds the restored document id of collection back to the
+// children of its parent, and restores the children its deletion cascaded
+// to, all the way down.
+func restoreAttached(ctx context.Context, collection documentStore, id primitive.ObjectID) error {
+	if l, hasParent := parentLinks[collection.Name()]; hasParent {
+		document, err := findLinked(ctx, collection, bson.M{"_id": id}, l.ParentField)
+		if err != nil {
+			return err
+		}
+		parentId, _ := document[l.ParentField].(string)
+		if err := attachChild(ctx, collection, id, parentId); err != nil {
+			return err
+		}
+	}
+
+	childContext := util.WithoutExpectedVersion(ctx)
+	for _, l := range childLinks[collection.Name()] {
+		children := collection.Collection(l.Child)
+		ids, err := children.FindIds(ctx, bson.M{l.ParentField: id.Hex(), "deleted_with": id.Hex()})
+		if err != nil {
+			return err
+		}
+		for _, childId := range ids {
+			restored, err := restoreDocument(childContext, children, childId.Hex())
+			if err != nil {
+				return err
+			}
+			if !restored {
+				continue
+			}
+			if err := restoreAttached(childContext, children, childId); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deleteChildren applies the delete policy of each child relation of the
+// document id of collection.
+func deleteChildren(ctx context.Context, collection documentStore, id primitive.ObjectID) error {
+	// Children are side effects of the delete: If-Match only applies to the
+	// document the request targets.
+	childContext := util.WithoutExpectedVersion(ctx)
+
+	for _, l := range childLinks[collection.Name()] {
+		children := collection.Collection(l.Child)
+		filter := notDeleted(ctx, bson.M{l.ParentField: id.Hex()})
+
+		switch l.deletePolicy() {
+		case enums.DeletePolicyRestrict:
+			count, err := children.Count(ctx, filter)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return exception.ChildrenConflictException(l.ParentName, id.Hex(), count, l.ChildName)
+			}
+			continue
+		case enums.DeletePolicyCascade:
+			ids, err := children.FindIds(ctx, filter)
+			if err != nil {
+				return err
+			}
+			for _, childId := range ids {
+				if err := deleteChildren(childContext, children, childId); err != nil {
+					return err
+				}
+				if _, err := cascadeDocument(childContext, children, bson.M{"_id": childId}, id.Hex()); err != nil {
+					return err
+				}
+			}
+		case enums.DeletePolicyNullify:
+			update := bson.M{
+				"$set": bson.M{l.ParentField: ""},
+				"$inc": bson.M{"version": 1},
+			}
+			if _, err := children.UpdateMany(ctx, filter, update); err != nil {
+				return err
+			}
+		}
+
+		parent := bson.M{"_id": id}
+		update := bson.M{
+			"$set": bson.M{l.ChildrenField: []string{}},
+			"$inc": bson.M{"version": 1},
+		}
+		if _, err := collection.UpdateOne(ctx, parent, update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachChild adds the document id of collection to the children of its
+// parent. The parent must exist.
+func attachChild(ctx context.Context, collection documentStore, id primitive.ObjectID, parentId string) error {
+	l, ok := parentLinks[collection.Name()]
+	if !ok || len(parentId) == 0 {
+		return nil
+	}
+
+	parentObjectId, _ := primitive.ObjectIDFromHex(parentId)
+	filter := notDeleted(ctx, bson.M{"_id": parentObjectId})
+	update := bson.M{
+		"$addToSet": bson.M{l.ChildrenField: id.Hex()},
+		"$inc":      bson.M{"version": 1},
+	}
+
+	matched, err := collection.Collection(l.Parent).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		return exception.ResourceNotFoundException(l.ParentName, "id", parentId)
+	}
+	return nil
+}
+
+// detachChild removes the document id of collection from the children of
+// its parent.
+func detachChild(ctx context.Context, collection documentStore, id primitive.ObjectID, parentId string) error {
+	l, ok := parentLinks[collection.Name()]
+	if !ok || len(parentId) == 0 {
+		return nil
+	}
+
+	parentObjectId, _ := primitive.ObjectIDFromHex(parentId)
+	update := bson.M{
+		"$pull": bson.M{l.ChildrenField: id.Hex()},
+		"$inc":  bson.M{"version": 1},
+	}
+
+	_, err := collection.Collection(l.Parent).UpdateOne(ctx, bson.M{"_id": parentObjectId}, update)
+	return err
+}
+
+// findLinked returns the _id and parent reference of the document matching
+// filter, or nil when there is none.
+func findLinked(ctx context.Context, collection documentStore, filter bson.M, parentField string) (bson.M, error) {
+	projection := bson.M{"_id": 1}
+	if len(parentField) > 0 {
+		projection[parentField] = 1
+	}
+
+	var document bson.M
+	found, err := collection.FindOne(ctx, filter, projection, &document)
+	if err != nil || !found {
+		return nil, err
+	}
+	return document, nil
+}
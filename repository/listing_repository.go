@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ListingRepository builds the listings customers browse, joining showtimes,
+// rooms, cinemas and movies.
+type ListingRepository interface {
+	GetNowShowing(ctx context.Context, query *model.ShowingQuery) (*model.NowShowingList, error)
+	GetComingSoon(ctx context.Context, query *model.ComingSoonQuery) (*model.PagedMovie, error)
+}
+
+type listingRepositoryImpl struct {
+	Connection *mongo.Database
+}
+
+func NewListingRepository(Connection *mongo.Database) ListingRepository {
+	return &listingRepositoryImpl{Connection: Connection}
+}
+
+// showingMovie is a movie of the now showing listing with its showtimes in
+// the range of the query, joined with their rooms and cinemas.
+type showingMovie struct {
+	Movie     model.Movie       `bson:"movie"`
+	Showtimes []showingShowtime `bson:"showtimes"`
+}
+
+type showingShowtime struct {
+	ID       primitive.ObjectID `bson:"_id"`
+	Date     time.Time          `bson:"date"`
+	RoomId   string             `bson:"roomId"`
+	Room     string             `bson:"room"`
+	Format   string             `bson:"format"`
+	CinemaId string             `bson:"cinemaId"`
+	Cinema   string             `bson:"cinema"`
+	CityId   string             `bson:"cityId"`
+}
+
+type listingCount struct {
+	Count int64 `bson:"count"`
+}
+
+// GetNowShowing joins the showtimes in the range of query with their rooms
+// and cinemas, keeps the ones of the city or cinema of query, groups them by
+// movie and joins the movies, sorted by title. Only the movies of the page
+// requested leave the database.
+func (listingRepository *listingRepositoryImpl) GetNowShowing(ctx context.Context, query *model.ShowingQuery) (*model.NowShowingList, error) {
+	page, limit := listingPage(query.Page, query.Limit)
+
+	cinemaFilter := bson.M{}
+	if len(query.CinemaId) > 0 {
+		objectId, _ := primitive.ObjectIDFromHex(query.CinemaId)
+		cinemaFilter["cinema._id"] = objectId
+	}
+	if len(query.CityId) > 0 {
+		cinemaFilter["cinema.cityId"] = query.CityId
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": notDeleted(ctx, bson.M{"date": bson.M{"$gte": query.From, "$lt": query.To}})},
+	}
+	pipeline = append(pipeline, listingLookup(ctx, "rooms", "roomId", "room")...)
+	pipeline = append(pipeline, listingLookup(ctx, "cinemas", "room.cinemaId", "cinema")...)
+	pipeline = append(pipeline,
+		bson.M{"$match": cinemaFilter},
+		bson.M{"$sort": bson.M{"date": 1}},
+		bson.M{"$group": bson.M{
+			"_id": "$movieId",
+			"showtimes": bson.M{"$push": bson.M{
+				"_id":      "$_id",
+				"date":     "$date",
+				"roomId":   "$roomId",
+				"room":     "$room.name",
+				"format":   "$room.format",
+				"cinemaId": "$room.cinemaId",
+				"cinema":   "$cinema.name",
+				"cityId":   "$cinema.cityId",
+			}},
+		}},
+	)
+	pipeline = append(pipeline, listingLookup(ctx, "movies", "_id", "movie")...)
+	pipeline = append(pipeline,
+		bson.M{"$sort": bson.D{{Key: "movie.title", Value: 1}, {Key: "_id", Value: 1}}},
+		listingFacet(page, limit),
+	)
+
+	var result []struct {
+		Total []listingCount `bson:"total"`
+		Data  []showingMovie `bson:"data"`
+	}
+	if err := aggregateListing(ctx, listingRepository.Connection.Collection("schedules"), pipeline, &result); err != nil {
+		return nil, err
+	}
+	var movies []showingMovie
+	var total int64
+	if len(result) > 0 {
+		movies, total = result[0].Data, listingTotal(result[0].Total)
+	}
+	return nowShowingList(ctx, mongoCollection(listingRepository.Connection, "cities"), query, movies, total, page, limit)
+}
+
+// GetComingSoon keeps the movies released in the range of query that no
+// showtime shows yet, the next releases first. The lookup stops at the first
+// showtime of each movie.
+func (listingRepository *listingRepositoryImpl) GetComingSoon(ctx context.Context, query *model.ComingSoonQuery) (*model.PagedMovie, error) {
+	page, limit := listingPage(query.Page, query.Limit)
+
+	showtime := notDeleted(ctx, bson.M{"$expr": bson.M{"$eq": bson.A{"$movieId", "$$ref"}}})
+	pipeline := bson.A{
+		bson.M{"$match": notDeleted(ctx, bson.M{"releaseDate": comingSoonRange(query)})},
+		bson.M{"$lookup": bson.M{
+			"from": "schedules",
+			"let":  bson.M{"ref": bson.M{"$toString": "$_id"}},
+			"pipeline": bson.A{
+				bson.M{"$match": showtime},
+				bson.M{"$limit": 1},
+				bson.M{"$project": bson.M{"_id": 1}},
+			},
+			"as": "showtimes",
+		}},
+		bson.M{"$match": bson.M{"showtimes": bson.M{"$size": 0}}},
+		bson.M{"$project": bson.M{"showtimes": 0}},
+		bson.M{"$sort": bson.D{{Key: "releaseDate", Value: 1}, {Key: "title", Value: 1}, {Key: "_id", Value: 1}}},
+		listingFacet(page, limit),
+	}
+
+	var result []struct {
+		Total []listingCount `bson:"total"`
+		Data  []model.Movie  `bson:"data"`
+	}
+	if err := aggregateListing(ctx, listingRepository.Connection.Collection("movies"), pipeline, &result); err != nil {
+		return nil, err
+	}
+	comingSoon := &model.PagedMovie{Data: []model.Movie{}, PageInfo: paginationData(0, page, limit)}
+	if len(result) > 0 && result[0].Data != nil {
+		comingSoon.Data = result[0].Data
+		comingSoon.PageInfo = paginationData(listingTotal(result[0].Total), page, limit)
+	}
+	return comingSoon, nil
+}
+
+// listingLookup joins the document of collection whose id is localField,
+// unless deleted, as the field as. Documents without one are dropped.
+func listingLookup(ctx context.Context, collection string, localField string, as string) bson.A {
+	match := notDeleted(ctx, bson.M{"$expr": bson.M{"$eq": bson.A{bson.M{"$toString": "$_id"}, "$$ref"}}})
+	return bson.A{
+		bson.M{"$lookup": bson.M{
+			"from":     collection,
+			"let":      bson.M{"ref": bson.M{"$toString": "$" + localField}},
+			"pipeline": bson.A{bson.M{"$match": match}},
+			"as":       as,
+		}},
+		bson.M{"$unwind": "$" + as},
+	}
+}
+
+// listingFacet counts the documents of the listing and keeps the ones of
+// page.
+func listingFacet(page int64, limit int64) bson.M {
+	return bson.M{"$facet": bson.M{
+		"total": bson.A{bson.M{"$count": "count"}},
+		"data":  bson.A{bson.M{"$skip": (page - 1) * limit}, bson.M{"$limit": limit}},
+	}}
+}
+
+func aggregateListing(ctx context.Context, collection *mongo.Collection, pipeline bson.A, results interface{}) error {
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	return cursor.All(ctx, results)
+}
+
+func listingTotal(counts []listingCount) int64 {
+	if len(counts) == 0 {
+		return 0
+	}
+	return counts[0].Count
+}
+
+func listingPage(page int64, limit int64) (int64, int64) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	return page, limit
+}
+
+func comingSoonRange(query *model.ComingSoonQuery) bson.M {
+	released := bson.M{"$gte": query.From.Time()}
+	if query.To != nil {
+		released["$lte"] = query.To.Time()
+	}
+	return released
+}
+
+// nowShowingList groups the showtimes of each movie of a page by cinema.
+// Cinemas are sorted by name and showtimes by date, given in the local time
+// of their cinema.
+func nowShowingList(ctx context.Context, store documentStore, query *model.ShowingQuery, movies []showingMovie, total int64, page int64, limit int64) (*model.NowShowingList, error) {
+	list := &model.NowShowingList{
+		Data:     []model.NowShowing{},
+		From:     query.From,
+		To:       query.To,
+		PageInfo: paginationData(total, page, limit),
+	}
+
+	var cityIds []string
+	for _, movie := range movies {
+		for _, showtime := range movie.Showtimes {
+			cityIds = append(cityIds, showtime.CityId)
+		}
+	}
+	locations, err := cityTimeZones(ctx, store, cityIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range movies {
+		showtimes := movies[i].Showtimes
+		sort.SliceStable(showtimes, func(i, j int) bool {
+			return showtimes[i].Date.Before(showtimes[j].Date)
+		})
+
+		nowShowing := model.NowShowing{Movie: &movies[i].Movie, Cinemas: []model.CinemaShowtimes{}}
+		cinemaIndex := map[string]int{}
+		for _, showtime := range showtimes {
+			c, ok := cinemaIndex[showtime.CinemaId]
+			if !ok {
+				c = len(nowShowing.Cinemas)
+				cinemaIndex[showtime.CinemaId] = c
+				nowShowing.Cinemas = append(nowShowing.Cinemas, model.CinemaShowtimes{
+					CinemaId: showtime.CinemaId,
+					Name:     showtime.Cinema,
+					TimeZone: locations[showtime.CityId].String(),
+					Formats:  []string{},
+				})
+			}
+			cinemaShowtimes := &nowShowing.Cinemas[c]
+
+			cinemaShowtimes.Showtimes = append(cinemaShowtimes.Showtimes, model.Showtime{
+				ID:     showtime.ID.Hex(),
+				Date:   showtime.Date.In(locations[showtime.CityId]),
+				Format: showtime.Format,
+				RoomId: showtime.RoomId,
+				Room:   showtime.Room,
+			})
+			if !containsString(cinemaShowtimes.Formats, showtime.Format) {
+				cinemaShowtimes.Formats = append(cinemaShowtimes.Formats, showtime.Format)
+			}
+		}
+		sort.SliceStable(nowShowing.Cinemas, func(i, j int) bool {
+			return nowShowing.Cinemas[i].Name < nowShowing.Cinemas[j].Name
+		})
+		list.Data = append(list.Data, nowShowing)
+	}
+	return list, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
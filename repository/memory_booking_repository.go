@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type memoryBookingRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryBookingRepository(Store *MemoryStore) BookingRepository {
+	return &memoryBookingRepository{Store: Store}
+}
+
+func (bookingRepository *memoryBookingRepository) GetUserBookings(ctx context.Context, userId string, query *model.CursorQuery) (*model.PagedBooking, error) {
+	collection := bookingRepository.Store.collection("bookings")
+
+	documents, cursorInfo, err := collection.findByCursor(ctx, notDeleted(ctx, bson.M{"userId": userId}), nil, query, nil, "created_at")
+	if err != nil {
+		return nil, err
+	}
+	return pagedBookings(documents, cursorInfo)
+}
+
+func (bookingRepository *memoryBookingRepository) GetUpcomingBookings(ctx context.Context, userId string, from time.Time) ([]model.Booking, error) {
+	return findUpcomingBookings(ctx, bookingRepository.Store.collection("bookings"), userId, from)
+}
+
+func (bookingRepository *memoryBookingRepository) GetBooking(ctx context.Context, id string) (*model.Booking, error) {
+	return findBooking(ctx, bookingRepository.Store.collection("bookings"), id)
+}
+
+func (bookingRepository *memoryBookingRepository) SaveBooking(ctx context.Context, booking *model.Booking) (*model.Booking, error) {
+	return insertBooking(ctx, bookingRepository.Store.collection("bookings"), booking)
+}
+
+func (bookingRepository *memoryBookingRepository) CancelBooking(ctx context.Context, id string) error {
+	return cancelBooking(ctx, bookingRepository.Store.collection("bookings"), id)
+}
+
+func (bookingRepository *memoryBookingRepository) CheckIn(ctx context.Context, id string, at time.Time) (*model.Booking, error) {
+	return checkInBooking(ctx, bookingRepository.Store.collection("bookings"), id, at)
+}
+
+func (bookingRepository *memoryBookingRepository) HasCheckedIn(ctx context.Context, userId string, movieId string) (bool, error) {
+	return hasCheckedIn(ctx, bookingRepository.Store.collection("bookings"), userId, movieId)
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryCinemaRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryCinemaRepository(Store *MemoryStore) CinemaRepository {
+	return &memoryCinemaRepository{Store: Store}
+}
+
+func (cinemaRepository *memoryCinemaRepository) GetAllCinemas(ctx context.Context, page int64, limit int64) (*model.PagedCinema, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	documents, pageInfo, err := cinemaRepository.Store.collection("cinemas").findPage(filter, cinemaProjection, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	cinemas, err := decodeCinemas(documents)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PagedCinema{
+		Data:     cinemas,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+func (cinemaRepository *memoryCinemaRepository) GetAllCinemasByCursor(ctx context.Context, query *model.CursorQuery) (*model.PagedCinema, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	documents, cursorInfo, err := cinemaRepository.Store.collection("cinemas").findByCursor(ctx, filter, cinemaProjection, query, nil, "name", "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	cinemas, err := decodeCinemas(documents)
+	if err != nil {
+		return nil, err
+	}
+	if cinemas == nil {
+		cinemas = []model.Cinema{}
+	}
+	return &model.PagedCinema{
+		Data:   cinemas,
+		Cursor: cursorInfo,
+	}, nil
+}
+
+func (cinemaRepository *memoryCinemaRepository) GetAllCinemaDocuments(ctx context.Context, query *model.DocumentQuery) (*model.PagedDocument, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	return cinemaRepository.Store.collection("cinemas").findDocuments(ctx, filter, query, "name", "created_at")
+}
+
+func (cinemaRepository *memoryCinemaRepository) GetCinemaById(ctx context.Context, id string) (*model.Cinema, error) {
+	var cinema model.Cinema
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	found, err := cinemaRepository.Store.collection("cinemas").FindOne(ctx, filter, nil, &cinema)
+	if err != nil || !found {
+		return nil, exception.ResourceNotFoundException("Cinema", "id", id)
+	}
+	return &cinema, nil
+}
+
+func (cinemaRepository *memoryCinemaRepository) GetCinemaDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	document, err := cinemaRepository.Store.collection("cinemas").findDocument(ctx, filter, query)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, exception.ResourceNotFoundException("Cinema", "id", id)
+	}
+	return document, nil
+}
+
+func (cinemaRepository *memoryCinemaRepository) GetCinemaByCity(ctx context.Context, cityId string) (*model.Cinema, error) {
+	panic("implement me")
+}
+
+func (cinemaRepository *memoryCinemaRepository) SaveCinema(ctx context.Context, cinema *model.Cinema) (*model.Cinema, error) {
+	cinema.ID = primitive.NewObjectID()
+	cinema.Version = 1
+	cinema.Rooms = []string{}
+
+	if err := insertLinked(ctx, cinemaRepository.Store.collection("cinemas"), cinema, cinema.ID, cinema.CityId); err != nil {
+		return nil, err
+	}
+
+	return cinema, nil
+}
+
+func (cinemaRepository *memoryCinemaRepository) UpdateCinema(ctx context.Context, id string, cinemaId *model.Cinema) (*model.Cinema, error) {
+	panic("implement me")
+}
+
+func (cinemaRepository *memoryCinemaRepository) DeleteCinema(ctx context.Context, id string, cinemaId string) error {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := bson.M{"_id": objectId}
+
+	deleted, err := deleteLinked(ctx, cinemaRepository.Store.collection("cinemas"), filter)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return exception.ResourceNotFoundException("Cinema", "id", id)
+	}
+
+	return nil
+}
+
+func (cinemaRepository *memoryCinemaRepository) RestoreCinema(ctx context.Context, id string) (*model.Cinema, error) {
+	restored, err := restoreLinked(ctx, cinemaRepository.Store.collection("cinemas"), id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, exception.ResourceNotFoundException("Cinema", "id", id)
+	}
+
+	return cinemaRepository.GetCinemaById(ctx, id)
+}
+
+// GetNearbyCinemas measures distances on a sphere of the radius MongoDB uses
+// for $geoNear, so both implementations agree to the meter.
+func (cinemaRepository *memoryCinemaRepository) GetNearbyCinemas(ctx context.Context, query *model.NearbyCinemaQuery) (*model.NearbyCinemaList, error) {
+	collection := cinemaRepository.Store.collection("cinemas")
+	filter, err := nearbyCinemaFilter(ctx, collection, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var cinemas []model.Cinema
+	if err := collection.Find(ctx, filter, &cinemas); err != nil {
+		return nil, err
+	}
+
+	nearby := []model.NearbyCinema{}
+	for i := range cinemas {
+		location := cinemas[i].Location
+		if location == nil || len(location.Coordinates) != 2 {
+			continue
+		}
+		distance := sphericalDistance(query.Latitude, query.Longitude, location.Latitude(), location.Longitude())
+		if distance <= query.Radius {
+			nearby = append(nearby, model.NearbyCinema{Cinema: &cinemas[i], Distance: math.Round(distance)})
+		}
+	}
+	sort.SliceStable(nearby, func(i, j int) bool {
+		return nearby[i].Distance < nearby[j].Distance
+	})
+
+	if limit := nearbyLimit(query.Limit); int64(len(nearby)) > limit {
+		nearby = nearby[:limit]
+	}
+	return &model.NearbyCinemaList{Data: nearby}, nil
+}
+
+// sphericalDistance is the haversine distance in meters between two points
+// given in degrees.
+func sphericalDistance(latitude1 float64, longitude1 float64, latitude2 float64, longitude2 float64) float64 {
+	const earthRadius = 6378100.0
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+
+	deltaLatitude := toRadians(latitude2 - latitude1)
+	deltaLongitude := toRadians(longitude2 - longitude1)
+	a := math.Sin(deltaLatitude/2)*math.Sin(deltaLatitude/2) +
+		math.Cos(toRadians(latitude1))*math.Cos(toRadians(latitude2))*math.Sin(deltaLongitude/2)*math.Sin(deltaLongitude/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func decodeCinemas(documents []bson.Raw) ([]model.Cinema, error) {
+	var cinemas []model.Cinema
+	for _, document := range documents {
+		var cinema model.Cinema
+		if err := bson.Unmarshal(document, &cinema); err != nil {
+			return nil, err
+		}
+		cinemas = append(cinemas, cinema)
+	}
+	return cinemas, nil
+}
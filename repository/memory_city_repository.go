@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryCityRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryCityRepository(Store *MemoryStore) CityRepository {
+	return &memoryCityRepository{Store: Store}
+}
+
+func (cityRepository *memoryCityRepository) GetAllCities(ctx context.Context, page int64, limit int64, stateId string) (*model.PagedCity, error) {
+	filter := bson.M{}
+	if len(stateId) > 0 {
+		filter["stateId"] = stateId
+	}
+	filter = notDeleted(ctx, filter)
+
+	documents, pageInfo, err := cityRepository.Store.collection("cities").findPage(filter, cityProjection, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	cities, err := decodeCities(documents)
+	if err != nil {
+		return nil, err
+	}
+	if cities == nil {
+		return nil, exception.ResourcesNotFoundException("Cities")
+	}
+	return &model.PagedCity{
+		Data:     cities,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+func (cityRepository *memoryCityRepository) GetAllCitiesByCursor(ctx context.Context, query *model.CursorQuery, stateId string) (*model.PagedCity, error) {
+	filter := bson.M{}
+	if len(stateId) > 0 {
+		filter["stateId"] = stateId
+	}
+	filter = notDeleted(ctx, filter)
+
+	documents, cursorInfo, err := cityRepository.Store.collection("cities").findByCursor(ctx, filter, cityProjection, query, nil, "name", "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	cities, err := decodeCities(documents)
+	if err != nil {
+		return nil, err
+	}
+	if cities == nil {
+		cities = []model.City{}
+	}
+	return &model.PagedCity{
+		Data:   cities,
+		Cursor: cursorInfo,
+	}, nil
+}
+
+func (cityRepository *memoryCityRepository) GetAllCityDocuments(ctx context.Context, query *model.DocumentQuery, stateId string) (*model.PagedDocument, error) {
+	filter := bson.M{}
+	if len(stateId) > 0 {
+		filter["stateId"] = stateId
+	}
+	filter = notDeleted(ctx, filter)
+
+	return cityRepository.Store.collection("cities").findDocuments(ctx, filter, query, "name", "created_at")
+}
+
+func (cityRepository *memoryCityRepository) GetCityById(ctx context.Context, id string) (*model.City, error) {
+	var city model.City
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	found, err := cityRepository.Store.collection("cities").FindOne(ctx, filter, nil, &city)
+	if err != nil || !found {
+		return nil, exception.ResourceNotFoundException("City", "id", id)
+	}
+	return &city, nil
+}
+
+func (cityRepository *memoryCityRepository) GetCityDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	document, err := cityRepository.Store.collection("cities").findDocument(ctx, filter, query)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, exception.ResourceNotFoundException("City", "id", id)
+	}
+	return document, nil
+}
+
+func (cityRepository *memoryCityRepository) GetCityByName(ctx context.Context, stateId string, name string) (*model.City, error) {
+	var city model.City
+	filter := notDeleted(ctx, bson.M{"stateId": stateId, "name": name})
+
+	found, err := cityRepository.Store.collection("cities").FindOne(ctx, filter, nil, &city)
+	if err != nil || !found {
+		return nil, exception.ResourceNotFoundException("City", "name", name)
+	}
+	return &city, nil
+}
+
+func (cityRepository *memoryCityRepository) SaveCity(ctx context.Context, city *model.City) (*model.City, error) {
+	city.ID = primitive.NewObjectID()
+	city.Version = 1
+	city.Cinemas = []string{}
+
+	if err := insertLinked(ctx, cityRepository.Store.collection("cities"), city, city.ID, city.StateId); err != nil {
+		return nil, err
+	}
+
+	return city, nil
+}
+
+func (cityRepository *memoryCityRepository) UpdateCity(ctx context.Context, id string, city *model.City) (*model.City, error) {
+	return cityRepository.updateCity(ctx, id, cityUpdateFields(city))
+}
+
+func (cityRepository *memoryCityRepository) PatchCity(ctx context.Context, id string, city *model.City) (*model.City, error) {
+	return cityRepository.updateCity(ctx, id, cityPatchFields(city))
+}
+
+func (cityRepository *memoryCityRepository) updateCity(ctx context.Context, id string, fields bson.M) (*model.City, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": bson.M{"$eq": objectId}})
+
+	var updated model.City
+	found, err := updateLinked(ctx, cityRepository.Store.collection("cities"), filter, fields, &updated)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, exception.ResourceNotFoundException("City", "id", id)
+	}
+
+	return &updated, nil
+}
+
+func (cityRepository *memoryCityRepository) DeleteCity(ctx context.Context, id string) error {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := bson.M{"_id": objectId}
+
+	deleted, err := deleteLinked(ctx, cityRepository.Store.collection("cities"), filter)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return exception.ResourceNotFoundException("City", "id", id)
+	}
+
+	return nil
+}
+
+func (cityRepository *memoryCityRepository) RestoreCity(ctx context.Context, id string) (*model.City, error) {
+	restored, err := restoreLinked(ctx, cityRepository.Store.collection("cities"), id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, exception.ResourceNotFoundException("City", "id", id)
+	}
+
+	return cityRepository.GetCityById(ctx, id)
+}
+
+func (cityRepository *memoryCityRepository) GetCityTimeZone(ctx context.Context, id string) (*time.Location, error) {
+	return findCityTimeZone(ctx, cityRepository.Store.collection("cities"), id)
+}
+
+func decodeCities(documents []bson.Raw) ([]model.City, error) {
+	var cities []model.City
+	for _, document := range documents {
+		var city model.City
+		if err := bson.Unmarshal(document, &city); err != nil {
+			return nil, err
+		}
+		cities = append(cities, city)
+	}
+	return cities, nil
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryCountryRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryCountryRepository(Store *MemoryStore) CountryRepository {
+	return &memoryCountryRepository{Store: Store}
+}
+
+func (countryRepository *memoryCountryRepository) GetAllCountries(ctx context.Context, page int64, limit int64) (*model.PagedCountry, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	documents, pageInfo, err := countryRepository.Store.collection("countries").findPage(filter, countryProjection, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	countries, err := decodeCountries(documents)
+	if err != nil {
+		return nil, err
+	}
+	if countries == nil {
+		return nil, exception.ResourcesNotFoundException("Countries")
+	}
+	return &model.PagedCountry{
+		Data:     countries,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+func (countryRepository *memoryCountryRepository) GetAllCountriesByCursor(ctx context.Context, query *model.CursorQuery) (*model.PagedCountry, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	documents, cursorInfo, err := countryRepository.Store.collection("countries").findByCursor(ctx, filter, countryProjection, query, nil, "name", "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	countries, err := decodeCountries(documents)
+	if err != nil {
+		return nil, err
+	}
+	if countries == nil {
+		countries = []model.Country{}
+	}
+	return &model.PagedCountry{
+		Data:   countries,
+		Cursor: cursorInfo,
+	}, nil
+}
+
+func (countryRepository *memoryCountryRepository) GetAllCountryDocuments(ctx context.Context, query *model.DocumentQuery) (*model.PagedDocument, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	return countryRepository.Store.collection("countries").findDocuments(ctx, filter, query, "name", "created_at")
+}
+
+func (countryRepository *memoryCountryRepository) GetCountryById(ctx context.Context, id string) (*model.Country, error) {
+	var country model.Country
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	found, err := countryRepository.Store.collection("countries").FindOne(ctx, filter, nil, &country)
+	if err != nil || !found {
+		return nil, exception.ResourceNotFoundException("Country", "id", id)
+	}
+	return &country, nil
+}
+
+func (countryRepository *memoryCountryRepository) GetCountryDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	document, err := countryRepository.Store.collection("countries").findDocument(ctx, filter, query)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, exception.ResourceNotFoundException("Country", "id", id)
+	}
+	return document, nil
+}
+
+func (countryRepository *memoryCountryRepository) GetCountryByName(ctx context.Context, name string) (*model.Country, error) {
+	var country model.Country
+	filter := notDeleted(ctx, bson.M{"name": name})
+
+	found, err := countryRepository.Store.collection("countries").FindOne(ctx, filter, nil, &country)
+	if err != nil || !found {
+		return nil, exception.ResourceNotFoundException("Country", "name", name)
+	}
+	return &country, nil
+}
+
+func (countryRepository *memoryCountryRepository) SaveCountry(ctx context.Context, country *model.Country) (*model.Country, error) {
+	country.ID = primitive.NewObjectID()
+	country.Version = 1
+	country.States = []string{}
+
+	if err := countryRepository.Store.collection("countries").InsertOne(ctx, country); err != nil {
+		return nil, err
+	}
+
+	return country, nil
+}
+
+func (countryRepository *memoryCountryRepository) UpdateCountry(ctx context.Context, id string, country *model.Country) (*model.Country, error) {
+	return countryRepository.updateCountry(ctx, id, countryUpdateFields(country))
+}
+
+func (countryRepository *memoryCountryRepository) PatchCountry(ctx context.Context, id string, country *model.Country) (*model.Country, error) {
+	return countryRepository.updateCountry(ctx, id, countryPatchFields(country))
+}
+
+func (countryRepository *memoryCountryRepository) updateCountry(ctx context.Context, id string, fields bson.M) (*model.Country, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": bson.M{"$eq": objectId}})
+
+	var updated model.Country
+	found, err := updateDocument(ctx, countryRepository.Store.collection("countries"), filter, fields, &updated)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, exception.ResourceNotFoundException("Country", "id", id)
+	}
+
+	return &updated, nil
+}
+
+func (countryRepository *memoryCountryRepository) DeleteCountry(ctx context.Context, id string) error {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := bson.M{"_id": objectId}
+
+	deleted, err := deleteLinked(ctx, countryRepository.Store.collection("countries"), filter)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return exception.ResourceNotFoundException("Country", "id", id)
+	}
+
+	return nil
+}
+
+func (countryRepository *memoryCountryRepository) RestoreCountry(ctx context.Context, id string) (*model.Country, error) {
+	restored, err := restoreDocument(ctx, countryRepository.Store.collection("countries"), id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, exception.ResourceNotFoundException("Country", "id", id)
+	}
+
+	return countryRepository.GetCountryById(ctx, id)
+}
+
+func decodeCountries(documents []bson.Raw) ([]model.Country, error) {
+	var countries []model.Country
+	for _, document := range documents {
+		var country model.Country
+		if err := bson.Unmarshal(document, &country); err != nil {
+			return nil, err
+		}
+		countries = append(countries, country)
+	}
+	return countries, nil
+}
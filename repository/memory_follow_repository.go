@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type memoryFollowRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryFollowRepository(Store *MemoryStore) FollowRepository {
+	return &memoryFollowRepository{Store: Store}
+}
+
+func (followRepository *memoryFollowRepository) Follow(ctx context.Context, followerId string, followingId string) (*model.Follow, error) {
+	return insertFollow(ctx, followRepository.Store.collection("follows"), followerId, followingId)
+}
+
+func (followRepository *memoryFollowRepository) Unfollow(ctx context.Context, followerId string, followingId string) error {
+	return deleteFollow(ctx, followRepository.Store.collection("follows"), followerId, followingId)
+}
+
+func (followRepository *memoryFollowRepository) GetFollowers(ctx context.Context, userId string, query *model.CursorQuery) (*model.PagedUser, error) {
+	collection := followRepository.Store.collection("follows")
+
+	documents, cursorInfo, err := collection.findByCursor(ctx, bson.M{"followingId": userId}, nil, query, nil, "created_at")
+	if err != nil {
+		return nil, err
+	}
+	return followUsers(ctx, collection, documents, cursorInfo, "followerId")
+}
+
+func (followRepository *memoryFollowRepository) GetFollowing(ctx context.Context, userId string, query *model.CursorQuery) (*model.PagedUser, error) {
+	collection := followRepository.Store.collection("follows")
+
+	documents, cursorInfo, err := collection.findByCursor(ctx, bson.M{"followerId": userId}, nil, query, nil, "created_at")
+	if err != nil {
+		return nil, err
+	}
+	return followUsers(ctx, collection, documents, cursorInfo, "followingId")
+}
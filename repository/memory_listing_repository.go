@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryListingRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryListingRepository(Store *MemoryStore) ListingRepository {
+	return &memoryListingRepository{Store: Store}
+}
+
+// GetNowShowing narrows the cinemas to the city or cinema of query, then
+// their rooms, then the showtimes of those rooms in the range of query, and
+// groups the showtimes by movie, sorted by title.
+func (listingRepository *memoryListingRepository) GetNowShowing(ctx context.Context, query *model.ShowingQuery) (*model.NowShowingList, error) {
+	page, limit := listingPage(query.Page, query.Limit)
+	schedules := listingRepository.Store.collection("schedules")
+
+	cinemaFilter := bson.M{}
+	if len(query.CinemaId) > 0 {
+		objectId, _ := primitive.ObjectIDFromHex(query.CinemaId)
+		cinemaFilter["_id"] = objectId
+	}
+	if len(query.CityId) > 0 {
+		cinemaFilter["cityId"] = query.CityId
+	}
+	var cinemas []model.Cinema
+	if err := schedules.Collection("cinemas").Find(ctx, notDeleted(ctx, cinemaFilter), &cinemas); err != nil {
+		return nil, err
+	}
+	cinemasById := map[string]model.Cinema{}
+	cinemaIds := bson.A{}
+	for _, cinema := range cinemas {
+		cinemasById[cinema.ID.Hex()] = cinema
+		cinemaIds = append(cinemaIds, cinema.ID.Hex())
+	}
+
+	var rooms []model.Room
+	if len(cinemaIds) > 0 {
+		if err := schedules.Collection("rooms").Find(ctx, notDeleted(ctx, bson.M{"cinemaId": bson.M{"$in": cinemaIds}}), &rooms); err != nil {
+			return nil, err
+		}
+	}
+	roomsById := map[string]model.Room{}
+	roomIds := bson.A{}
+	for _, room := range rooms {
+		roomsById[room.ID.Hex()] = room
+		roomIds = append(roomIds, room.ID.Hex())
+	}
+
+	var showtimes []model.Schedule
+	if len(roomIds) > 0 {
+		showtimeFilter := notDeleted(ctx, bson.M{
+			"roomId": bson.M{"$in": roomIds},
+			"date":   bson.M{"$gte": query.From, "$lt": query.To},
+		})
+		if err := schedules.Find(ctx, showtimeFilter, &showtimes); err != nil {
+			return nil, err
+		}
+	}
+
+	movieIds := bson.A{}
+	for _, showtime := range showtimes {
+		if objectId, err := primitive.ObjectIDFromHex(showtime.MovieId); err == nil {
+			movieIds = append(movieIds, objectId)
+		}
+	}
+	var movies []model.Movie
+	if len(movieIds) > 0 {
+		if err := schedules.Collection("movies").Find(ctx, notDeleted(ctx, bson.M{"_id": bson.M{"$in": movieIds}}), &movies); err != nil {
+			return nil, err
+		}
+	}
+	showing := map[string]*showingMovie{}
+	for _, movie := range movies {
+		showing[movie.ID.Hex()] = &showingMovie{Movie: movie}
+	}
+	for _, showtime := range showtimes {
+		movie, ok := showing[showtime.MovieId]
+		if !ok {
+			continue
+		}
+		room := roomsById[showtime.RoomId]
+		cinema := cinemasById[room.CinemaId]
+		movie.Showtimes = append(movie.Showtimes, showingShowtime{
+			ID:       showtime.ID,
+			Date:     showtime.Date,
+			RoomId:   showtime.RoomId,
+			Room:     room.Name,
+			Format:   room.Format,
+			CinemaId: room.CinemaId,
+			Cinema:   cinema.Name,
+			CityId:   cinema.CityId,
+		})
+	}
+
+	listed := []showingMovie{}
+	for _, movie := range showing {
+		if len(movie.Showtimes) > 0 {
+			listed = append(listed, *movie)
+		}
+	}
+	sort.Slice(listed, func(i, j int) bool {
+		if listed[i].Movie.Title != listed[j].Movie.Title {
+			return listed[i].Movie.Title < listed[j].Movie.Title
+		}
+		return listed[i].Movie.ID.Hex() < listed[j].Movie.ID.Hex()
+	})
+
+	total := int64(len(listed))
+	start, end := (page-1)*limit, page*limit
+	if start > total {
+		start = total
+	}
+	if end > total {
+		end = total
+	}
+	return nowShowingList(ctx, schedules, query, listed[start:end], total, page, limit)
+}
+
+// GetComingSoon returns the movies released in the range of query that no
+// showtime shows yet, the next releases first.
+func (listingRepository *memoryListingRepository) GetComingSoon(ctx context.Context, query *model.ComingSoonQuery) (*model.PagedMovie, error) {
+	page, limit := listingPage(query.Page, query.Limit)
+	movies := listingRepository.Store.collection("movies")
+
+	var candidates []model.Movie
+	if err := movies.Find(ctx, notDeleted(ctx, bson.M{"releaseDate": comingSoonRange(query)}), &candidates); err != nil {
+		return nil, err
+	}
+
+	comingSoon := []model.Movie{}
+	for _, movie := range candidates {
+		scheduled, err := movies.Collection("schedules").Count(ctx, notDeleted(ctx, bson.M{"movieId": movie.ID.Hex()}))
+		if err != nil {
+			return nil, err
+		}
+		if scheduled == 0 {
+			comingSoon = append(comingSoon, movie)
+		}
+	}
+	sort.Slice(comingSoon, func(i, j int) bool {
+		a, b := comingSoon[i].ReleaseDate.Time(), comingSoon[j].ReleaseDate.Time()
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		if comingSoon[i].Title != comingSoon[j].Title {
+			return comingSoon[i].Title < comingSoon[j].Title
+		}
+		return comingSoon[i].ID.Hex() < comingSoon[j].ID.Hex()
+	})
+
+	total := int64(len(comingSoon))
+	start, end := (page-1)*limit, page*limit
+	if start > total {
+		start = total
+	}
+	if end > total {
+		end = total
+	}
+	return &model.PagedMovie{
+		Data:     comingSoon[start:end],
+		PageInfo: paginationData(total, page, limit),
+	}, nil
+}
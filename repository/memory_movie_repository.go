@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryMovieRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryMovieRepository(Store *MemoryStore) MovieRepository {
+	return &memoryMovieRepository{Store: Store}
+}
+
+func (movieRepository *memoryMovieRepository) GetAllMovies(ctx context.Context, page int64, limit int64, movieFilter *model.MovieFilter) (*model.PagedMovie, error) {
+	filter := movieListFilter(ctx, movieFilter)
+
+	documents, pageInfo, err := movieRepository.Store.collection("movies").findPage(filter, movieProjection, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	movies, err := decodeMovies(documents)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PagedMovie{
+		Data:     movies,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+func (movieRepository *memoryMovieRepository) GetAllMoviesByCursor(ctx context.Context, query *model.CursorQuery, movieFilter *model.MovieFilter) (*model.PagedMovie, error) {
+	filter := movieListFilter(ctx, movieFilter)
+
+	documents, cursorInfo, err := movieRepository.Store.collection("movies").findByCursor(ctx, filter, movieProjection, query, nil, "title", "format", "releaseYear", "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	movies, err := decodeMovies(documents)
+	if err != nil {
+		return nil, err
+	}
+	if movies == nil {
+		movies = []model.Movie{}
+	}
+	return &model.PagedMovie{
+		Data:   movies,
+		Cursor: cursorInfo,
+	}, nil
+}
+
+func (movieRepository *memoryMovieRepository) GetAllMovieDocuments(ctx context.Context, query *model.DocumentQuery, movieFilter *model.MovieFilter) (*model.PagedDocument, error) {
+	filter := movieListFilter(ctx, movieFilter)
+
+	return movieRepository.Store.collection("movies").findDocuments(ctx, filter, query, "title", "format", "releaseYear", "created_at")
+}
+
+func (movieRepository *memoryMovieRepository) GetMovie(ctx context.Context, id string) (*model.Movie, error) {
+	var movie model.Movie
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	found, err := movieRepository.Store.collection("movies").FindOne(ctx, filter, nil, &movie)
+	if err != nil || !found {
+		return nil, exception.ResourceNotFoundException("Movie", "id", id)
+	}
+	return &movie, nil
+}
+
+func (movieRepository *memoryMovieRepository) GetMovieDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	document, err := movieRepository.Store.collection("movies").findDocument(ctx, filter, query)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, exception.ResourceNotFoundException("Movie", "id", id)
+	}
+	return document, nil
+}
+
+func (movieRepository *memoryMovieRepository) GetMovieByTitle(ctx context.Context, title string, releaseYear int) (*model.Movie, error) {
+	var movie model.Movie
+	filter := notDeleted(ctx, bson.M{"title": title, "releaseYear": releaseYear})
+
+	found, err := movieRepository.Store.collection("movies").FindOne(ctx, filter, nil, &movie)
+	if err != nil || !found {
+		return nil, exception.ResourceNotFoundException("Movie", "title", title)
+	}
+	return &movie, nil
+}
+
+func (movieRepository *memoryMovieRepository) SaveMovie(ctx context.Context, movie *model.Movie) (*model.Movie, error) {
+	movie.ID = primitive.NewObjectID()
+	movie.Version = 1
+	movie.SearchTitle = util.NormalizeText(movie.Title)
+	movie.SearchWords = util.SearchWords(movie.Title)
+	util.NormalizeMovie(movie.MovieInput)
+
+	if err := movieRepository.Store.collection("movies").InsertOne(ctx, movie); err != nil {
+		return nil, err
+	}
+
+	return movie, nil
+}
+
+func (movieRepository *memoryMovieRepository) UpdateMovie(ctx context.Context, id string, movie *model.Movie) (*model.Movie, error) {
+	return movieRepository.updateMovie(ctx, id, movieUpdateFields(movie))
+}
+
+func (movieRepository *memoryMovieRepository) PatchMovie(ctx context.Context, id string, movie *model.Movie) (*model.Movie, error) {
+	return movieRepository.updateMovie(ctx, id, moviePatchFields(movie))
+}
+
+func (movieRepository *memoryMovieRepository) updateMovie(ctx context.Context, id string, fields bson.M) (*model.Movie, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": bson.M{"$eq": objectId}})
+
+	var updated model.Movie
+	found, err := updateDocument(ctx, movieRepository.Store.collection("movies"), filter, fields, &updated)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, exception.ResourceNotFoundException("Movie", "id", id)
+	}
+
+	return &updated, nil
+}
+
+func (movieRepository *memoryMovieRepository) DeleteMovie(ctx context.Context, id string, movieId string) error {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := bson.M{"_id": objectId}
+
+	deleted, err := deleteDocument(ctx, movieRepository.Store.collection("movies"), filter)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return exception.ResourceNotFoundException("Movie", "id", id)
+	}
+
+	return nil
+}
+
+func (movieRepository *memoryMovieRepository) RestoreMovie(ctx context.Context, id string) (*model.Movie, error) {
+	restored, err := restoreDocument(ctx, movieRepository.Store.collection("movies"), id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, exception.ResourceNotFoundException("Movie", "id", id)
+	}
+
+	return movieRepository.GetMovie(ctx, id)
+}
+
+func (movieRepository *memoryMovieRepository) SaveMovieTranslation(ctx context.Context, id string, translation *model.MovieTranslation) (*model.Movie, error) {
+	return updateMovieTranslations(ctx, movieRepository.Store.collection("movies"), id, func(translations []model.MovieTranslation) ([]model.MovieTranslation, error) {
+		return putMovieTranslation(translations, translation), nil
+	})
+}
+
+func (movieRepository *memoryMovieRepository) DeleteMovieTranslation(ctx context.Context, id string, locale string) (*model.Movie, error) {
+	return updateMovieTranslations(ctx, movieRepository.Store.collection("movies"), id, func(translations []model.MovieTranslation) ([]model.MovieTranslation, error) {
+		updated, removed := removeMovieTranslation(translations, locale)
+		if !removed {
+			return nil, exception.ResourceNotFoundException("Translation", "locale", locale)
+		}
+		return updated, nil
+	})
+}
+
+func decodeMovies(documents []bson.Raw) ([]model.Movie, error) {
+	var movies []model.Movie
+	for _, document := range documents {
+		var movie model.Movie
+		if err := bson.Unmarshal(document, &movie); err != nil {
+			return nil, err
+		}
+		movies = append(movies, movie)
+	}
+	return movies, nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type memoryMovieSearchRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryMovieSearchRepository(Store *MemoryStore) MovieSearchRepository {
+	return &memoryMovieSearchRepository{Store: Store}
+}
+
+// SearchMovies ranks the movies the way the MongoDB implementation does:
+// whole words first, then word prefixes, then titles within a few typos. A
+// movie scores as its best matching title among the translated ones.
+func (movieSearchRepository *memoryMovieSearchRepository) SearchMovies(ctx context.Context, query *model.MovieSearchQuery) (*model.PagedMovieSearch, error) {
+	terms := strings.Fields(util.NormalizeText(query.Text))
+	if len(terms) == 0 {
+		return nil, exception.ParameterException("q")
+	}
+
+	page, limit := query.Page, query.Limit
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	filter := notDeleted(ctx, bson.M{})
+	if query.ReleaseYear > 0 {
+		filter["releaseYear"] = query.ReleaseYear
+	}
+
+	ranked := []model.MovieSearchHit{}
+	for _, document := range movieSearchRepository.Store.collection("movies").find(filter, nil) {
+		var movie model.Movie
+		if err := bson.Unmarshal(document, &movie); err != nil {
+			return nil, err
+		}
+		if len(query.Format) > 0 && !strings.EqualFold(movie.Format, query.Format) {
+			continue
+		}
+
+		best, found := 0.0, false
+		for _, searchTitle := range append([]string{util.NormalizeText(movie.Title)}, movie.SearchTranslations...) {
+			if score, ok := searchScore(terms, searchTitle); ok && score > best {
+				best, found = score, true
+			}
+		}
+		if found {
+			movie := movie
+			movie.SearchTranslations = nil
+			ranked = append(ranked, model.MovieSearchHit{Movie: &movie, Score: best})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Title < ranked[j].Title
+	})
+
+	return searchPage(ranked, page, limit), nil
+}
+
+// searchScore scores searchTitle against terms on the scale of the MongoDB
+// implementation: above 2 for whole words, above 1 for word prefixes and in
+// (0, 1] for fuzzy matches.
+func searchScore(terms []string, searchTitle string) (float64, bool) {
+	words := strings.Fields(searchTitle)
+
+	matched, prefixed := 0, 0
+	for _, term := range terms {
+		for _, word := range words {
+			if word == term {
+				matched++
+				prefixed++
+				break
+			}
+			if strings.HasPrefix(word, term) {
+				prefixed++
+				break
+			}
+		}
+	}
+
+	switch {
+	case matched > 0:
+		return 2 + float64(matched)/float64(len(words)), true
+	case prefixed == len(terms):
+		return 1 + float64(len(strings.Join(terms, " ")))/float64(len(searchTitle)+1), true
+	default:
+		return fuzzyScore(terms, words)
+	}
+}
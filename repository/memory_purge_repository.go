@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type memoryPurgeRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryPurgeRepository(Store *MemoryStore) PurgeRepository {
+	return &memoryPurgeRepository{Store: Store}
+}
+
+func (purgeRepository *memoryPurgeRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	filter := bson.M{
+		"deleted_at": bson.M{"$lt": deletedBefore},
+	}
+
+	var purged int64
+	for _, name := range softDeleteCollections {
+		purged += purgeRepository.Store.collection(name).delete(filter, true)
+	}
+	return purged, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type memoryReviewRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryReviewRepository(Store *MemoryStore) ReviewRepository {
+	return &memoryReviewRepository{Store: Store}
+}
+
+func (reviewRepository *memoryReviewRepository) GetMovieReviews(ctx context.Context, query *model.ReviewQuery) (*model.PagedReview, error) {
+	collection := reviewRepository.Store.collection("reviews")
+	documents, pageInfo, err := collection.findSortedPage(reviewFilter(ctx, query), reviewProjection, reviewSort(query), query.Page, query.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := []model.Review{}
+	for _, document := range documents {
+		var review model.Review
+		if err := bson.Unmarshal(document, &review); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	return &model.PagedReview{
+		Data:     reviews,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+func (reviewRepository *memoryReviewRepository) GetReview(ctx context.Context, id string) (*model.Review, error) {
+	return findReview(ctx, reviewRepository.Store.collection("reviews"), id)
+}
+
+func (reviewRepository *memoryReviewRepository) SaveReview(ctx context.Context, review *model.Review) (*model.Review, error) {
+	return insertReview(ctx, reviewRepository.Store.collection("reviews"), review)
+}
+
+func (reviewRepository *memoryReviewRepository) UpdateReview(ctx context.Context, id string, review *model.Review) (*model.Review, error) {
+	return updateReview(ctx, reviewRepository.Store.collection("reviews"), id, review)
+}
+
+func (reviewRepository *memoryReviewRepository) DeleteReview(ctx context.Context, id string) error {
+	return deleteReview(ctx, reviewRepository.Store.collection("reviews"), id)
+}
+
+func (reviewRepository *memoryReviewRepository) VoteReview(ctx context.Context, id string, userId string, vote string) (*model.Review, error) {
+	return voteReview(ctx, reviewRepository.Store.collection("reviews"), id, userId, vote)
+}
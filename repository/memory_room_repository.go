@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryRoomRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryRoomRepository(Store *MemoryStore) RoomRepository {
+	return &memoryRoomRepository{Store: Store}
+}
+
+func (roomRepository *memoryRoomRepository) GetAllRooms(ctx context.Context, page int64, limit int64) (*model.PagedRoom, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	documents, pageInfo, err := roomRepository.Store.collection("rooms").findPage(filter, roomProjection, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	rooms, err := decodeRooms(documents)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PagedRoom{
+		Data:     rooms,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+func (roomRepository *memoryRoomRepository) GetAllRoomsByCursor(ctx context.Context, query *model.CursorQuery) (*model.PagedRoom, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	documents, cursorInfo, err := roomRepository.Store.collection("rooms").findByCursor(ctx, filter, roomProjection, query, nil, "name", "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	rooms, err := decodeRooms(documents)
+	if err != nil {
+		return nil, err
+	}
+	if rooms == nil {
+		rooms = []model.Room{}
+	}
+	return &model.PagedRoom{
+		Data:   rooms,
+		Cursor: cursorInfo,
+	}, nil
+}
+
+func (roomRepository *memoryRoomRepository) GetAllRoomDocuments(ctx context.Context, query *model.DocumentQuery) (*model.PagedDocument, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	return roomRepository.Store.collection("rooms").findDocuments(ctx, filter, query, "name", "created_at")
+}
+
+func (roomRepository *memoryRoomRepository) GetRoomById(ctx context.Context, id string) (*model.Room, error) {
+	var room model.Room
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	found, err := roomRepository.Store.collection("rooms").FindOne(ctx, filter, nil, &room)
+	if err != nil || !found {
+		return nil, exception.ResourceNotFoundException("Room", "id", id)
+	}
+	return &room, nil
+}
+
+func (roomRepository *memoryRoomRepository) GetRoomDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	document, err := roomRepository.Store.collection("rooms").findDocument(ctx, filter, query)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, exception.ResourceNotFoundException("Room", "id", id)
+	}
+	return document, nil
+}
+
+func (roomRepository *memoryRoomRepository) GetRoomByCinema(ctx context.Context, cinemaId string) (*model.Room, error) {
+	panic("implement me")
+}
+
+func (roomRepository *memoryRoomRepository) SaveRoom(ctx context.Context, room *model.Room) (*model.Room, error) {
+	room.ID = primitive.NewObjectID()
+	room.Version = 1
+
+	if err := insertLinked(ctx, roomRepository.Store.collection("rooms"), room, room.ID, room.CinemaId); err != nil {
+		return nil, err
+	}
+
+	return room, nil
+}
+
+func (roomRepository *memoryRoomRepository) UpdateRoom(ctx context.Context, id string, room *model.Room) (*model.Room, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": bson.M{"$eq": objectId}})
+
+	var updated model.Room
+	found, err := updateLinked(ctx, roomRepository.Store.collection("rooms"), filter, roomUpdateFields(room), &updated)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, exception.ResourceNotFoundException("Room", "id", id)
+	}
+
+	return &updated, nil
+}
+
+func (roomRepository *memoryRoomRepository) DeleteRoom(ctx context.Context, id string, roomId string) error {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := bson.M{"_id": objectId}
+
+	deleted, err := deleteLinked(ctx, roomRepository.Store.collection("rooms"), filter)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return exception.ResourceNotFoundException("Room", "id", id)
+	}
+
+	return nil
+}
+
+func (roomRepository *memoryRoomRepository) RestoreRoom(ctx context.Context, id string) (*model.Room, error) {
+	restored, err := restoreLinked(ctx, roomRepository.Store.collection("rooms"), id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, exception.ResourceNotFoundException("Room", "id", id)
+	}
+
+	return roomRepository.GetRoomById(ctx, id)
+}
+
+func decodeRooms(documents []bson.Raw) ([]model.Room, error) {
+	var rooms []model.Room
+	for _, document := range documents {
+		var room model.Room
+		if err := bson.Unmarshal(document, &room); err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, room)
+	}
+	return rooms, nil
+}
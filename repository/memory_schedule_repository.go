@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+)
+
+type memoryScheduleRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryScheduleRepository(Store *MemoryStore) ScheduleRepository {
+	return &memoryScheduleRepository{Store: Store}
+}
+
+func (scheduleRepository *memoryScheduleRepository) GetScheduleById(ctx context.Context, id string) (*model.Schedule, error) {
+	return findSchedule(ctx, scheduleRepository.Store.collection("schedules"), id)
+}
+
+func (scheduleRepository *memoryScheduleRepository) SaveSchedule(ctx context.Context, schedule *model.Schedule) (*model.Schedule, error) {
+	return insertSchedule(ctx, scheduleRepository.Store.collection("schedules"), schedule)
+}
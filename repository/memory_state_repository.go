@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryStateRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryStateRepository(Store *MemoryStore) StateRepository {
+	return &memoryStateRepository{Store: Store}
+}
+
+func (stateRepository *memoryStateRepository) GetAllStates(ctx context.Context, page int64, limit int64, countryId string) (*model.PagedState, error) {
+	filter := bson.M{}
+	if len(countryId) > 0 {
+		filter["countryId"] = countryId
+	}
+	filter = notDeleted(ctx, filter)
+
+	documents, pageInfo, err := stateRepository.Store.collection("states").findPage(filter, stateProjection, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	states, err := decodeStates(documents)
+	if err != nil {
+		return nil, err
+	}
+	if states == nil {
+		return nil, exception.ResourcesNotFoundException("States")
+	}
+	return &model.PagedState{
+		Data:     states,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+func (stateRepository *memoryStateRepository) GetAllStatesByCursor(ctx context.Context, query *model.CursorQuery, countryId string) (*model.PagedState, error) {
+	filter := bson.M{}
+	if len(countryId) > 0 {
+		filter["countryId"] = countryId
+	}
+	filter = notDeleted(ctx, filter)
+
+	documents, cursorInfo, err := stateRepository.Store.collection("states").findByCursor(ctx, filter, stateProjection, query, nil, "name", "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	states, err := decodeStates(documents)
+	if err != nil {
+		return nil, err
+	}
+	if states == nil {
+		states = []model.State{}
+	}
+	return &model.PagedState{
+		Data:   states,
+		Cursor: cursorInfo,
+	}, nil
+}
+
+func (stateRepository *memoryStateRepository) GetAllStateDocuments(ctx context.Context, query *model.DocumentQuery, countryId string) (*model.PagedDocument, error) {
+	filter := bson.M{}
+	if len(countryId) > 0 {
+		filter["countryId"] = countryId
+	}
+	filter = notDeleted(ctx, filter)
+
+	return stateRepository.Store.collection("states").findDocuments(ctx, filter, query, "name", "created_at")
+}
+
+func (stateRepository *memoryStateRepository) GetStateById(ctx context.Context, id string) (*model.State, error) {
+	var state model.State
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	found, err := stateRepository.Store.collection("states").FindOne(ctx, filter, nil, &state)
+	if err != nil || !found {
+		return nil, exception.ResourceNotFoundException("State", "id", id)
+	}
+	return &state, nil
+}
+
+func (stateRepository *memoryStateRepository) GetStateDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	document, err := stateRepository.Store.collection("states").findDocument(ctx, filter, query)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, exception.ResourceNotFoundException("State", "id", id)
+	}
+	return document, nil
+}
+
+func (stateRepository *memoryStateRepository) GetStateByName(ctx context.Context, countryId string, name string) (*model.State, error) {
+	var state model.State
+	filter := notDeleted(ctx, bson.M{"countryId": countryId, "name": name})
+
+	found, err := stateRepository.Store.collection("states").FindOne(ctx, filter, nil, &state)
+	if err != nil || !found {
+		return nil, exception.ResourceNotFoundException("State", "name", name)
+	}
+	return &state, nil
+}
+
+func (stateRepository *memoryStateRepository) SaveState(ctx context.Context, state *model.State) (*model.State, error) {
+	state.ID = primitive.NewObjectID()
+	state.Version = 1
+	state.Cities = []string{}
+
+	if err := insertLinked(ctx, stateRepository.Store.collection("states"), state, state.ID, state.CountryId); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func (stateRepository *memoryStateRepository) UpdateState(ctx context.Context, id string, state *model.State) (*model.State, error) {
+	return stateRepository.updateState(ctx, id, stateUpdateFields(state))
+}
+
+func (stateRepository *memoryStateRepository) PatchState(ctx context.Context, id string, state *model.State) (*model.State, error) {
+	return stateRepository.updateState(ctx, id, statePatchFields(state))
+}
+
+func (stateRepository *memoryStateRepository) updateState(ctx context.Context, id string, fields bson.M) (*model.State, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": bson.M{"$eq": objectId}})
+
+	var updated model.State
+	found, err := updateLinked(ctx, stateRepository.Store.collection("states"), filter, fields, &updated)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, exception.ResourceNotFoundException("State", "id", id)
+	}
+
+	return &updated, nil
+}
+
+func (stateRepository *memoryStateRepository) DeleteState(ctx context.Context, id string) error {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := bson.M{"_id": objectId}
+
+	deleted, err := deleteLinked(ctx, stateRepository.Store.collection("states"), filter)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return exception.ResourceNotFoundException("State", "id", id)
+	}
+
+	return nil
+}
+
+func (stateRepository *memoryStateRepository) RestoreState(ctx context.Context, id string) (*model.State, error) {
+	restored, err := restoreLinked(ctx, stateRepository.Store.collection("states"), id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, exception.ResourceNotFoundException("State", "id", id)
+	}
+
+	return stateRepository.GetStateById(ctx, id)
+}
+
+func decodeStates(documents []bson.Raw) ([]model.State, error) {
+	var states []model.State
+	for _, document := range documents {
+		var state model.State
+		if err := bson.Unmarshal(document, &state); err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
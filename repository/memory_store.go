@@ -0,0 +1,933 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+	paginate "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MemoryStore keeps collections of BSON documents in memory. It backs the
+// memory repositories, which behave like the MongoDB ones without a server,
+// for tests and local development.
+type MemoryStore struct {
+	mu          sync.Mutex
+	txMu        sync.Mutex
+	collections map[string][]bson.M
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{collections: map[string][]bson.M{}}
+}
+
+// memoryCollection is a handle on one collection of a MemoryStore, with the
+// subset of the MongoDB operations the repositories use.
+type memoryCollection struct {
+	store *MemoryStore
+	name  string
+}
+
+func (store *MemoryStore) collection(name string) *memoryCollection {
+	return &memoryCollection{store: store, name: name}
+}
+
+// memoryUniqueFields mirrors the unique indexes created by the migrations.
+var memoryUniqueFields = map[string][]string{
+	"users": {"email", "username"},
+}
+
+type memoryTransactionKey struct{}
+
+// transaction runs fn atomically: when it fails, every collection is put
+// back as it was. Transactions are serialized, and nested ones join the
+// outermost.
+func (store *MemoryStore) transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(memoryTransactionKey{}) != nil {
+		return fn(ctx)
+	}
+
+	store.txMu.Lock()
+	defer store.txMu.Unlock()
+
+	store.mu.Lock()
+	snapshot := make(map[string][]bson.M, len(store.collections))
+	for name, documents := range store.collections {
+		snapshot[name] = append([]bson.M(nil), documents...)
+	}
+	store.mu.Unlock()
+
+	if err := fn(context.WithValue(ctx, memoryTransactionKey{}, true)); err != nil {
+		store.mu.Lock()
+		store.collections = snapshot
+		store.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// toMemoryDocument converts value to the form documents have once read back
+// from MongoDB.
+func toMemoryDocument(value interface{}) (bson.M, error) {
+	data, err := bson.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var document bson.M
+	if err := bson.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// find returns the documents matching filter sorted on sortKeys, as raw BSON
+// so callers can never change the stored ones.
+func (collection *memoryCollection) find(filter bson.M, sortKeys bson.D) []bson.Raw {
+	collection.store.mu.Lock()
+	var matched []bson.M
+	for _, document := range collection.store.collections[collection.name] {
+		if matchDocument(document, filter) {
+			matched = append(matched, document)
+		}
+	}
+	collection.store.mu.Unlock()
+
+	if len(sortKeys) > 0 {
+		sort.SliceStable(matched, func(i, j int) bool {
+			for _, key := range sortKeys {
+				a, _ := lookupField(matched[i], key.Key)
+				b, _ := lookupField(matched[j], key.Key)
+				if c := compareValues(a, b); c != 0 {
+					return (c < 0) == (toInt64(key.Value) > 0)
+				}
+			}
+			return false
+		})
+	}
+
+	documents := make([]bson.Raw, 0, len(matched))
+	for _, document := range matched {
+		data, _ := bson.Marshal(document)
+		documents = append(documents, data)
+	}
+	return documents
+}
+
+func (collection *memoryCollection) count(filter bson.M) int64 {
+	return int64(len(collection.find(filter, nil)))
+}
+
+// update applies the $set, $unset, $inc, $addToSet and $pull operators of
+// update to the documents matching filter, at most one unless many is set.
+// It returns how many matched and the last updated document.
+func (collection *memoryCollection) update(filter bson.M, update bson.M, many bool) (int64, bson.Raw, error) {
+	collection.store.mu.Lock()
+	defer collection.store.mu.Unlock()
+
+	var matched int64
+	var last bson.Raw
+	documents := collection.store.collections[collection.name]
+	for i, document := range documents {
+		if !matchDocument(document, filter) {
+			continue
+		}
+
+		updated, err := applyUpdate(document, update)
+		if err != nil {
+			return 0, nil, err
+		}
+		if err := collection.checkUnique(updated, i); err != nil {
+			return 0, nil, err
+		}
+		documents[i] = updated
+		matched++
+		last, _ = bson.Marshal(updated)
+		if !many {
+			break
+		}
+	}
+	return matched, last, nil
+}
+
+func (collection *memoryCollection) delete(filter bson.M, many bool) int64 {
+	collection.store.mu.Lock()
+	defer collection.store.mu.Unlock()
+
+	var deleted int64
+	documents := collection.store.collections[collection.name]
+	kept := make([]bson.M, 0, len(documents))
+	for _, document := range documents {
+		if (many || deleted == 0) && matchDocument(document, filter) {
+			deleted++
+			continue
+		}
+		kept = append(kept, document)
+	}
+	collection.store.collections[collection.name] = kept
+	return deleted
+}
+
+func (collection *memoryCollection) Name() string {
+	return collection.name
+}
+
+func (collection *memoryCollection) Collection(name string) documentStore {
+	return collection.store.collection(name)
+}
+
+func (collection *memoryCollection) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return collection.store.transaction(ctx, fn)
+}
+
+func (collection *memoryCollection) FindOne(ctx context.Context, filter bson.M, projection bson.M, result interface{}) (bool, error) {
+	documents := collection.find(filter, nil)
+	if len(documents) == 0 {
+		return false, nil
+	}
+
+	document := documents[0]
+	if projection != nil {
+		var err error
+		fields := bson.D{}
+		for key, value := range projection {
+			fields = append(fields, bson.E{Key: key, Value: value})
+		}
+		if document, err = projectDocument(document, fields); err != nil {
+			return false, err
+		}
+	}
+	return true, bson.Unmarshal(document, result)
+}
+
+// Find decodes the documents matching filter into results, a pointer to a
+// slice.
+func (collection *memoryCollection) Find(ctx context.Context, filter bson.M, results interface{}) error {
+	slice := reflect.ValueOf(results).Elem()
+	slice.Set(reflect.MakeSlice(slice.Type(), 0, 0))
+	for _, document := range collection.find(filter, nil) {
+		item := reflect.New(slice.Type().Elem())
+		if err := bson.Unmarshal(document, item.Interface()); err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, item.Elem()))
+	}
+	return nil
+}
+
+func (collection *memoryCollection) FindIds(ctx context.Context, filter bson.M) ([]primitive.ObjectID, error) {
+	var ids []primitive.ObjectID
+	for _, document := range collection.find(filter, bson.D{{Key: "_id", Value: 1}}) {
+		if id, ok := document.Lookup("_id").ObjectIDOK(); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (collection *memoryCollection) Count(ctx context.Context, filter bson.M) (int64, error) {
+	return collection.count(filter), nil
+}
+
+func (collection *memoryCollection) InsertOne(ctx context.Context, value interface{}) error {
+	document, err := toMemoryDocument(value)
+	if err != nil {
+		return err
+	}
+	if _, ok := document["_id"]; !ok {
+		document["_id"] = primitive.NewObjectID()
+	}
+
+	collection.store.mu.Lock()
+	defer collection.store.mu.Unlock()
+	if err := collection.checkUnique(document, -1); err != nil {
+		return err
+	}
+	collection.store.collections[collection.name] = append(collection.store.collections[collection.name], document)
+	return nil
+}
+
+// checkUnique fails with a duplicate key error when document shares the
+// value of a unique field with a stored document other than the one at
+// index skip. Like sparse indexes, documents without the field never clash.
+func (collection *memoryCollection) checkUnique(document bson.M, skip int) error {
+	for _, field := range memoryUniqueFields[collection.name] {
+		value, ok := lookupField(document, field)
+		if !ok {
+			continue
+		}
+		for i, existing := range collection.store.collections[collection.name] {
+			if i == skip {
+				continue
+			}
+			if existingValue, exists := lookupField(existing, field); matchEqual(existingValue, exists, value) {
+				return mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "duplicate key error: " + field}}}
+			}
+		}
+	}
+	return nil
+}
+
+func (collection *memoryCollection) UpdateOne(ctx context.Context, filter bson.M, update bson.M) (int64, error) {
+	matched, _, err := collection.update(filter, update, false)
+	return matched, err
+}
+
+func (collection *memoryCollection) UpdateMany(ctx context.Context, filter bson.M, update bson.M) (int64, error) {
+	matched, _, err := collection.update(filter, update, true)
+	return matched, err
+}
+
+func (collection *memoryCollection) FindOneAndUpdate(ctx context.Context, filter bson.M, update bson.M, result interface{}) (bool, error) {
+	matched, document, err := collection.update(filter, update, false)
+	if err != nil || matched == 0 {
+		return false, err
+	}
+	return true, bson.Unmarshal(document, result)
+}
+
+func (collection *memoryCollection) DeleteOne(ctx context.Context, filter bson.M) (int64, error) {
+	return collection.delete(filter, false), nil
+}
+
+// applyUpdate returns a copy of document with the update operators applied,
+// leaving the stored document untouched for readers holding it.
+func applyUpdate(document bson.M, update bson.M) (bson.M, error) {
+	updated, err := toMemoryDocument(document)
+	if err != nil {
+		return nil, err
+	}
+
+	for operator, value := range update {
+		fields, err := toMemoryDocument(value)
+		if err != nil {
+			return nil, err
+		}
+
+		for key, argument := range fields {
+			switch operator {
+			case "$set":
+				updated[key] = argument
+			case "$unset":
+				delete(updated, key)
+			case "$inc":
+				updated[key] = toInt64(updated[key]) + toInt64(argument)
+			case "$addToSet":
+				items, _ := updated[key].(primitive.A)
+				found := false
+				for _, item := range items {
+					found = found || compareValues(item, argument) == 0
+				}
+				if !found {
+					items = append(items, argument)
+				}
+				updated[key] = items
+			case "$pull":
+				items, _ := updated[key].(primitive.A)
+				kept := primitive.A{}
+				for _, item := range items {
+					if compareValues(item, argument) != 0 {
+						kept = append(kept, item)
+					}
+				}
+				updated[key] = kept
+			}
+		}
+	}
+	return updated, nil
+}
+
+// matchDocument evaluates the query operators the repositories build:
+// equality, regular expressions, $eq, $ne, $in, $nin, $all, $elemMatch, $gt,
+// $gte, $lt, $lte, $exists, $and and $or.
+func matchDocument(document bson.M, filter bson.M) bool {
+	for key, condition := range filter {
+		switch key {
+		case "$and":
+			for _, clause := range toArray(condition) {
+				if !matchDocument(document, toMap(clause)) {
+					return false
+				}
+			}
+			continue
+		case "$or":
+			matched := false
+			for _, clause := range toArray(condition) {
+				if matchDocument(document, toMap(clause)) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false
+			}
+			continue
+		}
+
+		value, exists := lookupField(document, key)
+		if operators := toMap(condition); isOperatorMap(operators) {
+			for operator, argument := range operators {
+				if !matchOperator(value, exists, operator, argument) {
+					return false
+				}
+			}
+		} else if !matchEqual(value, exists, condition) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchOperator(value interface{}, exists bool, operator string, argument interface{}) bool {
+	switch operator {
+	case "$eq":
+		return matchEqual(value, exists, argument)
+	case "$ne":
+		return !matchEqual(value, exists, argument)
+	case "$in":
+		for _, item := range toArray(argument) {
+			if matchEqual(value, exists, item) {
+				return true
+			}
+		}
+		return false
+	case "$nin":
+		return !matchOperator(value, exists, "$in", argument)
+	case "$all":
+		for _, item := range toArray(argument) {
+			if !matchEqual(value, exists, item) {
+				return false
+			}
+		}
+		return exists
+	case "$elemMatch":
+		items, _ := value.(primitive.A)
+		for _, item := range items {
+			if document := toMap(item); document != nil && matchDocument(document, toMap(argument)) {
+				return true
+			}
+		}
+		return false
+	case "$exists":
+		wanted, _ := argument.(bool)
+		return exists == wanted
+	case "$gt", "$gte", "$lt", "$lte":
+		argument = normalizeValue(argument)
+		if !exists || typeOrder(value) != typeOrder(argument) {
+			return false
+		}
+		c := compareValues(value, argument)
+		switch operator {
+		case "$gt":
+			return c > 0
+		case "$gte":
+			return c >= 0
+		case "$lt":
+			return c < 0
+		default:
+			return c <= 0
+		}
+	}
+	return false
+}
+
+// matchEqual follows MongoDB: null matches missing fields, and a scalar
+// matches an array holding it.
+func matchEqual(value interface{}, exists bool, target interface{}) bool {
+	target = normalizeValue(target)
+	if target == nil {
+		return !exists || value == nil
+	}
+	if !exists {
+		return false
+	}
+	if pattern, ok := target.(primitive.Regex); ok {
+		return matchRegex(value, pattern)
+	}
+	if items, ok := value.(primitive.A); ok {
+		if _, targetIsArray := target.(primitive.A); !targetIsArray {
+			for _, item := range items {
+				if compareValues(item, target) == 0 {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return typeOrder(value) == typeOrder(target) && compareValues(value, target) == 0
+}
+
+// matchRegex matches strings, or arrays holding one, against pattern.
+func matchRegex(value interface{}, pattern primitive.Regex) bool {
+	if items, ok := value.(primitive.A); ok {
+		for _, item := range items {
+			if matchRegex(item, pattern) {
+				return true
+			}
+		}
+		return false
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return false
+	}
+	expression := pattern.Pattern
+	if len(pattern.Options) > 0 {
+		expression = "(?" + strings.ReplaceAll(pattern.Options, "x", "") + ")" + expression
+	}
+	matched, err := regexp.MatchString(expression, text)
+	return err == nil && matched
+}
+
+func lookupField(document bson.M, path string) (interface{}, bool) {
+	var current interface{} = document
+	for _, key := range strings.Split(path, ".") {
+		node := toMap(current)
+		if node == nil {
+			return nil, false
+		}
+		value, ok := node[key]
+		if !ok {
+			return nil, false
+		}
+		current = value
+	}
+	return current, true
+}
+
+func isOperatorMap(m bson.M) bool {
+	if len(m) == 0 {
+		return false
+	}
+	for key := range m {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+func toMap(value interface{}) bson.M {
+	switch v := value.(type) {
+	case bson.M:
+		return v
+	case map[string]interface{}:
+		return v
+	case bson.D:
+		return v.Map()
+	}
+	return nil
+}
+
+func toArray(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case bson.A:
+		return v
+	case []interface{}:
+		return v
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return items
+	case []bson.M:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return items
+	}
+	return nil
+}
+
+// normalizeValue converts Go values to the types documents hold once decoded.
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		return primitive.NewDateTimeFromTime(v)
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case []string, []interface{}:
+		return primitive.A(toArray(v))
+	case bson.RawValue:
+		var decoded interface{}
+		if err := v.Unmarshal(&decoded); err == nil {
+			return normalizeValue(decoded)
+		}
+	}
+	return value
+}
+
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+func toFloat64(value interface{}) float64 {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+// typeOrder is the BSON comparison order of the type of value.
+func typeOrder(value interface{}) int {
+	switch normalizeValue(value).(type) {
+	case nil:
+		return 0
+	case int64, float64:
+		return 1
+	case string:
+		return 2
+	case bson.M, bson.D, map[string]interface{}:
+		return 3
+	case primitive.A:
+		return 4
+	case primitive.ObjectID:
+		return 5
+	case bool:
+		return 6
+	case primitive.DateTime:
+		return 7
+	}
+	return 8
+}
+
+// compareValues orders values the way MongoDB sorts them.
+func compareValues(a interface{}, b interface{}) int {
+	a, b = normalizeValue(a), normalizeValue(b)
+	if orderA, orderB := typeOrder(a), typeOrder(b); orderA != orderB {
+		return orderA - orderB
+	}
+
+	switch v := a.(type) {
+	case int64, float64:
+		x, y := toFloat64(v), toFloat64(b)
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(v, b.(string))
+	case primitive.ObjectID:
+		other := b.(primitive.ObjectID)
+		return bytes.Compare(v[:], other[:])
+	case bool:
+		if v == b.(bool) {
+			return 0
+		}
+		if !v {
+			return -1
+		}
+		return 1
+	case primitive.DateTime:
+		other := b.(primitive.DateTime)
+		if v < other {
+			return -1
+		}
+		if v > other {
+			return 1
+		}
+		return 0
+	case primitive.A:
+		other := b.(primitive.A)
+		for i := 0; i < len(v) && i < len(other); i++ {
+			if c := compareValues(v[i], other[i]); c != 0 {
+				return c
+			}
+		}
+		return len(v) - len(other)
+	}
+
+	x, _ := bson.Marshal(bson.M{"v": a})
+	y, _ := bson.Marshal(bson.M{"v": b})
+	return bytes.Compare(x, y)
+}
+
+// projectDocument applies an inclusion or exclusion projection to document.
+func projectDocument(document bson.Raw, projection bson.D) (bson.Raw, error) {
+	if len(projection) == 0 {
+		return document, nil
+	}
+
+	var source bson.M
+	if err := bson.Unmarshal(document, &source); err != nil {
+		return nil, err
+	}
+
+	projected := bson.M{}
+	if isInclusion(projection) {
+		if id, ok := source["_id"]; ok {
+			projected["_id"] = id
+		}
+		for _, element := range projection {
+			if toInt64(element.Value) == 0 {
+				delete(projected, element.Key)
+				continue
+			}
+			if value, ok := lookupField(source, element.Key); ok {
+				setField(projected, element.Key, value)
+			}
+		}
+	} else {
+		projected = source
+		for _, element := range projection {
+			deleteField(projected, element.Key)
+		}
+	}
+	return bson.Marshal(projected)
+}
+
+func setField(document bson.M, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		child := toMap(document[key])
+		if child == nil {
+			child = bson.M{}
+			document[key] = child
+		}
+		document = child
+	}
+	document[keys[len(keys)-1]] = value
+}
+
+func deleteField(document bson.M, path string) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		if document = toMap(document[key]); document == nil {
+			return
+		}
+	}
+	delete(document, keys[len(keys)-1])
+}
+
+// expandDocument embeds the related resources named in expand, like the
+// $lookup stages of documentLookups.
+func (collection *memoryCollection) expandDocument(ctx context.Context, document bson.Raw, expand []string) (bson.Raw, error) {
+	if len(expand) == 0 {
+		return document, nil
+	}
+
+	var source bson.M
+	if err := bson.Unmarshal(document, &source); err != nil {
+		return nil, err
+	}
+
+	for _, name := range expand {
+		relation := relations[collection.name][name]
+		local, _ := lookupField(source, relation.LocalField)
+		hidden, _ := documentProjection(relation.Collection, nil, nil)
+
+		var related primitive.A
+		for _, candidate := range collection.store.collection(relation.Collection).find(notDeleted(ctx, bson.M{}), bson.D{{Key: "_id", Value: 1}}) {
+			var foreign bson.M
+			if err := bson.Unmarshal(candidate, &foreign); err != nil {
+				return nil, err
+			}
+			value, _ := lookupField(foreign, relation.ForeignField)
+			if referenceString(value) != referenceString(local) {
+				continue
+			}
+
+			projected, err := projectDocument(candidate, hidden)
+			if err != nil {
+				return nil, err
+			}
+			var item bson.M
+			if err := bson.Unmarshal(projected, &item); err != nil {
+				return nil, err
+			}
+			related = append(related, item)
+		}
+
+		if relation.Many {
+			if related == nil {
+				related = primitive.A{}
+			}
+			source[name] = related
+		} else if len(related) > 0 {
+			source[name] = related[0]
+		}
+	}
+	return bson.Marshal(source)
+}
+
+func referenceString(value interface{}) string {
+	switch v := value.(type) {
+	case primitive.ObjectID:
+		return v.Hex()
+	case string:
+		return v
+	}
+	return ""
+}
+
+// findByCursor mirrors the MongoDB findByCursor on the memory collection.
+func (collection *memoryCollection) findByCursor(ctx context.Context, filter bson.M, projection bson.D, query *model.CursorQuery, expand []string, sortFields ...string) ([]bson.Raw, *model.CursorInfo, error) {
+	plan, err := newCursorPlan(filter, query, sortFields...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	documents := collection.find(plan.Filter, plan.Sort)
+	if int64(len(documents)) > plan.Limit+1 {
+		documents = documents[:plan.Limit+1]
+	}
+	projection = plan.projection(projection)
+	for i, document := range documents {
+		if document, err = collection.expandDocument(ctx, document, expand); err != nil {
+			return nil, nil, err
+		}
+		if documents[i], err = projectDocument(document, projection); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	documents, info, err := plan.page(documents, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !query.SkipCount {
+		total := collection.count(filter)
+		info.Total = &total
+	}
+	return documents, info, nil
+}
+
+// findPage returns the page of documents matching filter in _id order, with
+// the same pagination data as mongo-go-pagination.
+func (collection *memoryCollection) findPage(filter bson.M, projection bson.D, page int64, limit int64) ([]bson.Raw, *paginate.PaginationData, error) {
+	return collection.findSortedPage(filter, projection, bson.D{{Key: "_id", Value: 1}}, page, limit)
+}
+
+// findSortedPage returns the page of documents matching filter in the order
+// of sortKeys.
+func (collection *memoryCollection) findSortedPage(filter bson.M, projection bson.D, sortKeys bson.D, page int64, limit int64) ([]bson.Raw, *paginate.PaginationData, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	documents := collection.find(filter, sortKeys)
+	total := int64(len(documents))
+
+	start, end := (page-1)*limit, page*limit
+	if start > total {
+		start = total
+	}
+	if end > total {
+		end = total
+	}
+
+	paged := make([]bson.Raw, 0, end-start)
+	for _, document := range documents[start:end] {
+		projected, err := projectDocument(document, projection)
+		if err != nil {
+			return nil, nil, err
+		}
+		paged = append(paged, projected)
+	}
+	return paged, paginationData(total, page, limit), nil
+}
+
+// findDocument mirrors the MongoDB findDocument on the memory collection.
+func (collection *memoryCollection) findDocument(ctx context.Context, filter bson.M, query *model.DocumentQuery) (model.Document, error) {
+	projection, err := documentProjection(collection.name, query.Fields, query.Expand)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := documentLookups(ctx, collection.name, query.Expand); err != nil {
+		return nil, err
+	}
+
+	documents := collection.find(filter, nil)
+	if len(documents) == 0 {
+		return nil, nil
+	}
+
+	document, err := collection.expandDocument(ctx, documents[0], query.Expand)
+	if err != nil {
+		return nil, err
+	}
+	if document, err = projectDocument(document, projection); err != nil {
+		return nil, err
+	}
+	return toDocument(document)
+}
+
+// findDocuments mirrors the MongoDB findDocuments on the memory collection.
+func (collection *memoryCollection) findDocuments(ctx context.Context, filter bson.M, query *model.DocumentQuery, sortFields ...string) (*model.PagedDocument, error) {
+	projection, err := documentProjection(collection.name, query.Fields, query.Expand)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := documentLookups(ctx, collection.name, query.Expand); err != nil {
+		return nil, err
+	}
+
+	if query.Cursor != nil {
+		documents, cursorInfo, err := collection.findByCursor(ctx, filter, projection, query.Cursor, query.Expand, sortFields...)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := toDocuments(documents)
+		if err != nil {
+			return nil, err
+		}
+		return &model.PagedDocument{Data: data, Cursor: cursorInfo}, nil
+	}
+
+	documents, pageInfo, err := collection.findPage(filter, nil, query.Page, query.Limit)
+	if err != nil {
+		return nil, err
+	}
+	for i, document := range documents {
+		if document, err = collection.expandDocument(ctx, document, query.Expand); err != nil {
+			return nil, err
+		}
+		if documents[i], err = projectDocument(document, projection); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := toDocuments(documents)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PagedDocument{Data: data, PageInfo: pageInfo}, nil
+}
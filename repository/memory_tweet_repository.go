@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryTweetRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryTweetRepository(Store *MemoryStore) TweetRepository {
+	return &memoryTweetRepository{Store: Store}
+}
+
+func (tweetRepository *memoryTweetRepository) GetAllTweets(ctx context.Context, page int64, limit int64, userId string) (*model.PagedTweet, error) {
+	filter := notDeleted(ctx, bson.M{"userId": userId})
+
+	documents, pageInfo, err := tweetRepository.Store.collection("tweets").findPage(filter, tweetProjection, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	tweets, err := decodeTweets(documents)
+	if err != nil {
+		return nil, err
+	}
+	if tweets == nil {
+		return nil, exception.FilteredResourcesNotFoundException("Tweets", "userId", userId)
+	}
+	return &model.PagedTweet{
+		Data:     tweets,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+func (tweetRepository *memoryTweetRepository) GetAllTweetsByCursor(ctx context.Context, query *model.CursorQuery, userId string) (*model.PagedTweet, error) {
+	filter := notDeleted(ctx, bson.M{"userId": userId})
+
+	documents, cursorInfo, err := tweetRepository.Store.collection("tweets").findByCursor(ctx, filter, tweetProjection, query, nil, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	tweets, err := decodeTweets(documents)
+	if err != nil {
+		return nil, err
+	}
+	if tweets == nil {
+		tweets = []model.Tweet{}
+	}
+	return &model.PagedTweet{
+		Data:   tweets,
+		Cursor: cursorInfo,
+	}, nil
+}
+
+func (tweetRepository *memoryTweetRepository) GetAllTweetDocuments(ctx context.Context, query *model.DocumentQuery, userId string) (*model.PagedDocument, error) {
+	filter := notDeleted(ctx, bson.M{"userId": userId})
+
+	return tweetRepository.Store.collection("tweets").findDocuments(ctx, filter, query, "created_at")
+}
+
+func (tweetRepository *memoryTweetRepository) GetTweet(ctx context.Context, id string) (*model.Tweet, error) {
+	var tweet model.Tweet
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	found, err := tweetRepository.Store.collection("tweets").FindOne(ctx, filter, nil, &tweet)
+	if err != nil || !found {
+		return nil, exception.ResourceNotFoundException("Tweet", "id", id)
+	}
+	return &tweet, nil
+}
+
+func (tweetRepository *memoryTweetRepository) GetTweetDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	document, err := tweetRepository.Store.collection("tweets").findDocument(ctx, filter, query)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, exception.ResourceNotFoundException("Tweet", "id", id)
+	}
+	return document, nil
+}
+
+func (tweetRepository *memoryTweetRepository) SaveTweet(ctx context.Context, tweet *model.Tweet) (*model.Tweet, error) {
+	return insertTweet(ctx, tweetRepository.Store.collection("tweets"), tweet)
+}
+
+func (tweetRepository *memoryTweetRepository) DeleteTweet(ctx context.Context, id string, userId string) error {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := bson.M{"_id": objectId, "userId": userId}
+
+	deleted, err := deleteTweet(ctx, tweetRepository.Store.collection("tweets"), filter)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return exception.TweetNotFoundException("Tweet", "id", id, "userId", userId)
+	}
+
+	return nil
+}
+
+func (tweetRepository *memoryTweetRepository) RestoreTweet(ctx context.Context, id string) (*model.Tweet, error) {
+	restored, err := restoreTweet(ctx, tweetRepository.Store.collection("tweets"), id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, exception.ResourceNotFoundException("Tweet", "id", id)
+	}
+
+	return tweetRepository.GetTweet(ctx, id)
+}
+
+func (tweetRepository *memoryTweetRepository) GetTimeline(ctx context.Context, userId string, query *model.CursorQuery) (*model.PagedTweet, error) {
+	ids, err := followingIds(ctx, tweetRepository.Store.collection("follows"), userId)
+	if err != nil {
+		return nil, err
+	}
+	filter := notDeleted(ctx, bson.M{"userId": bson.M{"$in": ids}})
+
+	return tweetRepository.findTweets(ctx, filter, query)
+}
+
+func (tweetRepository *memoryTweetRepository) GetConversation(ctx context.Context, id string, query *model.CursorQuery) (*model.PagedTweet, error) {
+	tweet, err := findTweet(ctx, tweetRepository.Store.collection("tweets"), id)
+	if err != nil {
+		return nil, err
+	}
+
+	return tweetRepository.findTweets(ctx, conversationFilter(ctx, tweet), query)
+}
+
+func (tweetRepository *memoryTweetRepository) GetHashtagTweets(ctx context.Context, tag string, query *model.CursorQuery) (*model.PagedTweet, error) {
+	return tweetRepository.findTweets(ctx, hashtagFilter(ctx, tag), query)
+}
+
+func (tweetRepository *memoryTweetRepository) GetMentionTweets(ctx context.Context, userId string, query *model.CursorQuery) (*model.PagedTweet, error) {
+	return tweetRepository.findTweets(ctx, mentionFilter(ctx, userId), query)
+}
+
+func (tweetRepository *memoryTweetRepository) LikeTweet(ctx context.Context, id string, userId string) (*model.Tweet, error) {
+	return likeTweet(ctx, tweetRepository.Store.collection("tweets"), id, userId)
+}
+
+func (tweetRepository *memoryTweetRepository) UnlikeTweet(ctx context.Context, id string, userId string) (*model.Tweet, error) {
+	return unlikeTweet(ctx, tweetRepository.Store.collection("tweets"), id, userId)
+}
+
+func (tweetRepository *memoryTweetRepository) Retweet(ctx context.Context, id string, userId string) (*model.Tweet, error) {
+	return insertRetweet(ctx, tweetRepository.Store.collection("tweets"), id, userId)
+}
+
+func (tweetRepository *memoryTweetRepository) Unretweet(ctx context.Context, id string, userId string) error {
+	return deleteRetweet(ctx, tweetRepository.Store.collection("tweets"), id, userId)
+}
+
+func (tweetRepository *memoryTweetRepository) findTweets(ctx context.Context, filter bson.M, query *model.CursorQuery) (*model.PagedTweet, error) {
+	documents, cursorInfo, err := tweetRepository.Store.collection("tweets").findByCursor(ctx, filter, tweetProjection, query, nil, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	tweets, err := decodeTweets(documents)
+	if err != nil {
+		return nil, err
+	}
+	if tweets == nil {
+		tweets = []model.Tweet{}
+	}
+	return &model.PagedTweet{
+		Data:   tweets,
+		Cursor: cursorInfo,
+	}, nil
+}
+
+func decodeTweets(documents []bson.Raw) ([]model.Tweet, error) {
+	var tweets []model.Tweet
+	for _, document := range documents {
+		var tweet model.Tweet
+		if err := bson.Unmarshal(document, &tweet); err != nil {
+			return nil, err
+		}
+		tweets = append(tweets, tweet)
+	}
+	return tweets, nil
+}
@@ -0,0 +1,17 @@
+package repository
+
+import "context"
+
+type memoryUnitOfWork struct {
+	Store *MemoryStore
+}
+
+func NewMemoryUnitOfWork(Store *MemoryStore) UnitOfWork {
+	return &memoryUnitOfWork{Store: Store}
+}
+
+// Do runs fn in a MemoryStore transaction: every change fn makes is undone
+// when it fails.
+func (unitOfWork *memoryUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return unitOfWork.Store.transaction(ctx, fn)
+}
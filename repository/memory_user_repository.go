@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type memoryUserRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryUserRepository(Store *MemoryStore) UserRepository {
+	return &memoryUserRepository{Store: Store}
+}
+
+func (userRepository *memoryUserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	var existingUser model.User
+	filter := notDeleted(ctx, bson.M{"email": email})
+	found, err := userRepository.Store.collection("users").FindOne(ctx, filter, nil, &existingUser)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &existingUser, nil
+}
+
+func (userRepository *memoryUserRepository) GetAllUser(ctx context.Context, page int64, limit int64) (*model.PagedUser, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	documents, pageInfo, err := userRepository.Store.collection("users").findPage(filter, userProjection, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := decodeUsers(documents)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PagedUser{
+		Data:     users,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+func (userRepository *memoryUserRepository) GetAllUserByCursor(ctx context.Context, query *model.CursorQuery) (*model.PagedUser, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	documents, cursorInfo, err := userRepository.Store.collection("users").findByCursor(ctx, filter, userProjection, query, nil, "name", "lastname", "email", "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := decodeUsers(documents)
+	if err != nil {
+		return nil, err
+	}
+	if users == nil {
+		users = []model.User{}
+	}
+	return &model.PagedUser{
+		Data:   users,
+		Cursor: cursorInfo,
+	}, nil
+}
+
+func (userRepository *memoryUserRepository) GetAllUserDocuments(ctx context.Context, query *model.DocumentQuery) (*model.PagedDocument, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	return userRepository.Store.collection("users").findDocuments(ctx, filter, query, "name", "lastname", "email", "created_at")
+}
+
+func (userRepository *memoryUserRepository) GetUser(ctx context.Context, id string) (*model.User, error) {
+	var user model.User
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	found, err := userRepository.Store.collection("users").FindOne(ctx, filter, nil, &user)
+	if err != nil || !found {
+		return nil, exception.ResourceNotFoundException("User", "id", id)
+	}
+
+	user.Password = ""
+	return &user, nil
+}
+
+func (userRepository *memoryUserRepository) GetUserDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	document, err := userRepository.Store.collection("users").findDocument(ctx, filter, query)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, exception.ResourceNotFoundException("User", "id", id)
+	}
+	return document, nil
+}
+
+func (userRepository *memoryUserRepository) SaveUser(ctx context.Context, user *model.User) (*model.User, error) {
+	user.ID = primitive.NewObjectID()
+	user.Version = 1
+	user.Normalize()
+
+	err := userRepository.Store.collection("users").InsertOne(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, userConflict(ctx, userRepository.Store.collection("users"), user)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+func (userRepository *memoryUserRepository) UpdateUser(ctx context.Context, id string, user *model.User) (*model.User, error) {
+	return userRepository.updateUser(ctx, id, userUpdateFields(user))
+}
+
+func (userRepository *memoryUserRepository) PatchUser(ctx context.Context, id string, user *model.User) (*model.User, error) {
+	return userRepository.updateUser(ctx, id, userPatchFields(user))
+}
+
+func (userRepository *memoryUserRepository) UpdatePassword(ctx context.Context, id string, password string) error {
+	return updatePassword(ctx, userRepository.Store.collection("users"), id, password)
+}
+
+func (userRepository *memoryUserRepository) SetCalendarToken(ctx context.Context, id string, token string) error {
+	return setCalendarToken(ctx, userRepository.Store.collection("users"), id, token)
+}
+
+func (userRepository *memoryUserRepository) FindByCalendarToken(ctx context.Context, token string) (*model.User, error) {
+	return findByCalendarToken(ctx, userRepository.Store.collection("users"), token)
+}
+
+func (userRepository *memoryUserRepository) updateUser(ctx context.Context, id string, fields bson.M) (*model.User, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": bson.M{"$eq": objectId}})
+
+	var updated model.User
+	found, err := updateDocument(ctx, userRepository.Store.collection("users"), filter, fields, &updated)
+	if mongo.IsDuplicateKeyError(err) {
+		username, _ := fields["username"].(string)
+		return nil, exception.ConflictException("User", "username", username)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, exception.ResourceNotFoundException("User", "id", id)
+	}
+
+	updated.Password = ""
+	return &updated, nil
+}
+
+func (userRepository *memoryUserRepository) DeleteUser(ctx context.Context, id string) error {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := bson.M{"_id": objectId}
+
+	deleted, err := deleteDocument(ctx, userRepository.Store.collection("users"), filter)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return exception.ResourceNotFoundException("User", "id", id)
+	}
+
+	return nil
+}
+
+func (userRepository *memoryUserRepository) RestoreUser(ctx context.Context, id string) (*model.User, error) {
+	restored, err := restoreDocument(ctx, userRepository.Store.collection("users"), id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, exception.ResourceNotFoundException("User", "id", id)
+	}
+
+	return userRepository.GetUser(ctx, id)
+}
+
+func decodeUsers(documents []bson.Raw) ([]model.User, error) {
+	var users []model.User
+	for _, document := range documents {
+		var user model.User
+		if err := bson.Unmarshal(document, &user); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
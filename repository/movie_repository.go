@@ -2,21 +2,31 @@ package repository
 
 import (
 	"context"
-	"fmt"
 	"github.com/cbuelvasc/cinema-backend/exception"
 	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/util"
 	paginate "github.com/gobeam/mongo-go-pagination"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"regexp"
+	"strings"
 )
 
 type MovieRepository interface {
-	GetAllMovies(ctx context.Context, page int64, limit int64) (*model.PagedMovie, error)
+	GetAllMovies(ctx context.Context, page int64, limit int64, filter *model.MovieFilter) (*model.PagedMovie, error)
+	GetAllMoviesByCursor(ctx context.Context, query *model.CursorQuery, filter *model.MovieFilter) (*model.PagedMovie, error)
+	GetAllMovieDocuments(ctx context.Context, query *model.DocumentQuery, filter *model.MovieFilter) (*model.PagedDocument, error)
 	GetMovie(ctx context.Context, id string) (*model.Movie, error)
+	GetMovieDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error)
+	GetMovieByTitle(ctx context.Context, title string, releaseYear int) (*model.Movie, error)
 	SaveMovie(ctx context.Context, movie *model.Movie) (*model.Movie, error)
 	UpdateMovie(ctx context.Context, id string, movie *model.Movie) (*model.Movie, error)
+	PatchMovie(ctx context.Context, id string, movie *model.Movie) (*model.Movie, error)
 	DeleteMovie(ctx context.Context, id string, movieId string) error
+	RestoreMovie(ctx context.Context, id string) (*model.Movie, error)
+	SaveMovieTranslation(ctx context.Context, id string, translation *model.MovieTranslation) (*model.Movie, error)
+	DeleteMovieTranslation(ctx context.Context, id string, locale string) (*model.Movie, error)
 }
 
 type movieRepositoryImpl struct {
@@ -27,40 +37,162 @@ func NewMovieRepository(Connection *mongo.Database) MovieRepository {
 	return &movieRepositoryImpl{Connection: Connection}
 }
 
-func (movieRepository *movieRepositoryImpl) GetAllMovies(ctx context.Context, page int64, limit int64) (*model.PagedMovie, error) {
-	var movies []model.Movie
+var movieProjection = bson.D{
+	{"id", 1},
+	{"title", 1},
+	{"format", 1},
+	{"releaseYear", 1},
+	{"releaseMonth", 1},
+	{"releaseDay", 1},
+	{"releaseDate", 1},
+	{"runtime", 1},
+	{"genres", 1},
+	{"ratings", 1},
+	{"synopsis", 1},
+	{"tagline", 1},
+	{"languages", 1},
+	{"subtitles", 1},
+	{"cast", 1},
+	{"crew", 1},
+	{"trailerUrl", 1},
+	{"posterUrl", 1},
+	{"translations", 1},
+	{"score", 1},
+	{"version", 1},
+	{"deleted_at", 1},
+	{"deleted_by", 1},
+}
 
-	filter := bson.M{
+// movieListFilter returns the query of the movies filter lets through. Names
+// match any part of the name of a cast or crew member, in any case.
+func movieListFilter(ctx context.Context, filter *model.MovieFilter) bson.M {
+	query := bson.M{}
+	if filter == nil {
+		return notDeleted(ctx, query)
+	}
+
+	if len(filter.Format) > 0 {
+		query["format"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(filter.Format) + "$", Options: "i"}
+	}
+	if len(filter.Genres) > 0 {
+		genres := bson.A{}
+		for _, genre := range filter.Genres {
+			genres = append(genres, strings.ToLower(genre))
+		}
+		query["genres"] = bson.M{"$all": genres}
+	}
+	if len(filter.Language) > 0 {
+		query["languages"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(filter.Language) + "(-|$)", Options: "i"}
+	}
+	if len(filter.Subtitle) > 0 {
+		query["subtitles"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(filter.Subtitle) + "(-|$)", Options: "i"}
+	}
+	if len(filter.RatingCountry) > 0 || len(filter.Rating) > 0 {
+		rating := bson.M{}
+		if len(filter.RatingCountry) > 0 {
+			rating["country"] = strings.ToUpper(filter.RatingCountry)
+		}
+		if len(filter.Rating) > 0 {
+			rating["rating"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(filter.Rating) + "$", Options: "i"}
+		}
+		query["ratings"] = bson.M{"$elemMatch": rating}
 	}
+	if len(filter.Cast) > 0 {
+		query["cast"] = bson.M{"$elemMatch": bson.M{"name": primitive.Regex{Pattern: regexp.QuoteMeta(filter.Cast), Options: "i"}}}
+	}
+	if len(filter.Crew) > 0 || len(filter.CrewJob) > 0 {
+		member := bson.M{}
+		if len(filter.Crew) > 0 {
+			member["name"] = primitive.Regex{Pattern: regexp.QuoteMeta(filter.Crew), Options: "i"}
+		}
+		if len(filter.CrewJob) > 0 {
+			member["job"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(filter.CrewJob) + "$", Options: "i"}
+		}
+		query["crew"] = bson.M{"$elemMatch": member}
+	}
+	if filter.MinRuntime > 0 || filter.MaxRuntime > 0 {
+		runtime := bson.M{}
+		if filter.MinRuntime > 0 {
+			runtime["$gte"] = filter.MinRuntime
+		}
+		if filter.MaxRuntime > 0 {
+			runtime["$lte"] = filter.MaxRuntime
+		}
+		query["runtime"] = runtime
+	}
+	if filter.ReleaseYear > 0 {
+		query["releaseYear"] = filter.ReleaseYear
+	}
+	if filter.ReleasedAfter != nil || filter.ReleasedBefore != nil {
+		released := bson.M{}
+		if filter.ReleasedAfter != nil {
+			released["$gte"] = filter.ReleasedAfter.Time()
+		}
+		if filter.ReleasedBefore != nil {
+			released["$lte"] = filter.ReleasedBefore.Time()
+		}
+		query["releaseDate"] = released
+	}
+	return notDeleted(ctx, query)
+}
+
+func (movieRepository *movieRepositoryImpl) GetAllMovies(ctx context.Context, page int64, limit int64, movieFilter *model.MovieFilter) (*model.PagedMovie, error) {
+	var movies []model.Movie
+
+	filter := movieListFilter(ctx, movieFilter)
 
 	collection := movieRepository.Connection.Collection("movies")
 
-	projection := bson.D{
-		{"id", 1},
-		{"title", 1},
-		{"format", 1},
-		{"releaseYear", 1},
-		{"releaseMonth", 1},
-		{"releaseDay", 1},
+	paginatedData, err := paginate.New(collection).Context(ctx).Limit(limit).Page(page).Select(movieProjection).Filter(filter).Decode(&movies).Find()
+	if err != nil {
+		return nil, err
 	}
 
-	paginatedData, err := paginate.New(collection).Context(ctx).Limit(limit).Page(page).Select(projection).Filter(filter).Decode(&movies).Find()
+	return &model.PagedMovie{
+		Data:     movies,
+		PageInfo: &paginatedData.Pagination,
+	}, nil
+}
+
+func (movieRepository *movieRepositoryImpl) GetAllMoviesByCursor(ctx context.Context, query *model.CursorQuery, movieFilter *model.MovieFilter) (*model.PagedMovie, error) {
+	filter := movieListFilter(ctx, movieFilter)
+
+	collection := movieRepository.Connection.Collection("movies")
+
+	documents, cursorInfo, err := findByCursor(ctx, collection, filter, movieProjection, query, nil, "title", "format", "releaseYear", "created_at")
 	if err != nil {
 		return nil, err
 	}
 
+	movies := make([]model.Movie, 0, len(documents))
+	for _, document := range documents {
+		var movie model.Movie
+		if err := bson.Unmarshal(document, &movie); err != nil {
+			return nil, err
+		}
+		movies = append(movies, movie)
+	}
+
 	return &model.PagedMovie{
-		Data:     movies,
-		PageInfo: paginatedData.Pagination,
+		Data:   movies,
+		Cursor: cursorInfo,
 	}, nil
 }
 
+func (movieRepository *movieRepositoryImpl) GetAllMovieDocuments(ctx context.Context, query *model.DocumentQuery, movieFilter *model.MovieFilter) (*model.PagedDocument, error) {
+	filter := movieListFilter(ctx, movieFilter)
+
+	collection := movieRepository.Connection.Collection("movies")
+
+	return findDocuments(ctx, collection, filter, query, "title", "format", "releaseYear", "created_at")
+}
+
 func (movieRepository *movieRepositoryImpl) GetMovie(ctx context.Context, id string) (*model.Movie, error) {
 	var movie model.Movie
 	objectId, _ := primitive.ObjectIDFromHex(id)
-	filter := bson.M{
+	filter := notDeleted(ctx, bson.M{
 		"_id": objectId,
-	}
+	})
 
 	err := movieRepository.Connection.Collection("movies").FindOne(ctx, filter).Decode(&movie)
 	if err != nil {
@@ -69,8 +201,39 @@ func (movieRepository *movieRepositoryImpl) GetMovie(ctx context.Context, id str
 	return &movie, nil
 }
 
+func (movieRepository *movieRepositoryImpl) GetMovieDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{
+		"_id": objectId,
+	})
+
+	document, err := findDocument(ctx, movieRepository.Connection.Collection("movies"), filter, query)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, exception.ResourceNotFoundException("Movie", "id", id)
+	}
+	return document, nil
+}
+
+func (movieRepository *movieRepositoryImpl) GetMovieByTitle(ctx context.Context, title string, releaseYear int) (*model.Movie, error) {
+	var movie model.Movie
+	filter := notDeleted(ctx, bson.M{"title": title, "releaseYear": releaseYear})
+
+	err := movieRepository.Connection.Collection("movies").FindOne(ctx, filter).Decode(&movie)
+	if err != nil {
+		return nil, exception.ResourceNotFoundException("Movie", "title", title)
+	}
+	return &movie, nil
+}
+
 func (movieRepository *movieRepositoryImpl) SaveMovie(ctx context.Context, movie *model.Movie) (*model.Movie, error) {
 	movie.ID = primitive.NewObjectID()
+	movie.Version = 1
+	movie.SearchTitle = util.NormalizeText(movie.Title)
+	movie.SearchWords = util.SearchWords(movie.Title)
+	util.NormalizeMovie(movie.MovieInput)
 
 	_, err := movieRepository.Connection.Collection("movies").InsertOne(ctx, movie)
 	if err != nil {
@@ -80,55 +243,242 @@ func (movieRepository *movieRepositoryImpl) SaveMovie(ctx context.Context, movie
 	return movie, nil
 }
 
-func (movieRepository *movieRepositoryImpl) UpdateMovie(ctx context.Context, id string, movie *model.Movie) (*model.Movie, error) {
-	objectId, _ := primitive.ObjectIDFromHex(id)
+// movieUpdateFields returns the fields an update writes: only the ones set in
+// movie.
+func movieUpdateFields(movie *model.Movie) bson.M {
+	util.NormalizeMovie(movie.MovieInput)
 
-	registry := make(map[string]interface{})
+	fields := bson.M{}
 	if len(movie.Title) > 0 {
-		registry["title"] = movie.Title
+		fields["title"] = movie.Title
+		fields["searchTitle"] = util.NormalizeText(movie.Title)
+		fields["searchWords"] = util.SearchWords(movie.Title)
 	}
 	if len(movie.Format) > 0 {
-		registry["format"] = movie.Format
+		fields["format"] = movie.Format
+	}
+	if len(movie.Synopsis) > 0 {
+		fields["synopsis"] = movie.Synopsis
+	}
+	if len(movie.Tagline) > 0 {
+		fields["tagline"] = movie.Tagline
+	}
+	if movie.ReleaseDate != nil {
+		fields["releaseDate"] = movie.ReleaseDate
+	}
+	if movie.Runtime > 0 {
+		fields["runtime"] = movie.Runtime
+	}
+	if len(movie.Genres) > 0 {
+		fields["genres"] = movie.Genres
+	}
+	if len(movie.Ratings) > 0 {
+		fields["ratings"] = movie.Ratings
+	}
+	if len(movie.Languages) > 0 {
+		fields["languages"] = movie.Languages
+	}
+	if len(movie.Subtitles) > 0 {
+		fields["subtitles"] = movie.Subtitles
 	}
-	registry["releaseYear"] = movie.ReleaseYear
-	registry["releaseMonth"] = movie.ReleaseMonth
-	registry["releaseDay"] = movie.ReleaseDay
+	if len(movie.Cast) > 0 {
+		fields["cast"] = movie.Cast
+	}
+	if len(movie.Crew) > 0 {
+		fields["crew"] = movie.Crew
+	}
+	if len(movie.TrailerUrl) > 0 {
+		fields["trailerUrl"] = movie.TrailerUrl
+	}
+	if len(movie.PosterUrl) > 0 {
+		fields["posterUrl"] = movie.PosterUrl
+	}
+	fields["releaseYear"] = movie.ReleaseYear
+	fields["releaseMonth"] = movie.ReleaseMonth
+	fields["releaseDay"] = movie.ReleaseDay
+	return fields
+}
+
+func (movieRepository *movieRepositoryImpl) UpdateMovie(ctx context.Context, id string, movie *model.Movie) (*model.Movie, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+
+	registry := movieUpdateFields(movie)
 
-	filter := bson.M{"_id": bson.M{"$eq": objectId}}
+	filter := notDeleted(ctx, bson.M{"_id": bson.M{"$eq": objectId}})
 
-	updateString := bson.M{
-		"$set": registry,
+	var updated model.Movie
+	found, err := updateDocument(ctx, mongoCollection(movieRepository.Connection, "movies"), filter, registry, &updated)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, exception.ResourceNotFoundException("Movie", "id", id)
 	}
 
-	result, err := movieRepository.Connection.Collection("movies").UpdateOne(ctx, filter, updateString)
+	return &updated, nil
+}
+
+// moviePatchFields returns the fields a patch writes: every patchable field of
+// movie, so fields cleared by the patch are cleared in the stored document too.
+func moviePatchFields(movie *model.Movie) bson.M {
+	util.NormalizeMovie(movie.MovieInput)
+
+	fields := bson.M{
+		"title":        movie.Title,
+		"searchTitle":  util.NormalizeText(movie.Title),
+		"searchWords":  util.SearchWords(movie.Title),
+		"format":       movie.Format,
+		"releaseYear":  movie.ReleaseYear,
+		"releaseMonth": movie.ReleaseMonth,
+		"releaseDay":   movie.ReleaseDay,
+		"releaseDate":  movie.ReleaseDate,
+		"runtime":      movie.Runtime,
+		"genres":       movie.Genres,
+		"ratings":      movie.Ratings,
+		"synopsis":     movie.Synopsis,
+		"tagline":      movie.Tagline,
+		"languages":    movie.Languages,
+		"subtitles":    movie.Subtitles,
+		"cast":         movie.Cast,
+		"crew":         movie.Crew,
+		"trailerUrl":   movie.TrailerUrl,
+		"posterUrl":    movie.PosterUrl,
+	}
+	return fields
+}
+
+func (movieRepository *movieRepositoryImpl) PatchMovie(ctx context.Context, id string, movie *model.Movie) (*model.Movie, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+
+	registry := moviePatchFields(movie)
+
+	filter := notDeleted(ctx, bson.M{"_id": bson.M{"$eq": objectId}})
+
+	var updated model.Movie
+	found, err := updateDocument(ctx, mongoCollection(movieRepository.Connection, "movies"), filter, registry, &updated)
 	if err != nil {
 		return nil, err
 	}
-	if result.MatchedCount == 0 {
+	if !found {
 		return nil, exception.ResourceNotFoundException("Movie", "id", id)
 	}
 
-	movie.ID = objectId
-	return movie, nil
+	return &updated, nil
 }
 
 func (movieRepository *movieRepositoryImpl) DeleteMovie(ctx context.Context, id string, movieId string) error {
 	objectId, _ := primitive.ObjectIDFromHex(id)
 	filter := bson.M{
-		"_id":    objectId,
-		"movieId": movieId,
+		"_id": objectId,
 	}
 
-	result, err := movieRepository.Connection.Collection("movies").DeleteOne(ctx, filter)
+	deleted, err := deleteDocument(ctx, mongoCollection(movieRepository.Connection, "movies"), filter)
 	if err != nil {
 		return err
 	}
 
-	if result.DeletedCount == 0 {
-		return exception.NotFoundRequestException(fmt.Sprintf("Movie not found with id: %s and movieId: %s", id, movieId))
+	if !deleted {
+		return exception.ResourceNotFoundException("Movie", "id", id)
 	}
 
 	return nil
 }
 
+func (movieRepository *movieRepositoryImpl) RestoreMovie(ctx context.Context, id string) (*model.Movie, error) {
+	restored, err := restoreDocument(ctx, mongoCollection(movieRepository.Connection, "movies"), id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, exception.ResourceNotFoundException("Movie", "id", id)
+	}
+
+	return movieRepository.GetMovie(ctx, id)
+}
 
+func (movieRepository *movieRepositoryImpl) SaveMovieTranslation(ctx context.Context, id string, translation *model.MovieTranslation) (*model.Movie, error) {
+	return updateMovieTranslations(ctx, mongoCollection(movieRepository.Connection, "movies"), id, func(translations []model.MovieTranslation) ([]model.MovieTranslation, error) {
+		return putMovieTranslation(translations, translation), nil
+	})
+}
+
+func (movieRepository *movieRepositoryImpl) DeleteMovieTranslation(ctx context.Context, id string, locale string) (*model.Movie, error) {
+	return updateMovieTranslations(ctx, mongoCollection(movieRepository.Connection, "movies"), id, func(translations []model.MovieTranslation) ([]model.MovieTranslation, error) {
+		updated, removed := removeMovieTranslation(translations, locale)
+		if !removed {
+			return nil, exception.ResourceNotFoundException("Translation", "locale", locale)
+		}
+		return updated, nil
+	})
+}
+
+// updateMovieTranslations replaces the translations of a movie with the ones
+// change returns, along with their normalized titles and words for search.
+// The translations are written over the version they were read from, unless
+// the request expects another one.
+func updateMovieTranslations(ctx context.Context, collection documentStore, id string, change func([]model.MovieTranslation) ([]model.MovieTranslation, error)) (*model.Movie, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	var movie model.Movie
+	found, err := collection.FindOne(ctx, filter, nil, &movie)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, exception.ResourceNotFoundException("Movie", "id", id)
+	}
+
+	translations, err := change(movie.Translations)
+	if err != nil {
+		return nil, err
+	}
+
+	searchTranslations := make([]string, 0, len(translations))
+	titles := make([]string, 0, len(translations))
+	for _, translation := range translations {
+		searchTranslations = append(searchTranslations, util.NormalizeText(translation.Title))
+		titles = append(titles, translation.Title)
+	}
+
+	if _, ok := util.GetExpectedVersions(ctx); !ok {
+		ctx = util.WithExpectedVersion(ctx, movie.Version)
+	}
+
+	var updated model.Movie
+	found, err = updateDocument(ctx, collection, filter, bson.M{
+		"translations":       translations,
+		"searchTranslations": searchTranslations,
+		"translationWords":   util.SearchWords(titles...),
+	}, &updated)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, exception.ResourceNotFoundException("Movie", "id", id)
+	}
+	return &updated, nil
+}
+
+// putMovieTranslation adds translation, or replaces the one of its locale.
+func putMovieTranslation(translations []model.MovieTranslation, translation *model.MovieTranslation) []model.MovieTranslation {
+	updated := make([]model.MovieTranslation, 0, len(translations)+1)
+	for _, existing := range translations {
+		if !strings.EqualFold(existing.Locale, translation.Locale) {
+			updated = append(updated, existing)
+		}
+	}
+	return append(updated, *translation)
+}
+
+// removeMovieTranslation removes the translation of locale, reporting whether
+// there was one.
+func removeMovieTranslation(translations []model.MovieTranslation, locale string) ([]model.MovieTranslation, bool) {
+	updated := make([]model.MovieTranslation, 0, len(translations))
+	for _, existing := range translations {
+		if !strings.EqualFold(existing.Locale, locale) {
+			updated = append(updated, existing)
+		}
+	}
+	return updated, len(updated) < len(translations)
+}
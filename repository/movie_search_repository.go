@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MovieSearchRepository searches the movie catalog by title. Controllers only
+// depend on this interface, so an external search engine can replace the
+// MongoDB text index implementation.
+type MovieSearchRepository interface {
+	SearchMovies(ctx context.Context, query *model.MovieSearchQuery) (*model.PagedMovieSearch, error)
+}
+
+const (
+	maxSearchLimit    int64 = 50
+	fuzzyPrefixLength       = 2
+)
+
+type movieSearchRepositoryImpl struct {
+	Connection *mongo.Database
+}
+
+func NewMovieSearchRepository(Connection *mongo.Database) MovieSearchRepository {
+	return &movieSearchRepositoryImpl{Connection: Connection}
+}
+
+type movieSearchResult struct {
+	*model.MovieInput  `bson:",inline"`
+	ID                 primitive.ObjectID       `bson:"_id"`
+	Translations       []model.MovieTranslation `bson:"translations"`
+	SearchTranslations []string                 `bson:"searchTranslations"`
+	Score              float64                  `bson:"score"`
+}
+
+// searchTitles returns the normalized titles of the movie in every locale.
+func (result *movieSearchResult) searchTitles() []string {
+	return append([]string{result.SearchTitle}, result.SearchTranslations...)
+}
+
+func (movieSearchRepository *movieSearchRepositoryImpl) SearchMovies(ctx context.Context, query *model.MovieSearchQuery) (*model.PagedMovieSearch, error) {
+	terms := strings.Fields(util.NormalizeText(query.Text))
+	if len(terms) == 0 {
+		return nil, exception.ParameterException("q")
+	}
+
+	page, limit := query.Page, query.Limit
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	// One more hit than the pages up to this one tells whether there is a next.
+	wanted := page*limit + 1
+
+	filter := notDeleted(ctx, bson.M{})
+	if len(query.Format) > 0 {
+		filter["format"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(query.Format) + "$", Options: "i"}
+	}
+	if query.ReleaseYear > 0 {
+		filter["releaseYear"] = query.ReleaseYear
+	}
+
+	collection := movieSearchRepository.Connection.Collection("movies")
+	hits := map[primitive.ObjectID]*model.MovieSearchHit{}
+
+	// Whole words, ranked by the text index.
+	textFilter := withFilter(filter, bson.M{"$text": bson.M{"$search": strings.Join(terms, " ")}})
+	textOptions := options.Find().
+		SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetSort(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetLimit(wanted)
+	err := findSearchResults(ctx, collection, textFilter, textOptions, func(result *movieSearchResult) {
+		addSearchHit(hits, result, 2+result.Score)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Word prefixes, so titles match while they are being typed.
+	var prefixes bson.A
+	for _, term := range terms {
+		prefixes = append(prefixes, bson.M{"$or": wordPrefixFilters(term)})
+	}
+	err = findSearchResults(ctx, collection, withFilter(filter, bson.M{"$and": prefixes}), options.Find().SetLimit(wanted), func(result *movieSearchResult) {
+		addSearchHit(hits, result, prefixScore(terms, result.searchTitles()))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Typos: every candidate sharing the first letters of a term, ranked by
+	// edit distance.
+	if int64(len(hits)) < wanted {
+		var candidates bson.A
+		for _, term := range terms {
+			if len([]rune(term)) > fuzzyPrefixLength {
+				candidates = append(candidates, wordPrefixFilters(string([]rune(term)[:fuzzyPrefixLength]))...)
+			}
+		}
+		if len(candidates) > 0 {
+			err = findSearchResults(ctx, collection, withFilter(filter, bson.M{"$or": candidates}), options.Find(), func(result *movieSearchResult) {
+				best, found := 0.0, false
+				for _, searchTitle := range result.searchTitles() {
+					if score, ok := fuzzyScore(terms, strings.Fields(searchTitle)); ok && score > best {
+						best, found = score, true
+					}
+				}
+				if found {
+					addSearchHit(hits, result, best)
+				}
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	ranked := make([]model.MovieSearchHit, 0, len(hits))
+	for _, hit := range hits {
+		ranked = append(ranked, *hit)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Title < ranked[j].Title
+	})
+
+	return searchPage(ranked, page, limit), nil
+}
+
+// wordPrefixFilters match the movies with a word of a title starting with
+// prefix. The expressions are anchored, so the indexes of the words of
+// migration 9 bound them.
+func wordPrefixFilters(prefix string) bson.A {
+	pattern := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix)}
+	return bson.A{bson.M{"searchWords": pattern}, bson.M{"translationWords": pattern}}
+}
+
+// searchPage returns the hits of page among the ranked ones, which hold at
+// least one more than the pages up to it when there is a next page.
+func searchPage(ranked []model.MovieSearchHit, page int64, limit int64) *model.PagedMovieSearch {
+	count := int64(len(ranked))
+	start, end := (page-1)*limit, page*limit
+	if start > count {
+		start = count
+	}
+	if end > count {
+		end = count
+	}
+
+	return &model.PagedMovieSearch{
+		Data: ranked[start:end],
+		PageInfo: &model.SearchPageInfo{
+			Page:    page,
+			Limit:   limit,
+			HasNext: count > page*limit,
+			HasPrev: page > 1,
+		},
+	}
+}
+
+func findSearchResults(ctx context.Context, collection *mongo.Collection, filter bson.M, findOptions *options.FindOptions, fn func(result *movieSearchResult)) error {
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var result movieSearchResult
+		if err := cursor.Decode(&result); err != nil {
+			return err
+		}
+		fn(&result)
+	}
+	return cursor.Err()
+}
+
+func addSearchHit(hits map[primitive.ObjectID]*model.MovieSearchHit, result *movieSearchResult, score float64) {
+	if hit, ok := hits[result.ID]; ok {
+		if score > hit.Score {
+			hit.Score = score
+		}
+		return
+	}
+	hits[result.ID] = &model.MovieSearchHit{
+		Movie: &model.Movie{MovieInput: result.MovieInput, ID: result.ID, Translations: result.Translations},
+		Score: score,
+	}
+}
+
+// prefixScore scores titles whose words start with every term above 1, the
+// higher the more of the shortest such title the terms cover.
+func prefixScore(terms []string, searchTitles []string) float64 {
+	length := len(strings.Join(terms, " "))
+	best := 0.0
+	for _, searchTitle := range searchTitles {
+		words := strings.Fields(searchTitle)
+		prefixed := 0
+		for _, term := range terms {
+			for _, word := range words {
+				if strings.HasPrefix(word, term) {
+					prefixed++
+					break
+				}
+			}
+		}
+		if prefixed == len(terms) {
+			if score := 1 + float64(length)/float64(len(searchTitle)+1); score > best {
+				best = score
+			}
+		}
+	}
+	if best == 0 {
+		// The terms are spread over the titles of several locales.
+		best = 1
+	}
+	return best
+}
+
+// fuzzyScore matches every term against the closest title word, allowing one
+// edit for short terms and two for longer ones. The last term may also match
+// the start of a word. The score is in (0, 1], higher meaning fewer edits.
+func fuzzyScore(terms []string, words []string) (float64, bool) {
+	if len(words) == 0 {
+		return 0, false
+	}
+
+	edits, length := 0, 0
+	for i, term := range terms {
+		termRunes := []rune(term)
+		best := -1
+		for _, word := range words {
+			wordRunes := []rune(word)
+			distance := levenshtein(termRunes, wordRunes)
+			if i == len(terms)-1 && len(wordRunes) > len(termRunes) {
+				if prefixDistance := levenshtein(termRunes, wordRunes[:len(termRunes)]); prefixDistance < distance {
+					distance = prefixDistance
+				}
+			}
+			if best < 0 || distance < best {
+				best = distance
+			}
+		}
+
+		allowed := 1
+		if len(termRunes) > 5 {
+			allowed = 2
+		}
+		if best > allowed {
+			return 0, false
+		}
+		edits += best
+		length += len(termRunes)
+	}
+	return 1 - float64(edits)/float64(length+1), true
+}
+
+func levenshtein(a []rune, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
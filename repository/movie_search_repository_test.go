@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "alien", 5},
+		{"alien", "", 5},
+		{"alien", "alien", 0},
+		{"alien", "aliens", 1},
+		{"labirinto", "laberinto", 1},
+		{"kitten", "sitting", 3},
+		{"niño", "nino", 1},
+	}
+	for _, test := range tests {
+		if got := levenshtein([]rune(test.a), []rune(test.b)); got != test.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestPrefixScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		terms  []string
+		titles []string
+		want   float64
+	}{
+		{"whole title", []string{"alien"}, []string{"alien"}, 1 + 5.0/6},
+		{"longer title", []string{"alien"}, []string{"aliens"}, 1 + 5.0/7},
+		{"every term", []string{"lab", "fau"}, []string{"el laberinto del fauno"}, 1 + 7.0/23},
+		{"shortest title of the locales", []string{"lab"}, []string{"el laberinto del fauno", "pan s labyrinth"}, 1 + 3.0/16},
+		{"terms over several locales", []string{"pan", "fau"}, []string{"el laberinto del fauno", "pan s labyrinth"}, 1},
+	}
+	for _, test := range tests {
+		if got := prefixScore(test.terms, test.titles); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%s: prefixScore(%q, %q) = %v, want %v", test.name, test.terms, test.titles, got, test.want)
+		}
+	}
+}
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		name  string
+		terms []string
+		words []string
+		want  float64
+		ok    bool
+	}{
+		{"exact", []string{"alien"}, []string{"alien"}, 1, true},
+		{"transposition is two edits", []string{"alein"}, []string{"alien"}, 0, false},
+		{"one edit allowed in short terms", []string{"alen"}, []string{"alien"}, 1 - 1.0/5, true},
+		{"two edits allowed in long terms", []string{"labirynto"}, []string{"el", "laberinto"}, 1 - 2.0/10, true},
+		{"too many edits", []string{"lubirynto"}, []string{"laberinto"}, 0, false},
+		{"last term as a prefix", []string{"labe"}, []string{"laberinto"}, 1, true},
+		{"only the last term as a prefix", []string{"labe", "fauno"}, []string{"laberinto", "fauno"}, 0, false},
+		{"every term", []string{"labirinto", "fauo"}, []string{"el", "laberinto", "del", "fauno"}, 1 - 2.0/14, true},
+		{"no words", []string{"alien"}, nil, 0, false},
+	}
+	for _, test := range tests {
+		got, ok := fuzzyScore(test.terms, test.words)
+		if ok != test.ok || (ok && math.Abs(got-test.want) > 1e-9) {
+			t.Errorf("%s: fuzzyScore(%q, %q) = %v, %t, want %v, %t", test.name, test.terms, test.words, got, ok, test.want, test.ok)
+		}
+	}
+}
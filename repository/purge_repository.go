@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type PurgeRepository interface {
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
+}
+
+type purgeRepositoryImpl struct {
+	Connection *mongo.Database
+}
+
+func NewPurgeRepository(Connection *mongo.Database) PurgeRepository {
+	return &purgeRepositoryImpl{Connection: Connection}
+}
+
+// PurgeDeleted removes every document soft-deleted before deletedBefore and
+// returns how many were removed.
+func (purgeRepository *purgeRepositoryImpl) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	filter := bson.M{
+		"deleted_at": bson.M{"$lt": deletedBefore},
+	}
+
+	var purged int64
+	for _, name := range softDeleteCollections {
+		result, err := purgeRepository.Connection.Collection(name).DeleteMany(ctx, filter)
+		if err != nil {
+			return purged, err
+		}
+		purged += result.DeletedCount
+	}
+	return purged, nil
+}
@@ -0,0 +1,1253 @@
+// Package repositorytest holds the contract every repository implementation
+// must honour, so that controllers behave the same whatever backs them.
+//
+// Run it from a test of the package under test:
+//
+//	func TestRepositories(t *testing.T) {
+//		t.Run("memory", func(t *testing.T) { repositorytest.Run(t, repositorytest.Memory) })
+//		t.Run("mongo", func(t *testing.T) { repositorytest.Run(t, repositorytest.Mongo(os.Getenv("MONGODB_TEST_URL"))) })
+//	}
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/config"
+	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/cbuelvasc/cinema-backend/migration"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/repository"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Repositories is one implementation of every repository interface, sharing
+// the same data.
+type Repositories struct {
+	Users      repository.UserRepository
+	Movies     repository.MovieRepository
+	Search     repository.MovieSearchRepository
+	Listings   repository.ListingRepository
+	Countries  repository.CountryRepository
+	States     repository.StateRepository
+	Cities     repository.CityRepository
+	Cinemas    repository.CinemaRepository
+	Rooms      repository.RoomRepository
+	Schedules  repository.ScheduleRepository
+	Bookings   repository.BookingRepository
+	Tweets     repository.TweetRepository
+	Reviews    repository.ReviewRepository
+	Follows    repository.FollowRepository
+	Purge      repository.PurgeRepository
+	UnitOfWork repository.UnitOfWork
+}
+
+// Backend returns empty repositories for the test t.
+type Backend func(t *testing.T) *Repositories
+
+// Memory is the Backend of the memory repositories.
+func Memory(t *testing.T) *Repositories {
+	store := repository.NewMemoryStore()
+	return &Repositories{
+		Users:      repository.NewMemoryUserRepository(store),
+		Movies:     repository.NewMemoryMovieRepository(store),
+		Search:     repository.NewMemoryMovieSearchRepository(store),
+		Listings:   repository.NewMemoryListingRepository(store),
+		Countries:  repository.NewMemoryCountryRepository(store),
+		States:     repository.NewMemoryStateRepository(store),
+		Cities:     repository.NewMemoryCityRepository(store),
+		Cinemas:    repository.NewMemoryCinemaRepository(store),
+		Rooms:      repository.NewMemoryRoomRepository(store),
+		Schedules:  repository.NewMemoryScheduleRepository(store),
+		Bookings:   repository.NewMemoryBookingRepository(store),
+		Tweets:     repository.NewMemoryTweetRepository(store),
+		Reviews:    repository.NewMemoryReviewRepository(store),
+		Follows:    repository.NewMemoryFollowRepository(store),
+		Purge:      repository.NewMemoryPurgeRepository(store),
+		UnitOfWork: repository.NewMemoryUnitOfWork(store),
+	}
+}
+
+// Mongo returns the Backend of the MongoDB repositories. Every test gets its
+// own migrated database on the server at url, dropped when the test ends.
+// Tests are skipped when url is empty. Transactions need the server to be a
+// replica set.
+func Mongo(url string) Backend {
+	return func(t *testing.T) *Repositories {
+		if len(url) == 0 {
+			t.Skip("no MongoDB url")
+		}
+
+		ctx := context.Background()
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(url))
+		if err != nil {
+			t.Fatal(err)
+		}
+		database := client.Database(fmt.Sprintf("contract_%s", primitive.NewObjectID().Hex()))
+		t.Cleanup(func() {
+			_ = database.Drop(ctx)
+			_ = client.Disconnect(ctx)
+		})
+		if _, err := migration.NewMigrator(database).Up(ctx, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		return &Repositories{
+			Users:      repository.NewUserRepository(database),
+			Movies:     repository.NewMovieRepository(database),
+			Search:     repository.NewMovieSearchRepository(database),
+			Listings:   repository.NewListingRepository(database),
+			Countries:  repository.NewCountryRepository(database),
+			States:     repository.NewStateRepository(database),
+			Cities:     repository.NewCityRepository(database),
+			Cinemas:    repository.NewCinemaRepository(database),
+			Rooms:      repository.NewRoomRepository(database),
+			Schedules:  repository.NewScheduleRepository(database),
+			Bookings:   repository.NewBookingRepository(database),
+			Tweets:     repository.NewTeewtRepository(database),
+			Reviews:    repository.NewReviewRepository(database),
+			Follows:    repository.NewFollowRepository(database),
+			Purge:      repository.NewPurgeRepository(database),
+			UnitOfWork: repository.NewUnitOfWork(database),
+		}
+	}
+}
+
+// Run checks the repositories of backend against the contract.
+func Run(t *testing.T, backend Backend) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repositories *Repositories)
+	}{
+		{"Pagination", testPagination},
+		{"NotFound", testNotFound},
+		{"Conflict", testConflict},
+		{"Filtering", testFiltering},
+		{"SoftDelete", testSoftDelete},
+		{"Links", testLinks},
+		{"Cascade", testCascade},
+		{"UnitOfWork", testUnitOfWork},
+		{"Search", testSearch},
+		{"Translations", testTranslations},
+		{"ComingSoon", testComingSoon},
+		{"NearbyCinemas", testNearbyCinemas},
+		{"TimeZones", testTimeZones},
+		{"Reviews", testReviews},
+		{"Bookings", testBookings},
+		{"Follows", testFollows},
+		{"TweetInteractions", testTweetInteractions},
+		{"Passwords", testPasswords},
+		{"CalendarTokens", testCalendarTokens},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			test.test(t, backend(t))
+		})
+	}
+}
+
+func testPagination(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	for _, title := range []string{"Alien", "Brazil", "Casablanca"} {
+		saveMovie(t, repositories, title)
+	}
+
+	paged, err := repositories.Movies.GetAllMovies(ctx, 1, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paged.Data) != 2 {
+		t.Errorf("page 1 has %d movies, want 2", len(paged.Data))
+	}
+	pageInfo := paged.PageInfo
+	if pageInfo.Total != 3 || pageInfo.Page != 1 || pageInfo.PerPage != 2 || pageInfo.TotalPage != 2 || pageInfo.Next != 2 {
+		t.Errorf("page info is %+v", *pageInfo)
+	}
+
+	first, err := repositories.Movies.GetAllMoviesByCursor(ctx, &model.CursorQuery{Limit: 2, Sort: "title"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if titles := movieTitles(first.Data); titles != "Alien,Brazil" {
+		t.Errorf("first cursor page is %s, want Alien,Brazil", titles)
+	}
+	if !first.Cursor.HasNext || first.Cursor.HasPrev {
+		t.Errorf("first cursor page is %+v", *first.Cursor)
+	}
+
+	next, err := repositories.Movies.GetAllMoviesByCursor(ctx, &model.CursorQuery{Limit: 2, Sort: "title", After: first.Cursor.Next}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if titles := movieTitles(next.Data); titles != "Casablanca" {
+		t.Errorf("next cursor page is %s, want Casablanca", titles)
+	}
+	if next.Cursor.HasNext || !next.Cursor.HasPrev {
+		t.Errorf("next cursor page is %+v", *next.Cursor)
+	}
+
+	previous, err := repositories.Movies.GetAllMoviesByCursor(ctx, &model.CursorQuery{Limit: 2, Sort: "title", Before: next.Cursor.Prev}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if titles := movieTitles(previous.Data); titles != "Alien,Brazil" {
+		t.Errorf("previous cursor page is %s, want Alien,Brazil", titles)
+	}
+}
+
+func testNotFound(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	id := primitive.NewObjectID().Hex()
+
+	_, err := repositories.Movies.GetMovie(ctx, id)
+	expectStatus(t, "GetMovie", err, http.StatusNotFound)
+	_, err = repositories.Movies.UpdateMovie(ctx, id, &model.Movie{MovieInput: &model.MovieInput{Title: "Alien"}})
+	expectStatus(t, "UpdateMovie", err, http.StatusNotFound)
+	err = repositories.Movies.DeleteMovie(ctx, id, id)
+	expectStatus(t, "DeleteMovie", err, http.StatusNotFound)
+	_, err = repositories.Movies.RestoreMovie(ctx, id)
+	expectStatus(t, "RestoreMovie", err, http.StatusNotFound)
+	_, err = repositories.Movies.GetMovieDocument(ctx, id, &model.DocumentQuery{})
+	expectStatus(t, "GetMovieDocument", err, http.StatusNotFound)
+	err = repositories.Countries.DeleteCountry(ctx, id)
+	expectStatus(t, "DeleteCountry", err, http.StatusNotFound)
+
+	if _, err := repositories.Users.FindByEmail(ctx, "nobody@example.com"); err != mongo.ErrNoDocuments {
+		t.Errorf("FindByEmail returned %v, want %v", err, mongo.ErrNoDocuments)
+	}
+
+	_, err = repositories.Tweets.GetAllTweets(ctx, 1, 10, id)
+	expectStatus(t, "GetAllTweets", err, http.StatusNotFound)
+	_, err = repositories.States.GetAllStates(ctx, 1, 10, id)
+	expectStatus(t, "GetAllStates", err, http.StatusNotFound)
+}
+
+func testConflict(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	movie := saveMovie(t, repositories, "Alien")
+	id := movie.ID.Hex()
+	if movie.Version != 1 {
+		t.Errorf("saved movie has version %d, want 1", movie.Version)
+	}
+
+	updated, err := repositories.Movies.UpdateMovie(util.WithExpectedVersion(ctx, 1), id, &model.Movie{MovieInput: &model.MovieInput{Title: "Aliens"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Version != 2 || updated.Title != "Aliens" {
+		t.Errorf("updated movie is %s at version %d", updated.Title, updated.Version)
+	}
+
+	stale := util.WithExpectedVersion(ctx, 1)
+	_, err = repositories.Movies.UpdateMovie(stale, id, &model.Movie{MovieInput: &model.MovieInput{Title: "Alien 3"}})
+	expectStatus(t, "UpdateMovie", err, http.StatusPreconditionFailed)
+	_, err = repositories.Movies.PatchMovie(stale, id, &model.Movie{MovieInput: &model.MovieInput{Title: "Alien 3"}})
+	expectStatus(t, "PatchMovie", err, http.StatusPreconditionFailed)
+	err = repositories.Movies.DeleteMovie(stale, id, id)
+	expectStatus(t, "DeleteMovie", err, http.StatusPreconditionFailed)
+
+	updated, err = repositories.Movies.UpdateMovie(util.WithExpectedVersion(ctx, 1, 2), id, &model.Movie{MovieInput: &model.MovieInput{Title: "Alien 3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Version != 3 {
+		t.Errorf("movie updated with If-Match of versions 1 and 2 is at version %d, want 3", updated.Version)
+	}
+
+	for i := 0; i < 2; i++ {
+		user := &model.User{UserInput: &model.UserInput{Name: "Ada", Email: "ada@example.com", Password: "secret"}}
+		_, err = repositories.Users.SaveUser(ctx, user)
+	}
+	expectStatus(t, "SaveUser", err, http.StatusConflict)
+
+	country := saveCountry(t, repositories, "Colombia")
+	saveState(t, repositories, "Antioquia", country.ID.Hex())
+	err = repositories.Countries.DeleteCountry(ctx, country.ID.Hex())
+	expectStatus(t, "DeleteCountry", err, http.StatusConflict)
+}
+
+func testFiltering(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	colombia := saveCountry(t, repositories, "Colombia")
+	spain := saveCountry(t, repositories, "Spain")
+	saveState(t, repositories, "Antioquia", colombia.ID.Hex())
+	saveState(t, repositories, "Cundinamarca", colombia.ID.Hex())
+	saveState(t, repositories, "Madrid", spain.ID.Hex())
+
+	states, err := repositories.States.GetAllStates(ctx, 1, 10, colombia.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states.Data) != 2 || states.PageInfo.Total != 2 {
+		t.Errorf("Colombia has %d states out of %d, want 2", len(states.Data), states.PageInfo.Total)
+	}
+	for _, state := range states.Data {
+		if state.CountryId != colombia.ID.Hex() {
+			t.Errorf("state %s belongs to %s", state.Name, state.CountryId)
+		}
+	}
+
+	all, err := repositories.States.GetAllStates(ctx, 1, 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all.Data) != 3 {
+		t.Errorf("there are %d states, want 3", len(all.Data))
+	}
+
+	userId := primitive.NewObjectID().Hex()
+	for _, message := range []string{"first", "second"} {
+		tweet := &model.Tweet{TweetInput: &model.TweetInput{UserId: userId, Message: message, CreatedAt: time.Now()}}
+		if _, err := repositories.Tweets.SaveTweet(ctx, tweet); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := &model.Tweet{TweetInput: &model.TweetInput{UserId: primitive.NewObjectID().Hex(), Message: "other", CreatedAt: time.Now()}}
+	if _, err := repositories.Tweets.SaveTweet(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+
+	tweets, err := repositories.Tweets.GetAllTweetsByCursor(ctx, &model.CursorQuery{Limit: 10}, userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tweets.Data) != 2 {
+		t.Errorf("user has %d tweets, want 2", len(tweets.Data))
+	}
+
+	released := model.NewDate(1979, time.May, 25)
+	alien := &model.Movie{MovieInput: &model.MovieInput{
+		Title:       "Alien",
+		Format:      "2D",
+		ReleaseDate: &released,
+		Runtime:     117,
+		Genres:      []string{"Horror", "Science Fiction"},
+		Ratings:     []model.AgeRating{{Country: "us", Rating: "R"}},
+		Languages:   []string{"en"},
+		Cast:        []model.CastMember{{Name: "Sigourney Weaver", Character: "Ripley"}},
+		Crew:        []model.CrewMember{{Name: "Ridley Scott", Job: "Director"}},
+		CreatedAt:   time.Now(),
+	}}
+	if _, err := repositories.Movies.SaveMovie(ctx, alien); err != nil {
+		t.Fatal(err)
+	}
+	saveMovie(t, repositories, "Brazil")
+
+	after := model.NewDate(1979, time.January, 1)
+	for _, filter := range []*model.MovieFilter{
+		{Genres: []string{"horror", "science fiction"}},
+		{RatingCountry: "US", Rating: "r"},
+		{Cast: "weaver"},
+		{Crew: "scott", CrewJob: "director"},
+		{MinRuntime: 100, MaxRuntime: 120},
+		{ReleasedAfter: &after, Language: "en"},
+	} {
+		movies, err := repositories.Movies.GetAllMovies(ctx, 1, 10, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if titles := movieTitles(movies.Data); titles != "Alien" {
+			t.Errorf("filtering by %+v found %s, want Alien", *filter, titles)
+		}
+	}
+}
+
+func testSoftDelete(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	user := &model.User{UserInput: &model.UserInput{Name: "Ada", Lastname: "Lovelace", Email: "ada@example.com", Password: "secret"}}
+	saved, err := repositories.Users.SaveUser(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := saved.ID.Hex()
+	if len(saved.Password) > 0 {
+		t.Error("saved user has a password")
+	}
+
+	if err := repositories.Users.DeleteUser(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	_, err = repositories.Users.GetUser(ctx, id)
+	expectStatus(t, "GetUser", err, http.StatusNotFound)
+
+	deleted, err := repositories.Users.GetUser(util.WithIncludeDeleted(ctx), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted.DeletedAt == nil {
+		t.Error("deleted user has no deletion time")
+	}
+
+	restored, err := repositories.Users.RestoreUser(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.DeletedAt != nil || restored.Version != 3 {
+		t.Errorf("restored user is deleted at %v with version %d", restored.DeletedAt, restored.Version)
+	}
+
+	movie := saveMovie(t, repositories, "Alien")
+	if err := repositories.Movies.DeleteMovie(ctx, movie.ID.Hex(), movie.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	purged, err := repositories.Purge.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 1 {
+		t.Errorf("purged %d documents, want 1", purged)
+	}
+	_, err = repositories.Movies.GetMovie(util.WithIncludeDeleted(ctx), movie.ID.Hex())
+	expectStatus(t, "GetMovie", err, http.StatusNotFound)
+}
+
+func testLinks(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	colombia := saveCountry(t, repositories, "Colombia")
+	spain := saveCountry(t, repositories, "Spain")
+	state := saveState(t, repositories, "Antioquia", colombia.ID.Hex())
+	id := state.ID.Hex()
+
+	expectChildren(t, repositories, colombia.ID.Hex(), id)
+
+	document, err := repositories.States.GetStateDocument(ctx, id, &model.DocumentQuery{Fields: []string{"name"}, Expand: []string{"country"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if country, ok := document["country"].(model.Document); !ok || country["name"] != "Colombia" || document["countryId"] != nil {
+		t.Errorf("the state with fields name and expanded country is %v", document)
+	}
+
+	moved := &model.State{StateInput: &model.StateInput{Name: "Antioquia", CountryId: spain.ID.Hex()}}
+	if _, err := repositories.States.UpdateState(ctx, id, moved); err != nil {
+		t.Fatal(err)
+	}
+	expectChildren(t, repositories, colombia.ID.Hex())
+	expectChildren(t, repositories, spain.ID.Hex(), id)
+
+	unknown := &model.State{StateInput: &model.StateInput{Name: "Nowhere", CountryId: primitive.NewObjectID().Hex()}}
+	_, err = repositories.States.SaveState(ctx, unknown)
+	expectStatus(t, "SaveState", err, http.StatusNotFound)
+
+	if err := repositories.States.DeleteState(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	expectChildren(t, repositories, spain.ID.Hex())
+
+	if _, err := repositories.States.RestoreState(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	expectChildren(t, repositories, spain.ID.Hex(), id)
+}
+
+func testCascade(t *testing.T, repositories *Repositories) {
+	cityPolicy, cinemaPolicy := config.CityDeletePolicy, config.CinemaDeletePolicy
+	defer func() {
+		config.CityDeletePolicy, config.CinemaDeletePolicy = cityPolicy, cinemaPolicy
+	}()
+
+	ctx := context.Background()
+	country := saveCountry(t, repositories, "Colombia")
+	state := saveState(t, repositories, "Antioquia", country.ID.Hex())
+	city, err := repositories.Cities.SaveCity(ctx, &model.City{CityInput: &model.CityInput{Name: "Medellín", StateId: state.ID.Hex()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cinema, err := repositories.Cinemas.SaveCinema(ctx, &model.Cinema{CinemaInput: &model.CinemaInput{Name: "Centro", CityId: city.ID.Hex()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	room, err := repositories.Rooms.SaveRoom(ctx, &model.Room{RoomInput: &model.RoomInput{Name: "Sala 1", Capacity: "100", Format: "2D", CinemaId: cinema.ID.Hex()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectRooms(t, repositories, cinema.ID.Hex(), room.ID.Hex())
+
+	config.CinemaDeletePolicy = enums.DeletePolicyRestrict
+	err = repositories.Cinemas.DeleteCinema(ctx, cinema.ID.Hex(), cinema.ID.Hex())
+	expectStatus(t, "deleting a cinema with rooms", err, http.StatusConflict)
+
+	config.CityDeletePolicy, config.CinemaDeletePolicy = enums.DeletePolicyCascade, enums.DeletePolicyCascade
+	if err := repositories.Cities.DeleteCity(ctx, city.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	_, err = repositories.Cinemas.GetCinemaById(ctx, cinema.ID.Hex())
+	expectStatus(t, "GetCinemaById of a cascaded cinema", err, http.StatusNotFound)
+	_, err = repositories.Rooms.GetRoomById(ctx, room.ID.Hex())
+	expectStatus(t, "GetRoomById of a cascaded room", err, http.StatusNotFound)
+
+	if _, err := repositories.Cities.RestoreCity(ctx, city.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := repositories.Cities.GetCityById(ctx, city.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored.Cinemas) != 1 || restored.Cinemas[0] != cinema.ID.Hex() {
+		t.Errorf("the restored city has cinemas %v, want [%s]", restored.Cinemas, cinema.ID.Hex())
+	}
+	expectRooms(t, repositories, cinema.ID.Hex(), room.ID.Hex())
+	if _, err := repositories.Rooms.GetRoomById(ctx, room.ID.Hex()); err != nil {
+		t.Errorf("the room of the restored city: %v", err)
+	}
+
+	// Rooms deleted on their own stay deleted when their cinema comes back.
+	if err := repositories.Rooms.DeleteRoom(ctx, room.ID.Hex(), room.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	if err := repositories.Cinemas.DeleteCinema(ctx, cinema.ID.Hex(), cinema.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repositories.Cinemas.RestoreCinema(ctx, cinema.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	expectRooms(t, repositories, cinema.ID.Hex())
+}
+
+func testUnitOfWork(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	var id string
+	failure := errors.New("rollback")
+
+	err := repositories.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+		id = saveMovieIn(ctx, t, repositories, "Alien").ID.Hex()
+		return failure
+	})
+	if err != failure {
+		t.Fatalf("Do returned %v, want %v", err, failure)
+	}
+	_, err = repositories.Movies.GetMovie(ctx, id)
+	expectStatus(t, "GetMovie", err, http.StatusNotFound)
+
+	err = repositories.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+		id = saveMovieIn(ctx, t, repositories, "Brazil").ID.Hex()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repositories.Movies.GetMovie(ctx, id); err != nil {
+		t.Error(err)
+	}
+}
+
+func testSearch(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	for _, title := range []string{"El Laberinto del Fauno", "Alien", "Aliens"} {
+		saveMovie(t, repositories, title)
+	}
+
+	for _, text := range []string{"laberinto", "laber", "labirinto"} {
+		found, err := repositories.Search.SearchMovies(ctx, &model.MovieSearchQuery{Text: text})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if titles := searchTitles(found.Data); titles != "El Laberinto del Fauno" {
+			t.Errorf("searching %q found %s", text, titles)
+		}
+	}
+
+	found, err := repositories.Search.SearchMovies(ctx, &model.MovieSearchQuery{Text: "alien"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if titles := searchTitles(found.Data); titles != "Alien,Aliens" {
+		t.Errorf("searching \"alien\" found %s, want Alien,Aliens", titles)
+	}
+	if found.PageInfo.HasNext {
+		t.Error("searching \"alien\" has a next page, want none")
+	}
+
+	for page, want := range []string{"Alien", "Aliens"} {
+		found, err := repositories.Search.SearchMovies(ctx, &model.MovieSearchQuery{Text: "alien", Page: int64(page + 1), Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if titles := searchTitles(found.Data); titles != want || found.PageInfo.HasNext != (page == 0) {
+			t.Errorf("page %d of \"alien\" found %s with a next page %t", page+1, titles, found.PageInfo.HasNext)
+		}
+	}
+}
+
+func testTranslations(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	movie := saveMovie(t, repositories, "Pan's Labyrinth")
+	id := movie.ID.Hex()
+
+	translation := &model.MovieTranslation{Locale: "es", Title: "El Laberinto del Fauno"}
+	if _, err := repositories.Movies.SaveMovieTranslation(ctx, id, translation); err != nil {
+		t.Fatal(err)
+	}
+	translation = &model.MovieTranslation{Locale: "es", Title: "El Laberinto del Fauno", Tagline: "La inocencia tiene poder"}
+	updated, err := repositories.Movies.SaveMovieTranslation(ctx, id, translation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated.Translations) != 1 || updated.Version != movie.Version+2 {
+		t.Errorf("saving a translation twice left %d translations at version %d, want 1 at version %d", len(updated.Translations), updated.Version, movie.Version+2)
+	}
+
+	found, err := repositories.Search.SearchMovies(ctx, &model.MovieSearchQuery{Text: "fauno"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if titles := searchTitles(found.Data); titles != "Pan's Labyrinth" {
+		t.Errorf("searching \"fauno\" found %s, want Pan's Labyrinth", titles)
+	}
+
+	util.LocalizeMovie(updated, []string{"es-CO", "es", "en"})
+	if updated.Title != "El Laberinto del Fauno" || updated.Locale != "es" {
+		t.Errorf("localized to es-CO the title is %q in %q", updated.Title, updated.Locale)
+	}
+
+	stale := util.WithExpectedVersion(ctx, movie.Version)
+	_, err = repositories.Movies.DeleteMovieTranslation(stale, id, "es")
+	expectStatus(t, "deleting a translation of a stale version", err, http.StatusPreconditionFailed)
+
+	if _, err := repositories.Movies.DeleteMovieTranslation(ctx, id, "es"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = repositories.Movies.DeleteMovieTranslation(ctx, id, "es")
+	expectStatus(t, "deleting a missing translation", err, http.StatusNotFound)
+}
+
+func testComingSoon(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	for _, movie := range []struct {
+		title    string
+		released model.Date
+	}{
+		{"Dune", model.NewDate(2030, time.March, 1)},
+		{"Alien", model.NewDate(1979, time.May, 25)},
+		{"Brazil", model.NewDate(2030, time.January, 15)},
+	} {
+		released := movie.released
+		input := &model.MovieInput{Title: movie.title, Format: "2D", ReleaseDate: &released, CreatedAt: time.Now()}
+		if _, err := repositories.Movies.SaveMovie(ctx, &model.Movie{MovieInput: input}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	comingSoon, err := repositories.Listings.GetComingSoon(ctx, &model.ComingSoonQuery{From: model.NewDate(2029, time.January, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if titles := movieTitles(comingSoon.Data); titles != "Brazil,Dune" {
+		t.Errorf("coming soon are %s, want Brazil,Dune", titles)
+	}
+
+	nowShowing, err := repositories.Listings.GetNowShowing(ctx, &model.ShowingQuery{From: time.Now(), To: time.Now().Add(24 * time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nowShowing.Data) != 0 {
+		t.Errorf("%d movies are showing without showtimes", len(nowShowing.Data))
+	}
+}
+
+func testNearbyCinemas(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	movie := saveMovie(t, repositories, "Alien")
+
+	for _, movieId := range []string{"", movie.ID.Hex()} {
+		nearby, err := repositories.Cinemas.GetNearbyCinemas(ctx, &model.NearbyCinemaQuery{Latitude: 6.25, Longitude: -75.57, Radius: 5000, MovieId: movieId, From: time.Now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(nearby.Data) != 0 {
+			t.Errorf("%d cinemas are nearby without cinemas", len(nearby.Data))
+		}
+	}
+}
+
+func testTimeZones(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	country, err := repositories.Countries.SaveCountry(ctx, &model.Country{CountryInput: &model.CountryInput{Name: "Spain", TimeZone: "Europe/Madrid", CreatedAt: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := saveState(t, repositories, "Canarias", country.ID.Hex())
+
+	for _, city := range []struct {
+		name     string
+		timeZone string
+		want     string
+	}{
+		{"Madrid", "", "Europe/Madrid"},
+		{"Las Palmas", "Atlantic/Canary", "Atlantic/Canary"},
+	} {
+		saved, err := repositories.Cities.SaveCity(ctx, &model.City{CityInput: &model.CityInput{Name: city.name, StateId: state.ID.Hex(), TimeZone: city.timeZone, CreatedAt: time.Now()}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		location, err := repositories.Cities.GetCityTimeZone(ctx, saved.ID.Hex())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if location.String() != city.want {
+			t.Errorf("%s is in %s, want %s", city.name, location, city.want)
+		}
+	}
+
+	_, err = repositories.Cities.GetCityTimeZone(ctx, primitive.NewObjectID().Hex())
+	expectStatus(t, "getting the time zone of a missing city", err, http.StatusNotFound)
+}
+
+func testReviews(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	movie := saveMovie(t, repositories, "Alien")
+	movieId := movie.ID.Hex()
+
+	var reviews []*model.Review
+	for i, rating := range []int{5, 3, 4} {
+		review := &model.Review{
+			ReviewInput: &model.ReviewInput{Rating: rating, CreatedAt: time.Now().Add(time.Duration(i) * time.Minute)},
+			MovieId:     movieId,
+			UserId:      fmt.Sprintf("user%d", i),
+		}
+		saved, err := repositories.Reviews.SaveReview(ctx, review)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reviews = append(reviews, saved)
+	}
+
+	_, err := repositories.Reviews.SaveReview(ctx, &model.Review{ReviewInput: &model.ReviewInput{Rating: 1}, MovieId: movieId, UserId: "user0"})
+	expectStatus(t, "reviewing a movie twice", err, http.StatusConflict)
+
+	expectScore(t, repositories, movieId, 4, "0,0,1,1,1")
+
+	for _, userId := range []string{"user1", "user2", "user2"} {
+		if _, err := repositories.Reviews.VoteReview(ctx, reviews[0].ID.Hex(), userId, enums.ReviewVoteHelpful); err != nil {
+			t.Fatal(err)
+		}
+	}
+	voted, err := repositories.Reviews.GetReview(ctx, reviews[0].ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if voted.Helpful != 2 || voted.Version != reviews[0].Version {
+		t.Errorf("review has %d helpful votes at version %d, want 2 at version %d", voted.Helpful, voted.Version, reviews[0].Version)
+	}
+
+	for sort, want := range map[string]string{enums.ReviewSortHelpful: "5,4,3", enums.ReviewSortDate: "4,3,5"} {
+		paged, err := repositories.Reviews.GetMovieReviews(ctx, &model.ReviewQuery{MovieId: movieId, Sort: sort})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := reviewRatings(paged.Data); got != want {
+			t.Errorf("reviews sorted by %s are rated %s, want %s", sort, got, want)
+		}
+	}
+
+	update := &model.Review{ReviewInput: &model.ReviewInput{Rating: 1, UpdatedAt: time.Now()}}
+	if _, err := repositories.Reviews.UpdateReview(ctx, reviews[1].ID.Hex(), update); err != nil {
+		t.Fatal(err)
+	}
+	if err := repositories.Reviews.DeleteReview(ctx, reviews[2].ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	expectScore(t, repositories, movieId, 3, "1,0,0,0,1")
+}
+
+func testBookings(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	movieId := saveMovie(t, repositories, "Alien").ID.Hex()
+	room := saveRoom(t, repositories, "Sala 1", "XX_X", "XXXX")
+
+	_, err := repositories.Schedules.SaveSchedule(ctx, &model.Schedule{ScheduleInput: &model.ScheduleInput{RoomId: primitive.NewObjectID().Hex(), MovieId: movieId, Date: time.Now()}})
+	expectStatus(t, "scheduling a showtime in a missing room", err, http.StatusNotFound)
+
+	schedule := saveSchedule(t, repositories, room.ID.Hex(), movieId, time.Now().Add(2*time.Hour))
+	scheduleId := schedule.ID.Hex()
+	if len(schedule.SeatsEmpty) != 7 || len(schedule.SeatsOccupied) != 0 {
+		t.Errorf("a new showtime has %d free and %d occupied seats, want 7 and 0", len(schedule.SeatsEmpty), len(schedule.SeatsOccupied))
+	}
+
+	book := func(userId string, scheduleId string, seats ...string) (*model.Booking, error) {
+		return repositories.Bookings.SaveBooking(ctx, &model.Booking{
+			BookingInput: &model.BookingInput{ScheduleId: scheduleId, Seats: seats},
+			UserId:       userId,
+			CreatedAt:    time.Now(),
+		})
+	}
+	booking, err := book("ana", scheduleId, "A1", "A2", "A1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := booking.ID.Hex()
+	if strings.Join(booking.Seats, ",") != "A1,A2" || booking.MovieId != movieId || !booking.Date.Equal(schedule.Date.Truncate(time.Millisecond)) {
+		t.Errorf("booked the seats %v of the movie %s at %s", booking.Seats, booking.MovieId, booking.Date)
+	}
+	expectSeats(t, repositories, scheduleId, "A3,B1,B2,B3,B4", "A1,A2")
+
+	_, err = book("bob", scheduleId, "B1", "A2")
+	expectStatus(t, "booking a taken seat", err, http.StatusConflict)
+	_, err = book("bob", scheduleId, "Z9")
+	expectStatus(t, "booking a seat the room does not have", err, http.StatusBadRequest)
+	_, err = book("bob", primitive.NewObjectID().Hex(), "A1")
+	expectStatus(t, "booking a missing showtime", err, http.StatusNotFound)
+	started := saveSchedule(t, repositories, room.ID.Hex(), movieId, time.Now().Add(-time.Minute))
+	_, err = book("bob", started.ID.Hex(), "A1")
+	expectStatus(t, "booking a showtime that started", err, http.StatusConflict)
+	expectSeats(t, repositories, scheduleId, "A3,B1,B2,B3,B4", "A1,A2")
+
+	other, err := book("bob", scheduleId, "B1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repositories.Bookings.CancelBooking(ctx, other.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	err = repositories.Bookings.CancelBooking(ctx, other.ID.Hex())
+	expectStatus(t, "cancelling a booking twice", err, http.StatusNotFound)
+	expectSeats(t, repositories, scheduleId, "A3,B2,B3,B4,B1", "A1,A2")
+
+	checkedIn, err := repositories.Bookings.HasCheckedIn(ctx, "ana", movieId)
+	if err != nil || checkedIn {
+		t.Errorf("ana has checked in %t (%v) before checking in", checkedIn, err)
+	}
+	booking, err = repositories.Bookings.CheckIn(ctx, id, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if booking.CheckedInAt == nil || booking.Version != 2 {
+		t.Errorf("the booking checked in at %v is at version %d, want 2", booking.CheckedInAt, booking.Version)
+	}
+	_, err = repositories.Bookings.CheckIn(ctx, id, time.Now())
+	expectStatus(t, "checking a booking in twice", err, http.StatusConflict)
+	_, err = repositories.Bookings.CheckIn(ctx, other.ID.Hex(), time.Now())
+	expectStatus(t, "checking a cancelled booking in", err, http.StatusNotFound)
+	err = repositories.Bookings.CancelBooking(ctx, id)
+	expectStatus(t, "cancelling a checked-in booking", err, http.StatusConflict)
+	for userId, want := range map[string]bool{"ana": true, "bob": false} {
+		if checkedIn, err := repositories.Bookings.HasCheckedIn(ctx, userId, movieId); err != nil || checkedIn != want {
+			t.Errorf("%s has checked in %t (%v), want %t", userId, checkedIn, err, want)
+		}
+	}
+
+	paged, err := repositories.Bookings.GetUserBookings(ctx, "ana", &model.CursorQuery{Sort: "-created_at"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paged.Data) != 1 || paged.Data[0].ID.Hex() != id {
+		t.Errorf("ana has %d bookings, want 1", len(paged.Data))
+	}
+
+	later, err := book("ana", saveSchedule(t, repositories, room.ID.Hex(), movieId, time.Now().Add(4*time.Hour)).ID.Hex(), "A1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	upcoming, err := repositories.Bookings.GetUpcomingBookings(ctx, "ana", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(upcoming) != 2 || upcoming[0].ID.Hex() != id || upcoming[1].ID.Hex() != later.ID.Hex() {
+		t.Errorf("ana has %d upcoming bookings, want 2, the soonest first", len(upcoming))
+	}
+	upcoming, err = repositories.Bookings.GetUpcomingBookings(ctx, "ana", time.Now().Add(3*time.Hour))
+	if err != nil || len(upcoming) != 1 {
+		t.Errorf("ana has %d bookings in 3 hours (%v), want 1", len(upcoming), err)
+	}
+}
+
+func testFollows(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	var users []*model.User
+	for _, name := range []string{"ana", "bob", "eve"} {
+		user, err := repositories.Users.SaveUser(ctx, &model.User{UserInput: &model.UserInput{Name: name, Lastname: name, Email: name + "@example.com", CreatedAt: time.Now()}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		users = append(users, user)
+	}
+	ana, bob, eve := users[0].ID.Hex(), users[1].ID.Hex(), users[2].ID.Hex()
+
+	for _, followingId := range []string{bob, eve} {
+		if _, err := repositories.Follows.Follow(ctx, ana, followingId); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := repositories.Follows.Follow(ctx, ana, bob)
+	expectStatus(t, "following a user twice", err, http.StatusConflict)
+
+	for i, posted := range []struct{ userId, message string }{{bob, "bob 1"}, {eve, "eve 1"}, {ana, "ana 1"}, {bob, "bob 2"}} {
+		tweet := &model.Tweet{TweetInput: &model.TweetInput{UserId: posted.userId, Message: posted.message, CreatedAt: time.Now().Add(time.Duration(i) * time.Minute)}}
+		if _, err := repositories.Tweets.SaveTweet(ctx, tweet); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	query := &model.CursorQuery{Limit: 2, Sort: "-created_at"}
+	var messages []string
+	for {
+		timeline, err := repositories.Tweets.GetTimeline(ctx, ana, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tweet := range timeline.Data {
+			messages = append(messages, tweet.Message)
+		}
+		if !timeline.Cursor.HasNext {
+			break
+		}
+		query.After = timeline.Cursor.Next
+	}
+	if got := strings.Join(messages, ","); got != "bob 2,eve 1,bob 1" {
+		t.Errorf("the timeline of ana is %s, want bob 2,eve 1,bob 1", got)
+	}
+
+	if err := repositories.Follows.Unfollow(ctx, ana, eve); err != nil {
+		t.Fatal(err)
+	}
+	err = repositories.Follows.Unfollow(ctx, ana, eve)
+	expectStatus(t, "unfollowing a user not followed", err, http.StatusNotFound)
+
+	following, err := repositories.Follows.GetFollowing(ctx, ana, &model.CursorQuery{Sort: "-created_at"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	followers, err := repositories.Follows.GetFollowers(ctx, bob, &model.CursorQuery{Sort: "-created_at"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(following.Data) != 1 || following.Data[0].Name != "bob" || len(followers.Data) != 1 || followers.Data[0].Name != "ana" {
+		t.Errorf("ana follows %d users and bob has %d followers, want bob and ana", len(following.Data), len(followers.Data))
+	}
+
+	for _, counts := range []struct {
+		id                   string
+		followers, following int64
+	}{{ana, 0, 1}, {bob, 1, 0}, {eve, 0, 0}} {
+		user, err := repositories.Users.GetUser(ctx, counts.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.Followers != counts.followers || user.Following != counts.following {
+			t.Errorf("%s has %d followers and follows %d users, want %d and %d", user.Name, user.Followers, user.Following, counts.followers, counts.following)
+		}
+	}
+}
+
+func testTweetInteractions(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	var users []*model.User
+	for _, name := range []string{"Ana", "Bob"} {
+		user, err := repositories.Users.SaveUser(ctx, &model.User{UserInput: &model.UserInput{Name: name, Lastname: name, Email: name + "@example.com", Username: name, CreatedAt: time.Now()}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		users = append(users, user)
+	}
+	ana, bob := users[0].ID.Hex(), users[1].ID.Hex()
+
+	_, err := repositories.Users.SaveUser(ctx, &model.User{UserInput: &model.UserInput{Name: "eve", Lastname: "eve", Email: "eve@example.com", Username: "ANA", CreatedAt: time.Now()}})
+	expectStatus(t, "saving a user with a taken username", err, http.StatusConflict)
+
+	saveTweet := func(userId string, message string, replyToId string, minutes int) *model.Tweet {
+		t.Helper()
+		tweet, err := repositories.Tweets.SaveTweet(ctx, &model.Tweet{TweetInput: &model.TweetInput{UserId: userId, Message: message, ReplyToId: replyToId, CreatedAt: time.Now().Add(time.Duration(minutes) * time.Minute)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tweet
+	}
+	root := saveTweet(ana, "Watching #Dune tonight", "", 0)
+	reply := saveTweet(bob, "@ana enjoy #dune", root.ID.Hex(), 1)
+	saveTweet(ana, "@Bob thanks, and @nobody", reply.ID.Hex(), 2)
+	saveTweet(bob, "#DUNE part two #2", "", 3)
+
+	_, err = repositories.Tweets.SaveTweet(ctx, &model.Tweet{TweetInput: &model.TweetInput{UserId: ana, Message: "lost", ReplyToId: primitive.NewObjectID().Hex(), CreatedAt: time.Now()}})
+	expectStatus(t, "replying to a missing tweet", err, http.StatusNotFound)
+
+	conversation, err := repositories.Tweets.GetConversation(ctx, reply.ID.Hex(), &model.CursorQuery{Sort: "created_at"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tweetMessages(conversation.Data); got != "Watching #Dune tonight,@ana enjoy #dune,@Bob thanks, and @nobody" {
+		t.Errorf("the conversation is %s, want the root tweet and both replies", got)
+	}
+
+	hashtag, err := repositories.Tweets.GetHashtagTweets(ctx, "#dUnE", &model.CursorQuery{Sort: "-created_at"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tweetMessages(hashtag.Data); got != "#DUNE part two #2,@ana enjoy #dune,Watching #Dune tonight" {
+		t.Errorf("the tweets of #dune are %s", got)
+	}
+
+	mentions, err := repositories.Tweets.GetMentionTweets(ctx, bob, &model.CursorQuery{Sort: "-created_at"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tweetMessages(mentions.Data); got != "@Bob thanks, and @nobody" {
+		t.Errorf("the tweets mentioning bob are %s", got)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := repositories.Tweets.LikeTweet(ctx, root.ID.Hex(), bob); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := repositories.Tweets.UnlikeTweet(ctx, root.ID.Hex(), ana); err != nil {
+		t.Fatal(err)
+	}
+
+	retweet, err := repositories.Tweets.Retweet(ctx, root.ID.Hex(), bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = repositories.Tweets.Retweet(ctx, retweet.ID.Hex(), bob)
+	expectStatus(t, "retweeting a tweet twice", err, http.StatusConflict)
+	anaRetweet, err := repositories.Tweets.Retweet(ctx, retweet.ID.Hex(), ana)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A retweet is undone through its own id as well as the original one.
+	if err := repositories.Tweets.Unretweet(ctx, anaRetweet.ID.Hex(), ana); err != nil {
+		t.Fatal(err)
+	}
+	err = repositories.Tweets.Unretweet(ctx, root.ID.Hex(), ana)
+	expectStatus(t, "undoing a retweet twice", err, http.StatusNotFound)
+
+	tweet, err := repositories.Tweets.GetTweet(ctx, root.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tweet.Likes != 1 || tweet.Replies != 1 || tweet.Retweets != 1 || tweet.Version != root.Version {
+		t.Errorf("the root tweet has %d likes, %d replies and %d retweets at version %d, want 1, 1 and 1 at version %d", tweet.Likes, tweet.Replies, tweet.Retweets, tweet.Version, root.Version)
+	}
+}
+
+func testPasswords(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	user, err := repositories.Users.SaveUser(ctx, &model.User{UserInput: &model.UserInput{Name: "Ada", Lastname: "Lovelace", Email: "ada@example.com", Password: "old"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := user.ID.Hex()
+
+	_, err = repositories.Users.PatchUser(ctx, id, &model.User{UserInput: &model.UserInput{Name: "Ada", Lastname: "Byron", Password: "patched"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPassword(t, repositories, "ada@example.com", "old")
+
+	if err := repositories.Users.UpdatePassword(ctx, id, "new"); err != nil {
+		t.Fatal(err)
+	}
+	expectPassword(t, repositories, "ada@example.com", "new")
+
+	err = repositories.Users.UpdatePassword(ctx, primitive.NewObjectID().Hex(), "new")
+	expectStatus(t, "UpdatePassword", err, http.StatusNotFound)
+}
+
+func testCalendarTokens(t *testing.T, repositories *Repositories) {
+	ctx := context.Background()
+	user, err := repositories.Users.SaveUser(ctx, &model.User{UserInput: &model.UserInput{Name: "Ada", Lastname: "Lovelace", Email: "ada@example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := user.ID.Hex()
+
+	for _, token := range []string{"first", "second"} {
+		if err := repositories.Users.SetCalendarToken(ctx, id, token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	found, err := repositories.Users.FindByCalendarToken(ctx, "second")
+	if err != nil || found.ID != user.ID {
+		t.Errorf("FindByCalendarToken found %v (%v), want Ada", found, err)
+	}
+	_, err = repositories.Users.FindByCalendarToken(ctx, "first")
+	expectStatus(t, "FindByCalendarToken of a replaced token", err, http.StatusNotFound)
+
+	if err := repositories.Users.SetCalendarToken(ctx, id, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []string{"second", ""} {
+		_, err = repositories.Users.FindByCalendarToken(ctx, token)
+		expectStatus(t, "FindByCalendarToken of a revoked token", err, http.StatusNotFound)
+	}
+
+	err = repositories.Users.SetCalendarToken(ctx, primitive.NewObjectID().Hex(), "token")
+	expectStatus(t, "SetCalendarToken", err, http.StatusNotFound)
+}
+
+func saveMovie(t *testing.T, repositories *Repositories, title string) *model.Movie {
+	return saveMovieIn(context.Background(), t, repositories, title)
+}
+
+func saveMovieIn(ctx context.Context, t *testing.T, repositories *Repositories, title string) *model.Movie {
+	t.Helper()
+	movie := &model.Movie{MovieInput: &model.MovieInput{Title: title, Format: "2D", ReleaseYear: 2000, CreatedAt: time.Now()}}
+	saved, err := repositories.Movies.SaveMovie(ctx, movie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return saved
+}
+
+func saveCountry(t *testing.T, repositories *Repositories, name string) *model.Country {
+	t.Helper()
+	country := &model.Country{CountryInput: &model.CountryInput{Name: name, CreatedAt: time.Now()}}
+	saved, err := repositories.Countries.SaveCountry(context.Background(), country)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return saved
+}
+
+func saveState(t *testing.T, repositories *Repositories, name string, countryId string) *model.State {
+	t.Helper()
+	state := &model.State{StateInput: &model.StateInput{Name: name, CountryId: countryId, CreatedAt: time.Now()}}
+	saved, err := repositories.States.SaveState(context.Background(), state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return saved
+}
+
+func saveRoom(t *testing.T, repositories *Repositories, name string, seatMap ...string) *model.Room {
+	t.Helper()
+	ctx := context.Background()
+	state := saveState(t, repositories, "Antioquia", saveCountry(t, repositories, "Colombia").ID.Hex())
+	city, err := repositories.Cities.SaveCity(ctx, &model.City{CityInput: &model.CityInput{Name: "Medellín", StateId: state.ID.Hex(), CreatedAt: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cinema, err := repositories.Cinemas.SaveCinema(ctx, &model.Cinema{CinemaInput: &model.CinemaInput{Name: "Centro", CityId: city.ID.Hex(), CreatedAt: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	room, err := repositories.Rooms.SaveRoom(ctx, &model.Room{RoomInput: &model.RoomInput{Name: name, Capacity: "7", Format: "2D", CinemaId: cinema.ID.Hex(), SeatMap: seatMap, CreatedAt: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return room
+}
+
+func saveSchedule(t *testing.T, repositories *Repositories, roomId string, movieId string, date time.Time) *model.Schedule {
+	t.Helper()
+	schedule := &model.Schedule{ScheduleInput: &model.ScheduleInput{RoomId: roomId, MovieId: movieId, Date: date, CreatedAt: time.Now()}}
+	saved, err := repositories.Schedules.SaveSchedule(context.Background(), schedule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return saved
+}
+
+func expectChildren(t *testing.T, repositories *Repositories, countryId string, stateIds ...string) {
+	t.Helper()
+	country, err := repositories.Countries.GetCountryById(context.Background(), countryId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(country.States) != fmt.Sprint(stateIds) {
+		t.Errorf("country %s has states %v, want %v", country.Name, country.States, stateIds)
+	}
+}
+
+func expectRooms(t *testing.T, repositories *Repositories, cinemaId string, roomIds ...string) {
+	t.Helper()
+	cinema, err := repositories.Cinemas.GetCinemaById(context.Background(), cinemaId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(cinema.Rooms, ",") != strings.Join(roomIds, ",") {
+		t.Errorf("the cinema has rooms %v, want %v", cinema.Rooms, roomIds)
+	}
+}
+
+func expectSeats(t *testing.T, repositories *Repositories, scheduleId string, empty string, occupied string) {
+	t.Helper()
+	schedule, err := repositories.Schedules.GetScheduleById(context.Background(), scheduleId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(schedule.SeatsEmpty, ",") != empty || strings.Join(schedule.SeatsOccupied, ",") != occupied {
+		t.Errorf("the showtime has the free seats %v and the occupied seats %v, want %s and %s", schedule.SeatsEmpty, schedule.SeatsOccupied, empty, occupied)
+	}
+}
+
+func expectScore(t *testing.T, repositories *Repositories, movieId string, average float64, histogram string) {
+	t.Helper()
+	movie, err := repositories.Movies.GetMovie(context.Background(), movieId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if movie.Score == nil {
+		t.Fatalf("movie %s has no score", movie.Title)
+	}
+	var counts []string
+	for _, stars := range movie.Score.Histogram {
+		counts = append(counts, fmt.Sprint(stars.Count))
+	}
+	if movie.Score.Average != average || strings.Join(counts, ",") != histogram {
+		t.Errorf("movie %s scores %v with histogram %s, want %v with %s", movie.Title, movie.Score.Average, strings.Join(counts, ","), average, histogram)
+	}
+}
+
+func expectPassword(t *testing.T, repositories *Repositories, email string, password string) {
+	t.Helper()
+	user, err := repositories.Users.FindByEmail(context.Background(), email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Password != password {
+		t.Errorf("the password of %s is %q, want %q", email, user.Password, password)
+	}
+}
+
+func expectStatus(t *testing.T, operation string, err error, status int) {
+	t.Helper()
+	var httpError *echo.HTTPError
+	if !errors.As(err, &httpError) || httpError.Code != status {
+		t.Errorf("%s returned %v, want status %d", operation, err, status)
+	}
+}
+
+func movieTitles(movies []model.Movie) string {
+	var titles string
+	for i, movie := range movies {
+		if i > 0 {
+			titles += ","
+		}
+		titles += movie.Title
+	}
+	return titles
+}
+
+func reviewRatings(reviews []model.Review) string {
+	ratings := make([]string, 0, len(reviews))
+	for _, review := range reviews {
+		ratings = append(ratings, fmt.Sprint(review.Rating))
+	}
+	return strings.Join(ratings, ",")
+}
+
+func tweetMessages(tweets []model.Tweet) string {
+	messages := make([]string, 0, len(tweets))
+	for _, tweet := range tweets {
+		messages = append(messages, tweet.Message)
+	}
+	return strings.Join(messages, ",")
+}
+
+func searchTitles(hits []model.MovieSearchHit) string {
+	movies := make([]model.Movie, 0, len(hits))
+	for _, hit := range hits {
+		movies = append(movies, *hit.Movie)
+	}
+	return movieTitles(movies)
+}
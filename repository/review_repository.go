@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/cbuelvasc/cinema-backend/config"
+	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	paginate "github.com/gobeam/mongo-go-pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ReviewRepository stores the reviews of movies and keeps the score of each
+// movie in step with them.
+type ReviewRepository interface {
+	GetMovieReviews(ctx context.Context, query *model.ReviewQuery) (*model.PagedReview, error)
+	GetReview(ctx context.Context, id string) (*model.Review, error)
+	SaveReview(ctx context.Context, review *model.Review) (*model.Review, error)
+	UpdateReview(ctx context.Context, id string, review *model.Review) (*model.Review, error)
+	DeleteReview(ctx context.Context, id string) error
+	VoteReview(ctx context.Context, id string, userId string, vote string) (*model.Review, error)
+}
+
+type reviewRepositoryImpl struct {
+	Connection *mongo.Database
+}
+
+func NewReviewRepository(Connection *mongo.Database) ReviewRepository {
+	return &reviewRepositoryImpl{Connection: Connection}
+}
+
+func (reviewRepository *reviewRepositoryImpl) GetMovieReviews(ctx context.Context, query *model.ReviewQuery) (*model.PagedReview, error) {
+	reviews := []model.Review{}
+	collection := reviewRepository.Connection.Collection("reviews")
+
+	paging := paginate.New(collection).Context(ctx).Limit(query.Limit).Page(query.Page).Select(reviewProjection).Filter(reviewFilter(ctx, query))
+	for _, key := range reviewSort(query) {
+		paging = paging.Sort(key.Key, key.Value)
+	}
+	paginatedData, err := paging.Decode(&reviews).Find()
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PagedReview{
+		Data:     reviews,
+		PageInfo: &paginatedData.Pagination,
+	}, nil
+}
+
+func (reviewRepository *reviewRepositoryImpl) GetReview(ctx context.Context, id string) (*model.Review, error) {
+	return findReview(ctx, mongoCollection(reviewRepository.Connection, "reviews"), id)
+}
+
+func (reviewRepository *reviewRepositoryImpl) SaveReview(ctx context.Context, review *model.Review) (*model.Review, error) {
+	return insertReview(ctx, mongoCollection(reviewRepository.Connection, "reviews"), review)
+}
+
+func (reviewRepository *reviewRepositoryImpl) UpdateReview(ctx context.Context, id string, review *model.Review) (*model.Review, error) {
+	return updateReview(ctx, mongoCollection(reviewRepository.Connection, "reviews"), id, review)
+}
+
+func (reviewRepository *reviewRepositoryImpl) DeleteReview(ctx context.Context, id string) error {
+	return deleteReview(ctx, mongoCollection(reviewRepository.Connection, "reviews"), id)
+}
+
+func (reviewRepository *reviewRepositoryImpl) VoteReview(ctx context.Context, id string, userId string, vote string) (*model.Review, error) {
+	return voteReview(ctx, mongoCollection(reviewRepository.Connection, "reviews"), id, userId, vote)
+}
+
+// reviewProjection leaves the voters out of the reviews listed.
+var reviewProjection = bson.D{
+	{Key: "helpfulBy", Value: 0},
+	{Key: "reportedBy", Value: 0},
+}
+
+// reviewFilter selects the reviews of the movie of query. Reviews reported
+// REVIEW_REPORT_THRESHOLD times or more are left out.
+func reviewFilter(ctx context.Context, query *model.ReviewQuery) bson.M {
+	filter := bson.M{"movieId": query.MovieId}
+	if threshold, _ := strconv.ParseInt(config.ReviewReportThreshold, 10, 64); threshold > 0 {
+		filter["reports"] = bson.M{"$lt": threshold}
+	}
+	return notDeleted(ctx, filter)
+}
+
+// reviewSort lists the most helpful or the latest reviews first.
+func reviewSort(query *model.ReviewQuery) bson.D {
+	if query.Sort == enums.ReviewSortHelpful {
+		return bson.D{{Key: "helpful", Value: -1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+	}
+	return bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+}
+
+func findReview(ctx context.Context, reviews documentStore, id string) (*model.Review, error) {
+	var review model.Review
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	found, err := reviews.FindOne(ctx, filter, nil, &review)
+	if err != nil || !found {
+		return nil, exception.ResourceNotFoundException("Review", "id", id)
+	}
+	return &review, nil
+}
+
+// insertReview saves the first review of a user for a movie and scores the
+// movie again. A second review of the same movie is a conflict.
+func insertReview(ctx context.Context, reviews documentStore, review *model.Review) (*model.Review, error) {
+	review.ID = primitive.NewObjectID()
+	review.Version = 1
+
+	err := reviews.Transaction(ctx, func(ctx context.Context) error {
+		count, err := reviews.Count(ctx, bson.M{"movieId": review.MovieId, "userId": review.UserId, "deleted_at": nil})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return exception.ConflictException("Review", "userId", review.UserId)
+		}
+
+		if err := reviews.InsertOne(ctx, review); err != nil {
+			return err
+		}
+		return scoreMovie(ctx, reviews, review.MovieId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// updateReview changes the rating and text of a review and scores its movie
+// again.
+func updateReview(ctx context.Context, reviews documentStore, id string, review *model.Review) (*model.Review, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+	fields := bson.M{
+		"rating":     review.Rating,
+		"text":       review.Text,
+		"updated_at": review.UpdatedAt,
+	}
+
+	var updated model.Review
+	err := reviews.Transaction(ctx, func(ctx context.Context) error {
+		found, err := updateDocument(ctx, reviews, filter, fields, &updated)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return exception.ResourceNotFoundException("Review", "id", id)
+		}
+		return scoreMovie(ctx, reviews, updated.MovieId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// deleteReview deletes a review and scores its movie again without it.
+func deleteReview(ctx context.Context, reviews documentStore, id string) error {
+	return reviews.Transaction(ctx, func(ctx context.Context) error {
+		review, err := findReview(ctx, reviews, id)
+		if err != nil {
+			return err
+		}
+
+		deleted, err := deleteDocument(ctx, reviews, bson.M{"_id": review.ID})
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return exception.ResourceNotFoundException("Review", "id", id)
+		}
+		return scoreMovie(ctx, reviews, review.MovieId)
+	})
+}
+
+// voteReview counts the helpful vote or the report of a user on a review,
+// once per user: voting again leaves the review as it is. Votes are not edits
+// of the review, so they keep its version.
+func voteReview(ctx context.Context, reviews documentStore, id string, userId string, vote string) (*model.Review, error) {
+	counter, voters := "helpful", "helpfulBy"
+	if vote == enums.ReviewVoteReport {
+		counter, voters = "reports", "reportedBy"
+	}
+
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId, voters: bson.M{"$ne": userId}})
+	update := bson.M{
+		"$addToSet": bson.M{voters: userId},
+		"$inc":      bson.M{counter: 1},
+	}
+
+	var review model.Review
+	found, err := reviews.FindOneAndUpdate(ctx, filter, update, &review)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return findReview(ctx, reviews, id)
+	}
+	return &review, nil
+}
+
+// scoreMovie sets the score of a movie from its reviews, or removes it when
+// it has none left. Like votes, scores keep the version of the movie.
+func scoreMovie(ctx context.Context, reviews documentStore, movieId string) error {
+	var found []model.Review
+	if err := reviews.Find(ctx, bson.M{"movieId": movieId, "deleted_at": nil}, &found); err != nil {
+		return err
+	}
+
+	objectId, _ := primitive.ObjectIDFromHex(movieId)
+	movies := reviews.Collection("movies")
+	if len(found) == 0 {
+		_, err := movies.UpdateOne(ctx, bson.M{"_id": objectId}, bson.M{"$unset": bson.M{"score": ""}})
+		return err
+	}
+
+	score := model.MovieScore{Count: int64(len(found))}
+	for stars := 1; stars <= 5; stars++ {
+		score.Histogram = append(score.Histogram, model.StarsCount{Stars: stars})
+	}
+	total := 0
+	for _, review := range found {
+		total += review.Rating
+		if review.Rating >= 1 && review.Rating <= 5 {
+			score.Histogram[review.Rating-1].Count++
+		}
+	}
+	score.Average = math.Round(float64(total)/float64(score.Count)*10) / 10
+
+	_, err := movies.UpdateOne(ctx, bson.M{"_id": objectId}, bson.M{"$set": bson.M{"score": score}})
+	return err
+}
@@ -2,7 +2,6 @@ package repository
 
 import (
 	"context"
-	"fmt"
 	"github.com/cbuelvasc/cinema-backend/exception"
 	"github.com/cbuelvasc/cinema-backend/model"
 	paginate "github.com/gobeam/mongo-go-pagination"
@@ -13,11 +12,15 @@ import (
 
 type RoomRepository interface {
 	GetAllRooms(ctx context.Context, page int64, limit int64) (*model.PagedRoom, error)
+	GetAllRoomsByCursor(ctx context.Context, query *model.CursorQuery) (*model.PagedRoom, error)
+	GetAllRoomDocuments(ctx context.Context, query *model.DocumentQuery) (*model.PagedDocument, error)
 	GetRoomById(ctx context.Context, id string) (*model.Room, error)
+	GetRoomDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error)
 	GetRoomByCinema(ctx context.Context, cityId string) (*model.Room, error)
 	SaveRoom(ctx context.Context, room *model.Room) (*model.Room, error)
 	UpdateRoom(ctx context.Context, id string, roomId *model.Room) (*model.Room, error)
 	DeleteRoom(ctx context.Context, id string, roomId string) error
+	RestoreRoom(ctx context.Context, id string) (*model.Room, error)
 }
 
 type roomRepositoryImpl struct {
@@ -28,40 +31,77 @@ func NewRoomRepository(Connection *mongo.Database) RoomRepository {
 	return &roomRepositoryImpl{Connection: Connection}
 }
 
+var roomProjection = bson.D{
+	{"id", 1},
+	{"name", 1},
+	{"capacity", 1},
+	{"format", 1},
+	{"seatMap", 1},
+	{"schedules", 1},
+	{"created_at", 1},
+	{"version", 1},
+	{"deleted_at", 1},
+	{"deleted_by", 1},
+}
+
 func (roomRepository *roomRepositoryImpl) GetAllRooms(ctx context.Context, page int64, limit int64) (*model.PagedRoom, error) {
 	var rooms []model.Room
 
-	filter := bson.M{
-	}
+	filter := notDeleted(ctx, bson.M{
+	})
 
 	collection := roomRepository.Connection.Collection("rooms")
 
-	projection := bson.D{
-		{"id", 1},
-		{"name", 1},
-		{"capacity", 1},
-		{"format", 1},
-		{"schedules", 1},
-		{"created_at", 1},
+	paginatedData, err := paginate.New(collection).Context(ctx).Limit(limit).Page(page).Select(roomProjection).Filter(filter).Decode(&rooms).Find()
+	if err != nil {
+		return nil, err
 	}
 
-	paginatedData, err := paginate.New(collection).Context(ctx).Limit(limit).Page(page).Select(projection).Filter(filter).Decode(&rooms).Find()
+	return &model.PagedRoom{
+		Data:     rooms,
+		PageInfo: &paginatedData.Pagination,
+	}, nil
+}
+
+func (roomRepository *roomRepositoryImpl) GetAllRoomsByCursor(ctx context.Context, query *model.CursorQuery) (*model.PagedRoom, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	collection := roomRepository.Connection.Collection("rooms")
+
+	documents, cursorInfo, err := findByCursor(ctx, collection, filter, roomProjection, query, nil, "name", "created_at")
 	if err != nil {
 		return nil, err
 	}
 
+	rooms := make([]model.Room, 0, len(documents))
+	for _, document := range documents {
+		var room model.Room
+		if err := bson.Unmarshal(document, &room); err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, room)
+	}
+
 	return &model.PagedRoom{
-		Data:     rooms,
-		PageInfo: paginatedData.Pagination,
+		Data:   rooms,
+		Cursor: cursorInfo,
 	}, nil
 }
 
+func (roomRepository *roomRepositoryImpl) GetAllRoomDocuments(ctx context.Context, query *model.DocumentQuery) (*model.PagedDocument, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	collection := roomRepository.Connection.Collection("rooms")
+
+	return findDocuments(ctx, collection, filter, query, "name", "created_at")
+}
+
 func (roomRepository *roomRepositoryImpl) GetRoomById(ctx context.Context, id string) (*model.Room, error) {
 	var room model.Room
 	objectId, _ := primitive.ObjectIDFromHex(id)
-	filter := bson.M{
+	filter := notDeleted(ctx, bson.M{
 		"_id": objectId,
-	}
+	})
 
 	err := roomRepository.Connection.Collection("rooms").FindOne(ctx, filter).Decode(&room)
 	if err != nil {
@@ -70,14 +110,31 @@ func (roomRepository *roomRepositoryImpl) GetRoomById(ctx context.Context, id st
 	return &room, nil
 }
 
+func (roomRepository *roomRepositoryImpl) GetRoomDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{
+		"_id": objectId,
+	})
+
+	document, err := findDocument(ctx, roomRepository.Connection.Collection("rooms"), filter, query)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, exception.ResourceNotFoundException("Room", "id", id)
+	}
+	return document, nil
+}
+
 func (roomRepository *roomRepositoryImpl) GetRoomByCinema(ctx context.Context, cinemaId string) (*model.Room, error) {
 	panic("implement me")
 }
 
 func (roomRepository *roomRepositoryImpl) SaveRoom(ctx context.Context, room *model.Room) (*model.Room, error) {
 	room.ID = primitive.NewObjectID()
+	room.Version = 1
 
-	_, err := roomRepository.Connection.Collection("rooms").InsertOne(ctx, room)
+	err := insertLinked(ctx, mongoCollection(roomRepository.Connection, "rooms"), room, room.ID, room.CinemaId)
 	if err != nil {
 		return nil, err
 	}
@@ -85,59 +142,76 @@ func (roomRepository *roomRepositoryImpl) SaveRoom(ctx context.Context, room *mo
 	return room, nil
 }
 
-func (roomRepository *roomRepositoryImpl) UpdateRoom(ctx context.Context, id string, room *model.Room) (*model.Room, error) {
-	objectId, _ := primitive.ObjectIDFromHex(id)
-
-	registry := make(map[string]interface{})
+// roomUpdateFields returns the fields an update writes: only the ones set in
+// room.
+func roomUpdateFields(room *model.Room) bson.M {
+	fields := bson.M{}
 	if len(room.Name) > 0 {
-		registry["name"] = room.Name
+		fields["name"] = room.Name
 	}
 	if len(room.Capacity) > 0 {
-		registry["capacity"] = room.Capacity
+		fields["capacity"] = room.Capacity
 	}
 	if len(room.Format) > 0 {
-		registry["format"] = room.Format
+		fields["format"] = room.Format
 	}
 	if len(room.CinemaId) > 0 {
-		registry["cinemaId"] = room.CinemaId
+		fields["cinemaId"] = room.CinemaId
+	}
+	if len(room.SeatMap) > 0 {
+		fields["seatMap"] = room.SeatMap
 	}
 	if len(room.Schedules) > 0 {
-		registry["schedules"] = room.Schedules
+		fields["schedules"] = room.Schedules
 	}
+	return fields
+}
 
-	filter := bson.M{"_id": bson.M{"$eq": objectId}}
+func (roomRepository *roomRepositoryImpl) UpdateRoom(ctx context.Context, id string, room *model.Room) (*model.Room, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
 
-	updateString := bson.M{
-		"$set": registry,
-	}
+	registry := roomUpdateFields(room)
+
+	filter := notDeleted(ctx, bson.M{"_id": bson.M{"$eq": objectId}})
 
-	result, err := roomRepository.Connection.Collection("rooms").UpdateOne(ctx, filter, updateString)
+	var updated model.Room
+	found, err := updateLinked(ctx, mongoCollection(roomRepository.Connection, "rooms"), filter, registry, &updated)
 	if err != nil {
 		return nil, err
 	}
-	if result.MatchedCount == 0 {
+	if !found {
 		return nil, exception.ResourceNotFoundException("Room", "id", id)
 	}
 
-	room.ID = objectId
-	return room, nil
+	return &updated, nil
 }
 
 func (roomRepository *roomRepositoryImpl) DeleteRoom(ctx context.Context, id string, roomId string) error {
 	objectId, _ := primitive.ObjectIDFromHex(id)
 	filter := bson.M{
-		"_id":    objectId,
-		"roomId": roomId,
+		"_id": objectId,
 	}
 
-	result, err := roomRepository.Connection.Collection("rooms").DeleteOne(ctx, filter)
+	deleted, err := deleteLinked(ctx, mongoCollection(roomRepository.Connection, "rooms"), filter)
 	if err != nil {
 		return err
 	}
 
-	if result.DeletedCount == 0 {
-		return exception.NotFoundRequestException(fmt.Sprintf("Room not found with id: %s and roomId: %s", id, roomId))
+	if !deleted {
+		return exception.ResourceNotFoundException("Room", "id", id)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func (roomRepository *roomRepositoryImpl) RestoreRoom(ctx context.Context, id string) (*model.Room, error) {
+	restored, err := restoreLinked(ctx, mongoCollection(roomRepository.Connection, "rooms"), id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, exception.ResourceNotFoundException("Room", "id", id)
+	}
+
+	return roomRepository.GetRoomById(ctx, id)
+}
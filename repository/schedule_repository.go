@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ScheduleRepository stores the showtimes of the rooms. Bookings take and
+// free their seats through the BookingRepository.
+type ScheduleRepository interface {
+	GetScheduleById(ctx context.Context, id string) (*model.Schedule, error)
+	SaveSchedule(ctx context.Context, schedule *model.Schedule) (*model.Schedule, error)
+}
+
+type scheduleRepositoryImpl struct {
+	Connection *mongo.Database
+}
+
+func NewScheduleRepository(Connection *mongo.Database) ScheduleRepository {
+	return &scheduleRepositoryImpl{Connection: Connection}
+}
+
+func (scheduleRepository *scheduleRepositoryImpl) GetScheduleById(ctx context.Context, id string) (*model.Schedule, error) {
+	return findSchedule(ctx, mongoCollection(scheduleRepository.Connection, "schedules"), id)
+}
+
+func (scheduleRepository *scheduleRepositoryImpl) SaveSchedule(ctx context.Context, schedule *model.Schedule) (*model.Schedule, error) {
+	return insertSchedule(ctx, mongoCollection(scheduleRepository.Connection, "schedules"), schedule)
+}
+
+func findSchedule(ctx context.Context, schedules documentStore, id string) (*model.Schedule, error) {
+	var schedule model.Schedule
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+
+	found, err := schedules.FindOne(ctx, filter, nil, &schedule)
+	if err != nil || !found {
+		return nil, exception.ResourceNotFoundException("Showtime", "id", id)
+	}
+	return &schedule, nil
+}
+
+// insertSchedule saves a showtime and adds it to the schedules of its room,
+// as the seed loader does. A showtime without seats starts with every seat of
+// the seat map of its room free.
+func insertSchedule(ctx context.Context, schedules documentStore, schedule *model.Schedule) (*model.Schedule, error) {
+	schedule.ID = primitive.NewObjectID()
+	schedule.Version = 1
+	schedule.Date = schedule.Date.UTC()
+
+	err := schedules.Transaction(ctx, func(ctx context.Context) error {
+		var room model.Room
+		roomObjectId, _ := primitive.ObjectIDFromHex(schedule.RoomId)
+		rooms := schedules.Collection("rooms")
+		found, err := rooms.FindOne(ctx, notDeleted(ctx, bson.M{"_id": roomObjectId}), nil, &room)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return exception.ResourceNotFoundException("Room", "id", schedule.RoomId)
+		}
+
+		if schedule.SeatsEmpty == nil {
+			schedule.SeatsEmpty = util.SeatIds(room.SeatMap)
+		}
+		if schedule.SeatsEmpty == nil {
+			schedule.SeatsEmpty = []string{}
+		}
+		if schedule.SeatsOccupied == nil {
+			schedule.SeatsOccupied = []string{}
+		}
+
+		if err := schedules.InsertOne(ctx, schedule); err != nil {
+			return err
+		}
+		update := bson.M{
+			"$addToSet": bson.M{"schedules": schedule.ID.Hex()},
+			"$inc":      bson.M{"version": 1},
+		}
+		_, err = rooms.UpdateOne(ctx, bson.M{"_id": roomObjectId}, update)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
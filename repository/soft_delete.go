@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/config"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// softDeleteCollections are the collections whose documents are only marked
+// as deleted and later removed by the purge job.
+var softDeleteCollections = []string{
+	"users",
+	"tweets",
+	"reviews",
+	"movies",
+	"countries",
+	"states",
+	"cities",
+	"cinemas",
+	"rooms",
+	"schedules",
+	"bookings",
+}
+
+func isSoftDelete() bool {
+	softDelete, err := strconv.ParseBool(config.SoftDelete)
+	return err != nil || softDelete
+}
+
+// notDeleted restricts filter to documents that are not soft-deleted, unless
+// the request asked to include them.
+func notDeleted(ctx context.Context, filter bson.M) bson.M {
+	if util.IsIncludeDeleted(ctx) {
+		return filter
+	}
+	return withFilter(filter, bson.M{"deleted_at": nil})
+}
+
+// deleteDocument marks the document matching filter as deleted by the user of
+// ctx, or removes it when soft delete is disabled. It reports whether a
+// document matched.
+func deleteDocument(ctx context.Context, collection documentStore, filter bson.M) (bool, error) {
+	return cascadeDocument(ctx, collection, filter, "")
+}
+
+// cascadeDocument runs deleteDocument for the deletion of the parent
+// parentId, which the document records to be restored with it.
+func cascadeDocument(ctx context.Context, collection documentStore, filter bson.M, parentId string) (bool, error) {
+	if !isSoftDelete() {
+		deleted, err := collection.DeleteOne(ctx, withExpectedVersion(ctx, filter))
+		if err != nil {
+			return false, err
+		}
+		if deleted == 0 {
+			return false, checkVersion(ctx, collection, filter)
+		}
+		return true, nil
+	}
+
+	deletion := bson.M{
+		"deleted_at": time.Now(),
+		"deleted_by": util.GetUserIdFromContext(ctx),
+	}
+	if len(parentId) > 0 {
+		deletion["deleted_with"] = parentId
+	}
+	update := bson.M{
+		"$set": deletion,
+		"$inc": bson.M{"version": 1},
+	}
+
+	filter = withFilter(filter, bson.M{"deleted_at": nil})
+	matched, err := collection.UpdateOne(ctx, withExpectedVersion(ctx, filter), update)
+	if err != nil {
+		return false, err
+	}
+	if matched == 0 {
+		return false, checkVersion(ctx, collection, filter)
+	}
+	return true, nil
+}
+
+// restoreDocument clears the deletion mark of a soft-deleted document. It
+// reports whether a deleted document matched.
+func restoreDocument(ctx context.Context, collection documentStore, id string) (bool, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := bson.M{
+		"_id":        objectId,
+		"deleted_at": bson.M{"$ne": nil},
+	}
+
+	update := bson.M{
+		"$unset": bson.M{
+			"deleted_at":   "",
+			"deleted_by":   "",
+			"deleted_with": "",
+		},
+		"$inc": bson.M{"version": 1},
+	}
+
+	matched, err := collection.UpdateOne(ctx, withExpectedVersion(ctx, filter), update)
+	if err != nil {
+		return false, err
+	}
+	if matched == 0 {
+		return false, checkVersion(ctx, collection, filter)
+	}
+	return true, nil
+}
+
+func withFilter(filter bson.M, extra bson.M) bson.M {
+	combined := bson.M{}
+	for key, value := range filter {
+		combined[key] = value
+	}
+	for key, value := range extra {
+		combined[key] = value
+	}
+	return combined
+}
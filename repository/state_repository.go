@@ -2,7 +2,6 @@ package repository
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/cbuelvasc/cinema-backend/exception"
 	"github.com/cbuelvasc/cinema-backend/model"
@@ -14,10 +13,16 @@ import (
 
 type StateRepository interface {
 	GetAllStates(ctx context.Context, page int64, limit int64, countryId string) (*model.PagedState, error)
+	GetAllStatesByCursor(ctx context.Context, query *model.CursorQuery, countryId string) (*model.PagedState, error)
+	GetAllStateDocuments(ctx context.Context, query *model.DocumentQuery, countryId string) (*model.PagedDocument, error)
 	GetStateById(ctx context.Context, id string) (*model.State, error)
+	GetStateDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error)
+	GetStateByName(ctx context.Context, countryId string, name string) (*model.State, error)
 	SaveState(ctx context.Context, state *model.State) (*model.State, error)
 	UpdateState(ctx context.Context, id string, state *model.State) (*model.State, error)
+	PatchState(ctx context.Context, id string, state *model.State) (*model.State, error)
 	DeleteState(ctx context.Context, id string) error
+	RestoreState(ctx context.Context, id string) (*model.State, error)
 }
 
 type stateRepositoryImpl struct {
@@ -28,6 +33,17 @@ func NewStateRepository(Connection *mongo.Database) StateRepository {
 	return &stateRepositoryImpl{Connection: Connection}
 }
 
+var stateProjection = bson.D{
+	{"id", 1},
+	{"name", 1},
+	{"countryId", 1},
+	{"cities", 1},
+	{"created_at", 1},
+	{"version", 1},
+	{"deleted_at", 1},
+	{"deleted_by", 1},
+}
+
 func (stateRepository *stateRepositoryImpl) GetAllStates(ctx context.Context, page int64, limit int64, countryId string) (*model.PagedState, error) {
 	var states []model.State
 
@@ -37,37 +53,76 @@ func (stateRepository *stateRepositoryImpl) GetAllStates(ctx context.Context, pa
 			"countryId": countryId,
 		}
 	}
+	filter = notDeleted(ctx, filter)
 
 	collection := stateRepository.Connection.Collection("states")
-	projection := bson.D{
-		{"id", 1},
-		{"name", 1},
-		{"countryId", 1},
-		{"cities", 1},
-		{"created_at", 1},
-	}
 
-	paginatedData, err := paginate.New(collection).Context(ctx).Limit(limit).Page(page).Select(projection).Filter(filter).Decode(&states).Find()
+	paginatedData, err := paginate.New(collection).Context(ctx).Limit(limit).Page(page).Select(stateProjection).Filter(filter).Decode(&states).Find()
 	if err != nil {
 		return nil, err
 	}
 
 	if states == nil {
-		return nil, exception.NotFoundRequestException("States not found")
+		return nil, exception.ResourcesNotFoundException("States")
 	}
 
 	return &model.PagedState{
 		Data:     states,
-		PageInfo: paginatedData.Pagination,
+		PageInfo: &paginatedData.Pagination,
+	}, nil
+}
+
+func (stateRepository *stateRepositoryImpl) GetAllStatesByCursor(ctx context.Context, query *model.CursorQuery, countryId string) (*model.PagedState, error) {
+	var filter = bson.M{}
+	if len(countryId) > 0 {
+		filter = bson.M{
+			"countryId": countryId,
+		}
+	}
+	filter = notDeleted(ctx, filter)
+
+	collection := stateRepository.Connection.Collection("states")
+
+	documents, cursorInfo, err := findByCursor(ctx, collection, filter, stateProjection, query, nil, "name", "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]model.State, 0, len(documents))
+	for _, document := range documents {
+		var state model.State
+		if err := bson.Unmarshal(document, &state); err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+
+	return &model.PagedState{
+		Data:   states,
+		Cursor: cursorInfo,
 	}, nil
 }
 
+func (stateRepository *stateRepositoryImpl) GetAllStateDocuments(ctx context.Context, query *model.DocumentQuery, countryId string) (*model.PagedDocument, error) {
+	var filter = bson.M{}
+	if len(countryId) > 0 {
+		filter = bson.M{
+			"countryId": countryId,
+		}
+	}
+	filter = notDeleted(ctx, filter)
+
+	collection := stateRepository.Connection.Collection("states")
+
+	return findDocuments(ctx, collection, filter, query, "name", "created_at")
+}
+
 func (stateRepository *stateRepositoryImpl) GetStateById(ctx context.Context, id string) (*model.State, error) {
 	var state model.State
 	objectId, _ := primitive.ObjectIDFromHex(id)
-	filter := bson.M{
+	filter := notDeleted(ctx, bson.M{
 		"_id": objectId,
-	}
+	})
 
 	err := stateRepository.Connection.Collection("states").FindOne(ctx, filter).Decode(&state)
 	if err != nil {
@@ -76,10 +131,39 @@ func (stateRepository *stateRepositoryImpl) GetStateById(ctx context.Context, id
 	return &state, nil
 }
 
+func (stateRepository *stateRepositoryImpl) GetStateDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{
+		"_id": objectId,
+	})
+
+	document, err := findDocument(ctx, stateRepository.Connection.Collection("states"), filter, query)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, exception.ResourceNotFoundException("State", "id", id)
+	}
+	return document, nil
+}
+
+func (stateRepository *stateRepositoryImpl) GetStateByName(ctx context.Context, countryId string, name string) (*model.State, error) {
+	var state model.State
+	filter := notDeleted(ctx, bson.M{"countryId": countryId, "name": name})
+
+	err := stateRepository.Connection.Collection("states").FindOne(ctx, filter).Decode(&state)
+	if err != nil {
+		return nil, exception.ResourceNotFoundException("State", "name", name)
+	}
+	return &state, nil
+}
+
 func (stateRepository *stateRepositoryImpl) SaveState(ctx context.Context, state *model.State) (*model.State, error) {
 	state.ID = primitive.NewObjectID()
+	state.Version = 1
+	state.Cities = []string{}
 
-	_, err := stateRepository.Connection.Collection("states").InsertOne(ctx, state)
+	err := insertLinked(ctx, mongoCollection(stateRepository.Connection, "states"), state, state.ID, state.CountryId)
 	if err != nil {
 		return nil, err
 	}
@@ -87,15 +171,69 @@ func (stateRepository *stateRepositoryImpl) SaveState(ctx context.Context, state
 	return state, nil
 }
 
+// stateUpdateFields returns the fields an update writes: only the ones set in
+// state.
+func stateUpdateFields(state *model.State) bson.M {
+	fields := bson.M{}
+	if len(state.Name) > 0 {
+		fields["name"] = state.Name
+	}
+	if len(state.CountryId) > 0 {
+		fields["countryId"] = state.CountryId
+	}
+	if !state.UpdatedAt.IsZero() {
+		fields["updated_at"] = state.UpdatedAt
+	}
+	return fields
+}
+
 func (stateRepository *stateRepositoryImpl) UpdateState(ctx context.Context, id string, state *model.State) (*model.State, error) {
-	state.ID = primitive.NewObjectID()
+	objectId, _ := primitive.ObjectIDFromHex(id)
+
+	registry := stateUpdateFields(state)
 
-	_, err := stateRepository.Connection.Collection("states").InsertOne(ctx, state)
+	filter := notDeleted(ctx, bson.M{"_id": bson.M{"$eq": objectId}})
+
+	var updated model.State
+	found, err := updateLinked(ctx, mongoCollection(stateRepository.Connection, "states"), filter, registry, &updated)
 	if err != nil {
 		return nil, err
 	}
+	if !found {
+		return nil, exception.ResourceNotFoundException("State", "id", id)
+	}
 
-	return state, nil
+	return &updated, nil
+}
+
+// statePatchFields returns the fields a patch writes: every patchable field of
+// state, so fields cleared by the patch are cleared in the stored document too.
+func statePatchFields(state *model.State) bson.M {
+	fields := bson.M{
+		"name":       state.Name,
+		"countryId":  state.CountryId,
+		"updated_at": state.UpdatedAt,
+	}
+	return fields
+}
+
+func (stateRepository *stateRepositoryImpl) PatchState(ctx context.Context, id string, state *model.State) (*model.State, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+
+	registry := statePatchFields(state)
+
+	filter := notDeleted(ctx, bson.M{"_id": bson.M{"$eq": objectId}})
+
+	var updated model.State
+	found, err := updateLinked(ctx, mongoCollection(stateRepository.Connection, "states"), filter, registry, &updated)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, exception.ResourceNotFoundException("State", "id", id)
+	}
+
+	return &updated, nil
 }
 
 func (stateRepository *stateRepositoryImpl) DeleteState(ctx context.Context, id string) error {
@@ -104,14 +242,26 @@ func (stateRepository *stateRepositoryImpl) DeleteState(ctx context.Context, id
 		"_id": objectId,
 	}
 
-	result, err := stateRepository.Connection.Collection("states").DeleteOne(ctx, filter)
+	deleted, err := deleteLinked(ctx, mongoCollection(stateRepository.Connection, "states"), filter)
 	if err != nil {
 		return err
 	}
 
-	if result.DeletedCount == 0 {
-		return exception.NotFoundRequestException(fmt.Sprintf("State not found with id: %s", id))
+	if !deleted {
+		return exception.ResourceNotFoundException("State", "id", id)
 	}
 
 	return nil
 }
+
+func (stateRepository *stateRepositoryImpl) RestoreState(ctx context.Context, id string) (*model.State, error) {
+	restored, err := restoreLinked(ctx, mongoCollection(stateRepository.Connection, "states"), id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, exception.ResourceNotFoundException("State", "id", id)
+	}
+
+	return stateRepository.GetStateById(ctx, id)
+}
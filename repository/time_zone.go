@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/config"
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// cityTimeZones returns the location of every city of cityIds: its own time
+// zone, else the one of its country, else DEFAULT_TIME_ZONE.
+func cityTimeZones(ctx context.Context, store documentStore, cityIds []string) (map[string]*time.Location, error) {
+	objectIds := bson.A{}
+	for _, cityId := range cityIds {
+		if objectId, err := primitive.ObjectIDFromHex(cityId); err == nil {
+			objectIds = append(objectIds, objectId)
+		}
+	}
+	var cities []model.City
+	if err := store.Collection("cities").Find(ctx, bson.M{"_id": bson.M{"$in": objectIds}}, &cities); err != nil {
+		return nil, err
+	}
+
+	stateIds := bson.A{}
+	for _, city := range cities {
+		if objectId, err := primitive.ObjectIDFromHex(city.StateId); err == nil && len(city.TimeZone) == 0 {
+			stateIds = append(stateIds, objectId)
+		}
+	}
+	var states []model.State
+	if len(stateIds) > 0 {
+		if err := store.Collection("states").Find(ctx, bson.M{"_id": bson.M{"$in": stateIds}}, &states); err != nil {
+			return nil, err
+		}
+	}
+
+	countryIds := bson.A{}
+	stateCountries := map[string]string{}
+	for _, state := range states {
+		stateCountries[state.ID.Hex()] = state.CountryId
+		if objectId, err := primitive.ObjectIDFromHex(state.CountryId); err == nil {
+			countryIds = append(countryIds, objectId)
+		}
+	}
+	var countries []model.Country
+	if len(countryIds) > 0 {
+		if err := store.Collection("countries").Find(ctx, bson.M{"_id": bson.M{"$in": countryIds}}, &countries); err != nil {
+			return nil, err
+		}
+	}
+	countryTimeZones := map[string]string{}
+	for _, country := range countries {
+		countryTimeZones[country.ID.Hex()] = country.TimeZone
+	}
+
+	locations := map[string]*time.Location{}
+	for _, cityId := range cityIds {
+		locations[cityId] = config.DefaultLocation()
+	}
+	for _, city := range cities {
+		timeZone := city.TimeZone
+		if len(timeZone) == 0 {
+			timeZone = countryTimeZones[stateCountries[city.StateId]]
+		}
+		if location, err := model.LoadTimeZone(timeZone); err == nil && len(timeZone) > 0 {
+			locations[city.ID.Hex()] = location
+		}
+	}
+	return locations, nil
+}
+
+func findCityTimeZone(ctx context.Context, cities documentStore, id string) (*time.Location, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	count, err := cities.Count(ctx, notDeleted(ctx, bson.M{"_id": objectId}))
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, exception.ResourceNotFoundException("City", "id", id)
+	}
+
+	locations, err := cityTimeZones(ctx, cities, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	return locations[id], nil
+}
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// insertTweet saves a tweet with the entities of its message. A reply joins
+// the conversation of the tweet it replies to, which counts one more reply;
+// any other tweet starts a conversation of its own.
+func insertTweet(ctx context.Context, tweets documentStore, tweet *model.Tweet) (*model.Tweet, error) {
+	tweet.ID = primitive.NewObjectID()
+	tweet.Version = 1
+	tweet.ConversationId = tweet.ID.Hex()
+
+	err := tweets.Transaction(ctx, func(ctx context.Context) error {
+		if len(tweet.QuoteOfId) > 0 {
+			if _, err := findTweet(ctx, tweets, tweet.QuoteOfId); err != nil {
+				return err
+			}
+		}
+		if len(tweet.ReplyToId) > 0 {
+			parent, err := findTweet(ctx, tweets, tweet.ReplyToId)
+			if err != nil {
+				return err
+			}
+			tweet.ConversationId = conversationId(parent)
+		}
+
+		entities, err := tweetEntities(ctx, tweets, tweet.Message)
+		if err != nil {
+			return err
+		}
+		tweet.Entities = entities
+
+		if err := tweets.InsertOne(ctx, tweet); err != nil {
+			return err
+		}
+		return countTweet(ctx, tweets, tweet, 1)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tweet, nil
+}
+
+// insertRetweet saves the retweet by userId of the tweet id, or of the tweet
+// it retweets, and counts it. Retweeting a tweet twice is a conflict.
+func insertRetweet(ctx context.Context, tweets documentStore, id string, userId string) (*model.Tweet, error) {
+	now := time.Now()
+	retweet := &model.Tweet{
+		TweetInput: &model.TweetInput{UserId: userId, CreatedAt: now, UpdatedAt: now},
+		ID:         primitive.NewObjectID(),
+		Version:    1,
+	}
+
+	err := tweets.Transaction(ctx, func(ctx context.Context) error {
+		original, err := findTweet(ctx, tweets, id)
+		if err != nil {
+			return err
+		}
+		retweet.RetweetOfId = original.ID.Hex()
+		if len(original.RetweetOfId) > 0 {
+			retweet.RetweetOfId = original.RetweetOfId
+		}
+
+		count, err := tweets.Count(ctx, bson.M{"userId": userId, "retweetOfId": retweet.RetweetOfId, "deleted_at": nil})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return exception.ConflictException("Tweet", "retweetOfId", retweet.RetweetOfId)
+		}
+
+		if err := tweets.InsertOne(ctx, retweet); err != nil {
+			return err
+		}
+		return countTweet(ctx, tweets, retweet, 1)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return retweet, nil
+}
+
+// deleteRetweet undoes the retweet of a user. Like insertRetweet, it takes
+// the id of the original tweet or of any retweet of it. The id of a tweet
+// deleted since is taken as the original one.
+func deleteRetweet(ctx context.Context, tweets documentStore, id string, userId string) error {
+	retweetOfId := id
+	if tweet, err := findTweet(ctx, tweets, id); err == nil && len(tweet.RetweetOfId) > 0 {
+		retweetOfId = tweet.RetweetOfId
+	}
+
+	deleted, err := deleteTweet(ctx, tweets, bson.M{"userId": userId, "retweetOfId": retweetOfId})
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return exception.TweetNotFoundException("Tweet", "retweetOfId", retweetOfId, "userId", userId)
+	}
+	return nil
+}
+
+// deleteTweet deletes the tweet matching filter and takes it off the counts
+// of the tweet it replies to or retweets. It reports whether a tweet matched.
+func deleteTweet(ctx context.Context, tweets documentStore, filter bson.M) (bool, error) {
+	deleted := false
+	err := tweets.Transaction(ctx, func(ctx context.Context) error {
+		var tweet model.Tweet
+		found, err := tweets.FindOne(ctx, notDeleted(ctx, filter), nil, &tweet)
+		if err != nil || !found {
+			return err
+		}
+
+		if deleted, err = deleteDocument(ctx, tweets, bson.M{"_id": tweet.ID}); err != nil || !deleted {
+			return err
+		}
+		return countTweet(ctx, tweets, &tweet, -1)
+	})
+	return deleted, err
+}
+
+// restoreTweet restores a deleted tweet and counts it again.
+func restoreTweet(ctx context.Context, tweets documentStore, id string) (bool, error) {
+	restored := false
+	err := tweets.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if restored, err = restoreDocument(ctx, tweets, id); err != nil || !restored {
+			return err
+		}
+
+		tweet, err := findTweet(ctx, tweets, id)
+		if err != nil {
+			return err
+		}
+		return countTweet(ctx, tweets, tweet, 1)
+	})
+	return restored, err
+}
+
+// likeTweet adds the like of userId to a tweet, once per user. Likes,
+// replies and retweets are not edits of the tweet they count on, so they keep
+// its version.
+func likeTweet(ctx context.Context, tweets documentStore, id string, userId string) (*model.Tweet, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId, "likedBy": bson.M{"$ne": userId}})
+	update := bson.M{
+		"$addToSet": bson.M{"likedBy": userId},
+		"$inc":      bson.M{"likes": 1},
+	}
+	return voteTweet(ctx, tweets, id, filter, update)
+}
+
+// unlikeTweet removes the like of userId from a tweet, if any.
+func unlikeTweet(ctx context.Context, tweets documentStore, id string, userId string) (*model.Tweet, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId, "likedBy": userId})
+	update := bson.M{
+		"$pull": bson.M{"likedBy": userId},
+		"$inc":  bson.M{"likes": -1},
+	}
+	return voteTweet(ctx, tweets, id, filter, update)
+}
+
+func voteTweet(ctx context.Context, tweets documentStore, id string, filter bson.M, update bson.M) (*model.Tweet, error) {
+	var tweet model.Tweet
+	found, err := tweets.FindOneAndUpdate(ctx, filter, update, &tweet)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return findTweet(ctx, tweets, id)
+	}
+	return &tweet, nil
+}
+
+// countTweet adds delta to the replies of the tweet tweet replies to and to
+// the retweets of the tweet it retweets.
+func countTweet(ctx context.Context, tweets documentStore, tweet *model.Tweet, delta int) error {
+	for field, parentId := range map[string]string{"replies": tweet.ReplyToId, "retweets": tweet.RetweetOfId} {
+		if len(parentId) == 0 {
+			continue
+		}
+		objectId, _ := primitive.ObjectIDFromHex(parentId)
+		if _, err := tweets.UpdateOne(ctx, bson.M{"_id": objectId}, bson.M{"$inc": bson.M{field: delta}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tweetEntities parses the entities of message and links its mentions to the
+// users of those usernames.
+func tweetEntities(ctx context.Context, tweets documentStore, message string) (*model.TweetEntities, error) {
+	entities := model.ParseTweetEntities(message)
+	if entities == nil || len(entities.Mentions) == 0 {
+		return entities, nil
+	}
+
+	usernames := bson.A{}
+	for _, mention := range entities.Mentions {
+		usernames = append(usernames, mention.Username)
+	}
+	var users []model.User
+	if err := tweets.Collection("users").Find(ctx, bson.M{"username": bson.M{"$in": usernames}, "deleted_at": nil}, &users); err != nil {
+		return nil, err
+	}
+
+	userIds := map[string]string{}
+	for _, user := range users {
+		userIds[user.Username] = user.ID.Hex()
+	}
+	for i, mention := range entities.Mentions {
+		entities.Mentions[i].UserId = userIds[mention.Username]
+	}
+	return entities, nil
+}
+
+func findTweet(ctx context.Context, tweets documentStore, id string) (*model.Tweet, error) {
+	var tweet model.Tweet
+	objectId, _ := primitive.ObjectIDFromHex(id)
+
+	found, err := tweets.FindOne(ctx, notDeleted(ctx, bson.M{"_id": objectId}), nil, &tweet)
+	if err != nil || !found {
+		return nil, exception.ResourceNotFoundException("Tweet", "id", id)
+	}
+	return &tweet, nil
+}
+
+// conversationId is the conversation of tweet. Tweets saved before replies
+// existed start their own.
+func conversationId(tweet *model.Tweet) string {
+	if len(tweet.ConversationId) > 0 {
+		return tweet.ConversationId
+	}
+	return tweet.ID.Hex()
+}
+
+// conversationFilter matches the tweets of the conversation of tweet.
+func conversationFilter(ctx context.Context, tweet *model.Tweet) bson.M {
+	objectId, _ := primitive.ObjectIDFromHex(conversationId(tweet))
+	return notDeleted(ctx, bson.M{"$or": bson.A{
+		bson.M{"_id": objectId},
+		bson.M{"conversationId": conversationId(tweet)},
+	}})
+}
+
+func hashtagFilter(ctx context.Context, tag string) bson.M {
+	return notDeleted(ctx, bson.M{"entities.hashtags": bson.M{"$elemMatch": bson.M{"tag": strings.ToLower(strings.TrimPrefix(tag, "#"))}}})
+}
+
+func mentionFilter(ctx context.Context, userId string) bson.M {
+	return notDeleted(ctx, bson.M{"entities.mentions": bson.M{"$elemMatch": bson.M{"userId": userId}}})
+}
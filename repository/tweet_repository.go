@@ -2,7 +2,6 @@ package repository
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/cbuelvasc/cinema-backend/exception"
 	"github.com/cbuelvasc/cinema-backend/model"
@@ -14,9 +13,21 @@ import (
 
 type TweetRepository interface {
 	GetAllTweets(ctx context.Context, page int64, limit int64, userId string) (*model.PagedTweet, error)
+	GetAllTweetsByCursor(ctx context.Context, query *model.CursorQuery, userId string) (*model.PagedTweet, error)
+	GetAllTweetDocuments(ctx context.Context, query *model.DocumentQuery, userId string) (*model.PagedDocument, error)
 	GetTweet(ctx context.Context, id string) (*model.Tweet, error)
+	GetTweetDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error)
 	SaveTweet(ctx context.Context, tweet *model.Tweet) (*model.Tweet, error)
 	DeleteTweet(ctx context.Context, id string, userId string) error
+	RestoreTweet(ctx context.Context, id string) (*model.Tweet, error)
+	GetTimeline(ctx context.Context, userId string, query *model.CursorQuery) (*model.PagedTweet, error)
+	GetConversation(ctx context.Context, id string, query *model.CursorQuery) (*model.PagedTweet, error)
+	GetHashtagTweets(ctx context.Context, tag string, query *model.CursorQuery) (*model.PagedTweet, error)
+	GetMentionTweets(ctx context.Context, userId string, query *model.CursorQuery) (*model.PagedTweet, error)
+	LikeTweet(ctx context.Context, id string, userId string) (*model.Tweet, error)
+	UnlikeTweet(ctx context.Context, id string, userId string) (*model.Tweet, error)
+	Retweet(ctx context.Context, id string, userId string) (*model.Tweet, error)
+	Unretweet(ctx context.Context, id string, userId string) error
 }
 
 type tweetRepositoryImpl struct {
@@ -27,43 +38,91 @@ func NewTeewtRepository(Connection *mongo.Database) TweetRepository {
 	return &tweetRepositoryImpl{Connection: Connection}
 }
 
+var tweetProjection = bson.D{
+	{"id", 1},
+	{"userId", 1},
+	{"message", 1},
+	{"replyToId", 1},
+	{"quoteOfId", 1},
+	{"retweetOfId", 1},
+	{"conversationId", 1},
+	{"entities", 1},
+	{"likes", 1},
+	{"replies", 1},
+	{"retweets", 1},
+	{"created_at", 1},
+	{"version", 1},
+	{"deleted_at", 1},
+	{"deleted_by", 1},
+}
+
 func (tweetRepository *tweetRepositoryImpl) GetAllTweets(ctx context.Context, page int64, limit int64, userId string) (*model.PagedTweet, error) {
 	var tweets []model.Tweet
 
-	filter := bson.M{
+	filter := notDeleted(ctx, bson.M{
 		"userId": userId,
-	}
+	})
 
 	collection := tweetRepository.Connection.Collection("tweets")
 
-	projection := bson.D{
-		{"id", 1},
-		{"userId", 1},
-		{"message", 1},
-		{"created_at", 1},
-	}
-
-	paginatedData, err := paginate.New(collection).Context(ctx).Limit(limit).Page(page).Select(projection).Filter(filter).Decode(&tweets).Find()
+	paginatedData, err := paginate.New(collection).Context(ctx).Limit(limit).Page(page).Select(tweetProjection).Filter(filter).Decode(&tweets).Find()
 	if err != nil {
 		return nil, err
 	}
 
 	if tweets == nil {
-		return nil, exception.ResourceNotFoundException("Tweets", "userId", userId)
+		return nil, exception.FilteredResourcesNotFoundException("Tweets", "userId", userId)
 	}
 
 	return &model.PagedTweet{
 		Data:     tweets,
-		PageInfo: paginatedData.Pagination,
+		PageInfo: &paginatedData.Pagination,
+	}, nil
+}
+
+func (tweetRepository *tweetRepositoryImpl) GetAllTweetsByCursor(ctx context.Context, query *model.CursorQuery, userId string) (*model.PagedTweet, error) {
+	filter := notDeleted(ctx, bson.M{
+		"userId": userId,
+	})
+
+	collection := tweetRepository.Connection.Collection("tweets")
+
+	documents, cursorInfo, err := findByCursor(ctx, collection, filter, tweetProjection, query, nil, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	tweets := make([]model.Tweet, 0, len(documents))
+	for _, document := range documents {
+		var tweet model.Tweet
+		if err := bson.Unmarshal(document, &tweet); err != nil {
+			return nil, err
+		}
+		tweets = append(tweets, tweet)
+	}
+
+	return &model.PagedTweet{
+		Data:   tweets,
+		Cursor: cursorInfo,
 	}, nil
 }
 
+func (tweetRepository *tweetRepositoryImpl) GetAllTweetDocuments(ctx context.Context, query *model.DocumentQuery, userId string) (*model.PagedDocument, error) {
+	filter := notDeleted(ctx, bson.M{
+		"userId": userId,
+	})
+
+	collection := tweetRepository.Connection.Collection("tweets")
+
+	return findDocuments(ctx, collection, filter, query, "created_at")
+}
+
 func (tweetRepository *tweetRepositoryImpl) GetTweet(ctx context.Context, id string) (*model.Tweet, error) {
 	var tweet model.Tweet
 	objectId, _ := primitive.ObjectIDFromHex(id)
-	filter := bson.M{
+	filter := notDeleted(ctx, bson.M{
 		"_id": objectId,
-	}
+	})
 
 	err := tweetRepository.Connection.Collection("tweets").FindOne(ctx, filter).Decode(&tweet)
 	if err != nil {
@@ -72,15 +131,24 @@ func (tweetRepository *tweetRepositoryImpl) GetTweet(ctx context.Context, id str
 	return &tweet, nil
 }
 
-func (tweetRepository *tweetRepositoryImpl) SaveTweet(ctx context.Context, tweet *model.Tweet) (*model.Tweet, error) {
-	tweet.ID = primitive.NewObjectID()
+func (tweetRepository *tweetRepositoryImpl) GetTweetDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{
+		"_id": objectId,
+	})
 
-	_, err := tweetRepository.Connection.Collection("tweets").InsertOne(ctx, tweet)
+	document, err := findDocument(ctx, tweetRepository.Connection.Collection("tweets"), filter, query)
 	if err != nil {
 		return nil, err
 	}
+	if document == nil {
+		return nil, exception.ResourceNotFoundException("Tweet", "id", id)
+	}
+	return document, nil
+}
 
-	return tweet, nil
+func (tweetRepository *tweetRepositoryImpl) SaveTweet(ctx context.Context, tweet *model.Tweet) (*model.Tweet, error) {
+	return insertTweet(ctx, mongoCollection(tweetRepository.Connection, "tweets"), tweet)
 }
 
 func (tweetRepository *tweetRepositoryImpl) DeleteTweet(ctx context.Context, id string, userId string) error {
@@ -90,14 +158,97 @@ func (tweetRepository *tweetRepositoryImpl) DeleteTweet(ctx context.Context, id
 		"userId": userId,
 	}
 
-	result, err := tweetRepository.Connection.Collection("tweets").DeleteOne(ctx, filter)
+	deleted, err := deleteTweet(ctx, mongoCollection(tweetRepository.Connection, "tweets"), filter)
 	if err != nil {
 		return err
 	}
 
-	if result.DeletedCount == 0 {
-		return exception.NotFoundRequestException(fmt.Sprintf("Tweet not found with id: %s and userId: %s", id, userId))
+	if !deleted {
+		return exception.TweetNotFoundException("Tweet", "id", id, "userId", userId)
 	}
 
 	return nil
 }
+
+func (tweetRepository *tweetRepositoryImpl) RestoreTweet(ctx context.Context, id string) (*model.Tweet, error) {
+	restored, err := restoreTweet(ctx, mongoCollection(tweetRepository.Connection, "tweets"), id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, exception.ResourceNotFoundException("Tweet", "id", id)
+	}
+
+	return tweetRepository.GetTweet(ctx, id)
+}
+
+// GetTimeline returns the tweets of the users userId follows, ordered by
+// query, which the home timeline sets to the latest first.
+func (tweetRepository *tweetRepositoryImpl) GetTimeline(ctx context.Context, userId string, query *model.CursorQuery) (*model.PagedTweet, error) {
+	ids, err := followingIds(ctx, mongoCollection(tweetRepository.Connection, "follows"), userId)
+	if err != nil {
+		return nil, err
+	}
+	filter := notDeleted(ctx, bson.M{
+		"userId": bson.M{"$in": ids},
+	})
+
+	return tweetRepository.findTweets(ctx, filter, query)
+}
+
+func (tweetRepository *tweetRepositoryImpl) GetConversation(ctx context.Context, id string, query *model.CursorQuery) (*model.PagedTweet, error) {
+	tweet, err := findTweet(ctx, mongoCollection(tweetRepository.Connection, "tweets"), id)
+	if err != nil {
+		return nil, err
+	}
+
+	return tweetRepository.findTweets(ctx, conversationFilter(ctx, tweet), query)
+}
+
+func (tweetRepository *tweetRepositoryImpl) GetHashtagTweets(ctx context.Context, tag string, query *model.CursorQuery) (*model.PagedTweet, error) {
+	return tweetRepository.findTweets(ctx, hashtagFilter(ctx, tag), query)
+}
+
+func (tweetRepository *tweetRepositoryImpl) GetMentionTweets(ctx context.Context, userId string, query *model.CursorQuery) (*model.PagedTweet, error) {
+	return tweetRepository.findTweets(ctx, mentionFilter(ctx, userId), query)
+}
+
+func (tweetRepository *tweetRepositoryImpl) LikeTweet(ctx context.Context, id string, userId string) (*model.Tweet, error) {
+	return likeTweet(ctx, mongoCollection(tweetRepository.Connection, "tweets"), id, userId)
+}
+
+func (tweetRepository *tweetRepositoryImpl) UnlikeTweet(ctx context.Context, id string, userId string) (*model.Tweet, error) {
+	return unlikeTweet(ctx, mongoCollection(tweetRepository.Connection, "tweets"), id, userId)
+}
+
+func (tweetRepository *tweetRepositoryImpl) Retweet(ctx context.Context, id string, userId string) (*model.Tweet, error) {
+	return insertRetweet(ctx, mongoCollection(tweetRepository.Connection, "tweets"), id, userId)
+}
+
+func (tweetRepository *tweetRepositoryImpl) Unretweet(ctx context.Context, id string, userId string) error {
+	return deleteRetweet(ctx, mongoCollection(tweetRepository.Connection, "tweets"), id, userId)
+}
+
+// findTweets returns the page of tweets matching filter that query asks for.
+func (tweetRepository *tweetRepositoryImpl) findTweets(ctx context.Context, filter bson.M, query *model.CursorQuery) (*model.PagedTweet, error) {
+	collection := tweetRepository.Connection.Collection("tweets")
+
+	documents, cursorInfo, err := findByCursor(ctx, collection, filter, tweetProjection, query, nil, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	tweets := make([]model.Tweet, 0, len(documents))
+	for _, document := range documents {
+		var tweet model.Tweet
+		if err := bson.Unmarshal(document, &tweet); err != nil {
+			return nil, err
+		}
+		tweets = append(tweets, tweet)
+	}
+
+	return &model.PagedTweet{
+		Data:   tweets,
+		Cursor: cursorInfo,
+	}, nil
+}
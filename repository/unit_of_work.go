@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	maxTransactionAttempts = 5
+	transactionRetryDelay  = 50 * time.Millisecond
+)
+
+// UnitOfWork runs several repository calls atomically. The transaction travels
+// in the context given to fn, so any repository called with that context
+// takes part in it without knowing about it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type unitOfWorkImpl struct {
+	Connection *mongo.Database
+}
+
+func NewUnitOfWork(Connection *mongo.Database) UnitOfWork {
+	return &unitOfWorkImpl{Connection: Connection}
+}
+
+// Do runs fn in a MongoDB transaction, committing it when fn succeeds and
+// aborting it otherwise. The whole transaction is retried on transient
+// errors, so fn must not have side effects outside the database. When ctx
+// already belongs to a transaction, fn joins it.
+func (unitOfWork *unitOfWorkImpl) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	session, err := unitOfWork.Connection.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	for attempt := 1; ; attempt++ {
+		err = mongo.WithSession(ctx, session, func(sessionContext mongo.SessionContext) error {
+			return runTransaction(sessionContext, fn)
+		})
+		if err == nil || attempt == maxTransactionAttempts || !hasErrorLabel(err, "TransientTransactionError") {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * transactionRetryDelay):
+		}
+	}
+}
+
+func runTransaction(sessionContext mongo.SessionContext, fn func(ctx context.Context) error) error {
+	if err := sessionContext.StartTransaction(); err != nil {
+		return err
+	}
+
+	if err := fn(sessionContext); err != nil {
+		_ = sessionContext.AbortTransaction(context.Background())
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := sessionContext.CommitTransaction(sessionContext)
+		if err == nil || attempt == maxTransactionAttempts || !hasErrorLabel(err, "UnknownTransactionCommitResult") {
+			return err
+		}
+	}
+}
+
+func hasErrorLabel(err error, label string) bool {
+	var serverError mongo.ServerError
+	return errors.As(err, &serverError) && serverError.HasErrorLabel(label)
+}
+
+// withTransaction runs fn in a unit of work on database, for repository
+// methods that change several documents.
+func withTransaction(ctx context.Context, database *mongo.Database, fn func(ctx context.Context) error) error {
+	return NewUnitOfWork(database).Do(ctx, fn)
+}
@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/cbuelvasc/cinema-backend/exception"
 	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/util"
 	paginate "github.com/gobeam/mongo-go-pagination"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -13,11 +15,19 @@ import (
 
 type UserRepository interface {
 	GetAllUser(ctx context.Context, page int64, limit int64) (*model.PagedUser, error)
+	GetAllUserByCursor(ctx context.Context, query *model.CursorQuery) (*model.PagedUser, error)
+	GetAllUserDocuments(ctx context.Context, query *model.DocumentQuery) (*model.PagedDocument, error)
 	SaveUser(ctx context.Context, user *model.User) (*model.User, error)
 	FindByEmail(ctx context.Context, email string) (*model.User, error)
 	GetUser(ctx context.Context, id string) (*model.User, error)
+	GetUserDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error)
 	UpdateUser(ctx context.Context, id string, user *model.User) (*model.User, error)
+	PatchUser(ctx context.Context, id string, user *model.User) (*model.User, error)
+	UpdatePassword(ctx context.Context, id string, password string) error
+	SetCalendarToken(ctx context.Context, id string, token string) error
+	FindByCalendarToken(ctx context.Context, token string) (*model.User, error)
 	DeleteUser(ctx context.Context, id string) error
+	RestoreUser(ctx context.Context, id string) (*model.User, error)
 }
 
 type userRepositoryImpl struct {
@@ -28,9 +38,28 @@ func NewUserRepository(Connection *mongo.Database) UserRepository {
 	return &userRepositoryImpl{Connection: Connection}
 }
 
+var userProjection = bson.D{
+	{"id", 1},
+	{"name", 1},
+	{"lastname", 1},
+	{"birthDate", 1},
+	{"email", 1},
+	{"username", 1},
+	{"avatar", 1},
+	{"banner", 1},
+	{"biography", 1},
+	{"location", 1},
+	{"webSite", 1},
+	{"followers", 1},
+	{"following", 1},
+	{"version", 1},
+	{"deleted_at", 1},
+	{"deleted_by", 1},
+}
+
 func (userRepository *userRepositoryImpl) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	var existingUser model.User
-	filter := bson.M{"email": email}
+	filter := notDeleted(ctx, bson.M{"email": email})
 	err := userRepository.Connection.Collection("users").FindOne(ctx, filter).Decode(&existingUser)
 	if err != nil {
 		return nil, err
@@ -41,38 +70,58 @@ func (userRepository *userRepositoryImpl) FindByEmail(ctx context.Context, email
 func (userRepository *userRepositoryImpl) GetAllUser(ctx context.Context, page int64, limit int64) (*model.PagedUser, error) {
 	var users []model.User
 
-	filter := bson.M{}
+	filter := notDeleted(ctx, bson.M{})
 
 	collection := userRepository.Connection.Collection("users")
 
-	projection := bson.D{
-		{"id", 1},
-		{"name", 1},
-		{"lastname", 1},
-		{"birthDate", 1},
-		{"email", 1},
-		{"avatar", 1},
-		{"banner", 1},
-		{"biography", 1},
-		{"location", 1},
-		{"webSite", 1},
+	paginatedData, err := paginate.New(collection).Context(ctx).Limit(limit).Page(page).Select(userProjection).Filter(filter).Decode(&users).Find()
+	if err != nil {
+		return nil, err
 	}
 
-	paginatedData, err := paginate.New(collection).Context(ctx).Limit(limit).Page(page).Select(projection).Filter(filter).Decode(&users).Find()
+	return &model.PagedUser{
+		Data:     users,
+		PageInfo: &paginatedData.Pagination,
+	}, nil
+}
+
+func (userRepository *userRepositoryImpl) GetAllUserByCursor(ctx context.Context, query *model.CursorQuery) (*model.PagedUser, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	collection := userRepository.Connection.Collection("users")
+
+	documents, cursorInfo, err := findByCursor(ctx, collection, filter, userProjection, query, nil, "name", "lastname", "email", "created_at")
 	if err != nil {
 		return nil, err
 	}
 
+	users := make([]model.User, 0, len(documents))
+	for _, document := range documents {
+		var user model.User
+		if err := bson.Unmarshal(document, &user); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
 	return &model.PagedUser{
-		Data:     users,
-		PageInfo: paginatedData.Pagination,
+		Data:   users,
+		Cursor: cursorInfo,
 	}, nil
 }
 
+func (userRepository *userRepositoryImpl) GetAllUserDocuments(ctx context.Context, query *model.DocumentQuery) (*model.PagedDocument, error) {
+	filter := notDeleted(ctx, bson.M{})
+
+	collection := userRepository.Connection.Collection("users")
+
+	return findDocuments(ctx, collection, filter, query, "name", "lastname", "email", "created_at")
+}
+
 func (userRepository *userRepositoryImpl) GetUser(ctx context.Context, id string) (*model.User, error) {
 	var user model.User
 	objectId, _ := primitive.ObjectIDFromHex(id)
-	filter := bson.M{"_id": objectId}
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
 
 	err := userRepository.Connection.Collection("users").FindOne(ctx, filter).Decode(&user)
 	if err != nil {
@@ -83,10 +132,31 @@ func (userRepository *userRepositoryImpl) GetUser(ctx context.Context, id string
 	return &user, nil
 }
 
+func (userRepository *userRepositoryImpl) GetUserDocument(ctx context.Context, id string, query *model.DocumentQuery) (model.Document, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{
+		"_id": objectId,
+	})
+
+	document, err := findDocument(ctx, userRepository.Connection.Collection("users"), filter, query)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, exception.ResourceNotFoundException("User", "id", id)
+	}
+	return document, nil
+}
+
 func (userRepository *userRepositoryImpl) SaveUser(ctx context.Context, user *model.User) (*model.User, error) {
 	user.ID = primitive.NewObjectID()
+	user.Version = 1
+	user.Normalize()
 
 	_, err := userRepository.Connection.Collection("users").InsertOne(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, userConflict(ctx, mongoCollection(userRepository.Connection, "users"), user)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -95,63 +165,218 @@ func (userRepository *userRepositoryImpl) SaveUser(ctx context.Context, user *mo
 	return user, nil
 }
 
-func (userRepository *userRepositoryImpl) UpdateUser(ctx context.Context, id string, user *model.User) (*model.User, error) {
-	objectId, _ := primitive.ObjectIDFromHex(id)
+// userUpdateFields returns the fields an update writes: only the ones set in
+// user.
+func userUpdateFields(user *model.User) bson.M {
+	user.Normalize()
 
-	registry := make(map[string]interface{})
+	fields := bson.M{}
 	if len(user.Name) > 0 {
-		registry["name"] = user.Name
+		fields["name"] = user.Name
 	}
 	if len(user.Lastname) > 0 {
-		registry["lastname"] = user.Lastname
+		fields["lastname"] = user.Lastname
+	}
+	fields["birthDate"] = user.BirthDate
+	if len(user.Username) > 0 {
+		fields["username"] = user.Username
 	}
-	registry["birthDate"] = user.BirthDate
 	if len(user.Avatar) > 0 {
-		registry["avatar"] = user.Avatar
+		fields["avatar"] = user.Avatar
 	}
 	if len(user.Banner) > 0 {
-		registry["banner"] = user.Banner
+		fields["banner"] = user.Banner
 	}
 	if len(user.Biography) > 0 {
-		registry["biography"] = user.Biography
+		fields["biography"] = user.Biography
 	}
 	if len(user.Location) > 0 {
-		registry["location"] = user.Location
+		fields["location"] = user.Location
 	}
 	if len(user.WebSite) > 0 {
-		registry["webSite"] = user.WebSite
+		fields["webSite"] = user.WebSite
+	}
+	return fields
+}
+
+func (userRepository *userRepositoryImpl) UpdateUser(ctx context.Context, id string, user *model.User) (*model.User, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+
+	registry := userUpdateFields(user)
+
+	filter := notDeleted(ctx, bson.M{"_id": bson.M{"$eq": objectId}})
+
+	var updated model.User
+	found, err := updateDocument(ctx, mongoCollection(userRepository.Connection, "users"), filter, registry, &updated)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, exception.ConflictException("User", "username", user.Username)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, exception.ResourceNotFoundException("User", "id", id)
 	}
 
-	filter := bson.M{"_id": bson.M{"$eq": objectId}}
+	updated.Password = ""
+	return &updated, nil
+}
+
+// userPatchFields returns the fields a patch writes: every patchable field of
+// user, so fields cleared by the patch are cleared in the stored document too.
+// Usernames can be changed but not cleared.
+func userPatchFields(user *model.User) bson.M {
+	user.Normalize()
 
-	updateString := bson.M{
-		"$set": registry,
+	fields := bson.M{
+		"name":       user.Name,
+		"lastname":   user.Lastname,
+		"birthDate":  user.BirthDate,
+		"avatar":     user.Avatar,
+		"banner":     user.Banner,
+		"biography":  user.Biography,
+		"location":   user.Location,
+		"webSite":    user.WebSite,
+		"updated_at": user.UpdatedAt,
+	}
+	if len(user.Username) > 0 {
+		fields["username"] = user.Username
 	}
+	return fields
+}
+
+func (userRepository *userRepositoryImpl) PatchUser(ctx context.Context, id string, user *model.User) (*model.User, error) {
+	objectId, _ := primitive.ObjectIDFromHex(id)
 
-	result, err := userRepository.Connection.Collection("users").UpdateOne(ctx, filter, updateString)
+	registry := userPatchFields(user)
+
+	filter := notDeleted(ctx, bson.M{"_id": bson.M{"$eq": objectId}})
+
+	var updated model.User
+	found, err := updateDocument(ctx, mongoCollection(userRepository.Connection, "users"), filter, registry, &updated)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, exception.ConflictException("User", "username", user.Username)
+	}
 	if err != nil {
 		return nil, err
 	}
-	if result.MatchedCount == 0 {
+	if !found {
 		return nil, exception.ResourceNotFoundException("User", "id", id)
 	}
 
-	user.ID = objectId
+	updated.Password = ""
+	return &updated, nil
+}
+
+func (userRepository *userRepositoryImpl) UpdatePassword(ctx context.Context, id string, password string) error {
+	return updatePassword(ctx, mongoCollection(userRepository.Connection, "users"), id, password)
+}
+
+// updatePassword replaces the hashed password of a user. Passwords only
+// change through here, never with the rest of the profile.
+func updatePassword(ctx context.Context, users documentStore, id string, password string) error {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+	fields := bson.M{
+		"password":   password,
+		"updated_at": time.Now(),
+	}
+
+	var updated model.User
+	found, err := updateDocument(ctx, users, filter, fields, &updated)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return exception.ResourceNotFoundException("User", "id", id)
+	}
+	return nil
+}
+
+func (userRepository *userRepositoryImpl) SetCalendarToken(ctx context.Context, id string, token string) error {
+	return setCalendarToken(ctx, mongoCollection(userRepository.Connection, "users"), id, token)
+}
+
+// setCalendarToken replaces the calendar token of a user, which revokes the
+// previous one, and an empty token leaves the user without a feed. Only the
+// hash of the token is stored.
+func setCalendarToken(ctx context.Context, users documentStore, id string, token string) error {
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	filter := notDeleted(ctx, bson.M{"_id": objectId})
+	hash := ""
+	if len(token) > 0 {
+		hash = util.CalendarTokenHash(token)
+	}
+
+	var updated model.User
+	found, err := updateDocument(ctx, users, filter, bson.M{"calendarToken": hash}, &updated)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return exception.ResourceNotFoundException("User", "id", id)
+	}
+	return nil
+}
+
+func (userRepository *userRepositoryImpl) FindByCalendarToken(ctx context.Context, token string) (*model.User, error) {
+	return findByCalendarToken(ctx, mongoCollection(userRepository.Connection, "users"), token)
+}
+
+func findByCalendarToken(ctx context.Context, users documentStore, token string) (*model.User, error) {
+	var user model.User
+	filter := notDeleted(ctx, bson.M{"calendarToken": util.CalendarTokenHash(token)})
+
+	found, err := users.FindOne(ctx, filter, nil, &user)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, exception.ResourceNotFoundException("Calendar", "token", token)
+	}
+
 	user.Password = ""
-	return user, nil
+	return &user, nil
 }
 
 func (userRepository *userRepositoryImpl) DeleteUser(ctx context.Context, id string) error {
 	objectId, _ := primitive.ObjectIDFromHex(id)
 	filter := bson.M{"_id": objectId}
 
-	result, err := userRepository.Connection.Collection("users").DeleteOne(ctx, filter)
+	deleted, err := deleteDocument(ctx, mongoCollection(userRepository.Connection, "users"), filter)
 	if err != nil {
 		return err
 	}
-	if result.DeletedCount == 0 {
+	if !deleted {
 		return exception.ResourceNotFoundException("User", "id", id)
 	}
 
 	return nil
 }
+
+func (userRepository *userRepositoryImpl) RestoreUser(ctx context.Context, id string) (*model.User, error) {
+	restored, err := restoreDocument(ctx, mongoCollection(userRepository.Connection, "users"), id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, exception.ResourceNotFoundException("User", "id", id)
+	}
+
+	return userRepository.GetUser(ctx, id)
+}
+
+// userConflict is the error of saving user when it shares its email or its
+// username with another user.
+func userConflict(ctx context.Context, users documentStore, user *model.User) error {
+	if len(user.Username) > 0 {
+		count, err := users.Count(ctx, bson.M{"username": user.Username})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return exception.ConflictException("User", "username", user.Username)
+		}
+	}
+	return exception.ConflictException("User", "email", user.Email)
+}
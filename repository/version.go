@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// withExpectedVersion restricts filter to the versions the request expects
+// through If-Match, if any. Documents saved before versioning are version 0.
+func withExpectedVersion(ctx context.Context, filter bson.M) bson.M {
+	versions, ok := util.GetExpectedVersions(ctx)
+	if !ok {
+		return filter
+	}
+
+	in := bson.A{}
+	for _, version := range versions {
+		in = append(in, version)
+		if version == 0 {
+			in = append(in, nil)
+		}
+	}
+	return withFilter(filter, bson.M{"version": bson.M{"$in": in}})
+}
+
+// checkVersion is called when a conditional write matched nothing. It fails
+// with a precondition error when the document exists at another version.
+func checkVersion(ctx context.Context, collection documentStore, filter bson.M) error {
+	if _, ok := util.GetExpectedVersions(ctx); !ok {
+		return nil
+	}
+
+	count, err := collection.Count(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return exception.PreconditionFailedException()
+	}
+	return nil
+}
+
+// updateDocument sets fields on the document matching filter, bumps its
+// version and decodes the updated document into result. It reports whether a
+// document matched.
+func updateDocument(ctx context.Context, collection documentStore, filter bson.M, fields bson.M, result interface{}) (bool, error) {
+	update := bson.M{
+		"$inc": bson.M{"version": 1},
+	}
+	if len(fields) > 0 {
+		update["$set"] = fields
+	}
+
+	found, err := collection.FindOneAndUpdate(ctx, withExpectedVersion(ctx, filter), update, result)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return false, checkVersion(ctx, collection, filter)
+	}
+	return true, nil
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/cbuelvasc/cinema-backend/controller"
+	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/cbuelvasc/cinema-backend/security"
+	"github.com/labstack/echo/v4"
+)
+
+func GetBookingApiRoutes(e *echo.Echo, bookingController *controller.BookingController) {
+	v1 := e.Group(enums.BasePath)
+	{
+		v1.GET(enums.GetBookings, bookingController.GetBookings)
+		v1.GET(enums.GetBookingById, bookingController.GetBooking)
+		v1.POST(enums.CreateBooking, bookingController.SaveBooking)
+		v1.DELETE(enums.CancelBookingById, bookingController.CancelBooking)
+		v1.POST(enums.CheckInBooking, bookingController.CheckInBooking, security.RequireAdmin)
+		v1.GET(enums.GetBookingEvent, bookingController.GetBookingCalendar)
+		v1.GET(enums.GetUserCalendar, bookingController.GetUserCalendar)
+	}
+}
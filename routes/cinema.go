@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/cbuelvasc/cinema-backend/controller"
+	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/labstack/echo/v4"
+)
+
+func GetCinemaApiRoutes(e *echo.Echo, cinemaController *controller.CinemaController) {
+	v1 := e.Group(enums.BasePath)
+	{
+		v1.GET(enums.GetCinemas, cinemaController.GetAllCinemas)
+		v1.GET(enums.GetNearbyCinemas, cinemaController.GetNearbyCinemas)
+		v1.GET(enums.GetCinemaById, cinemaController.GetCinema)
+	}
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/cbuelvasc/cinema-backend/controller"
 	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/cbuelvasc/cinema-backend/security"
 	"github.com/labstack/echo/v4"
 )
 
@@ -13,6 +14,10 @@ func GetCityApiRoutes(e *echo.Echo, cityController *controller.CityController) {
 		v1.GET(enums.GetCities, cityController.GetAllCities)
 		v1.GET(enums.GetCityById, cityController.GetCity)
 		v1.PUT(enums.UpdateCityById, cityController.UpdateCity)
+		v1.PATCH(enums.PatchCityById, cityController.PatchCity)
 		v1.DELETE(enums.DeleteCityById, cityController.DeleteCity)
+		v1.POST(enums.RestoreCityById, cityController.RestoreCity, security.RequireAdmin)
+		v1.POST(enums.ImportCities, cityController.ImportCities, security.RequireAdmin)
+		v1.GET(enums.ExportCities, cityController.ExportCities)
 	}
 }
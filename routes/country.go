@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/cbuelvasc/cinema-backend/controller"
 	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/cbuelvasc/cinema-backend/security"
 	"github.com/labstack/echo/v4"
 )
 
@@ -13,6 +14,10 @@ func GetCountryApiRoutes(e *echo.Echo, movieController *controller.CountryContro
 		v1.GET(enums.GetCountries, movieController.GetAllCountries)
 		v1.GET(enums.GetCountryById, movieController.GetCountry)
 		v1.PUT(enums.UpdateCountryById, movieController.UpdateCountry)
+		v1.PATCH(enums.PatchCountryById, movieController.PatchCountry)
 		v1.DELETE(enums.DeleteCountryById, movieController.DeleteCountry)
+		v1.POST(enums.RestoreCountryById, movieController.RestoreCountry, security.RequireAdmin)
+		v1.POST(enums.ImportCountries, movieController.ImportCountries, security.RequireAdmin)
+		v1.GET(enums.ExportCountries, movieController.ExportCountries)
 	}
 }
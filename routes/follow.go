@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/cbuelvasc/cinema-backend/controller"
+	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/labstack/echo/v4"
+)
+
+func GetFollowApiRoutes(e *echo.Echo, followController *controller.FollowController) {
+	v1 := e.Group(enums.BasePath)
+	{
+		v1.POST(enums.FollowUser, followController.FollowUser)
+		v1.DELETE(enums.UnfollowUser, followController.UnfollowUser)
+		v1.GET(enums.GetFollowers, followController.GetFollowers)
+		v1.GET(enums.GetFollowing, followController.GetFollowing)
+	}
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/cbuelvasc/cinema-backend/controller"
+	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/labstack/echo/v4"
+)
+
+func GetListingApiRoutes(e *echo.Echo, listingController *controller.ListingController) {
+	v1 := e.Group(enums.BasePath)
+	{
+		v1.GET(enums.GetNowShowing, listingController.GetNowShowing)
+		v1.GET(enums.GetComingSoon, listingController.GetComingSoon)
+		v1.GET(enums.GetCinemaCalendar, listingController.GetCinemaCalendar)
+	}
+}
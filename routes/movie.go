@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/cbuelvasc/cinema-backend/controller"
 	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/cbuelvasc/cinema-backend/security"
 	"github.com/labstack/echo/v4"
 )
 
@@ -11,9 +12,17 @@ func GetMovieApiRoutes(e *echo.Echo, movieController *controller.MovieController
 	{
 		v1.POST(enums.CreateMovie, movieController.SaveMovie)
 		v1.GET(enums.GetMovies, movieController.GetAllMovie)
+		v1.GET(enums.SearchMovies, movieController.SearchMovies)
 		v1.GET(enums.GetMovieById, movieController.GetMovie)
 		v1.PUT(enums.UpdateMovieById, movieController.UpdateMovie)
+		v1.PATCH(enums.PatchMovieById, movieController.PatchMovie)
 		v1.DELETE(enums.DeleteMovieById, movieController.DeleteMovie)
+		v1.POST(enums.RestoreMovieById, movieController.RestoreMovie, security.RequireAdmin)
+		v1.POST(enums.ImportMovies, movieController.ImportMovies, security.RequireAdmin)
+		v1.GET(enums.ExportMovies, movieController.ExportMovies)
+		v1.GET(enums.GetMovieTranslations, movieController.GetMovieTranslations)
+		v1.PUT(enums.SaveMovieTranslation, movieController.SaveMovieTranslation, security.RequireAdmin)
+		v1.DELETE(enums.DeleteMovieTranslation, movieController.DeleteMovieTranslation, security.RequireAdmin)
 
 	}
 }
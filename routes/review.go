@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/cbuelvasc/cinema-backend/controller"
+	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/labstack/echo/v4"
+)
+
+func GetReviewApiRoutes(e *echo.Echo, reviewController *controller.ReviewController) {
+	v1 := e.Group(enums.BasePath)
+	{
+		v1.GET(enums.GetMovieReviews, reviewController.GetMovieReviews)
+		v1.POST(enums.CreateMovieReview, reviewController.SaveReview)
+		v1.GET(enums.GetReviewById, reviewController.GetReview)
+		v1.PUT(enums.UpdateReviewById, reviewController.UpdateReview)
+		v1.DELETE(enums.DeleteReviewById, reviewController.DeleteReview)
+		v1.POST(enums.MarkReviewHelpful, reviewController.MarkReviewHelpful)
+		v1.POST(enums.ReportReview, reviewController.ReportReview)
+	}
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/cbuelvasc/cinema-backend/controller"
 	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/cbuelvasc/cinema-backend/security"
 	"github.com/labstack/echo/v4"
 )
 
@@ -13,6 +14,10 @@ func GetStateApiRoutes(e *echo.Echo, stateController *controller.StateController
 		v1.GET(enums.GetStates, stateController.GetAllStates)
 		v1.GET(enums.GetStateById, stateController.GetState)
 		v1.PUT(enums.UpdateStateById, stateController.UpdateState)
+		v1.PATCH(enums.PatchStateById, stateController.PatchState)
 		v1.DELETE(enums.DeleteStateById, stateController.DeleteState)
+		v1.POST(enums.RestoreStateById, stateController.RestoreState, security.RequireAdmin)
+		v1.POST(enums.ImportStates, stateController.ImportStates, security.RequireAdmin)
+		v1.GET(enums.ExportStates, stateController.ExportStates)
 	}
 }
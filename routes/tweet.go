@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/cbuelvasc/cinema-backend/controller"
 	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/cbuelvasc/cinema-backend/security"
 	"github.com/labstack/echo/v4"
 )
 
@@ -10,9 +11,18 @@ func GetTweetApiRoutes(e *echo.Echo, tweetController *controller.TweetController
 	v1 := e.Group(enums.BasePath)
 	{
 		v1.GET(enums.GetTweets, tweetController.GetAllTweet)
+		v1.GET(enums.GetTimeline, tweetController.GetTimeline)
 		v1.GET(enums.GetTweetById, tweetController.GetTweet)
 		v1.POST(enums.CreateTweets, tweetController.SaveTweet)
 		//v1.PUT(enums.UpdateTweetById, tweetController.UpdateTweet)
 		v1.DELETE(enums.DeleteTweetById, tweetController.DeleteTweet)
+		v1.POST(enums.RestoreTweetById, tweetController.RestoreTweet, security.RequireAdmin)
+		v1.GET(enums.GetConversation, tweetController.GetConversation)
+		v1.POST(enums.LikeTweet, tweetController.LikeTweet)
+		v1.DELETE(enums.UnlikeTweet, tweetController.UnlikeTweet)
+		v1.POST(enums.Retweet, tweetController.Retweet)
+		v1.DELETE(enums.Unretweet, tweetController.Unretweet)
+		v1.GET(enums.GetHashtagTweets, tweetController.GetHashtagTweets)
+		v1.GET(enums.GetMentionTweets, tweetController.GetMentionTweets)
 	}
 }
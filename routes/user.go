@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/cbuelvasc/cinema-backend/controller"
 	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/cbuelvasc/cinema-backend/security"
 	"github.com/labstack/echo/v4"
 )
 
@@ -15,7 +16,12 @@ func GetUserApiRoutes(e *echo.Echo, userController *controller.UserController) {
 		v1.GET(enums.GetUsers, userController.GetAllUser)
 		v1.GET(enums.GetUserById, userController.GetUser)
 		v1.PUT(enums.UpdateUserById, userController.UpdateUser)
+		v1.PATCH(enums.PatchUserById, userController.PatchUser)
+		v1.PUT(enums.ChangePassword, userController.ChangePassword)
+		v1.POST(enums.ResetCalendarToken, userController.ResetCalendarToken)
+		v1.DELETE(enums.RevokeCalendarToken, userController.RevokeCalendarToken)
 		v1.DELETE(enums.DeleteUserById, userController.DeleteUser)
+		v1.POST(enums.RestoreUserById, userController.RestoreUser, security.RequireAdmin)
 
 	}
 }
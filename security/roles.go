@@ -0,0 +1,40 @@
+package security
+
+import (
+	"strconv"
+
+	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"github.com/labstack/echo/v4"
+)
+
+// RequireAdmin restricts a route to users with the admin role.
+func RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if util.GetUserRoleFromToken(c) != enums.RoleAdmin {
+			return exception.ForbiddenException()
+		}
+		return next(c)
+	}
+}
+
+// IncludeDeletedConfig lets admins read soft-deleted documents by adding
+// includeDeleted=true to the query string.
+func IncludeDeletedConfig(e *echo.Echo) {
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			includeDeleted, _ := strconv.ParseBool(c.QueryParam("includeDeleted"))
+			if !includeDeleted {
+				return next(c)
+			}
+			if util.GetUserRoleFromToken(c) != enums.RoleAdmin {
+				return exception.ForbiddenException()
+			}
+
+			ctx := util.WithIncludeDeleted(c.Request().Context())
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	})
+}
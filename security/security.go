@@ -3,6 +3,7 @@ package security
 import (
 	"github.com/cbuelvasc/cinema-backend/config"
 	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/util"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
@@ -13,6 +14,9 @@ var whiteListPaths = []string{
 	"/api/*",
 	"/api/cinema/v1/signin",
 	"/api/cinema/v1/signup",
+	// Calendar applications subscribe to feeds without credentials.
+	"/api/cinema/v1/cinemas/:id/calendar",
+	"/api/cinema/v1/calendar/:token",
 }
 
 // change default error message
@@ -23,9 +27,10 @@ func init() {
 
 func WebSecurityConfig(e *echo.Echo) {
 	config := middleware.JWTConfig{
-		Claims:     &model.JwtCustomClaims{},
-		SigningKey: []byte(config.JWTSecret),
-		Skipper:    skipAuth,
+		Claims:         &model.JwtCustomClaims{},
+		SigningKey:     []byte(config.JWTSecret),
+		Skipper:        skipAuth,
+		SuccessHandler: authenticated,
 	}
 	e.Use(middleware.JWTWithConfig(config))
 }
@@ -39,3 +44,10 @@ func skipAuth(e echo.Context) bool {
 	}
 	return false
 }
+
+// authenticated makes the user id available to the repositories through the
+// request context.
+func authenticated(c echo.Context) {
+	ctx := util.WithUserId(c.Request().Context(), util.GetUserIdFromToken(c))
+	c.SetRequest(c.Request().WithContext(ctx))
+}
@@ -0,0 +1,120 @@
+package seed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Bundle is a set of fixtures. Records name themselves with a ref, unique
+// within their kind, and point to the records they belong to by that ref.
+type Bundle struct {
+	Countries []CountryFixture  `json:"countries" yaml:"countries"`
+	States    []StateFixture    `json:"states" yaml:"states"`
+	Cities    []CityFixture     `json:"cities" yaml:"cities"`
+	Cinemas   []CinemaFixture   `json:"cinemas" yaml:"cinemas"`
+	Rooms     []RoomFixture     `json:"rooms" yaml:"rooms"`
+	Movies    []MovieFixture    `json:"movies" yaml:"movies"`
+	Showtimes []ShowtimeFixture `json:"showtimes" yaml:"showtimes"`
+	Users     []UserFixture     `json:"users" yaml:"users"`
+}
+
+type CountryFixture struct {
+	Ref      string `json:"ref" yaml:"ref"`
+	Name     string `json:"name" yaml:"name"`
+	TimeZone string `json:"timeZone" yaml:"timeZone"`
+}
+
+type StateFixture struct {
+	Ref     string `json:"ref" yaml:"ref"`
+	Name    string `json:"name" yaml:"name"`
+	Country string `json:"country" yaml:"country"`
+}
+
+// CityFixture takes the time zone of its country when it gives none.
+type CityFixture struct {
+	Ref      string `json:"ref" yaml:"ref"`
+	Name     string `json:"name" yaml:"name"`
+	State    string `json:"state" yaml:"state"`
+	TimeZone string `json:"timeZone" yaml:"timeZone"`
+}
+
+// CinemaFixture is located when it gives both its latitude and longitude.
+type CinemaFixture struct {
+	Ref       string   `json:"ref" yaml:"ref"`
+	Name      string   `json:"name" yaml:"name"`
+	City      string   `json:"city" yaml:"city"`
+	Address   string   `json:"address" yaml:"address"`
+	Latitude  *float64 `json:"latitude" yaml:"latitude"`
+	Longitude *float64 `json:"longitude" yaml:"longitude"`
+}
+
+// RoomFixture describes its seats with a seat map, as read by util.SeatIds.
+type RoomFixture struct {
+	Ref     string   `json:"ref" yaml:"ref"`
+	Name    string   `json:"name" yaml:"name"`
+	Cinema  string   `json:"cinema" yaml:"cinema"`
+	Format  string   `json:"format" yaml:"format"`
+	SeatMap []string `json:"seatMap" yaml:"seatMap"`
+}
+
+type MovieFixture struct {
+	Ref          string   `json:"ref" yaml:"ref"`
+	Title        string   `json:"title" yaml:"title"`
+	Format       string   `json:"format" yaml:"format"`
+	ReleaseYear  int      `json:"releaseYear" yaml:"releaseYear"`
+	ReleaseMonth int      `json:"releaseMonth" yaml:"releaseMonth"`
+	ReleaseDay   int      `json:"releaseDay" yaml:"releaseDay"`
+	Runtime      int      `json:"runtime" yaml:"runtime"`
+	Genres       []string `json:"genres" yaml:"genres"`
+	Languages    []string `json:"languages" yaml:"languages"`
+}
+
+// ShowtimeFixture gives its date as an RFC 3339 time, or as a wall clock
+// time without offset (2030-01-10T19:00) in the time zone of its cinema.
+type ShowtimeFixture struct {
+	Ref   string `json:"ref" yaml:"ref"`
+	Room  string `json:"room" yaml:"room"`
+	Movie string `json:"movie" yaml:"movie"`
+	Date  string `json:"date" yaml:"date"`
+}
+
+// UserFixture holds the password in clear; it is hashed when loaded.
+type UserFixture struct {
+	Ref      string `json:"ref" yaml:"ref"`
+	Name     string `json:"name" yaml:"name"`
+	Lastname string `json:"lastname" yaml:"lastname"`
+	Email    string `json:"email" yaml:"email"`
+	Password string `json:"password" yaml:"password"`
+	Role     string `json:"role" yaml:"role"`
+}
+
+// ReadBundle reads the bundle at path, in JSON or YAML depending on its
+// extension.
+func ReadBundle(path string) (*Bundle, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := new(Bundle)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(bundle)
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(data, bundle)
+	default:
+		return nil, fmt.Errorf("%s: unknown fixture format, expected .json, .yaml or .yml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return bundle, nil
+}
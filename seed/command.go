@@ -0,0 +1,79 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var errUsage = errors.New("usage: seed load <file>... | seed generate [-scale n] [-seed n] [-from yyyy-mm-dd] [-days n]")
+
+// Command runs the seed subcommand with its arguments:
+//
+//	seed load <file>...   loads JSON or YAML fixture bundles, in order
+//	seed generate         loads a random bundle, sized by -scale
+func Command(ctx context.Context, Connection *mongo.Database, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	loader := NewLoader(Connection)
+	switch args[0] {
+	case "load":
+		if len(args) < 2 {
+			return errUsage
+		}
+		for _, path := range args[1:] {
+			bundle, err := ReadBundle(path)
+			if err != nil {
+				return err
+			}
+			if err := loader.Load(ctx, bundle); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		}
+	case "generate":
+		flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+		flags.SetOutput(out)
+		scale := flags.Int("scale", 1, "size of the data set")
+		seed := flags.Int64("seed", 1, "random seed; the same seed generates the same data")
+		from := flags.String("from", time.Now().Format("2006-01-02"), "first day of showtimes")
+		days := flags.Int("days", 7, "days of showtimes")
+		if err := flags.Parse(args[1:]); err != nil {
+			return errUsage
+		}
+		fromDate, err := time.Parse("2006-01-02", *from)
+		if err != nil || *scale < 1 || *days < 0 {
+			return errUsage
+		}
+
+		bundle := Generate(GeneratorOptions{Scale: *scale, Seed: *seed, From: fromDate, Days: *days})
+		if err := loader.Load(ctx, bundle); err != nil {
+			return err
+		}
+	default:
+		return errUsage
+	}
+
+	printSummary(out, loader.Summary())
+	return nil
+}
+
+func printSummary(out io.Writer, summary Summary) {
+	collections := make([]string, 0, len(summary))
+	for collection := range summary {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+
+	for _, collection := range collections {
+		count := summary[collection]
+		fmt.Fprintf(out, "%-10s %6d inserted %6d existing\n", collection, count.Inserted, count.Existing)
+	}
+}
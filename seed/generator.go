@@ -0,0 +1,138 @@
+package seed
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/enums"
+)
+
+var (
+	generatedFormats = []string{"2D", "3D", "IMAX", "4DX"}
+	generatedGenres  = []string{"action", "comedy", "drama", "horror", "science fiction", "animation", "thriller"}
+	generatedWords   = []string{
+		"Night", "River", "Shadow", "Summer", "Empire", "Silent", "Last", "Iron",
+		"Golden", "Broken", "Hidden", "Storm", "Garden", "Winter", "Lost", "Wild",
+		"Noche", "Sombra", "Verano", "Fuego", "Camino", "Mar", "Tierra", "Luna",
+	}
+	generatedShowHours = []int{14, 17, 20, 22}
+	generatedTimeZones = []string{"America/Bogota", "America/New_York", "America/Santiago", "Europe/Madrid", "Australia/Sydney"}
+)
+
+// GeneratorOptions sizes a generated bundle. Scale multiplies every kind of
+// record; Seed makes the bundle reproducible, so loading it again is a no-op.
+type GeneratorOptions struct {
+	Scale int
+	Seed  int64
+	From  time.Time
+	Days  int
+}
+
+// Generate returns a random bundle: per unit of scale one country with three
+// states of three cities, two cinemas per city and four rooms per cinema,
+// plus twenty movies and fifty users. Every room gets showtimes on the Days
+// days starting at From.
+func Generate(generatorOptions GeneratorOptions) *Bundle {
+	random := rand.New(rand.NewSource(generatorOptions.Seed))
+	from := generatorOptions.From.Truncate(24 * time.Hour)
+	bundle := new(Bundle)
+
+	for m := 0; m < 20*generatorOptions.Scale; m++ {
+		bundle.Movies = append(bundle.Movies, MovieFixture{
+			Ref:          fmt.Sprintf("movie-%d", m),
+			Title:        fmt.Sprintf("%s %s %d", pick(random, generatedWords), pick(random, generatedWords), m+1),
+			Format:       pick(random, generatedFormats),
+			ReleaseYear:  1980 + random.Intn(45),
+			ReleaseMonth: 1 + random.Intn(12),
+			ReleaseDay:   1 + random.Intn(28),
+			Runtime:      80 + random.Intn(90),
+			Genres:       []string{pick(random, generatedGenres)},
+			Languages:    []string{"en"},
+		})
+	}
+
+	for c := 0; c < generatorOptions.Scale; c++ {
+		country := CountryFixture{Ref: fmt.Sprintf("country-%d", c), Name: fmt.Sprintf("Country %d", c+1), TimeZone: generatedTimeZones[c%len(generatedTimeZones)]}
+		bundle.Countries = append(bundle.Countries, country)
+
+		for s := 0; s < 3; s++ {
+			state := StateFixture{Ref: fmt.Sprintf("%s-state-%d", country.Ref, s), Name: fmt.Sprintf("State %d", s+1), Country: country.Ref}
+			bundle.States = append(bundle.States, state)
+
+			for t := 0; t < 3; t++ {
+				city := CityFixture{Ref: fmt.Sprintf("%s-city-%d", state.Ref, t), Name: fmt.Sprintf("%s %d", pick(random, generatedWords), t+1), State: state.Ref}
+				bundle.Cities = append(bundle.Cities, city)
+				latitude, longitude := random.Float64()*120-60, random.Float64()*360-180
+
+				for n := 0; n < 2; n++ {
+					cinema := CinemaFixture{Ref: fmt.Sprintf("%s-cinema-%d", city.Ref, n), Name: fmt.Sprintf("Cinema %s %d", city.Name, n+1), City: city.Ref}
+					// Cinemas are spread within a few kilometers of their city.
+					cinemaLatitude, cinemaLongitude := latitude+random.Float64()*0.1-0.05, longitude+random.Float64()*0.1-0.05
+					cinema.Address = fmt.Sprintf("%d %s Street", 1+random.Intn(200), pick(random, generatedWords))
+					cinema.Latitude, cinema.Longitude = &cinemaLatitude, &cinemaLongitude
+					bundle.Cinemas = append(bundle.Cinemas, cinema)
+
+					for r := 0; r < 4; r++ {
+						room := RoomFixture{
+							Ref:     fmt.Sprintf("%s-room-%d", cinema.Ref, r),
+							Name:    fmt.Sprintf("Room %d", r+1),
+							Cinema:  cinema.Ref,
+							Format:  pick(random, generatedFormats),
+							SeatMap: generateSeatMap(random),
+						}
+						bundle.Rooms = append(bundle.Rooms, room)
+
+						for d := 0; d < generatorOptions.Days; d++ {
+							// Hours are wall clock times in the time zone of the country.
+							for _, hour := range generatedShowHours {
+								bundle.Showtimes = append(bundle.Showtimes, ShowtimeFixture{
+									Ref:   fmt.Sprintf("%s-show-%d-%d", room.Ref, d, hour),
+									Room:  room.Ref,
+									Movie: bundle.Movies[random.Intn(len(bundle.Movies))].Ref,
+									Date:  from.AddDate(0, 0, d).Add(time.Duration(hour) * time.Hour).Format("2006-01-02T15:04"),
+								})
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for u := 0; u < 50*generatorOptions.Scale; u++ {
+		role := enums.RoleUser
+		if u == 0 {
+			role = enums.RoleAdmin
+		}
+		bundle.Users = append(bundle.Users, UserFixture{
+			Ref:      fmt.Sprintf("user-%d", u),
+			Name:     pick(random, generatedWords),
+			Lastname: pick(random, generatedWords),
+			Email:    fmt.Sprintf("user%d@example.com", u+1),
+			Password: "password",
+			Role:     role,
+		})
+	}
+	return bundle
+}
+
+// generateSeatMap returns 6 to 15 rows of 8 to 20 seats, split by a central
+// aisle.
+func generateSeatMap(random *rand.Rand) []string {
+	rows := 6 + random.Intn(10)
+	seats := 8 + random.Intn(13)
+	left := seats / 2
+	row := strings.Repeat("S", left) + "_" + strings.Repeat("S", seats-left)
+
+	seatMap := make([]string, rows)
+	for i := range seatMap {
+		seatMap[i] = row
+	}
+	return seatMap
+}
+
+func pick(random *rand.Rand, values []string) string {
+	return values[random.Intn(len(values))]
+}
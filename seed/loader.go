@@ -0,0 +1,347 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cbuelvasc/cinema-backend/config"
+	"github.com/cbuelvasc/cinema-backend/enums"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/cbuelvasc/cinema-backend/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Loader writes bundles to the database. Every record is identified by its
+// natural key, such as the name of a country or the email of a user: records
+// that already exist are left as they are, so loading a bundle twice changes
+// nothing. Refs resolve across all the bundles given to the same Loader.
+type Loader struct {
+	Connection *mongo.Database
+	refs       map[string]map[string]primitive.ObjectID
+	seatMaps   map[string][]string
+	timeZones  map[string]string
+	passwords  map[string]string
+	summary    Summary
+}
+
+// Summary counts, by collection, the records inserted and the ones that
+// already existed.
+type Summary map[string]*Count
+
+type Count struct {
+	Inserted int
+	Existing int
+}
+
+func NewLoader(Connection *mongo.Database) *Loader {
+	return &Loader{
+		Connection: Connection,
+		refs:       map[string]map[string]primitive.ObjectID{},
+		seatMaps:   map[string][]string{},
+		timeZones:  map[string]string{},
+		passwords:  map[string]string{},
+		summary:    Summary{},
+	}
+}
+
+// Summary returns the counts of everything loaded so far.
+func (loader *Loader) Summary() Summary {
+	return loader.summary
+}
+
+// Load writes bundle, parents before their children.
+func (loader *Loader) Load(ctx context.Context, bundle *Bundle) error {
+	now := time.Now()
+
+	for _, fixture := range bundle.Countries {
+		if err := loader.setTimeZone("countries", fixture.Ref, fixture.TimeZone, ""); err != nil {
+			return err
+		}
+		country := &model.Country{
+			CountryInput: &model.CountryInput{Name: fixture.Name, TimeZone: fixture.TimeZone, States: []string{}, CreatedAt: now, UpdatedAt: now},
+			ID:           primitive.NewObjectID(),
+			Version:      1,
+		}
+		if _, err := loader.upsert(ctx, "countries", fixture.Ref, bson.M{"name": fixture.Name}, country, country.ID); err != nil {
+			return err
+		}
+	}
+
+	for _, fixture := range bundle.States {
+		countryId, err := loader.resolve("countries", fixture.Country)
+		if err != nil {
+			return fmt.Errorf("state %s: %w", fixture.Ref, err)
+		}
+		loader.timeZones["states/"+fixture.Ref] = loader.timeZones["countries/"+fixture.Country]
+		state := &model.State{
+			StateInput: &model.StateInput{Name: fixture.Name, CountryId: countryId.Hex(), Cities: []string{}, CreatedAt: now, UpdatedAt: now},
+			ID:         primitive.NewObjectID(),
+			Version:    1,
+		}
+		key := bson.M{"name": fixture.Name, "countryId": state.CountryId}
+		if err := loader.upsertChild(ctx, "states", fixture.Ref, key, state, state.ID, "countries", countryId, "states"); err != nil {
+			return err
+		}
+	}
+
+	for _, fixture := range bundle.Cities {
+		stateId, err := loader.resolve("states", fixture.State)
+		if err != nil {
+			return fmt.Errorf("city %s: %w", fixture.Ref, err)
+		}
+		if err := loader.setTimeZone("cities", fixture.Ref, fixture.TimeZone, "states/"+fixture.State); err != nil {
+			return err
+		}
+		city := &model.City{
+			CityInput: &model.CityInput{Name: fixture.Name, StateId: stateId.Hex(), TimeZone: fixture.TimeZone, Cinemas: []string{}, CreatedAt: now, UpdatedAt: now},
+			ID:        primitive.NewObjectID(),
+			Version:   1,
+		}
+		key := bson.M{"name": fixture.Name, "stateId": city.StateId}
+		if err := loader.upsertChild(ctx, "cities", fixture.Ref, key, city, city.ID, "states", stateId, "cities"); err != nil {
+			return err
+		}
+	}
+
+	for _, fixture := range bundle.Cinemas {
+		cityId, err := loader.resolve("cities", fixture.City)
+		if err != nil {
+			return fmt.Errorf("cinema %s: %w", fixture.Ref, err)
+		}
+		loader.timeZones["cinemas/"+fixture.Ref] = loader.timeZones["cities/"+fixture.City]
+		cinema := &model.Cinema{
+			CinemaInput: &model.CinemaInput{Name: fixture.Name, CityId: cityId.Hex(), Address: fixture.Address, Premieres: []string{}, Rooms: []string{}, CreatedAt: now, UpdatedAt: now},
+			ID:          primitive.NewObjectID(),
+			Version:     1,
+		}
+		if fixture.Latitude != nil && fixture.Longitude != nil {
+			cinema.Location = model.NewGeoPoint(*fixture.Latitude, *fixture.Longitude)
+		}
+		key := bson.M{"name": fixture.Name, "cityId": cinema.CityId}
+		if err := loader.upsertChild(ctx, "cinemas", fixture.Ref, key, cinema, cinema.ID, "cities", cityId, "cinemas"); err != nil {
+			return err
+		}
+	}
+
+	for _, fixture := range bundle.Rooms {
+		cinemaId, err := loader.resolve("cinemas", fixture.Cinema)
+		if err != nil {
+			return fmt.Errorf("room %s: %w", fixture.Ref, err)
+		}
+		loader.timeZones["rooms/"+fixture.Ref] = loader.timeZones["cinemas/"+fixture.Cinema]
+		room := &model.Room{
+			RoomInput: &model.RoomInput{
+				Name:      fixture.Name,
+				Capacity:  strconv.Itoa(len(util.SeatIds(fixture.SeatMap))),
+				Format:    fixture.Format,
+				CinemaId:  cinemaId.Hex(),
+				SeatMap:   fixture.SeatMap,
+				Schedules: []string{},
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+			ID:      primitive.NewObjectID(),
+			Version: 1,
+		}
+		key := bson.M{"name": fixture.Name, "cinemaId": room.CinemaId}
+		if err := loader.upsertChild(ctx, "rooms", fixture.Ref, key, room, room.ID, "cinemas", cinemaId, "rooms"); err != nil {
+			return err
+		}
+		loader.seatMaps[fixture.Ref] = fixture.SeatMap
+	}
+
+	for _, fixture := range bundle.Movies {
+		movie := &model.Movie{
+			MovieInput: &model.MovieInput{
+				Title:        fixture.Title,
+				Format:       fixture.Format,
+				ReleaseYear:  fixture.ReleaseYear,
+				ReleaseMonth: fixture.ReleaseMonth,
+				ReleaseDay:   fixture.ReleaseDay,
+				Runtime:      fixture.Runtime,
+				Genres:       fixture.Genres,
+				Languages:    fixture.Languages,
+				SearchTitle:  util.NormalizeText(fixture.Title),
+				SearchWords:  util.SearchWords(fixture.Title),
+				CreatedAt:    now,
+				UpdatedAt:    now,
+			},
+			ID:      primitive.NewObjectID(),
+			Version: 1,
+		}
+		util.NormalizeMovie(movie.MovieInput)
+		key := bson.M{"title": fixture.Title, "releaseYear": fixture.ReleaseYear}
+		if _, err := loader.upsert(ctx, "movies", fixture.Ref, key, movie, movie.ID); err != nil {
+			return err
+		}
+	}
+
+	for _, fixture := range bundle.Showtimes {
+		roomId, err := loader.resolve("rooms", fixture.Room)
+		if err != nil {
+			return fmt.Errorf("showtime %s: %w", fixture.Ref, err)
+		}
+		movieId, err := loader.resolve("movies", fixture.Movie)
+		if err != nil {
+			return fmt.Errorf("showtime %s: %w", fixture.Ref, err)
+		}
+		location := config.DefaultLocation()
+		if timeZone := loader.timeZones["rooms/"+fixture.Room]; len(timeZone) > 0 {
+			location, _ = model.LoadTimeZone(timeZone)
+		}
+		date, err := model.ParseLocalTime(fixture.Date, location)
+		if err != nil {
+			return fmt.Errorf("showtime %s: %w", fixture.Ref, err)
+		}
+		seats := util.SeatIds(loader.seatMaps[fixture.Room])
+		if seats == nil {
+			seats = []string{}
+		}
+		schedule := &model.Schedule{
+			ScheduleInput: &model.ScheduleInput{
+				Date:          date.UTC(),
+				RoomId:        roomId.Hex(),
+				MovieId:       movieId.Hex(),
+				SeatsEmpty:    seats,
+				SeatsOccupied: []string{},
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			},
+			ID:      primitive.NewObjectID(),
+			Version: 1,
+		}
+		key := bson.M{"roomId": schedule.RoomId, "date": schedule.Date}
+		if err := loader.upsertChild(ctx, "schedules", fixture.Ref, key, schedule, schedule.ID, "rooms", roomId, "schedules"); err != nil {
+			return err
+		}
+	}
+
+	for _, fixture := range bundle.Users {
+		password, err := loader.hash(fixture.Password)
+		if err != nil {
+			return err
+		}
+		role := fixture.Role
+		if len(role) == 0 {
+			role = enums.RoleUser
+		}
+		user := &model.User{
+			UserInput: &model.UserInput{
+				Name:      fixture.Name,
+				Lastname:  fixture.Lastname,
+				Email:     fixture.Email,
+				Password:  password,
+				Role:      role,
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+			ID:      primitive.NewObjectID(),
+			Version: 1,
+		}
+		if _, err := loader.upsert(ctx, "users", fixture.Ref, bson.M{"email": fixture.Email}, user, user.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upsert inserts document, whose id is id, unless a document of collection
+// matches key. It records the id stored under ref and reports whether
+// document was inserted.
+func (loader *Loader) upsert(ctx context.Context, collection string, ref string, key bson.M, document interface{}, id primitive.ObjectID) (bool, error) {
+	if len(ref) > 0 {
+		if _, ok := loader.refs[collection][ref]; ok {
+			return false, fmt.Errorf("%s: ref %s defined twice", collection, ref)
+		}
+	}
+
+	count, ok := loader.summary[collection]
+	if !ok {
+		count = &Count{}
+		loader.summary[collection] = count
+	}
+
+	var existing struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	inserted := false
+	err := loader.Connection.Collection(collection).FindOne(ctx, key, options.FindOne().SetProjection(bson.M{"_id": 1})).Decode(&existing)
+	switch err {
+	case nil:
+		id = existing.ID
+		count.Existing++
+	case mongo.ErrNoDocuments:
+		if _, err := loader.Connection.Collection(collection).InsertOne(ctx, document); err != nil {
+			return false, fmt.Errorf("%s %s: %w", collection, ref, err)
+		}
+		inserted = true
+		count.Inserted++
+	default:
+		return false, err
+	}
+
+	if len(ref) > 0 {
+		if loader.refs[collection] == nil {
+			loader.refs[collection] = map[string]primitive.ObjectID{}
+		}
+		loader.refs[collection][ref] = id
+	}
+	return inserted, nil
+}
+
+// upsertChild upserts document and, when it is inserted, adds it to the
+// children of its parent the way the repositories do.
+func (loader *Loader) upsertChild(ctx context.Context, collection string, ref string, key bson.M, document interface{}, id primitive.ObjectID, parent string, parentId primitive.ObjectID, childrenField string) error {
+	inserted, err := loader.upsert(ctx, collection, ref, key, document, id)
+	if err != nil || !inserted {
+		return err
+	}
+
+	update := bson.M{
+		"$addToSet": bson.M{childrenField: id.Hex()},
+		"$inc":      bson.M{"version": 1},
+	}
+	_, err = loader.Connection.Collection(parent).UpdateOne(ctx, bson.M{"_id": parentId}, update)
+	return err
+}
+
+// setTimeZone records the time zone of a country or city, or the one of its
+// parent when it has none, to read the showtimes of its cinemas in.
+func (loader *Loader) setTimeZone(collection string, ref string, timeZone string, parent string) error {
+	if len(timeZone) == 0 {
+		loader.timeZones[collection+"/"+ref] = loader.timeZones[parent]
+		return nil
+	}
+	if _, err := model.LoadTimeZone(timeZone); err != nil {
+		return fmt.Errorf("%s %s: %w", collection, ref, err)
+	}
+	loader.timeZones[collection+"/"+ref] = timeZone
+	return nil
+}
+
+func (loader *Loader) resolve(collection string, ref string) (primitive.ObjectID, error) {
+	id, ok := loader.refs[collection][ref]
+	if !ok {
+		return primitive.NilObjectID, fmt.Errorf("unknown %s ref %q", collection, ref)
+	}
+	return id, nil
+}
+
+// hash hashes password once per distinct password: generated bundles share
+// a few passwords among many users.
+func (loader *Loader) hash(password string) (string, error) {
+	if hashed, ok := loader.passwords[password]; ok {
+		return hashed, nil
+	}
+	hashed, err := util.EncryptPassword(password)
+	if err != nil {
+		return "", err
+	}
+	loader.passwords[password] = string(hashed)
+	return string(hashed), nil
+}
@@ -0,0 +1,111 @@
+package util
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	MIMETextCalendar = "text/calendar"
+
+	calendarTimeLayout = "20060102T150405Z"
+	calendarLineLength = 75
+	calendarTokenBytes = 32
+)
+
+var calendarTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// Calendar writes calendar as an iCalendar response. Calendars are only
+// available in this format, so they bypass the content negotiation.
+func Calendar(c echo.Context, status int, calendar *model.Calendar) error {
+	var buffer bytes.Buffer
+	EncodeCalendar(&buffer, calendar)
+	return c.Blob(status, MIMETextCalendar+"; charset=utf-8", buffer.Bytes())
+}
+
+// EncodeCalendar writes calendar to buffer as iCalendar. Times are written
+// in UTC, which calendar applications show in the local time of the user.
+func EncodeCalendar(buffer *bytes.Buffer, calendar *model.Calendar) {
+	writeCalendarLine(buffer, "BEGIN:VCALENDAR")
+	writeCalendarLine(buffer, "VERSION:2.0")
+	writeCalendarLine(buffer, "PRODID:-//cinema-backend//Cinema REST API//EN")
+	writeCalendarLine(buffer, "CALSCALE:GREGORIAN")
+	writeCalendarLine(buffer, "METHOD:PUBLISH")
+	if len(calendar.Name) > 0 {
+		writeCalendarLine(buffer, "X-WR-CALNAME:"+calendarTextEscaper.Replace(calendar.Name))
+	}
+	if len(calendar.TimeZone) > 0 {
+		writeCalendarLine(buffer, "X-WR-TIMEZONE:"+calendar.TimeZone)
+	}
+
+	now := time.Now()
+	for _, event := range calendar.Events {
+		writeCalendarLine(buffer, "BEGIN:VEVENT")
+		writeCalendarLine(buffer, "UID:"+event.UID)
+		writeCalendarLine(buffer, "DTSTAMP:"+now.UTC().Format(calendarTimeLayout))
+		writeCalendarLine(buffer, "DTSTART:"+event.Start.UTC().Format(calendarTimeLayout))
+		if !event.End.IsZero() {
+			writeCalendarLine(buffer, "DTEND:"+event.End.UTC().Format(calendarTimeLayout))
+		}
+		if !event.Updated.IsZero() {
+			writeCalendarLine(buffer, "LAST-MODIFIED:"+event.Updated.UTC().Format(calendarTimeLayout))
+		}
+		writeCalendarLine(buffer, "SUMMARY:"+calendarTextEscaper.Replace(event.Summary))
+		if len(event.Description) > 0 {
+			writeCalendarLine(buffer, "DESCRIPTION:"+calendarTextEscaper.Replace(event.Description))
+		}
+		if len(event.Location) > 0 {
+			writeCalendarLine(buffer, "LOCATION:"+calendarTextEscaper.Replace(event.Location))
+		}
+		if event.Geo != nil && len(event.Geo.Coordinates) == 2 {
+			writeCalendarLine(buffer, fmt.Sprintf("GEO:%g;%g", event.Geo.Latitude(), event.Geo.Longitude()))
+		}
+		writeCalendarLine(buffer, "END:VEVENT")
+	}
+	writeCalendarLine(buffer, "END:VCALENDAR")
+}
+
+// writeCalendarLine ends line with CRLF, folding it every 75 octets without
+// splitting a character. Continuation lines start with a space.
+func writeCalendarLine(buffer *bytes.Buffer, line string) {
+	limit := calendarLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buffer.WriteString(line[:cut])
+		buffer.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space counts towards the length of the next line.
+		limit = calendarLineLength - 1
+	}
+	buffer.WriteString(line)
+	buffer.WriteString("\r\n")
+}
+
+// NewCalendarToken returns a random secret for the URL of a personal calendar
+// feed, which calendar applications read without credentials.
+func NewCalendarToken() (string, error) {
+	secret := make([]byte, calendarTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// CalendarTokenHash is what is stored of a calendar token, so the stored
+// hashes cannot be used as feed URLs.
+func CalendarTokenHash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,50 @@
+package util
+
+import "context"
+
+type contextKey string
+
+const (
+	userIdContextKey          contextKey = "userId"
+	includeDeletedContextKey  contextKey = "includeDeleted"
+	expectedVersionContextKey contextKey = "expectedVersion"
+)
+
+// WithUserId returns a copy of ctx carrying the id of the authenticated user,
+// so repositories can record who made a change.
+func WithUserId(ctx context.Context, userId string) context.Context {
+	return context.WithValue(ctx, userIdContextKey, userId)
+}
+
+func GetUserIdFromContext(ctx context.Context) string {
+	userId, _ := ctx.Value(userIdContextKey).(string)
+	return userId
+}
+
+// WithIncludeDeleted returns a copy of ctx whose reads also return
+// soft-deleted documents.
+func WithIncludeDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedContextKey, true)
+}
+
+func IsIncludeDeleted(ctx context.Context) bool {
+	includeDeleted, _ := ctx.Value(includeDeletedContextKey).(bool)
+	return includeDeleted
+}
+
+// WithExpectedVersion returns a copy of ctx whose writes only apply when the
+// document is still at one of versions, as requested through If-Match.
+func WithExpectedVersion(ctx context.Context, versions ...int64) context.Context {
+	return context.WithValue(ctx, expectedVersionContextKey, versions)
+}
+
+func GetExpectedVersions(ctx context.Context) ([]int64, bool) {
+	versions, ok := ctx.Value(expectedVersionContextKey).([]int64)
+	return versions, ok
+}
+
+// WithoutExpectedVersion returns a copy of ctx whose writes are unconditional,
+// for documents changed as a side effect of the one the request targets.
+func WithoutExpectedVersion(ctx context.Context) context.Context {
+	return context.WithValue(ctx, expectedVersionContextKey, nil)
+}
@@ -0,0 +1,41 @@
+package util
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/labstack/echo/v4"
+)
+
+// GetDocumentQuery reads the fields and expand parameters of the request. The
+// second value is false when neither was given and the typed models apply.
+func GetDocumentQuery(c echo.Context) (*model.DocumentQuery, bool) {
+	fields := splitList(c.QueryParam("fields"))
+	expand := splitList(c.QueryParam("expand"))
+	if len(fields) == 0 && len(expand) == 0 {
+		return nil, false
+	}
+
+	page, _ := strconv.ParseInt(c.QueryParam("page"), 10, 64)
+	limit, _ := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
+	cursorQuery, _ := GetCursorQuery(c)
+
+	return &model.DocumentQuery{
+		Fields: fields,
+		Expand: expand,
+		Page:   page,
+		Limit:  limit,
+		Cursor: cursorQuery,
+	}, true
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,128 @@
+package util
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Encoder writes the response bodies of one format. It may set headers
+// until it first writes to w.
+type Encoder interface {
+	// ContentType is the Content-Type header of the bodies it writes.
+	ContentType() string
+	Encode(w http.ResponseWriter, i interface{}) error
+}
+
+// Decoder is implemented by the encoders that also read request bodies.
+type Decoder interface {
+	Decode(r io.Reader, i interface{}) error
+}
+
+type registeredEncoder struct {
+	name       string
+	mediaTypes []string
+	encoder    Encoder
+}
+
+// encoders are the formats of request and response bodies, in order of
+// preference. The first one is the default.
+var encoders []registeredEncoder
+
+func init() {
+	RegisterEncoder("json", jsonEncoder{}, echo.MIMEApplicationJSON, MIMEProblemJSON)
+	RegisterEncoder("xml", xmlEncoder{}, echo.MIMEApplicationXML, echo.MIMETextXML, MIMEProblemXML)
+	RegisterEncoder("yaml", yamlEncoder{}, MIMEYAML, "application/x-yaml", "text/yaml")
+	RegisterEncoder("msgpack", msgpackEncoder{}, MIMEMsgpack, "application/x-msgpack")
+	RegisterEncoder("csv", csvEncoder{}, MIMETextCSV)
+}
+
+// RegisterEncoder makes encoder available to every controller for the given
+// media types, the first being its own, and for the mediaType query parameter
+// under name.
+func RegisterEncoder(name string, encoder Encoder, mediaTypes ...string) {
+	encoders = append(encoders, registeredEncoder{
+		name:       name,
+		mediaTypes: mediaTypes,
+		encoder:    encoder,
+	})
+}
+
+// MediaTypes lists the media types of the registered encoders.
+func MediaTypes() []string {
+	var mediaTypes []string
+	for _, registered := range encoders {
+		mediaTypes = append(mediaTypes, registered.mediaTypes[0])
+	}
+	return mediaTypes
+}
+
+// DecoderMediaTypes lists the media types of the registered encoders that
+// read request bodies.
+func DecoderMediaTypes() []string {
+	var mediaTypes []string
+	for _, registered := range encoders {
+		if _, ok := registered.encoder.(Decoder); ok {
+			mediaTypes = append(mediaTypes, registered.mediaTypes[0])
+		}
+	}
+	return mediaTypes
+}
+
+func encoderByName(name string) (Encoder, bool) {
+	for _, registered := range encoders {
+		if registered.name == name {
+			return registered.encoder, true
+		}
+	}
+	return nil, false
+}
+
+func decoderByMediaType(mediaType string) (Decoder, bool) {
+	for _, registered := range encoders {
+		decoder, ok := registered.encoder.(Decoder)
+		if !ok {
+			continue
+		}
+		for _, candidate := range registered.mediaTypes {
+			if candidate == mediaType {
+				return decoder, true
+			}
+		}
+	}
+	return nil, false
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) ContentType() string {
+	return echo.MIMEApplicationJSONCharsetUTF8
+}
+
+func (jsonEncoder) Encode(w http.ResponseWriter, i interface{}) error {
+	return json.NewEncoder(w).Encode(i)
+}
+
+func (jsonEncoder) Decode(r io.Reader, i interface{}) error {
+	return json.NewDecoder(r).Decode(i)
+}
+
+type xmlEncoder struct{}
+
+func (xmlEncoder) ContentType() string {
+	return echo.MIMEApplicationXMLCharsetUTF8
+}
+
+func (xmlEncoder) Encode(w http.ResponseWriter, i interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(i)
+}
+
+func (xmlEncoder) Decode(r io.Reader, i interface{}) error {
+	return xml.NewDecoder(r).Decode(i)
+}
@@ -0,0 +1,58 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerETag        = "ETag"
+	headerIfNoneMatch = "If-None-Match"
+	HeaderIfMatch     = "If-Match"
+)
+
+// ETag formats the version of a document as an entity tag.
+func ETag(version int64) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// ParseETag returns the version held by an entity tag built by ETag.
+func ParseETag(etag string) (int64, bool) {
+	etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+	if len(etag) < 2 || !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(etag[1:len(etag)-1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+// SetETag sets the ETag header of the response to the given version.
+func SetETag(c echo.Context, version int64) {
+	c.Response().Header().Set(headerETag, ETag(version))
+}
+
+// NotModified sets the ETag header for version and reports whether the
+// If-None-Match header of the request already matches it.
+func NotModified(c echo.Context, version int64) bool {
+	etag := ETag(version)
+	SetETag(c, version)
+
+	ifNoneMatch := c.Request().Header.Get(headerIfNoneMatch)
+	if len(ifNoneMatch) == 0 {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,342 @@
+package util
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	MIMEYAML    = "application/yaml"
+	MIMEMsgpack = "application/msgpack"
+)
+
+// The YAML, MessagePack and CSV encoders write the JSON representation of a
+// value, so that they use the same field names, in the same order, and the
+// same values (hex ids, RFC 3339 times) as the JSON responses.
+
+// orderedField is a member of a JSON object.
+type orderedField struct {
+	key   string
+	value interface{}
+}
+
+// orderedObject is a JSON object that keeps the order of its members.
+type orderedObject []orderedField
+
+func (object orderedObject) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, field := range object {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		key, err := json.Marshal(field.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(key)
+		buffer.WriteByte(':')
+		buffer.Write(value)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// jsonTree returns the JSON representation of i made of orderedObject,
+// []interface{}, string, json.Number, bool and nil values.
+func jsonTree(i interface{}) (interface{}, error) {
+	data, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decodeTree(decoder)
+}
+
+func decodeTree(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		object := orderedObject{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeTree(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, orderedField{key: key.(string), value: value})
+		}
+		_, err = decoder.Token()
+		return object, err
+	case json.Delim('['):
+		array := []interface{}{}
+		for decoder.More() {
+			value, err := decodeTree(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = decoder.Token()
+		return array, err
+	}
+	return token, nil
+}
+
+// number returns n as an int64 when it is integral, as a float64 otherwise.
+func number(n json.Number) interface{} {
+	if value, err := n.Int64(); err == nil {
+		return value
+	}
+	value, _ := n.Float64()
+	return value
+}
+
+type yamlEncoder struct{}
+
+func (yamlEncoder) ContentType() string {
+	return MIMEYAML
+}
+
+func (yamlEncoder) Encode(w http.ResponseWriter, i interface{}) error {
+	tree, err := jsonTree(i)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(yamlValue(tree))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func yamlValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case orderedObject:
+		mapSlice := make(yaml.MapSlice, 0, len(value))
+		for _, field := range value {
+			mapSlice = append(mapSlice, yaml.MapItem{Key: field.key, Value: yamlValue(field.value)})
+		}
+		return mapSlice
+	case []interface{}:
+		array := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			array = append(array, yamlValue(item))
+		}
+		return array
+	case json.Number:
+		return number(value)
+	}
+	return value
+}
+
+type msgpackEncoder struct{}
+
+func (msgpackEncoder) ContentType() string {
+	return MIMEMsgpack
+}
+
+func (msgpackEncoder) Encode(w http.ResponseWriter, i interface{}) error {
+	tree, err := jsonTree(i)
+	if err != nil {
+		return err
+	}
+	var buffer bytes.Buffer
+	if err := encodeMsgpack(msgpack.NewEncoder(&buffer), tree); err != nil {
+		return err
+	}
+	_, err = w.Write(buffer.Bytes())
+	return err
+}
+
+func encodeMsgpack(encoder *msgpack.Encoder, value interface{}) error {
+	switch value := value.(type) {
+	case orderedObject:
+		if err := encoder.EncodeMapLen(len(value)); err != nil {
+			return err
+		}
+		for _, field := range value {
+			if err := encoder.EncodeString(field.key); err != nil {
+				return err
+			}
+			if err := encodeMsgpack(encoder, field.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		if err := encoder.EncodeArrayLen(len(value)); err != nil {
+			return err
+		}
+		for _, item := range value {
+			if err := encodeMsgpack(encoder, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case json.Number:
+		return encoder.Encode(number(value))
+	}
+	return encoder.Encode(value)
+}
+
+// csvEncoder writes one row per record. The data of a paged response are the
+// rows and its other members, such as pageInfo and cursor, are sent as
+// X-Page-Info-Total, X-Cursor-Has-Next... headers. Nested objects are
+// flattened into dotted columns and arrays are written as JSON.
+type csvEncoder struct{}
+
+func (csvEncoder) ContentType() string {
+	return MIMETextCSV + "; charset=utf-8"
+}
+
+func (csvEncoder) Encode(w http.ResponseWriter, i interface{}) error {
+	tree, err := jsonTree(i)
+	if err != nil {
+		return err
+	}
+
+	records := []interface{}{tree}
+	switch tree := tree.(type) {
+	case []interface{}:
+		records = tree
+	case orderedObject:
+		for _, field := range tree {
+			if data, ok := field.value.([]interface{}); ok && field.key == "data" {
+				records = data
+				for _, field := range tree {
+					if field.key != "data" {
+						setCSVHeaders(w.Header(), []string{field.key}, field.value)
+					}
+				}
+				break
+			}
+		}
+	}
+
+	var columns []string
+	indexes := map[string]int{}
+	var rows [][]csvCell
+	for _, record := range records {
+		var row []csvCell
+		flattenCSV(&row, "", record)
+		for _, cell := range row {
+			if _, ok := indexes[cell.column]; !ok {
+				indexes[cell.column] = len(columns)
+				columns = append(columns, cell.column)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		values := make([]string, len(columns))
+		for _, cell := range row {
+			values[indexes[cell.column]] = cell.value
+		}
+		if err := writer.Write(values); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvCell is the value of a record in one column.
+type csvCell struct {
+	column string
+	value  string
+}
+
+// flattenCSV appends the cells of value to row, in columns prefixed by
+// prefix. A record that is not an object is a single value column.
+func flattenCSV(row *[]csvCell, prefix string, value interface{}) {
+	if object, ok := value.(orderedObject); ok {
+		for _, field := range object {
+			column := field.key
+			if len(prefix) > 0 {
+				column = prefix + "." + field.key
+			}
+			flattenCSV(row, column, field.value)
+		}
+		return
+	}
+
+	if len(prefix) == 0 {
+		prefix = "value"
+	}
+	*row = append(*row, csvCell{column: prefix, value: csvValue(value)})
+}
+
+func csvValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// setCSVHeaders sends the scalar members of value as X- headers named after
+// their path: pageInfo.totalPage becomes X-Page-Info-Total-Page.
+func setCSVHeaders(header http.Header, path []string, value interface{}) {
+	switch value := value.(type) {
+	case orderedObject:
+		for _, field := range value {
+			setCSVHeaders(header, append(path, field.key), field.value)
+		}
+	case []interface{}:
+	default:
+		header.Set(csvHeaderName(path), csvValue(value))
+	}
+}
+
+func csvHeaderName(path []string) string {
+	var words []string
+	for _, key := range path {
+		word := []rune{}
+		for _, r := range key {
+			if unicode.IsUpper(r) && len(word) > 0 {
+				words = append(words, string(word))
+				word = []rune{}
+			}
+			word = append(word, r)
+		}
+		words = append(words, string(word))
+	}
+	for i, word := range words {
+		words[i] = strings.Title(strings.ToLower(word))
+	}
+	return "X-" + strings.Join(words, "-")
+}
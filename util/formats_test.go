@@ -0,0 +1,120 @@
+package util
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type csvShowtime struct {
+	ID     string     `json:"id"`
+	Cinema *csvCinema `json:"cinema,omitempty"`
+	Seats  []string   `json:"seats,omitempty"`
+	Sold   bool       `json:"sold"`
+	Price  *float64   `json:"price"`
+}
+
+type csvCinema struct {
+	Name     string      `json:"name"`
+	Location csvLocation `json:"location"`
+}
+
+type csvLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type csvPageInfo struct {
+	Total     int64 `json:"total"`
+	TotalPage int64 `json:"totalPage"`
+}
+
+type csvCursor struct {
+	Next    string `json:"next"`
+	HasNext bool   `json:"hasNext"`
+}
+
+func TestCSVEncoder(t *testing.T) {
+	price := 9.5
+	cinema := &csvCinema{Name: "Centro", Location: csvLocation{Latitude: 4.6, Longitude: -74.08}}
+	tests := []struct {
+		name    string
+		value   interface{}
+		body    string
+		headers map[string]string
+	}{
+		{
+			name: "paged response",
+			value: struct {
+				Data     []csvShowtime `json:"data"`
+				PageInfo csvPageInfo   `json:"pageInfo"`
+				Cursor   csvCursor     `json:"cursor"`
+			}{
+				Data: []csvShowtime{
+					{ID: "1", Cinema: cinema, Seats: []string{"A1", "A2"}, Sold: true, Price: &price},
+					{ID: "2"},
+				},
+				PageInfo: csvPageInfo{Total: 12, TotalPage: 2},
+				Cursor:   csvCursor{Next: "abc", HasNext: true},
+			},
+			body: "id,cinema.name,cinema.location.latitude,cinema.location.longitude,seats,sold,price\n" +
+				`1,Centro,4.6,-74.08,"[""A1"",""A2""]",true,9.5` + "\n" +
+				"2,,,,,false,\n",
+			headers: map[string]string{
+				"X-Page-Info-Total":      "12",
+				"X-Page-Info-Total-Page": "2",
+				"X-Cursor-Next":          "abc",
+				"X-Cursor-Has-Next":      "true",
+			},
+		},
+		{
+			name:  "single document",
+			value: csvShowtime{ID: "1", Cinema: cinema},
+			body:  "id,cinema.name,cinema.location.latitude,cinema.location.longitude,sold,price\n1,Centro,4.6,-74.08,false,\n",
+		},
+		{
+			name:  "array of values",
+			value: []string{"2D", "3D, IMAX"},
+			body:  "value\n2D\n\"3D, IMAX\"\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			if err := (csvEncoder{}).Encode(recorder, test.value); err != nil {
+				t.Fatal(err)
+			}
+			if body := recorder.Body.String(); body != test.body {
+				t.Errorf("the body is\n%s\nwant\n%s", body, test.body)
+			}
+			for header, want := range test.headers {
+				if value := recorder.Header().Get(header); value != want {
+					t.Errorf("%s is %q, want %q", header, value, want)
+				}
+			}
+			for header := range recorder.Header() {
+				if _, ok := test.headers[header]; !ok && strings.HasPrefix(header, "X-") {
+					t.Errorf("sent %s, want no other X- header", header)
+				}
+			}
+		})
+	}
+}
+
+func TestCSVHeaderName(t *testing.T) {
+	tests := []struct {
+		path []string
+		want string
+	}{
+		{[]string{"pageInfo", "total"}, "X-Page-Info-Total"},
+		{[]string{"pageInfo", "totalPage"}, "X-Page-Info-Total-Page"},
+		{[]string{"cursor", "hasNext"}, "X-Cursor-Has-Next"},
+		{[]string{"from"}, "X-From"},
+	}
+	for _, test := range tests {
+		if got := csvHeaderName(test.path); got != test.want {
+			t.Errorf("csvHeaderName(%q) = %q, want %q", test.path, got, test.want)
+		}
+	}
+}
@@ -7,7 +7,7 @@ import (
 
 	"github.com/cbuelvasc/cinema-backend/config"
 	"github.com/cbuelvasc/cinema-backend/model"
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
 )
 
@@ -25,6 +25,7 @@ func GenerateJwtToken(user *model.User) (string, error) {
 		user.Biography,
 		user.Location,
 		user.WebSite,
+		user.Role,
 		jwt.StandardClaims{
 			ExpiresAt: exp,
 		},
@@ -43,3 +44,12 @@ func GetUserIdFromToken(c echo.Context) string {
 	claims := user.Claims.(*model.JwtCustomClaims)
 	return claims.ID
 }
+
+func GetUserRoleFromToken(c echo.Context) string {
+	user, ok := c.Get("user").(*jwt.Token)
+	if !ok {
+		return ""
+	}
+	claims := user.Claims.(*model.JwtCustomClaims)
+	return claims.Role
+}
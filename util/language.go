@@ -0,0 +1,89 @@
+package util
+
+import (
+	"strings"
+
+	"github.com/cbuelvasc/cinema-backend/i18n"
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerAcceptLanguage  = "Accept-Language"
+	headerContentLanguage = "Content-Language"
+)
+
+// Language returns the language of the messages of the response, the one the
+// Accept-Language header prefers among the supported ones.
+func Language(c echo.Context) string {
+	language := i18n.Language(c.Request().Header.Get(headerAcceptLanguage))
+	addVary(c, headerAcceptLanguage)
+	c.Response().Header().Set(headerContentLanguage, language)
+	return language
+}
+
+// Locales returns the locales to show content such as movie titles in, most
+// preferred first: the lang query parameter when given, then the ones of the
+// Accept-Language header, each followed by its broader locales and the
+// default language.
+func Locales(c echo.Context) []string {
+	if lang := c.QueryParam("lang"); len(lang) > 0 {
+		return i18n.Locales(lang)
+	}
+	addVary(c, headerAcceptLanguage)
+	return i18n.Locales(c.Request().Header.Get(headerAcceptLanguage))
+}
+
+// LocalizeMovie replaces the title, synopsis and tagline of movie with the
+// ones of the first of locales it has a translation for. A synopsis or tagline
+// the translation lacks is taken further down the chain, and the untranslated
+// metadata is the last resort.
+func LocalizeMovie(movie *model.Movie, locales []string) {
+	if movie.MovieInput == nil || len(movie.Translations) == 0 {
+		return
+	}
+
+	title, synopsis, tagline := "", "", ""
+	for _, locale := range locales {
+		for _, translation := range movie.Translations {
+			if !strings.EqualFold(translation.Locale, locale) {
+				continue
+			}
+			if len(title) == 0 {
+				title = translation.Title
+				movie.Locale = translation.Locale
+			}
+			if len(synopsis) == 0 {
+				synopsis = translation.Synopsis
+			}
+			if len(tagline) == 0 {
+				tagline = translation.Tagline
+			}
+		}
+	}
+
+	localized := *movie.MovieInput
+	if len(title) > 0 {
+		localized.Title = title
+	}
+	if len(synopsis) > 0 {
+		localized.Synopsis = synopsis
+	}
+	if len(tagline) > 0 {
+		localized.Tagline = tagline
+	}
+	movie.MovieInput = &localized
+}
+
+// NormalizeMovie normalizes input as model.MovieInput.Normalize does and
+// writes its languages and subtitles as locales, such as es-CO.
+func NormalizeMovie(input *model.MovieInput) {
+	input.Normalize()
+	for _, languages := range [][]string{input.Languages, input.Subtitles} {
+		for i, language := range languages {
+			if locale, ok := i18n.NormalizeLocale(language); ok {
+				languages[i] = locale
+			}
+		}
+	}
+}
@@ -1,18 +1,223 @@
 package util
 
 import (
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	MIMEProblemJSON = "application/problem+json"
+	MIMEProblemXML  = "application/problem+xml"
+)
+
+// mediaRange is one entry of an Accept header.
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// Negotiate writes i with the encoder the request accepts best: the one named
+// by the mediaType query parameter, otherwise the one preferred by the Accept
+// header. It fails with 406 when no registered encoder is acceptable.
 func Negotiate(c echo.Context, code int, i interface{}) error {
-	mediaType := c.QueryParam("mediaType")
+	encoder, err := negotiateEncoder(c)
+	if err != nil {
+		return err
+	}
+	return render(c, code, i, encoder)
+}
+
+// NegotiateOrDefault is Negotiate, falling back to the default encoder when
+// the request accepts none. Errors are sent this way: a response has to be
+// written even if the client cannot read it.
+func NegotiateOrDefault(c echo.Context, code int, i interface{}) error {
+	encoder, err := negotiateEncoder(c)
+	if err != nil {
+		encoder = encoders[0].encoder
+	}
+	return render(c, code, i, encoder)
+}
+
+// NegotiateProblem is NegotiateOrDefault for RFC 7807 problem details, which
+// are sent as application/problem+json or application/problem+xml when the
+// JSON or XML encoder is chosen.
+func NegotiateProblem(c echo.Context, code int, problem interface{}) error {
+	encoder, err := negotiateEncoder(c)
+	if err != nil {
+		encoder = encoders[0].encoder
+	}
+
+	switch encoder.(type) {
+	case jsonEncoder:
+		encoder = contentTypeEncoder{Encoder: encoder, contentType: MIMEProblemJSON}
+	case xmlEncoder:
+		encoder = contentTypeEncoder{Encoder: encoder, contentType: MIMEProblemXML}
+	}
+	return render(c, code, problem, encoder)
+}
+
+// contentTypeEncoder sends the bodies of Encoder under another media type.
+type contentTypeEncoder struct {
+	Encoder
+	contentType string
+}
+
+func (encoder contentTypeEncoder) ContentType() string {
+	return encoder.contentType
+}
+
+// Bind decodes the request body into i with the encoder registered for its
+// Content-Type. It fails with 415 when there is none. An empty body leaves i
+// untouched.
+func Bind(c echo.Context, i interface{}) error {
+	request := c.Request()
+	if request.ContentLength == 0 {
+		return nil
+	}
 
-	switch mediaType {
-	case "xml":
-		return c.XML(code, i)
-	case "json":
-		return c.JSON(code, i)
+	mediaType, _, _ := mime.ParseMediaType(request.Header.Get(echo.HeaderContentType))
+	decoder, ok := decoderByMediaType(mediaType)
+	if !ok {
+		return exception.UnsupportedMediaTypeException(DecoderMediaTypes()...)
+	}
+	if err := decoder.Decode(request.Body, i); err != nil && err != io.EOF {
+		return exception.BadRequestException(err.Error())
+	}
+	return nil
+}
+
+// render sends the status with the first write of the encoder, which can
+// still set headers until then.
+func render(c echo.Context, code int, i interface{}, encoder Encoder) error {
+	response := c.Response()
+	response.Header().Set(echo.HeaderContentType, encoder.ContentType())
+	response.Status = code
+	if err := encoder.Encode(response, i); err != nil {
+		return err
+	}
+	if !response.Committed {
+		response.WriteHeader(code)
+	}
+	return nil
+}
+
+func negotiateEncoder(c echo.Context) (Encoder, error) {
+	addVary(c, echo.HeaderAccept)
+
+	if name := c.QueryParam("mediaType"); len(name) > 0 {
+		if encoder, ok := encoderByName(name); ok {
+			return encoder, nil
+		}
+		return nil, exception.NotAcceptableException(MediaTypes()...)
+	}
+
+	accept := c.Request().Header.Get(echo.HeaderAccept)
+	if len(strings.TrimSpace(accept)) == 0 {
+		return encoders[0].encoder, nil
+	}
+
+	ranges := parseAccept(accept)
+	var best Encoder
+	bestQuality := 0.0
+	for _, registered := range encoders {
+		if quality := encoderQuality(ranges, registered.mediaTypes); quality > bestQuality {
+			best, bestQuality = registered.encoder, quality
+		}
+	}
+	if best == nil {
+		return nil, exception.NotAcceptableException(MediaTypes()...)
+	}
+	return best, nil
+}
+
+// parseAccept reads the media ranges of an Accept header, the most specific
+// first so that the first range matching a media type is the one that
+// applies to it.
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil || quality < 0 || quality > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return specificity(ranges[i].mediaType) > specificity(ranges[j].mediaType)
+	})
+	return ranges
+}
+
+func specificity(mediaRange string) int {
+	switch {
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*"):
+		return 1
 	default:
-		return c.JSON(code, i)
+		return 2
+	}
+}
+
+// acceptQuality is the quality the ranges give to mediaType, 0 when they do
+// not accept it.
+func acceptQuality(ranges []mediaRange, mediaType string) float64 {
+	if accepted, ok := acceptedRange(ranges, mediaType); ok {
+		return accepted.quality
+	}
+	return 0
+}
+
+// encoderQuality is the quality the ranges give to an encoder: the one of the
+// most specific range matching any of its media types, so that */* matching
+// application/problem+json does not undo application/json;q=0.
+func encoderQuality(ranges []mediaRange, mediaTypes []string) float64 {
+	quality, rank := 0.0, -1
+	for _, mediaType := range mediaTypes {
+		accepted, ok := acceptedRange(ranges, mediaType)
+		if !ok {
+			continue
+		}
+		if s := specificity(accepted.mediaType); s > rank || (s == rank && accepted.quality > quality) {
+			quality, rank = accepted.quality, s
+		}
+	}
+	return quality
+}
+
+// acceptedRange returns the most specific of the ranges matching mediaType.
+func acceptedRange(ranges []mediaRange, mediaType string) (mediaRange, bool) {
+	for _, accepted := range ranges {
+		if accepted.mediaType == mediaType || accepted.mediaType == "*/*" ||
+			(strings.HasSuffix(accepted.mediaType, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(accepted.mediaType, "*"))) {
+			return accepted, true
+		}
+	}
+	return mediaRange{}, false
+}
+
+// addVary adds header to the Vary header of the response, once.
+func addVary(c echo.Context, header string) {
+	for _, vary := range c.Response().Header().Values(echo.HeaderVary) {
+		for _, existing := range strings.Split(vary, ",") {
+			if strings.EqualFold(strings.TrimSpace(existing), header) {
+				return
+			}
+		}
 	}
+	c.Response().Header().Add(echo.HeaderVary, header)
 }
@@ -0,0 +1,92 @@
+package util
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestParseAccept(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   []mediaRange
+	}{
+		{
+			accept: "application/json",
+			want:   []mediaRange{{"application/json", 1}},
+		},
+		{
+			accept: "*/*;q=0.1, text/*;q=0.5, application/xml;q=0.8",
+			want:   []mediaRange{{"application/xml", 0.8}, {"text/*", 0.5}, {"*/*", 0.1}},
+		},
+		{
+			accept: "application/yaml; q=0.9, application/json",
+			want:   []mediaRange{{"application/yaml", 0.9}, {"application/json", 1}},
+		},
+		{
+			accept: "application/json;q=2, application/xml;q=abc, ;q=1, text/csv;q=0",
+			want:   []mediaRange{{"text/csv", 0}},
+		},
+	}
+	for _, test := range tests {
+		if got := parseAccept(test.accept); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseAccept(%q) = %v, want %v", test.accept, got, test.want)
+		}
+	}
+}
+
+func TestNegotiateEncoder(t *testing.T) {
+	tests := []struct {
+		name      string
+		accept    string
+		mediaType string
+		want      Encoder
+	}{
+		{name: "no Accept header", want: jsonEncoder{}},
+		{name: "exact media type", accept: "application/xml", want: xmlEncoder{}},
+		{name: "alias media type", accept: "application/x-yaml", want: yamlEncoder{}},
+		{name: "highest quality", accept: "application/json;q=0.5, text/csv;q=0.9, application/xml;q=0.7", want: csvEncoder{}},
+		{name: "subtype wildcard", accept: "text/*", want: xmlEncoder{}},
+		{name: "any media type", accept: "*/*", want: jsonEncoder{}},
+		{name: "specific range over a wildcard", accept: "*/*;q=0.9, application/json;q=0.1", want: xmlEncoder{}},
+		{name: "alias media type of the default", accept: "application/problem+json, */*;q=0.5", want: jsonEncoder{}},
+		{name: "excluded media type", accept: "application/json;q=0, */*", want: xmlEncoder{}},
+		{name: "mediaType parameter over Accept", accept: "application/json", mediaType: "msgpack", want: msgpackEncoder{}},
+		{name: "nothing acceptable", accept: "image/png, text/html;q=0.9"},
+		{name: "everything excluded", accept: "*/*;q=0"},
+		{name: "unknown mediaType parameter", accept: "application/json", mediaType: "pdf"},
+	}
+
+	e := echo.New()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			target := "/"
+			if len(test.mediaType) > 0 {
+				target += "?mediaType=" + test.mediaType
+			}
+			request := httptest.NewRequest(http.MethodGet, target, nil)
+			if len(test.accept) > 0 {
+				request.Header.Set(echo.HeaderAccept, test.accept)
+			}
+			recorder := httptest.NewRecorder()
+			c := e.NewContext(request, recorder)
+
+			encoder, err := negotiateEncoder(c)
+			if test.want == nil {
+				var httpError *echo.HTTPError
+				if !errors.As(err, &httpError) || httpError.Code != http.StatusNotAcceptable {
+					t.Errorf("negotiateEncoder returned %v, %v, want status %d", encoder, err, http.StatusNotAcceptable)
+				}
+			} else if err != nil || encoder != test.want {
+				t.Errorf("negotiateEncoder returned %T, %v, want %T", encoder, err, test.want)
+			}
+			if vary := recorder.Header().Get(echo.HeaderVary); vary != echo.HeaderAccept {
+				t.Errorf("Vary is %q, want %q", vary, echo.HeaderAccept)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package util
+
+import (
+	"strconv"
+
+	"github.com/cbuelvasc/cinema-backend/model"
+	"github.com/labstack/echo/v4"
+)
+
+// GetCursorQuery reads the cursor pagination parameters of the request. The
+// second value is false when the client asked for page/limit pagination.
+func GetCursorQuery(c echo.Context) (*model.CursorQuery, bool) {
+	after := c.QueryParam("after")
+	before := c.QueryParam("before")
+	if len(after) == 0 && len(before) == 0 && c.QueryParam("pagination") != "cursor" {
+		return nil, false
+	}
+
+	limit, _ := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
+	skipCount, _ := strconv.ParseBool(c.QueryParam("skipCount"))
+
+	return &model.CursorQuery{
+		After:     after,
+		Before:    before,
+		Limit:     limit,
+		Sort:      c.QueryParam("sort"),
+		SkipCount: skipCount,
+	}, true
+}
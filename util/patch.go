@@ -0,0 +1,337 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	MIMEMergePatch = "application/merge-patch+json"
+	MIMEJSONPatch  = "application/json-patch+json"
+)
+
+// patchOperation is one operation of a JSON Patch (RFC 6902) document.
+type patchOperation struct {
+	Op    string           `json:"op"`
+	Path  string           `json:"path"`
+	From  string           `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// BindPatch applies the JSON Merge Patch or JSON Patch in the request body to
+// current and decodes the patched document into target, which is validated.
+// Members set to null by a merge patch, or removed by a JSON Patch, are left
+// at their zero value in target.
+func BindPatch(c echo.Context, current interface{}, target interface{}) error {
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if mediaType != MIMEMergePatch && mediaType != MIMEJSONPatch {
+		return exception.UnsupportedMediaTypeException(MIMEMergePatch, MIMEJSONPatch)
+	}
+
+	body, err := ioutil.ReadAll(c.Request().Body)
+	if err != nil {
+		return exception.InvalidRequestException("error.invalid_body")
+	}
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return err
+	}
+
+	if mediaType == MIMEMergePatch {
+		var patch interface{}
+		if err := json.Unmarshal(body, &patch); err != nil {
+			return exception.InvalidRequestException("error.invalid_merge_patch", err.Error())
+		}
+		document = mergePatch(document, patch)
+	} else {
+		var operations []patchOperation
+		if err := json.Unmarshal(body, &operations); err != nil {
+			return exception.InvalidRequestException("error.invalid_json_patch", err.Error())
+		}
+		if document, err = jsonPatch(document, operations); err != nil {
+			return err
+		}
+	}
+
+	if data, err = json.Marshal(document); err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return exception.InvalidRequestException("error.invalid_patched_document", err.Error())
+	}
+
+	if err := c.Validate(target); err != nil {
+		return exception.ValidationException(err)
+	}
+	return nil
+}
+
+// mergePatch applies patch to target as described by RFC 7386.
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+		} else {
+			targetObject[key] = mergePatch(targetObject[key], value)
+		}
+	}
+	return targetObject
+}
+
+// jsonPatch applies operations to document as described by RFC 6902. The
+// whole patch fails if any operation does.
+func jsonPatch(document interface{}, operations []patchOperation) (interface{}, error) {
+	var err error
+	for _, operation := range operations {
+		switch operation.Op {
+		case "add", "replace", "test":
+			if operation.Value == nil {
+				return nil, exception.InvalidRequestException("error.patch_missing_value", operation.Op)
+			}
+			var value interface{}
+			if err := json.Unmarshal(*operation.Value, &value); err != nil {
+				return nil, exception.InvalidRequestException("error.patch_invalid_value", operation.Op, err.Error())
+			}
+
+			switch operation.Op {
+			case "add":
+				document, err = addValue(document, operation.Path, value)
+			case "replace":
+				if document, _, err = removeValue(document, operation.Path); err == nil {
+					document, err = addValue(document, operation.Path, value)
+				}
+			case "test":
+				var current interface{}
+				if current, err = getValue(document, operation.Path); err == nil && !reflect.DeepEqual(current, value) {
+					err = exception.RequestConflictException("error.patch_test_failed", operation.Path)
+				}
+			}
+		case "remove":
+			document, _, err = removeValue(document, operation.Path)
+		case "move", "copy":
+			var value interface{}
+			if operation.Op == "move" {
+				if strings.HasPrefix(operation.Path, operation.From+"/") {
+					return nil, exception.InvalidRequestException("error.patch_move_into_child", operation.From)
+				}
+				document, value, err = removeValue(document, operation.From)
+			} else {
+				value, err = getValue(document, operation.From)
+				value = copyValue(value)
+			}
+			if err == nil {
+				document, err = addValue(document, operation.Path, value)
+			}
+		default:
+			return nil, exception.InvalidRequestException("error.patch_invalid_operation", operation.Op)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return document, nil
+}
+
+// splitPointer returns the reference tokens of a JSON Pointer (RFC 6901).
+func splitPointer(pointer string) ([]string, error) {
+	if len(pointer) == 0 {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, exception.InvalidRequestException("error.patch_invalid_path", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+func getValue(document interface{}, pointer string) (interface{}, error) {
+	tokens, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	current := document
+	for _, token := range tokens {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, exception.RequestConflictException("error.patch_path_not_found", pointer)
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, exception.RequestConflictException("error.patch_path_not_found", pointer)
+			}
+			current = node[index]
+		default:
+			return nil, exception.RequestConflictException("error.patch_path_not_found", pointer)
+		}
+	}
+	return current, nil
+}
+
+// addValue sets value at pointer, inserting it when the parent is an array.
+func addValue(document interface{}, pointer string, value interface{}) (interface{}, error) {
+	tokens, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, err := getValue(document, parentPointer)
+	if err != nil {
+		return nil, err
+	}
+
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+		return document, nil
+	case []interface{}:
+		index := len(node)
+		if last != "-" {
+			if index, err = arrayIndex(last, len(node)); err != nil {
+				return nil, exception.RequestConflictException("error.patch_path_not_found", pointer)
+			}
+		}
+		node = append(node, nil)
+		copy(node[index+1:], node[index:])
+		node[index] = value
+		return setValue(document, parentPointer, node)
+	default:
+		return nil, exception.RequestConflictException("error.patch_path_not_found", pointer)
+	}
+}
+
+// removeValue deletes the value at pointer and returns it.
+func removeValue(document interface{}, pointer string) (interface{}, interface{}, error) {
+	tokens, err := splitPointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, document, nil
+	}
+
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, err := getValue(document, parentPointer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		value, ok := node[last]
+		if !ok {
+			return nil, nil, exception.RequestConflictException("error.patch_path_not_found", pointer)
+		}
+		delete(node, last)
+		return document, value, nil
+	case []interface{}:
+		index, err := arrayIndex(last, len(node)-1)
+		if err != nil {
+			return nil, nil, exception.RequestConflictException("error.patch_path_not_found", pointer)
+		}
+		value := node[index]
+		node = append(node[:index:index], node[index+1:]...)
+		document, err = setValue(document, parentPointer, node)
+		return document, value, err
+	default:
+		return nil, nil, exception.RequestConflictException("error.patch_path_not_found", pointer)
+	}
+}
+
+// setValue replaces the existing value at pointer. Arrays are rebuilt when
+// their length changes, so their parent has to point to the new slice.
+func setValue(document interface{}, pointer string, value interface{}) (interface{}, error) {
+	tokens, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	parent, err := getValue(document, pointer[:strings.LastIndex(pointer, "/")])
+	if err != nil {
+		return nil, err
+	}
+
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+	case []interface{}:
+		index, err := arrayIndex(last, len(node)-1)
+		if err != nil {
+			return nil, exception.RequestConflictException("error.patch_path_not_found", pointer)
+		}
+		node[index] = value
+	}
+	return document, nil
+}
+
+func arrayIndex(token string, max int) (int, error) {
+	if len(token) > 1 && strings.HasPrefix(token, "0") {
+		return 0, exception.InvalidRequestException("error.patch_invalid_index", token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > max {
+		return 0, exception.InvalidRequestException("error.patch_invalid_index", token)
+	}
+	return index, nil
+}
+
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = copyValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	default:
+		return v
+	}
+}
@@ -0,0 +1,201 @@
+package util
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cbuelvasc/cinema-backend/i18n"
+	"github.com/labstack/echo/v4"
+)
+
+type patchDocument struct {
+	Name   string            `json:"name" validate:"required"`
+	Tags   []string          `json:"tags,omitempty"`
+	Links  map[string]string `json:"links,omitempty"`
+	Nested *patchNested      `json:"nested,omitempty"`
+}
+
+type patchNested struct {
+	Value string       `json:"value,omitempty"`
+	Child *patchNested `json:"child,omitempty"`
+}
+
+func currentPatchDocument() *patchDocument {
+	return &patchDocument{
+		Name:   "Leo",
+		Tags:   []string{"a", "b"},
+		Links:  map[string]string{"home": "h"},
+		Nested: &patchNested{Value: "v"},
+	}
+}
+
+func TestBindPatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        *patchDocument
+		status      int
+		key         string
+	}{
+		{
+			name:        "merge patch sets and removes members",
+			contentType: MIMEMergePatch,
+			body:        `{"name": "Ana", "tags": null, "nested": {"value": "w"}}`,
+			want:        &patchDocument{Name: "Ana", Links: map[string]string{"home": "h"}, Nested: &patchNested{Value: "w"}},
+		},
+		{
+			name:        "replace",
+			contentType: MIMEJSONPatch,
+			body:        `[{"op": "replace", "path": "/name", "value": "Ana"}]`,
+			want:        &patchDocument{Name: "Ana", Tags: []string{"a", "b"}, Links: map[string]string{"home": "h"}, Nested: &patchNested{Value: "v"}},
+		},
+		{
+			name:        "escaped pointers",
+			contentType: MIMEJSONPatch,
+			body:        `[{"op": "add", "path": "/links/a~1b", "value": "x"}, {"op": "add", "path": "/links/m~0n", "value": "y"}]`,
+			want:        &patchDocument{Name: "Leo", Tags: []string{"a", "b"}, Links: map[string]string{"home": "h", "a/b": "x", "m~n": "y"}, Nested: &patchNested{Value: "v"}},
+		},
+		{
+			name:        "array insert and append",
+			contentType: MIMEJSONPatch,
+			body:        `[{"op": "add", "path": "/tags/1", "value": "x"}, {"op": "add", "path": "/tags/-", "value": "z"}]`,
+			want:        &patchDocument{Name: "Leo", Tags: []string{"a", "x", "b", "z"}, Links: map[string]string{"home": "h"}, Nested: &patchNested{Value: "v"}},
+		},
+		{
+			name:        "array remove",
+			contentType: MIMEJSONPatch,
+			body:        `[{"op": "remove", "path": "/tags/0"}]`,
+			want:        &patchDocument{Name: "Leo", Tags: []string{"b"}, Links: map[string]string{"home": "h"}, Nested: &patchNested{Value: "v"}},
+		},
+		{
+			name:        "move and copy",
+			contentType: MIMEJSONPatch,
+			body:        `[{"op": "move", "from": "/nested/value", "path": "/name"}, {"op": "copy", "from": "/tags/1", "path": "/tags/0"}]`,
+			want:        &patchDocument{Name: "v", Tags: []string{"b", "a", "b"}, Links: map[string]string{"home": "h"}, Nested: &patchNested{}},
+		},
+		{
+			name:        "test passes",
+			contentType: MIMEJSONPatch,
+			body:        `[{"op": "test", "path": "/tags", "value": ["a", "b"]}, {"op": "remove", "path": "/links"}]`,
+			want:        &patchDocument{Name: "Leo", Tags: []string{"a", "b"}, Nested: &patchNested{Value: "v"}},
+		},
+		{
+			name:        "unsupported media type",
+			contentType: echo.MIMEApplicationJSON,
+			body:        `{"name": "Ana"}`,
+			status:      http.StatusUnsupportedMediaType,
+			key:         "error.unsupported_media_type",
+		},
+		{
+			name:        "invalid merge patch",
+			contentType: MIMEMergePatch,
+			body:        `{"name":`,
+			status:      http.StatusBadRequest,
+			key:         "error.invalid_merge_patch",
+		},
+		{
+			name:        "bad pointer",
+			contentType: MIMEJSONPatch,
+			body:        `[{"op": "replace", "path": "name", "value": "Ana"}]`,
+			status:      http.StatusBadRequest,
+			key:         "error.patch_invalid_path",
+		},
+		{
+			name:        "missing path",
+			contentType: MIMEJSONPatch,
+			body:        `[{"op": "remove", "path": "/nested/child"}]`,
+			status:      http.StatusConflict,
+			key:         "error.patch_path_not_found",
+		},
+		{
+			name:        "failed test",
+			contentType: MIMEJSONPatch,
+			body:        `[{"op": "test", "path": "/name", "value": "Ana"}, {"op": "replace", "path": "/name", "value": "Ana"}]`,
+			status:      http.StatusConflict,
+			key:         "error.patch_test_failed",
+		},
+		{
+			name:        "out of range index",
+			contentType: MIMEJSONPatch,
+			body:        `[{"op": "add", "path": "/tags/3", "value": "x"}]`,
+			status:      http.StatusConflict,
+			key:         "error.patch_path_not_found",
+		},
+		{
+			name:        "index with leading zero",
+			contentType: MIMEJSONPatch,
+			body:        `[{"op": "remove", "path": "/tags/01"}]`,
+			status:      http.StatusConflict,
+			key:         "error.patch_path_not_found",
+		},
+		{
+			name:        "move into child",
+			contentType: MIMEJSONPatch,
+			body:        `[{"op": "move", "from": "/nested", "path": "/nested/child"}]`,
+			status:      http.StatusBadRequest,
+			key:         "error.patch_move_into_child",
+		},
+		{
+			name:        "missing value",
+			contentType: MIMEJSONPatch,
+			body:        `[{"op": "add", "path": "/name"}]`,
+			status:      http.StatusBadRequest,
+			key:         "error.patch_missing_value",
+		},
+		{
+			name:        "invalid operation",
+			contentType: MIMEJSONPatch,
+			body:        `[{"op": "merge", "path": "/name", "value": "Ana"}]`,
+			status:      http.StatusBadRequest,
+			key:         "error.patch_invalid_operation",
+		},
+		{
+			name:        "unknown field",
+			contentType: MIMEJSONPatch,
+			body:        `[{"op": "add", "path": "/email", "value": "ana@example.com"}]`,
+			status:      http.StatusBadRequest,
+			key:         "error.invalid_patched_document",
+		},
+		{
+			name:        "invalid patched document",
+			contentType: MIMEMergePatch,
+			body:        `{"name": null}`,
+			status:      http.StatusBadRequest,
+		},
+	}
+
+	e := echo.New()
+	e.Validator = NewValidationUtil()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(test.body))
+			request.Header.Set(echo.HeaderContentType, test.contentType)
+			c := e.NewContext(request, httptest.NewRecorder())
+
+			target := new(patchDocument)
+			err := BindPatch(c, currentPatchDocument(), target)
+			if test.status == 0 {
+				if err != nil {
+					t.Fatalf("BindPatch returned %v", err)
+				}
+				if !reflect.DeepEqual(target, test.want) {
+					t.Errorf("BindPatch patched %+v, want %+v", target, test.want)
+				}
+				return
+			}
+
+			var httpError *echo.HTTPError
+			if !errors.As(err, &httpError) || httpError.Code != test.status {
+				t.Fatalf("BindPatch returned %v, want status %d", err, test.status)
+			}
+			if message, ok := httpError.Message.(*i18n.Message); len(test.key) > 0 && (!ok || message.Key != test.key) {
+				t.Errorf("BindPatch returned %v, want %s", httpError.Message, test.key)
+			}
+		})
+	}
+}
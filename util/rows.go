@@ -0,0 +1,222 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	MIMETextCSV = "text/csv"
+	MIMENDJSON  = "application/x-ndjson"
+)
+
+const maxRowSize = 1024 * 1024
+
+// RowReader reads the records of a CSV or NDJSON request body one by one, as
+// the value of each column. An error on one row does not prevent reading the
+// next ones.
+type RowReader interface {
+	Read() (map[string]string, error)
+}
+
+// NewRowReader returns the reader of the request body, which must be CSV with
+// a header row or NDJSON objects. Only the given columns are accepted.
+func NewRowReader(c echo.Context, columns []string) (RowReader, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	switch mediaType {
+	case MIMETextCSV:
+		reader := csv.NewReader(c.Request().Body)
+		reader.TrimLeadingSpace = true
+		reader.LazyQuotes = true
+		header, err := reader.Read()
+		if err != nil {
+			return nil, exception.InvalidRequestException("error.invalid_csv_header", err.Error())
+		}
+		if len(header) > 0 {
+			// Spreadsheets often start UTF-8 files with a byte order mark.
+			header[0] = strings.TrimPrefix(header[0], "\ufeff")
+		}
+		for _, name := range header {
+			if !hasColumn(columns, name) {
+				return nil, exception.InvalidRequestException("error.unknown_column", name, strings.Join(columns, ", "))
+			}
+		}
+		return &csvRowReader{reader: reader, header: header}, nil
+	case MIMENDJSON, "application/ndjson":
+		scanner := bufio.NewScanner(c.Request().Body)
+		scanner.Buffer(make([]byte, 64*1024), maxRowSize)
+		return &ndjsonRowReader{scanner: scanner, columns: columns}, nil
+	default:
+		return nil, exception.UnsupportedMediaTypeException(MIMETextCSV, MIMENDJSON)
+	}
+}
+
+type csvRowReader struct {
+	reader *csv.Reader
+	header []string
+}
+
+func (rowReader *csvRowReader) Read() (map[string]string, error) {
+	record, err := rowReader.reader.Read()
+	if err == io.EOF {
+		return nil, err
+	}
+	if err != nil {
+		return nil, exception.InvalidRequestException("error.invalid_csv_row", err.Error())
+	}
+
+	row := make(map[string]string, len(record))
+	for i, value := range record {
+		row[rowReader.header[i]] = value
+	}
+	return row, nil
+}
+
+type ndjsonRowReader struct {
+	scanner *bufio.Scanner
+	columns []string
+}
+
+func (rowReader *ndjsonRowReader) Read() (map[string]string, error) {
+	var line []byte
+	for len(line) == 0 {
+		if !rowReader.scanner.Scan() {
+			if err := rowReader.scanner.Err(); err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		line = bytes.TrimSpace(rowReader.scanner.Bytes())
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, exception.InvalidRequestException("error.invalid_json", err.Error())
+	}
+
+	row := make(map[string]string, len(object))
+	for name, value := range object {
+		if !hasColumn(rowReader.columns, name) {
+			return nil, exception.InvalidRequestException("error.unknown_field", name)
+		}
+		switch v := value.(type) {
+		case nil:
+		case string:
+			row[name] = v
+		case json.Number:
+			row[name] = v.String()
+		case bool:
+			row[name] = strconv.FormatBool(v)
+		default:
+			return nil, exception.InvalidRequestException("error.field_not_scalar", name)
+		}
+	}
+	return row, nil
+}
+
+// RowWriter streams records as CSV or NDJSON.
+type RowWriter interface {
+	Write(values ...interface{}) error
+	Flush() error
+}
+
+// NewRowWriter starts a CSV or NDJSON download of name with the given
+// columns. The format is the format query parameter, csv or ndjson, and
+// otherwise NDJSON when the Accept header asks for it.
+func NewRowWriter(c echo.Context, name string, columns []string) (RowWriter, error) {
+	format := c.QueryParam("format")
+	if accept := c.Request().Header.Get(echo.HeaderAccept); len(format) == 0 && len(strings.TrimSpace(accept)) > 0 {
+		addVary(c, echo.HeaderAccept)
+		ranges := parseAccept(accept)
+		csvQuality, ndjsonQuality := acceptQuality(ranges, MIMETextCSV), acceptQuality(ranges, MIMENDJSON)
+		switch {
+		case ndjsonQuality > csvQuality:
+			format = "ndjson"
+		case csvQuality == 0:
+			return nil, exception.NotAcceptableException(MIMETextCSV, MIMENDJSON)
+		}
+	}
+	if len(format) == 0 {
+		format = "csv"
+	}
+
+	response := c.Response()
+	switch format {
+	case "csv":
+		response.Header().Set(echo.HeaderContentType, MIMETextCSV+"; charset=utf-8")
+		response.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", name+".csv"))
+		response.WriteHeader(http.StatusOK)
+		writer := csv.NewWriter(response)
+		if err := writer.Write(columns); err != nil {
+			return nil, err
+		}
+		return &csvRowWriter{writer: writer, response: response}, nil
+	case "ndjson":
+		response.Header().Set(echo.HeaderContentType, MIMENDJSON)
+		response.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", name+".ndjson"))
+		response.WriteHeader(http.StatusOK)
+		return &ndjsonRowWriter{encoder: json.NewEncoder(response), columns: columns, response: response}, nil
+	default:
+		return nil, exception.InvalidParameterException("format", format)
+	}
+}
+
+type csvRowWriter struct {
+	writer   *csv.Writer
+	response *echo.Response
+}
+
+func (rowWriter *csvRowWriter) Write(values ...interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = fmt.Sprint(value)
+	}
+	return rowWriter.writer.Write(record)
+}
+
+func (rowWriter *csvRowWriter) Flush() error {
+	rowWriter.writer.Flush()
+	rowWriter.response.Flush()
+	return rowWriter.writer.Error()
+}
+
+type ndjsonRowWriter struct {
+	encoder  *json.Encoder
+	columns  []string
+	response *echo.Response
+}
+
+func (rowWriter *ndjsonRowWriter) Write(values ...interface{}) error {
+	object := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		object[rowWriter.columns[i]] = value
+	}
+	return rowWriter.encoder.Encode(object)
+}
+
+func (rowWriter *ndjsonRowWriter) Flush() error {
+	rowWriter.response.Flush()
+	return nil
+}
+
+func hasColumn(columns []string, name string) bool {
+	for _, column := range columns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
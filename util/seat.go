@@ -0,0 +1,31 @@
+package util
+
+import "strconv"
+
+// SeatIds lists the seats of a room seat map. Each string of the map is a row,
+// front row first, where every character is a seat except '_', a gap. Rows are
+// lettered from A and seats numbered from 1, left to right.
+func SeatIds(seatMap []string) []string {
+	var seats []string
+	for i, row := range seatMap {
+		number := 0
+		for _, place := range row {
+			if place == '_' {
+				continue
+			}
+			number++
+			seats = append(seats, rowName(i)+strconv.Itoa(number))
+		}
+	}
+	return seats
+}
+
+// rowName returns the letters of the row at index: A to Z, then AA, AB...
+func rowName(index int) string {
+	name := string(rune('A' + index%26))
+	for index >= 26 {
+		index = index/26 - 1
+		name = string(rune('A'+index%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,42 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeText lowercases text, strips accents and collapses everything that
+// is not a letter or a digit into single spaces, so "Él Niño!" and "el nino"
+// compare equal.
+func NormalizeText(text string) string {
+	stripped, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), text)
+	if err != nil {
+		stripped = text
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(stripped), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// SearchWords returns the distinct words of texts once normalized. Stored in
+// an indexed array, they let a title be matched by the start of any of its
+// words.
+func SearchWords(texts ...string) []string {
+	words := []string{}
+	seen := map[string]bool{}
+	for _, text := range texts {
+		for _, word := range strings.Fields(NormalizeText(text)) {
+			if !seen[word] {
+				seen[word] = true
+				words = append(words, word)
+			}
+		}
+	}
+	return words
+}
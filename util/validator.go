@@ -1,7 +1,10 @@
 package util
 
 import (
+	"time"
+
 	"github.com/cbuelvasc/cinema-backend/exception"
+	"github.com/cbuelvasc/cinema-backend/model"
 	"github.com/go-playground/validator"
 	"github.com/labstack/echo/v4"
 )
@@ -11,7 +14,12 @@ type ValidationUtil struct {
 }
 
 func NewValidationUtil() echo.Validator {
-	return &ValidationUtil{validator: validator.New()}
+	validate := validator.New()
+	validate.RegisterTagNameFunc(exception.FieldName)
+	_ = validate.RegisterValidation("timezone", isTimeZone)
+	_ = validate.RegisterValidation("username", isUsername)
+	validate.RegisterStructValidation(validateRelease, model.MovieInput{})
+	return &ValidationUtil{validator: validate}
 }
 
 func (v *ValidationUtil) Validate(i interface{}) error {
@@ -19,12 +27,37 @@ func (v *ValidationUtil) Validate(i interface{}) error {
 }
 
 func BindAndValidate(c echo.Context, i interface{}) error {
-	if err := c.Bind(i); err != nil {
-		return exception.BadRequestException(err.Error())
+	if err := Bind(c, i); err != nil {
+		return err
 	}
 
 	if err := c.Validate(i); err != nil {
-		return exception.BadRequestException(err.Error())
+		return exception.ValidationException(err)
 	}
 	return nil
 }
+
+// isTimeZone validates IANA time zone names, such as America/Bogota.
+func isTimeZone(field validator.FieldLevel) bool {
+	_, err := model.LoadTimeZone(field.Field().String())
+	return err == nil
+}
+
+// isUsername validates usernames, which are written after @ in mentions: 1
+// to 15 letters, digits or underscores.
+func isUsername(field validator.FieldLevel) bool {
+	return model.UsernamePattern.MatchString(field.Field().String())
+}
+
+// validateRelease rejects a release day that is not in its month, such as
+// February 31, which the release date would otherwise roll over to March.
+func validateRelease(level validator.StructLevel) {
+	input, ok := level.Current().Interface().(model.MovieInput)
+	if !ok || input.ReleaseDate != nil || input.ReleaseYear <= 0 || input.ReleaseMonth < 1 || input.ReleaseDay < 1 {
+		return
+	}
+	released := time.Date(input.ReleaseYear, time.Month(input.ReleaseMonth), input.ReleaseDay, 0, 0, 0, 0, time.UTC)
+	if released.Day() != input.ReleaseDay {
+		level.ReportError(input.ReleaseDay, "releaseDay", "ReleaseDay", "invalid", "")
+	}
+}